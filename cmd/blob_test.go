@@ -0,0 +1,110 @@
+package cmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtypes"
+)
+
+func TestBlob(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	// Make sure datasets are by default in RowID order
+	sqtables.RowOrder = true
+
+	err := sq.ProcessSQFile("./testdata/blobtests.sq")
+	if err != nil {
+		t.Fatalf("Unable to load test data: %s", err)
+	}
+
+	data := []SelectData{
+		{
+			TestName: "Select blob columns",
+			Command:  "SELECT id, data, thumb FROM files",
+			ExpRows:  3,
+			ExpCols:  []string{"id", "data", "thumb"},
+			ExpVals:  sqtypes.RawVals{{1, []byte{0xDE, 0xAD, 0xBE, 0xEF}, []byte{}}, {2, []byte("hello"), []byte{1, 2}}, {3, nil, nil}},
+		},
+		{
+			TestName: "Compare with hex literal",
+			Command:  "SELECT id FROM files WHERE data = x'deadbeef'",
+			ExpRows:  1,
+			ExpCols:  []string{"id"},
+			ExpVals:  sqtypes.RawVals{{1}},
+		},
+		{
+			TestName: "Length",
+			Command:  "SELECT id, LENGTH(data), LENGTH(name), length(thumb) FROM files",
+			ExpRows:  3,
+			ExpCols:  []string{"id", "LENGTH(data)", "LENGTH(name)", "LENGTH(thumb)"},
+			ExpVals:  sqtypes.RawVals{{1, 4, 7, 0}, {2, 5, 9, 2}, {3, nil, 5, nil}},
+		},
+		{
+			TestName: "Length as a column name",
+			Command:  "SELECT id, length, LENGTH(data) FROM clips WHERE length > 40 ORDER BY length",
+			ExpRows:  1,
+			ExpCols:  []string{"id", "length", "LENGTH(data)"},
+			ExpVals:  sqtypes.RawVals{{2, 45, 3}},
+		},
+		{
+			TestName: "Substr",
+			Command:  "SELECT id, SUBSTR(data, 2, 2), SUBSTR(name, id + 1), substr(name, 1, LENGTH(name) - 4) FROM files WHERE id < 3",
+			ExpRows:  2,
+			ExpCols:  []string{"id", "SUBSTR(data, 2, 2)", "SUBSTR(name, (id+1))", "SUBSTR(name, 1, (LENGTH(name)-4))"},
+			ExpVals:  sqtypes.RawVals{{1, []byte{0xAD, 0xBE}, "ne.bin", "one"}, {2, []byte("el"), "llo.txt", "hello"}},
+		},
+		{
+			TestName: "Concatenate and convert",
+			Command:  "SELECT data + x'00', STRING(data), BLOB(name) FROM files WHERE id = 2",
+			ExpRows:  1,
+			ExpCols:  []string{"(data+x'00')", "STRING(data)", "BLOB(name)"},
+			ExpVals:  sqtypes.RawVals{{[]byte("hello\x00"), "hello", []byte("hello.txt")}},
+		},
+		{
+			TestName: "Constant functions",
+			Command:  "SELECT SUBSTR(\"abcdef\", 3), LENGTH(x'0102030405') FROM files WHERE id = 1",
+			ExpRows:  1,
+			ExpCols:  []string{"cdef", "5"},
+			ExpVals:  sqtypes.RawVals{{"cdef", 5}},
+		},
+		{
+			TestName: "Invalid hex literal",
+			Command:  "SELECT id FROM files WHERE data = x'ABC'",
+			ExpErr:   "Syntax Error: x'ABC' is not a valid hex value",
+		},
+		{
+			TestName: "Type Mismatch",
+			Command:  "SELECT id FROM files WHERE data = \"hello\"",
+			ExpErr:   "Error: Type Mismatch: hello is not a Blob",
+		},
+		{
+			TestName: "Length of Int",
+			Command:  "SELECT LENGTH(id) FROM files",
+			ExpErr:   "Error: LENGTH can only be used with STRING or BLOB values not INT",
+		},
+		{
+			TestName: "Substr missing start",
+			Command:  "SELECT SUBSTR(name) FROM files",
+			ExpErr:   "Syntax Error: Function SUBSTR is missing , after expression",
+		},
+		{
+			TestName: "Substr too many arguments",
+			Command:  "SELECT SUBSTR(name, 1, 2, 3) FROM files",
+			ExpErr:   "Syntax Error: Function SUBSTR has too many arguments",
+		},
+		{
+			TestName: "Substr aggregate argument",
+			Command:  "SELECT SUBSTR(name, 1, max(id)) FROM files",
+			ExpErr:   "Syntax Error: Aggregate functions are not allowed in the arguments of SUBSTR",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testSelectFunc(profile, row))
+
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// percentileFunc processes the rest of PERCENTILE_CONT or PERCENTILE_DISC after the (
+//   fraction) WITHIN GROUP (ORDER BY expr [ASC|DESC])
+func percentileFunc(tkns *tokens.TokenList, ftkn tokens.Token, distinct bool) (sqtables.Expr, error) {
+	var err error
+	var opts sqtables.AggregateOptions
+
+	cmd := ftkn.ID()
+	if distinct {
+		return nil, sqerr.NewSyntaxf("DISTINCT can not be used with %s", tokens.IDName(cmd))
+	}
+	opts.Param, err = constArg(tkns, cmd, "fraction", tokens.CloseBracket)
+	if err != nil {
+		return nil, err
+	}
+	fraction, err := opts.Param.Convert(tokens.Float)
+	if f, ok := fraction.(sqtypes.SQFloat); err != nil || !ok || f.Val < 0 || f.Val > 1 {
+		return nil, sqerr.NewSyntaxf("The fraction for %s must be a number between 0 and 1", tokens.IDName(cmd))
+	}
+	if !tkns.IsARemove(tokens.CloseBracket) {
+		return nil, sqerr.NewSyntaxf("Function %s is missing ) after expression", tokens.IDName(cmd))
+	}
+
+	if !tkns.IsARemove(tokens.Within) || !tkns.IsARemove(tokens.Group) || !tkns.IsARemove(tokens.OpenBracket) ||
+		!tkns.IsARemove(tokens.Order) {
+		return nil, sqerr.NewSyntaxf("Expecting WITHIN GROUP (ORDER BY after %s", tokens.IDName(cmd))
+	}
+	opts.OrderBy, err = orderExprList(tkns)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.OrderBy) != 1 {
+		return nil, sqerr.NewSyntaxf("WITHIN GROUP of %s must have one ORDER BY expression", tokens.IDName(cmd))
+	}
+	if !tkns.IsARemove(tokens.CloseBracket) {
+		return nil, sqerr.NewSyntaxf("Expecting ) after WITHIN GROUP of %s", tokens.IDName(cmd))
+	}
+	return funcExpr(tkns, ftkn, []sqtables.Expr{opts.OrderBy[0].Exp}, opts)
+}
+
+// stringAggArgs processes the , separator [ORDER BY expr [ASC|DESC], ...] that follows the expression of STRING_AGG
+func stringAggArgs(tkns *tokens.TokenList, opts *sqtables.AggregateOptions) error {
+	var err error
+
+	if !tkns.IsARemove(tokens.Comma) {
+		return sqerr.NewSyntax("Function STRING_AGG is missing a separator")
+	}
+	opts.Param, err = constArg(tkns, tokens.StringAgg, "separator", tokens.Order, tokens.CloseBracket)
+	if err != nil {
+		return err
+	}
+	if tkns.IsARemove(tokens.Order) {
+		opts.OrderBy, err = orderExprList(tkns)
+	}
+	return err
+}
+
+// constArg processes an argument of a function that must be a constant value
+func constArg(tkns *tokens.TokenList, cmd tokens.TokenID, argName string, terminators ...tokens.TokenID) (sqtypes.Value, error) {
+	exp, err := GetExpr(tkns, nil, 0, terminators...)
+	if err != nil {
+		return nil, err
+	}
+	if exp == nil {
+		return nil, sqerr.NewSyntaxf("Function %s is missing a %s", tokens.IDName(cmd), argName)
+	}
+	exp, err = exp.Reduce()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := exp.(*sqtables.ValueExpr); !ok {
+		return nil, sqerr.NewSyntaxf("The %s for %s must be a constant", argName, tokens.IDName(cmd))
+	}
+	return exp.Evaluate(nil, false)
+}
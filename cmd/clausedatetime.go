@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// typedLiteral processes a type followed by a quoted string such as DATE "2020-01-31". The string is
+//   converted to a value of the type
+func typedLiteral(tkns *tokens.TokenList) (sqtables.Expr, error) {
+	typeID := tkns.Peek().ID()
+	tkns.Remove()
+	str := tkns.Peek().(*tokens.ValueToken).Value()
+	tkns.Remove()
+	v, err := sqtypes.NewSQString(str).Convert(typeID)
+	if err != nil {
+		return nil, err
+	}
+	return sqtables.NewValueExpr(v), nil
+}
+
+// extractFunc processes the rest of EXTRACT after the ( field FROM expr)
+func extractFunc(tkns *tokens.TokenList, ftkn tokens.Token) (sqtables.Expr, error) {
+	tkn := tkns.TestTkn(tokens.Ident)
+	if tkn == nil || !sqtypes.IsExtractField(tkn.(*tokens.ValueToken).Value()) {
+		return nil, sqerr.NewSyntax("EXTRACT must have a field of YEAR, QUARTER, MONTH, WEEK, DAY, DOW, DOY, HOUR, MINUTE, SECOND or EPOCH")
+	}
+	field := strings.ToUpper(tkn.(*tokens.ValueToken).Value())
+	tkns.Remove()
+	if !tkns.IsARemove(tokens.From) {
+		return nil, sqerr.NewSyntax("Expecting FROM after the field of EXTRACT")
+	}
+	return dateFuncArg(tkns, ftkn, sqtypes.NewSQString(field))
+}
+
+// dateTruncFunc processes the rest of DATE_TRUNC after the ( unit, expr)
+func dateTruncFunc(tkns *tokens.TokenList, ftkn tokens.Token) (sqtables.Expr, error) {
+	unit, err := constArg(tkns, tokens.DateTrunc, "unit", tokens.Comma, tokens.CloseBracket)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := unit.(sqtypes.SQString); !ok || !sqtypes.IsTruncUnit(unit.String()) {
+		return nil, sqerr.NewSyntaxf("%s is not a valid unit for DATE_TRUNC", unit.String())
+	}
+	if !tkns.IsARemove(tokens.Comma) {
+		return nil, sqerr.NewSyntax("Function DATE_TRUNC is missing , after the unit")
+	}
+	return dateFuncArg(tkns, ftkn, unit)
+}
+
+// dateFuncArg processes the expression and closing bracket of EXTRACT and DATE_TRUNC
+func dateFuncArg(tkns *tokens.TokenList, ftkn tokens.Token, param sqtypes.Value) (sqtables.Expr, error) {
+	exp, err := GetExpr(tkns, nil, 0, tokens.CloseBracket)
+	if err != nil {
+		return nil, err
+	}
+	if exp == nil {
+		return nil, sqerr.NewSyntaxf("Function %s is missing an expression", ftkn.Name())
+	}
+	if !tkns.IsARemove(tokens.CloseBracket) {
+		return nil, sqerr.NewSyntaxf("Function %s is missing ) after expression", ftkn.Name())
+	}
+	return funcExpr(tkns, ftkn, []sqtables.Expr{exp}, sqtables.AggregateOptions{Param: param})
+}
@@ -0,0 +1,238 @@
+package cmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// dtVal converts a string to a date/time value for the expected results of a test
+func dtVal(s string, typeID tokens.TokenID) sqtypes.Value {
+	v, err := sqtypes.NewSQString(s).Convert(typeID)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestDateTime(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	// Make sure datasets are by default in RowID order
+	sqtables.RowOrder = true
+
+	err := sq.ProcessSQFile("./testdata/datetimetests.sq")
+	if err != nil {
+		t.Fatalf("Unable to load test data: %s", err)
+	}
+
+	data := []SelectData{
+		{
+			TestName: "Select temporal columns",
+			Command:  "SELECT day, start, stamp, duration FROM events WHERE id = 3",
+			ExpRows:  1,
+			ExpCols:  []string{"day", "start", "stamp", "duration"},
+			ExpVals: sqtypes.RawVals{
+				{dtVal("2020-03-15", tokens.Date), dtVal("16:45:30", tokens.Time), dtVal("2020-03-15 16:45:30.5", tokens.Timestamp), dtVal("45 minutes", tokens.Interval)},
+			},
+		},
+		{
+			TestName: "Compare with typed literal",
+			Command:  "SELECT id, day > DATE \"2020-02-01\" FROM events WHERE id < 4",
+			ExpRows:  3,
+			ExpCols:  []string{"id", "(day>2020-02-01)"},
+			ExpVals:  sqtypes.RawVals{{1, false}, {2, true}, {3, true}},
+		},
+		{
+			TestName: "Order by date",
+			Command:  "SELECT id, day FROM events ORDER BY day DESC",
+			ExpRows:  4,
+			ExpCols:  []string{"id", "day"},
+			ExpVals:  sqtypes.RawVals{{4, nil}, {3, dtVal("2020-03-15", tokens.Date)}, {2, dtVal("2020-02-29", tokens.Date)}, {1, dtVal("2020-01-31", tokens.Date)}},
+		},
+		{
+			TestName: "Date arithmetic",
+			Command:  "SELECT day + 1, day - DATE \"2020-01-01\", day + INTERVAL \"1 month\" FROM events WHERE id = 1",
+			ExpRows:  1,
+			ExpCols:  []string{"(day+1)", "(day-2020-01-01)", "(day+1 mon)"},
+			ExpVals:  sqtypes.RawVals{{dtVal("2020-02-01", tokens.Date), 30, dtVal("2020-02-29", tokens.Timestamp)}},
+		},
+		{
+			TestName: "Timestamp and time arithmetic",
+			Command:  "SELECT stamp + duration, stamp - TIMESTAMP \"2020-02-28 12:00\", start - INTERVAL \"10:00\", -duration FROM events WHERE id = 2",
+			ExpRows:  1,
+			ExpCols:  []string{"(stamp+duration)", "(stamp-2020-02-28 12:00:00)", "(start-10:00:00)", "(-duration)"},
+			ExpVals: sqtypes.RawVals{
+				{dtVal("2020-03-01 14:30", tokens.Timestamp), dtVal("1 day 02:00", tokens.Interval), dtVal("04:00", tokens.Time), dtVal("-1 day -00:30", tokens.Interval)},
+			},
+		},
+		{
+			TestName: "Interval arithmetic",
+			Command:  "SELECT duration * 2, duration / 3, duration + INTERVAL \"1 hour\" FROM events WHERE id = 1",
+			ExpRows:  1,
+			ExpCols:  []string{"(duration*2)", "(duration/3)", "(duration+01:00:00)"},
+			ExpVals:  sqtypes.RawVals{{dtVal("4 hours", tokens.Interval), dtVal("40 minutes", tokens.Interval), dtVal("3 hours", tokens.Interval)}},
+		},
+		{
+			TestName: "Extract",
+			Command:  "SELECT id, EXTRACT(year FROM day), EXTRACT(month FROM stamp), EXTRACT(hour FROM start), EXTRACT(second FROM stamp), EXTRACT(minute FROM duration) FROM events",
+			ExpRows:  4,
+			ExpCols:  []string{"id", "EXTRACT(YEAR FROM day)", "EXTRACT(MONTH FROM stamp)", "EXTRACT(HOUR FROM start)", "EXTRACT(SECOND FROM stamp)", "EXTRACT(MINUTE FROM duration)"},
+			ExpVals: sqtypes.RawVals{
+				{1, 2020, 1, 9, 0.0, 0},
+				{2, 2020, 2, 14, 0.0, 30},
+				{3, 2020, 3, 16, 30.5, 45},
+				{4, nil, nil, nil, nil, nil},
+			},
+		},
+		{
+			TestName: "Date Trunc",
+			Command:  "SELECT id, DATE_TRUNC(\"month\", stamp), date_trunc(\"week\", day) FROM events WHERE id < 4",
+			ExpRows:  3,
+			ExpCols:  []string{"id", "DATE_TRUNC(month, stamp)", "DATE_TRUNC(week, day)"},
+			ExpVals: sqtypes.RawVals{
+				{1, dtVal("2020-01-01", tokens.Timestamp), dtVal("2020-01-27", tokens.Timestamp)},
+				{2, dtVal("2020-02-01", tokens.Timestamp), dtVal("2020-02-24", tokens.Timestamp)},
+				{3, dtVal("2020-03-01", tokens.Timestamp), dtVal("2020-03-09", tokens.Timestamp)},
+			},
+		},
+		{
+			TestName: "Min and Max",
+			Command:  "SELECT min(day), max(stamp), max(duration) FROM events",
+			ExpRows:  1,
+			ExpCols:  []string{"MIN(day)", "MAX(stamp)", "MAX(duration)"},
+			ExpVals:  sqtypes.RawVals{{dtVal("2020-01-31", tokens.Date), dtVal("2020-03-15 16:45:30.5", tokens.Timestamp), dtVal("1 day 30 minutes", tokens.Interval)}},
+		},
+		{
+			TestName: "Now and Current Date",
+			Command:  "SELECT now() > stamp, CURRENT_DATE > day, EXTRACT(year FROM CURRENT_DATE) >= 2020 FROM events WHERE id = 1",
+			ExpRows:  1,
+			ExpCols:  []string{"(NOW()>stamp)", "(CURRENT_DATE>day)", "(EXTRACT(YEAR FROM CURRENT_DATE)>=2020)"},
+			ExpVals:  sqtypes.RawVals{{true, true, true}},
+		},
+		{
+			TestName: "Conversion functions",
+			Command:  "SELECT DATE(stamp), TIME(stamp), TIMESTAMP(day), STRING(stamp), DATE(\"2021-12-25\") FROM events WHERE id = 3",
+			ExpRows:  1,
+			ExpCols:  []string{"DATE(stamp)", "TIME(stamp)", "TIMESTAMP(day)", "STRING(stamp)", "2021-12-25"},
+			ExpVals: sqtypes.RawVals{
+				{dtVal("2020-03-15", tokens.Date), dtVal("16:45:30.5", tokens.Time), dtVal("2020-03-15", tokens.Timestamp), "2020-03-15 16:45:30.5", dtVal("2021-12-25", tokens.Date)},
+			},
+		},
+		{
+			TestName: "Type names as column names",
+			Command:  "SELECT date, time, timestamp, interval FROM schedule WHERE date = DATE \"2020-01-31\"",
+			ExpRows:  1,
+			ExpCols:  []string{"date", "time", "timestamp", "interval"},
+			ExpVals: sqtypes.RawVals{
+				{dtVal("2020-01-31", tokens.Date), dtVal("09:30", tokens.Time), dtVal("2020-01-31 09:30:00", tokens.Timestamp), dtVal("2 hours", tokens.Interval)},
+			},
+		},
+		{
+			TestName: "Type name columns in functions",
+			Command:  "SELECT DATE(timestamp), schedule.time FROM schedule WHERE interval < INTERVAL \"1 hour\"",
+			ExpRows:  1,
+			ExpCols:  []string{"DATE(timestamp)", "schedule.time"},
+			ExpVals:  sqtypes.RawVals{{dtVal("2020-02-01", tokens.Date), dtVal("10:00", tokens.Time)}},
+		},
+		{
+			TestName: "Invalid typed literal",
+			Command:  "SELECT id FROM events WHERE day = DATE \"2020-13-01\"",
+			ExpErr:   "Error: Unable to Convert \"2020-13-01\" to a DATE",
+		},
+		{
+			TestName: "Compare with string literal",
+			Command:  "SELECT id FROM events WHERE id < 4 AND day = \"2020-01-31\"",
+			ExpRows:  1,
+			ExpCols:  []string{"id"},
+			ExpVals:  sqtypes.RawVals{{1}},
+		},
+		{
+			TestName: "Compare string literal with columns",
+			Command:  "SELECT id FROM events WHERE \"2020-02-15\" < day AND start <= \"14:00\" AND stamp != \"2020-03-15 16:45:30.5\" AND duration > \"30 minutes\"",
+			ExpRows:  1,
+			ExpCols:  []string{"id"},
+			ExpVals:  sqtypes.RawVals{{2}},
+		},
+		{
+			TestName: "Insert string literals",
+			Command:  "SELECT id, day, start, stamp, duration FROM holidays",
+			ExpRows:  2,
+			ExpCols:  []string{"id", "day", "start", "stamp", "duration"},
+			ExpVals: sqtypes.RawVals{
+				{1, dtVal("2020-12-25", tokens.Date), dtVal("08:00", tokens.Time), dtVal("2020-12-25 08:00", tokens.Timestamp), dtVal("1 day", tokens.Interval)},
+				{2, dtVal("2021-01-01", tokens.Date), dtVal("00:00", tokens.Time), dtVal("2021-01-01 00:00", tokens.Timestamp), dtVal("12 hours", tokens.Interval)},
+			},
+		},
+		{
+			TestName: "Invalid string literal",
+			Command:  "SELECT id FROM events WHERE stamp > \"soon\"",
+			ExpErr:   "Error: Unable to Convert \"soon\" to a TIMESTAMP",
+		},
+		{
+			TestName: "Type Mismatch",
+			Command:  "SELECT id FROM events WHERE id < 4 AND day + 0 = \"2020-01-31\"",
+			ExpErr:   "Error: Type Mismatch: 2020-01-31 is not a Date",
+		},
+		{
+			TestName: "Extract invalid field",
+			Command:  "SELECT EXTRACT(century FROM day) FROM events",
+			ExpErr:   "Syntax Error: EXTRACT must have a field of YEAR, QUARTER, MONTH, WEEK, DAY, DOW, DOY, HOUR, MINUTE, SECOND or EPOCH",
+		},
+		{
+			TestName: "Extract missing FROM",
+			Command:  "SELECT EXTRACT(year day) FROM events",
+			ExpErr:   "Syntax Error: Expecting FROM after the field of EXTRACT",
+		},
+		{
+			TestName: "Extract missing expression",
+			Command:  "SELECT EXTRACT(year FROM) FROM events",
+			ExpErr:   "Syntax Error: Function EXTRACT is missing an expression",
+		},
+		{
+			TestName: "Extract missing )",
+			Command:  "SELECT EXTRACT(year FROM day FROM events",
+			ExpErr:   "Syntax Error: Function EXTRACT is missing ) after expression",
+		},
+		{
+			TestName: "Extract from Int",
+			Command:  "SELECT EXTRACT(year FROM id) FROM events",
+			ExpErr:   "Error: EXTRACT can only be used with DATE, TIME, TIMESTAMP or INTERVAL values not INT",
+		},
+		{
+			TestName: "Extract over",
+			Command:  "SELECT EXTRACT(year FROM day) OVER () FROM events",
+			ExpErr:   "Syntax Error: EXTRACT can not be used as a window function",
+		},
+		{
+			TestName: "Date Trunc invalid unit",
+			Command:  "SELECT DATE_TRUNC(\"century\", day) FROM events",
+			ExpErr:   "Syntax Error: century is not a valid unit for DATE_TRUNC",
+		},
+		{
+			TestName: "Date Trunc unit not constant",
+			Command:  "SELECT DATE_TRUNC(name, day) FROM events",
+			ExpErr:   "Syntax Error: The unit for DATE_TRUNC must be a constant",
+		},
+		{
+			TestName: "Date Trunc missing comma",
+			Command:  "SELECT DATE_TRUNC(\"day\") FROM events",
+			ExpErr:   "Syntax Error: Function DATE_TRUNC is missing , after the unit",
+		},
+		{
+			TestName: "Date Trunc of time",
+			Command:  "SELECT DATE_TRUNC(\"day\", start) FROM events",
+			ExpErr:   "Error: DATE_TRUNC can only be used with DATE or TIMESTAMP values not TIME",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testSelectFunc(profile, row))
+
+	}
+}
@@ -6,27 +6,224 @@ import (
 	"github.com/wilphi/sqsrv/tokens"
 )
 
-//GroupByClause processing
-func GroupByClause(tkns *tokens.TokenList) (*sqtables.ExprList, error) {
+//GroupByClause processing. Each item of the GROUP BY is an expression, ROLLUP (expr, ...), CUBE (expr, ...)
+//   or GROUPING SETS (set, ...). All of the expressions are returned with the grouping sets as indexes into the
+//   expression list. If only expressions are used then there is a single grouping set and nil is returned
+func GroupByClause(tkns *tokens.TokenList) (*sqtables.ExprList, [][]int, error) {
+	var eList sqtables.ExprList
+	var err error
 
 	if tkns.IsA(tokens.Group) {
 		tkns.Remove()
 	}
 	if !tkns.IsA(tokens.By) {
-		return nil, sqerr.NewSyntax("GROUP missing BY")
+		return nil, nil, sqerr.NewSyntax("GROUP missing BY")
 	}
 	tkns.Remove()
 
-	eList, err := GetExprList(tkns, tokens.NilToken, tokens.Group)
-	if err != nil {
-		return nil, err
+	// The grouping sets of the items are combined so there is a set for each combination
+	sets := [][]int{{}}
+	hasSets := false
+	for {
+		var itemSets [][]int
+		switch {
+		case tkns.IsAKeyword("ROLLUP") || tkns.IsAKeyword("CUBE"):
+			isCube := tkns.IsAKeyword("CUBE")
+			tkns.Remove()
+			hasSets = true
+			var cols []int
+			cols, err = groupingList(tkns, &eList)
+			if err != nil {
+				return nil, nil, err
+			}
+			if isCube {
+				itemSets = cubeSets(cols)
+			} else {
+				itemSets = rollupSets(cols)
+			}
+		case tkns.IsARemove(tokens.Grouping):
+			hasSets = true
+			itemSets, err = groupingSets(tkns, &eList)
+			if err != nil {
+				return nil, nil, err
+			}
+		default:
+			var idx int
+			idx, err = groupingExpr(tkns, &eList)
+			if err != nil {
+				return nil, nil, err
+			}
+			if idx < 0 {
+				break
+			}
+			itemSets = [][]int{{idx}}
+		}
+		if itemSets == nil {
+			break
+		}
+		sets = crossSets(sets, itemSets)
+		if !tkns.IsARemove(tokens.Comma) {
+			break
+		}
+	}
+
+	if eList.Len() <= 0 {
+		return nil, nil, sqerr.NewSyntax("No expressions defined for GROUP BY clause")
+	}
+	if !tkns.IsEmpty() && !tkns.IsReservedWord() {
+		return nil, nil, sqerr.NewSyntax("Comma is required to separate expressions")
 	}
 
 	if eList.HasAggregateFunc() {
 		flist, _ := eList.FindAggregateFuncs()
 		expr := flist[0]
-		return nil, sqerr.NewSyntaxf("GROUP BY clause expression can't contain aggregate functions: %s", expr.Name())
+		return nil, nil, sqerr.NewSyntaxf("GROUP BY clause expression can't contain aggregate functions: %s", expr.Name())
+	}
+	if !hasSets {
+		sets = nil
+	}
+	return &eList, sets, nil
+
+}
+
+// groupingSets processes the SETS (set, ...) that follows GROUPING. Each set is an expression or a list of
+//   expressions in brackets
+func groupingSets(tkns *tokens.TokenList, eList *sqtables.ExprList) ([][]int, error) {
+	var sets [][]int
+
+	if !tkns.IsAKeywordRemove("SETS") {
+		return nil, sqerr.NewSyntax("Expecting SETS after GROUPING")
 	}
-	return eList, nil
+	if !tkns.IsARemove(tokens.OpenBracket) {
+		return nil, sqerr.NewSyntax("Expecting ( after GROUPING SETS")
+	}
+	for {
+		var set []int
+		if tkns.IsA(tokens.OpenBracket) {
+			cols, err := groupingList(tkns, eList)
+			if err != nil {
+				return nil, err
+			}
+			set = cols
+		} else {
+			idx, err := groupingExpr(tkns, eList)
+			if err != nil {
+				return nil, err
+			}
+			if idx < 0 {
+				return nil, sqerr.NewSyntax("Expecting an expression or ( in GROUPING SETS")
+			}
+			set = []int{idx}
+		}
+		sets = append(sets, set)
+		if !tkns.IsARemove(tokens.Comma) {
+			break
+		}
+	}
+	if !tkns.IsARemove(tokens.CloseBracket) {
+		return nil, sqerr.NewSyntax("Expecting ) to end GROUPING SETS")
+	}
+	return sets, nil
+}
+
+// groupingList processes a list of expressions in brackets. The list may be empty
+func groupingList(tkns *tokens.TokenList, eList *sqtables.ExprList) ([]int, error) {
+	var cols []int
 
+	if !tkns.IsARemove(tokens.OpenBracket) {
+		return nil, sqerr.NewSyntax("Expecting ( to start a list of GROUP BY expressions")
+	}
+	if tkns.IsARemove(tokens.CloseBracket) {
+		return cols, nil
+	}
+	for {
+		idx, err := groupingExpr(tkns, eList)
+		if err != nil {
+			return nil, err
+		}
+		if idx < 0 {
+			return nil, sqerr.NewSyntax("Expecting an expression in list of GROUP BY expressions")
+		}
+		cols = append(cols, idx)
+		if !tkns.IsARemove(tokens.Comma) {
+			break
+		}
+	}
+	if !tkns.IsARemove(tokens.CloseBracket) {
+		return nil, sqerr.NewSyntax("Expecting ) to end a list of GROUP BY expressions")
+	}
+	return cols, nil
+}
+
+// groupingExpr processes a GROUP BY expression and returns its index in the expression list. The expression
+//   is only added to the list once. If there is no expression then -1 is returned
+func groupingExpr(tkns *tokens.TokenList, eList *sqtables.ExprList) (int, error) {
+	exp, err := GetExpr(tkns, nil, 1, tokens.Comma, tokens.CloseBracket)
+	if err != nil {
+		return -1, err
+	}
+	if exp == nil {
+		return -1, nil
+	}
+	exp, err = exp.Reduce()
+	if err != nil {
+		return -1, err
+	}
+	if idx := eList.FindName(exp.Name()); idx >= 0 {
+		return idx, nil
+	}
+	return eList.Add(exp), nil
+}
+
+// rollupSets returns the grouping sets for ROLLUP. There is a set for each leading part of the list
+//   starting with the whole list and ending with the empty set
+func rollupSets(cols []int) [][]int {
+	sets := make([][]int, 0, len(cols)+1)
+	for i := len(cols); i >= 0; i-- {
+		sets = append(sets, cols[:i])
+	}
+	return sets
+}
+
+// cubeSets returns the grouping sets for CUBE. There is a set for every combination of the list
+func cubeSets(cols []int) [][]int {
+	n := len(cols)
+	sets := make([][]int, 0, 1<<n)
+	for mask := 1<<n - 1; mask >= 0; mask-- {
+		set := []int{}
+		for i, col := range cols {
+			if mask&(1<<(n-1-i)) != 0 {
+				set = append(set, col)
+			}
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// crossSets combines every set in a with every set in b
+func crossSets(a, b [][]int) [][]int {
+	var sets [][]int
+	for _, setA := range a {
+		for _, setB := range b {
+			set := append([]int{}, setA...)
+			for _, col := range setB {
+				if !containsInt(set, col) {
+					set = append(set, col)
+				}
+			}
+			sets = append(sets, set)
+		}
+	}
+	return sets
+}
+
+// containsInt returns true if the list contains the value
+func containsInt(list []int, val int) bool {
+	for _, v := range list {
+		if v == val {
+			return true
+		}
+	}
+	return false
 }
@@ -8,6 +8,8 @@ import (
 	"github.com/wilphi/sqsrv/cmd"
 	"github.com/wilphi/sqsrv/sqprofile"
 	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtables/column"
+	"github.com/wilphi/sqsrv/sqtables/moniker"
 	"github.com/wilphi/sqsrv/sqtest"
 	"github.com/wilphi/sqsrv/tokens"
 )
@@ -24,7 +26,7 @@ func testGroupByFunc(profile *sqprofile.SQProfile, d GroupByData) func(*testing.
 
 		tkns := tokens.Tokenize(d.Command)
 
-		eList, err := cmd.GroupByClause(tkns)
+		eList, sets, err := cmd.GroupByClause(tkns)
 		if sqtest.CheckErr(t, err, d.ExpErr) {
 			return
 		}
@@ -34,6 +36,9 @@ func testGroupByFunc(profile *sqprofile.SQProfile, d GroupByData) func(*testing.
 				t.Errorf("Expected Order By expressions do not match actual expressions\n\tExpect: %s\n\tActual: %s", d.ExpEList.String(), eList.String())
 			}
 		}
+		if !reflect.DeepEqual(sets, d.ExpSets) {
+			t.Errorf("Expected grouping sets %v do not match actual grouping sets %v", d.ExpSets, sets)
+		}
 	}
 }
 
@@ -42,6 +47,7 @@ type GroupByData struct {
 	Command  string
 	ExpErr   string
 	ExpEList *sqtables.ExprList
+	ExpSets  [][]int
 }
 
 func TestGroupBy(t *testing.T) {
@@ -49,6 +55,10 @@ func TestGroupBy(t *testing.T) {
 	//make sure table exists for testing
 	profile := sqprofile.CreateSQProfile()
 
+	col1Exp := sqtables.NewColExpr(column.Ref{ColName: "col1", TableName: moniker.New("", "")})
+	col2Exp := sqtables.NewColExpr(column.Ref{ColName: "col2", TableName: moniker.New("", "")})
+	col3Exp := sqtables.NewColExpr(column.Ref{ColName: "col3", TableName: moniker.New("", "")})
+
 	data := []GroupByData{
 		{
 			TestName: "Empty string",
@@ -95,6 +105,80 @@ func TestGroupBy(t *testing.T) {
 			Command:  "GROUP By col1, avg(col2)",
 			ExpErr:   "Syntax Error: GROUP BY clause expression can't contain aggregate functions: AVG(col2)",
 		},
+		{
+			TestName: "GROUP By ROLLUP",
+			Command:  "GROUP By ROLLUP(col1, col2)",
+			ExpEList: sqtables.NewExprList(col1Exp, col2Exp),
+			ExpSets:  [][]int{{0, 1}, {0}, {}},
+		},
+		{
+			TestName: "GROUP By CUBE",
+			Command:  "GROUP By CUBE(col1, col2)",
+			ExpEList: sqtables.NewExprList(col1Exp, col2Exp),
+			ExpSets:  [][]int{{0, 1}, {0}, {1}, {}},
+		},
+		{
+			TestName: "GROUP By GROUPING SETS",
+			Command:  "GROUP By GROUPING SETS ((col1), col2, (col1, col2), ())",
+			ExpEList: sqtables.NewExprList(col1Exp, col2Exp),
+			ExpSets:  [][]int{{0}, {1}, {0, 1}, {}},
+		},
+		{
+			TestName: "GROUP By col and ROLLUP",
+			Command:  "GROUP By col3, ROLLUP(col1, col3)",
+			ExpEList: sqtables.NewExprList(col3Exp, col1Exp),
+			ExpSets:  [][]int{{0, 1}, {0, 1}, {0}},
+		},
+		{
+			TestName: "GROUP By duplicate col",
+			Command:  "GROUP By col1, col2, col1",
+			ExpEList: sqtables.NewExprList(col1Exp, col2Exp),
+		},
+		{
+			TestName: "GROUP By ROLLUP missing (",
+			Command:  "GROUP By ROLLUP col1",
+			ExpErr:   "Syntax Error: Expecting ( to start a list of GROUP BY expressions",
+		},
+		{
+			TestName: "GROUP By ROLLUP missing )",
+			Command:  "GROUP By ROLLUP(col1, col2 HAVING",
+			ExpErr:   "Syntax Error: Expecting ) to end a list of GROUP BY expressions",
+		},
+		{
+			TestName: "GROUP By ROLLUP empty expression",
+			Command:  "GROUP By ROLLUP(col1, )",
+			ExpErr:   "Syntax Error: Expecting an expression in list of GROUP BY expressions",
+		},
+		{
+			TestName: "GROUP By GROUPING missing SETS",
+			Command:  "GROUP By GROUPING (col1)",
+			ExpErr:   "Syntax Error: Expecting SETS after GROUPING",
+		},
+		{
+			TestName: "GROUP By GROUPING SETS missing (",
+			Command:  "GROUP By GROUPING SETS col1",
+			ExpErr:   "Syntax Error: Expecting ( after GROUPING SETS",
+		},
+		{
+			TestName: "GROUP By GROUPING SETS missing )",
+			Command:  "GROUP By GROUPING SETS ((col1), (col2)",
+			ExpErr:   "Syntax Error: Expecting ) to end GROUPING SETS",
+		},
+		{
+			TestName: "GROUP By GROUPING SETS empty set",
+			Command:  "GROUP By GROUPING SETS (, col1)",
+			ExpErr:   "Syntax Error: Expecting an expression or ( in GROUPING SETS",
+		},
+		{
+			TestName: "GROUP By missing comma",
+			Command:  "GROUP By col1 col2",
+			ExpErr:   "Syntax Error: Comma is required to separate expressions",
+		},
+		{
+			TestName: "GROUP By ROLLUP aggregate",
+			Command:  "GROUP By ROLLUP(col1, count())",
+			ExpErr:   "Syntax Error: GROUP BY clause expression can't contain aggregate functions: COUNT()",
+		},
 	}
 
 	for i, row := range data {
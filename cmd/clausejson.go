@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// jsonPathArg processes the , path that follows the expression of JSON_EXTRACT
+func jsonPathArg(tkns *tokens.TokenList) (sqtypes.Value, error) {
+	if !tkns.IsARemove(tokens.Comma) {
+		return nil, sqerr.NewSyntax("Function JSON_EXTRACT is missing , after expression")
+	}
+	path, err := constArg(tkns, tokens.JSONExtract, "path", tokens.CloseBracket)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := path.(sqtypes.SQString); !ok || !sqtypes.IsJSONPath(path.String()) {
+		return nil, sqerr.NewSyntaxf("%s is not a valid path for JSON_EXTRACT", path.String())
+	}
+	return path, nil
+}
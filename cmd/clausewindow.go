@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// windowFunc creates the expression for a function that is followed by an OVER clause
+func windowFunc(tkns *tokens.TokenList, ftkn tokens.Token, args []sqtables.Expr) (sqtables.Expr, error) {
+	cmd := ftkn.ID()
+	if !ftkn.TestFlags(tokens.IsWindow) && !ftkn.TestFlags(tokens.IsAggregate) {
+		return nil, sqerr.NewSyntaxf("%s can not be used as a window function", tokens.IDName(cmd))
+	}
+
+	over, err := OverClause(tkns)
+	if err != nil {
+		return nil, err
+	}
+	return sqtables.NewWindowExpr(cmd, args, over), nil
+}
+
+// OverClause processes OVER ([PARTITION BY expr, ...] [ORDER BY expr [ASC|DESC], ...] [frame])
+func OverClause(tkns *tokens.TokenList) (*sqtables.Window, error) {
+	var err error
+	var exp sqtables.Expr
+	var over sqtables.Window
+
+	tkns.IsARemove(tokens.Over)
+	if !tkns.IsARemove(tokens.OpenBracket) {
+		return nil, sqerr.NewSyntax("Expecting ( after OVER")
+	}
+
+	if tkns.IsAKeywordRemove("PARTITION") {
+		if !tkns.IsARemove(tokens.By) {
+			return nil, sqerr.NewSyntax("PARTITION missing BY")
+		}
+		for {
+			exp, err = GetExpr(tkns, nil, 0, tokens.Comma, tokens.Order, tokens.CloseBracket)
+			if err != nil {
+				return nil, err
+			}
+			if exp == nil {
+				return nil, sqerr.NewSyntax("Expecting an expression in PARTITION BY")
+			}
+			over.PartitionBy = append(over.PartitionBy, exp)
+			if !tkns.IsARemove(tokens.Comma) {
+				break
+			}
+		}
+	}
+
+	if tkns.IsARemove(tokens.Order) {
+		over.OrderBy, err = orderExprList(tkns)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if tkns.IsAKeyword("ROWS") || tkns.IsAKeyword("RANGE") {
+		over.Frame, err = frameClause(tkns)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !tkns.IsARemove(tokens.CloseBracket) {
+		return nil, sqerr.NewSyntax("Expecting ) to end OVER clause")
+	}
+	return &over, nil
+}
+
+// orderExprList processes the BY expr [ASC|DESC], ... that follows ORDER in a window or an aggregate function.
+//   The list must be followed by )
+func orderExprList(tkns *tokens.TokenList) ([]sqtables.OrderExpr, error) {
+	var orderBy []sqtables.OrderExpr
+
+	if !tkns.IsARemove(tokens.By) {
+		return nil, sqerr.NewSyntax("ORDER missing BY")
+	}
+	for {
+		exp, err := GetExpr(tkns, nil, 0, tokens.Comma, tokens.CloseBracket)
+		if err != nil {
+			return nil, err
+		}
+		if exp == nil {
+			return nil, sqerr.NewSyntax("Expecting an expression in ORDER BY")
+		}
+		item := sqtables.OrderExpr{Exp: exp, SortType: tokens.Asc}
+		if tkn := tkns.TestTkn(tokens.Asc, tokens.Desc); tkn != nil {
+			item.SortType = tkn.ID()
+			tkns.Remove()
+		}
+		orderBy = append(orderBy, item)
+		if !tkns.IsARemove(tokens.Comma) {
+			return orderBy, nil
+		}
+	}
+}
+
+// frameClause processes {ROWS|RANGE} {start | BETWEEN start AND end}.
+//   If only the start is given then the frame ends at the current row
+func frameClause(tkns *tokens.TokenList) (*sqtables.WindowFrame, error) {
+	var err error
+
+	frame := sqtables.WindowFrame{IsRows: tkns.IsAKeyword("ROWS")}
+	tkns.Remove()
+
+	if tkns.IsAKeywordRemove("BETWEEN") {
+		frame.Start, err = frameBound(tkns)
+		if err != nil {
+			return nil, err
+		}
+		if !tkns.IsARemove(tokens.And) {
+			return nil, sqerr.NewSyntax("Expecting AND in window frame")
+		}
+		frame.End, err = frameBound(tkns)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		frame.Start, err = frameBound(tkns)
+		if err != nil {
+			return nil, err
+		}
+		frame.End = sqtables.FrameBound{Type: sqtables.CurrentRow}
+	}
+
+	if frame.Start.Type == sqtables.UnboundedFollowing {
+		return nil, sqerr.NewSyntax("Window frame can not start with UNBOUNDED FOLLOWING")
+	}
+	if frame.End.Type == sqtables.UnboundedPreceding {
+		return nil, sqerr.NewSyntax("Window frame can not end with UNBOUNDED PRECEDING")
+	}
+	if frame.Start.Type > frame.End.Type {
+		return nil, sqerr.NewSyntaxf("Window frame can not start with %s and end with %s", frame.Start, frame.End)
+	}
+	if !frame.IsRows {
+		for _, bound := range []sqtables.FrameBound{frame.Start, frame.End} {
+			if bound.Type == sqtables.OffsetPreceding || bound.Type == sqtables.OffsetFollowing {
+				return nil, sqerr.NewSyntax("RANGE frames only support UNBOUNDED and CURRENT ROW")
+			}
+		}
+	}
+	return &frame, nil
+}
+
+// frameBound processes UNBOUNDED PRECEDING, n PRECEDING, CURRENT ROW, n FOLLOWING or UNBOUNDED FOLLOWING
+func frameBound(tkns *tokens.TokenList) (sqtables.FrameBound, error) {
+	var bound sqtables.FrameBound
+
+	switch {
+	case tkns.IsAKeywordRemove("CURRENT"):
+		if !tkns.IsAKeywordRemove("ROW") {
+			return bound, sqerr.NewSyntax("Expecting ROW after CURRENT")
+		}
+		bound.Type = sqtables.CurrentRow
+		return bound, nil
+	case tkns.IsAKeywordRemove("UNBOUNDED"):
+		bound.Type = sqtables.UnboundedPreceding
+		if tkns.IsAKeyword("FOLLOWING") {
+			bound.Type = sqtables.UnboundedFollowing
+		}
+	default:
+		tkn := tkns.TestTkn(tokens.Num)
+		if tkn == nil {
+			return bound, sqerr.NewSyntax("Expecting UNBOUNDED, CURRENT ROW or a number in window frame")
+		}
+		offset, err := strconv.Atoi(tkn.(*tokens.ValueToken).Value())
+		if err != nil || offset < 0 {
+			return bound, sqerr.NewSyntaxf("Window frame offset must be a non negative integer: %s", tkn.(*tokens.ValueToken).Value())
+		}
+		tkns.Remove()
+		bound.Offset = offset
+		bound.Type = sqtables.OffsetPreceding
+		if tkns.IsAKeyword("FOLLOWING") {
+			bound.Type = sqtables.OffsetFollowing
+		}
+	}
+	if !tkns.IsAKeywordRemove("PRECEDING") && !tkns.IsAKeywordRemove("FOLLOWING") {
+		return bound, sqerr.NewSyntax("Expecting PRECEDING or FOLLOWING in window frame")
+	}
+	return bound, nil
+}
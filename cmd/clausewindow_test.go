@@ -0,0 +1,321 @@
+package cmd_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/wilphi/sqsrv/cmd"
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+type OverData struct {
+	TestName string
+	Command  string
+	ExpErr   string
+	ExpStr   string
+}
+
+func testOverFunc(d OverData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		tkns := tokens.Tokenize(d.Command)
+		over, err := cmd.OverClause(tkns)
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+		if !tkns.IsEmpty() {
+			t.Errorf("Unexpected tokens after OVER clause: %s", tkns.String())
+			return
+		}
+		str := sqtables.NewWindowExpr(tokens.RowNumber, nil, over).String()
+		if str != d.ExpStr {
+			t.Errorf("Actual window %q does not match expected %q", str, d.ExpStr)
+		}
+	}
+}
+
+func TestOverClause(t *testing.T) {
+
+	data := []OverData{
+		{TestName: "Empty Window", Command: "OVER ()", ExpStr: "ROW_NUMBER() OVER ()"},
+		{TestName: "Missing (", Command: "OVER id", ExpErr: "Syntax Error: Expecting ( after OVER"},
+		{TestName: "Missing )", Command: "OVER (ORDER BY id", ExpErr: "Syntax Error: Expecting ) to end OVER clause"},
+		{
+			TestName: "Partition By",
+			Command:  "OVER (PARTITION BY dept, name)",
+			ExpStr:   "ROW_NUMBER() OVER (PARTITION BY dept, name)",
+		},
+		{TestName: "Partition missing By", Command: "OVER (PARTITION dept)", ExpErr: "Syntax Error: PARTITION missing BY"},
+		{TestName: "Partition By no expression", Command: "OVER (PARTITION BY)", ExpErr: "Syntax Error: Expecting an expression in PARTITION BY"},
+		{
+			TestName: "Order By",
+			Command:  "OVER (ORDER BY salary DESC, id ASC)",
+			ExpStr:   "ROW_NUMBER() OVER (ORDER BY salary DESC, id)",
+		},
+		{TestName: "Order missing By", Command: "OVER (ORDER id)", ExpErr: "Syntax Error: ORDER missing BY"},
+		{TestName: "Order By no expression", Command: "OVER (ORDER BY )", ExpErr: "Syntax Error: Expecting an expression in ORDER BY"},
+		{
+			TestName: "Partition and Order By expressions",
+			Command:  "OVER (PARTITION BY dept ORDER BY salary*2)",
+			ExpStr:   "ROW_NUMBER() OVER (PARTITION BY dept ORDER BY (salary*2))",
+		},
+		{
+			TestName: "Rows frame",
+			Command:  "OVER (ORDER BY id ROWS BETWEEN 1 PRECEDING AND 2 FOLLOWING)",
+			ExpStr:   "ROW_NUMBER() OVER (ORDER BY id ROWS BETWEEN 1 PRECEDING AND 2 FOLLOWING)",
+		},
+		{
+			TestName: "Rows frame start only",
+			Command:  "OVER (ROWS UNBOUNDED PRECEDING)",
+			ExpStr:   "ROW_NUMBER() OVER (ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)",
+		},
+		{
+			TestName: "Range frame",
+			Command:  "OVER (ORDER BY id RANGE BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING)",
+			ExpStr:   "ROW_NUMBER() OVER (ORDER BY id RANGE BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING)",
+		},
+		{
+			TestName: "Range frame with offset",
+			Command:  "OVER (ORDER BY id RANGE BETWEEN 1 PRECEDING AND CURRENT ROW)",
+			ExpErr:   "Syntax Error: RANGE frames only support UNBOUNDED and CURRENT ROW",
+		},
+		{
+			TestName: "Frame ends before start",
+			Command:  "OVER (ROWS BETWEEN CURRENT ROW AND 1 PRECEDING)",
+			ExpErr:   "Syntax Error: Window frame can not start with CURRENT ROW and end with 1 PRECEDING",
+		},
+		{
+			TestName: "Frame starts with Unbounded Following",
+			Command:  "OVER (ROWS UNBOUNDED FOLLOWING)",
+			ExpErr:   "Syntax Error: Window frame can not start with UNBOUNDED FOLLOWING",
+		},
+		{
+			TestName: "Frame ends with Unbounded Preceding",
+			Command:  "OVER (ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED PRECEDING)",
+			ExpErr:   "Syntax Error: Window frame can not end with UNBOUNDED PRECEDING",
+		},
+		{
+			TestName: "Frame missing AND",
+			Command:  "OVER (ROWS BETWEEN 1 PRECEDING 1 FOLLOWING)",
+			ExpErr:   "Syntax Error: Expecting AND in window frame",
+		},
+		{TestName: "Frame missing ROW", Command: "OVER (ROWS CURRENT)", ExpErr: "Syntax Error: Expecting ROW after CURRENT"},
+		{TestName: "Frame missing PRECEDING", Command: "OVER (ROWS 1)", ExpErr: "Syntax Error: Expecting PRECEDING or FOLLOWING in window frame"},
+		{
+			TestName: "Frame invalid bound",
+			Command:  "OVER (ROWS id PRECEDING)",
+			ExpErr:   "Syntax Error: Expecting UNBOUNDED, CURRENT ROW or a number in window frame",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testOverFunc(row))
+	}
+}
+
+type WindowData struct {
+	TestName string
+	Command  string
+	ExpErr   string
+	ExpCols  []string
+	ExpVals  sqtypes.RawVals
+}
+
+func testWindowFunc(profile *sqprofile.SQProfile, d WindowData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		tkns := tokens.Tokenize(d.Command)
+		trans := sqtables.BeginTrans(profile, true)
+		_, data, err := cmd.Select(trans, tkns)
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+		if d.ExpCols != nil && !reflect.DeepEqual(data.GetColNames(), d.ExpCols) {
+			t.Errorf("Expected Cols (%v) do not match actual cols (%v)", d.ExpCols, data.GetColNames())
+			return
+		}
+		msg := sqtypes.Compare2DValue(data.Vals, sqtypes.CreateValuesFromRaw(d.ExpVals), "Actual", "Expect", false)
+		if msg != "" {
+			t.Error(msg)
+		}
+	}
+}
+
+func TestWindowFunctions(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	// Make sure datasets are by default in RowID order
+	sqtables.RowOrder = true
+
+	err := sq.ProcessSQFile("./testdata/windowtests.sq")
+	if err != nil {
+		t.Fatalf("Unable to load test data: %s", err)
+	}
+
+	data := []WindowData{
+		{
+			TestName: "Row Number",
+			Command:  "SELECT id, row_number() over (order by id desc) FROM winemp ORDER BY id",
+			ExpCols:  []string{"id", "ROW_NUMBER() OVER (ORDER BY id DESC)"},
+			ExpVals:  sqtypes.RawVals{{1, 7}, {2, 6}, {3, 5}, {4, 4}, {5, 3}, {6, 2}, {7, 1}},
+		},
+		{
+			TestName: "Row Number with Where",
+			Command:  "SELECT name, row_number() over (order by salary, id) rn FROM winemp WHERE dept = \"Sales\" ORDER BY rn",
+			ExpCols:  []string{"name", "rn"},
+			ExpVals:  sqtypes.RawVals{{"Ann", 1}, {"Bob", 2}, {"Cal", 3}},
+		},
+		{
+			TestName: "Rank",
+			Command:  "SELECT id, rank() over (partition by dept order by salary desc) FROM winemp ORDER BY id",
+			ExpVals:  sqtypes.RawVals{{1, 3}, {2, 1}, {3, 1}, {4, 1}, {5, 3}, {6, 1}, {7, 1}},
+		},
+		{
+			TestName: "Dense Rank",
+			Command:  "SELECT id, dense_rank() over (partition by dept order by salary desc) FROM winemp ORDER BY id",
+			ExpVals:  sqtypes.RawVals{{1, 2}, {2, 1}, {3, 1}, {4, 1}, {5, 2}, {6, 1}, {7, 1}},
+		},
+		{
+			TestName: "Running total",
+			Command:  "SELECT id, sum(salary) over (order by id) total FROM winemp ORDER BY id",
+			ExpVals:  sqtypes.RawVals{{1, 100}, {2, 300}, {3, 500}, {4, 800}, {5, 950}, {6, 1250}, {7, 1250}},
+		},
+		{
+			TestName: "Running total includes peers",
+			Command:  "SELECT id, sum(salary) over (order by salary) total FROM winemp ORDER BY id",
+			ExpVals:  sqtypes.RawVals{{1, 100}, {2, 650}, {3, 650}, {4, 1250}, {5, 250}, {6, 1250}, {7, 1250}},
+		},
+		{
+			TestName: "Running total by rows",
+			Command:  "SELECT id, sum(salary) over (order by salary rows unbounded preceding) total FROM winemp ORDER BY id",
+			ExpVals:  sqtypes.RawVals{{1, 100}, {2, 450}, {3, 650}, {4, 950}, {5, 250}, {6, 1250}, {7, 1250}},
+		},
+		{
+			TestName: "Moving sum",
+			Command:  "SELECT id, sum(salary) over (order by id rows between 1 preceding and 1 following) FROM winemp ORDER BY id",
+			ExpVals:  sqtypes.RawVals{{1, 300}, {2, 500}, {3, 700}, {4, 650}, {5, 750}, {6, 450}, {7, 300}},
+		},
+		{
+			TestName: "Count to end of partition",
+			Command:  "SELECT id, count() over (order by id rows between current row and unbounded following) FROM winemp ORDER BY id",
+			ExpVals:  sqtypes.RawVals{{1, 7}, {2, 6}, {3, 5}, {4, 4}, {5, 3}, {6, 2}, {7, 1}},
+		},
+		{
+			TestName: "Partition aggregates",
+			Command:  "SELECT id, sum(salary) over (partition by dept), count() over (partition by dept), count(salary) over (partition by dept), min(salary) over (partition by dept), max(salary) over (partition by dept) FROM winemp ORDER BY id",
+			ExpVals: sqtypes.RawVals{
+				{1, 500, 3, 3, 100, 200},
+				{2, 500, 3, 3, 100, 200},
+				{3, 500, 3, 3, 100, 200},
+				{4, 750, 3, 3, 150, 300},
+				{5, 750, 3, 3, 150, 300},
+				{6, 750, 3, 3, 150, 300},
+				{7, nil, 1, 0, nil, nil},
+			},
+		},
+		{
+			TestName: "Average",
+			Command:  "SELECT id, avg(salary) over (partition by dept) FROM winemp WHERE dept = \"Dev\" ORDER BY id",
+			ExpVals:  sqtypes.RawVals{{4, 250.0}, {5, 250.0}, {6, 250.0}},
+		},
+		{
+			TestName: "Lag",
+			Command:  "SELECT id, lag(salary) over (order by id) FROM winemp ORDER BY id",
+			ExpVals:  sqtypes.RawVals{{1, nil}, {2, 100}, {3, 200}, {4, 200}, {5, 300}, {6, 150}, {7, 300}},
+		},
+		{
+			TestName: "Lead with offset and default",
+			Command:  "SELECT id, lead(name, 2, \"none\") over (partition by dept order by id) FROM winemp ORDER BY id",
+			ExpVals:  sqtypes.RawVals{{1, "Cal"}, {2, "none"}, {3, "none"}, {4, "Fay"}, {5, "none"}, {6, "none"}, {7, "none"}},
+		},
+		{
+			TestName: "Lag negative offset",
+			Command:  "SELECT id, lag(salary, -1) over (order by id) FROM winemp",
+			ExpErr:   "Error: The offset for LAG must be a non negative integer",
+		},
+		{
+			TestName: "Lag too many arguments",
+			Command:  "SELECT id, lag(salary, 1, 0, 2) over (order by id) FROM winemp",
+			ExpErr:   "Syntax Error: Function LAG has too many arguments",
+		},
+		{
+			TestName: "First Value",
+			Command:  "SELECT id, first_value(name) over (partition by dept order by salary desc, id) FROM winemp ORDER BY id",
+			ExpVals:  sqtypes.RawVals{{1, "Bob"}, {2, "Bob"}, {3, "Bob"}, {4, "Dee"}, {5, "Dee"}, {6, "Dee"}, {7, "Gus"}},
+		},
+		{
+			TestName: "Median and Variance",
+			Command:  "SELECT id, median(salary) over (partition by dept), var_samp(salary) over (order by id rows between 1 preceding and current row) FROM winemp ORDER BY id",
+			ExpVals:  sqtypes.RawVals{{1, 200.0, nil}, {2, 200.0, 5000.0}, {3, 200.0, 0.0}, {4, 300.0, 5000.0}, {5, 300.0, 11250.0}, {6, 300.0, 11250.0}, {7, nil, nil}},
+		},
+		{
+			TestName: "Percentile with OVER",
+			Command:  "SELECT percentile_cont(0.5) WITHIN GROUP (ORDER BY salary) OVER () FROM winemp",
+			ExpErr:   "Syntax Error: PERCENTILE_CONT can not be used as a window function",
+		},
+		{
+			TestName: "Window function in expression",
+			Command:  "SELECT id, row_number() over (order by id) * 10 FROM winemp WHERE id < 4 ORDER BY id",
+			ExpVals:  sqtypes.RawVals{{1, 10}, {2, 20}, {3, 30}},
+		},
+		{
+			TestName: "Window function with Distinct",
+			Command:  "SELECT DISTINCT dept, count() over (partition by dept) FROM winemp ORDER BY dept",
+			ExpVals:  sqtypes.RawVals{{"Dev", 3}, {"Ops", 1}, {"Sales", 3}},
+		},
+		{
+			TestName: "Window function names as column names",
+			Command:  "SELECT lead, rank, rank() over (partition by partition order by lag desc), lag(lead) over (order by lag) FROM standings ORDER BY lead",
+			ExpCols:  []string{"lead", "rank", "RANK() OVER (PARTITION BY partition ORDER BY lag DESC)", "LAG(lead) OVER (ORDER BY lag)"},
+			ExpVals:  sqtypes.RawVals{{"Ann", 2, 2, nil}, {"Bob", 1, 1, "Ann"}, {"Cal", 1, 1, "Bob"}},
+		},
+		{
+			TestName: "Window function without OVER",
+			Command:  "SELECT id, rank() FROM winemp",
+			ExpErr:   "Syntax Error: RANK must be followed by an OVER clause",
+		},
+		{
+			TestName: "OVER on non window function",
+			Command:  "SELECT id, int(salary) over () FROM winemp",
+			ExpErr:   "Syntax Error: INT can not be used as a window function",
+		},
+		{
+			TestName: "Window function in Where",
+			Command:  "SELECT id FROM winemp WHERE row_number() over (order by id) = 1",
+			ExpErr:   "Syntax Error: Window functions are not allowed in the WHERE clause",
+		},
+		{
+			TestName: "Window function with Group By",
+			Command:  "SELECT dept, count() over () FROM winemp GROUP BY dept",
+			ExpErr:   "Syntax Error: Window functions can not be used with GROUP BY or aggregate functions",
+		},
+		{
+			TestName: "Window function with aggregate",
+			Command:  "SELECT count(), row_number() over (order by id) FROM winemp",
+			ExpErr:   "Syntax Error: Window functions can not be used with GROUP BY or aggregate functions",
+		},
+		{
+			TestName: "Aggregate in window function",
+			Command:  "SELECT sum(count()) over () FROM winemp",
+			ExpErr:   "Syntax Error: Window functions can not be used with GROUP BY or aggregate functions",
+		},
+		{
+			TestName: "Nested window functions",
+			Command:  "SELECT sum(row_number() over (order by id)) over () FROM winemp",
+			ExpErr:   "Syntax Error: Window functions can not be nested in SUM",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testWindowFunc(profile, row))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqprofile"
 	"github.com/wilphi/sqsrv/sqtables"
 	"github.com/wilphi/sqsrv/sqtables/column"
 	"github.com/wilphi/sqsrv/sqtables/moniker"
@@ -142,11 +143,32 @@ func getValCol(tkns *tokens.TokenList) (exp sqtables.Expr, err error) {
 		return exp, err
 	}
 
+	// A non reserved word is a function when it is followed by ( and a type when it is followed by a string
+	if next := tkns.Peekx(1); next != nil && next.ID() == tokens.OpenBracket {
+		tkns.UseKeyword(tokens.IsFunction)
+	} else if next != nil && next.ID() == tokens.Quote {
+		tkns.UseKeyword(tokens.IsType)
+	}
+
 	if tkn := tkns.Peek(); tkn != nil {
+		// A type followed by a string is a literal of that type
+		if next := tkns.Peekx(1); tkn.TestFlags(tokens.IsType) && next != nil && next.ID() == tokens.Quote {
+			exp, err = typedLiteral(tkns)
+			if err == nil && mSign {
+				exp = sqtables.NewNegateExpr(exp)
+			}
+			return exp, err
+		}
+		if tkns.IsARemove(tokens.CurrentDate) {
+			return sqtables.NewFuncExpr(tokens.CurrentDate, nil), nil
+		}
 		v, err = sqtypes.CreateValueFromToken(tkn)
 		if err == nil {
 			//Token is a value
 			exp = sqtables.NewValueExpr(v)
+			if tkn.ID() == tokens.Num {
+				exp = sqtables.NewNumberExpr(v, tkn.(*tokens.ValueToken).Value())
+			}
 			tkns.Remove()
 			if mSign {
 				exp = sqtables.NewNegateExpr(exp)
@@ -154,6 +176,10 @@ func getValCol(tkns *tokens.TokenList) (exp sqtables.Expr, err error) {
 			}
 			return exp, nil
 		}
+		if tkn.ID() == tokens.Hex {
+			// Invalid hex literal
+			return nil, err
+		}
 		// is token a ColName
 		if tkn := tkns.TestTkn(tokens.Ident); tkn != nil {
 			cName := tkn.(*tokens.ValueToken).Value()
@@ -193,14 +219,37 @@ func getValCol(tkns *tokens.TokenList) (exp sqtables.Expr, err error) {
 				}
 				return nil, sqerr.NewSyntaxf("Function %s is missing an expression followed by )", tokens.IDName(cmd))
 			}
+			// Aggregates may use only the distinct values of the expression
+			distinct := ftkn.TestFlags(tokens.IsAggregate) && tkns.IsARemove(tokens.Distinct)
+			// COUNT(*) is the same as COUNT()
+			if cmd == tokens.Count && !distinct && tkns.IsARemove(tokens.Asterix) && !tkns.IsA(tokens.CloseBracket) {
+				return nil, sqerr.NewSyntax("Expecting ) after COUNT(*")
+			}
 			// No Args if close bracket
-			if tkns.Peek().ID() == tokens.CloseBracket {
+			if tkns.IsA(tokens.CloseBracket) {
 				tkns.Remove()
+				if distinct {
+					return nil, sqerr.NewSyntaxf("Function %s is missing an expression after DISTINCT", tokens.IDName(cmd))
+				}
 				if !ftkn.TestFlags(tokens.IsNoArg) {
 					return nil, sqerr.NewSyntaxf("Function %s is missing an expression between ( and )", tokens.IDName(cmd))
 				}
-				exp = sqtables.NewFuncExpr(cmd, nil)
-				return exp, nil
+				return funcExpr(tkns, ftkn, nil, sqtables.AggregateOptions{})
+			}
+			// PERCENTILE_CONT and PERCENTILE_DISC have a fraction and get their values from WITHIN GROUP
+			if cmd == tokens.PercentileCont || cmd == tokens.PercentileDisc {
+				return percentileFunc(tkns, ftkn, distinct)
+			}
+			// EXTRACT and DATE_TRUNC have a field or unit as well as an expression
+			if cmd == tokens.Extract {
+				return extractFunc(tkns, ftkn)
+			}
+			if cmd == tokens.DateTrunc {
+				return dateTruncFunc(tkns, ftkn)
+			}
+			// NEXTVAL, CURRVAL and SETVAL have the name of a sequence
+			if cmd == tokens.NextVal || cmd == tokens.CurrVal || cmd == tokens.SetVal {
+				return sequenceFunc(tkns, ftkn)
 			}
 			// At least one arg
 			exp, err = GetExpr(tkns, nil, 0, tokens.CloseBracket)
@@ -213,12 +262,55 @@ func getValCol(tkns *tokens.TokenList) (exp sqtables.Expr, err error) {
 			if exp == nil {
 				return nil, sqerr.NewSyntaxf("Function %s is missing an expression between ( and )", ftkn.Name())
 			}
+			args := []sqtables.Expr{exp}
+			// LAG and LEAD have an optional offset and default value, SUBSTR has a start and optional length
+			//   and JSON_OBJECTAGG has a value after the key
+			for (cmd == tokens.Lag || cmd == tokens.Lead || cmd == tokens.Substr || cmd == tokens.JSONObjectAgg) &&
+				tkns.IsARemove(tokens.Comma) {
+				if len(args) == 3 || (cmd == tokens.JSONObjectAgg && len(args) == 2) {
+					return nil, sqerr.NewSyntaxf("Function %s has too many arguments", ftkn.Name())
+				}
+				exp, err = GetExpr(tkns, nil, 0, tokens.Comma, tokens.CloseBracket)
+				if err != nil {
+					return nil, err
+				}
+				if exp == nil {
+					return nil, sqerr.NewSyntaxf("Function %s is missing an expression after ,", ftkn.Name())
+				}
+				args = append(args, exp)
+			}
+			if cmd == tokens.Substr && len(args) == 1 {
+				return nil, sqerr.NewSyntax("Function SUBSTR is missing , after expression")
+			}
+			if cmd == tokens.JSONObjectAgg && len(args) == 1 {
+				return nil, sqerr.NewSyntax("Function JSON_OBJECTAGG is missing , after the key")
+			}
+			opts := sqtables.AggregateOptions{Distinct: distinct}
+			// STRING_AGG has a separator and may order its values
+			if cmd == tokens.StringAgg {
+				err = stringAggArgs(tkns, &opts)
+				if err != nil {
+					return nil, err
+				}
+			}
+			// JSON_EXTRACT has a path and JSON_ARRAYAGG may order its values
+			if cmd == tokens.JSONExtract {
+				opts.Param, err = jsonPathArg(tkns)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if cmd == tokens.JSONArrayAgg && tkns.IsARemove(tokens.Order) {
+				opts.OrderBy, err = orderExprList(tkns)
+				if err != nil {
+					return nil, err
+				}
+			}
 			if tkns.IsEmpty() || tkns.Peek().ID() != tokens.CloseBracket {
 				return nil, sqerr.NewSyntaxf("Function %s is missing ) after expression", ftkn.Name())
 			}
 			tkns.Remove()
-			fexp := sqtables.NewFuncExpr(tkn.ID(), exp)
-			return fexp, nil
+			return funcExpr(tkns, ftkn, args, opts)
 
 		}
 	}
@@ -235,6 +327,67 @@ func getValCol(tkns *tokens.TokenList) (exp sqtables.Expr, err error) {
 	return nil, sqerr.NewSyntaxf("Invalid expression: Unable to find a value or column near %s", val)
 }
 
+// funcExpr creates the expression for a function once its arguments have been parsed. Aggregate functions
+//   may be followed by FILTER (WHERE expr) and aggregate or window functions by an OVER clause
+func funcExpr(tkns *tokens.TokenList, ftkn tokens.Token, args []sqtables.Expr, opts sqtables.AggregateOptions) (sqtables.Expr, error) {
+	var exp sqtables.Expr
+	var err error
+
+	cmd := ftkn.ID()
+	// FILTER is only a keyword after the ) of an aggregate function. After any other function it is
+	//   only checked when followed by ( so that the error is clear
+	next := tkns.Peekx(1)
+	isFilter := tkns.IsAKeyword("FILTER") && (ftkn.TestFlags(tokens.IsAggregate) || (next != nil && next.ID() == tokens.OpenBracket))
+	if isFilter {
+		tkns.Remove()
+		if !ftkn.TestFlags(tokens.IsAggregate) {
+			return nil, sqerr.NewSyntaxf("FILTER can only be used with aggregate functions not %s", tokens.IDName(cmd))
+		}
+		if !tkns.IsARemove(tokens.OpenBracket) || !tkns.IsARemove(tokens.Where) {
+			return nil, sqerr.NewSyntaxf("Expecting (WHERE after FILTER in %s", tokens.IDName(cmd))
+		}
+		if tkns.IsA(tokens.CloseBracket) {
+			return nil, sqerr.NewSyntaxf("Expecting an expression after WHERE in FILTER of %s", tokens.IDName(cmd))
+		}
+		opts.Filter, err = ParseWhereClause(tkns, true, tokens.CloseBracket)
+		if err != nil {
+			return nil, err
+		}
+		if !tkns.IsARemove(tokens.CloseBracket) {
+			return nil, sqerr.NewSyntaxf("Expecting ) after FILTER of %s", tokens.IDName(cmd))
+		}
+	}
+
+	if tkns.IsA(tokens.Over) {
+		if opts.Param != nil {
+			return nil, sqerr.NewSyntaxf("%s can not be used as a window function", tokens.IDName(cmd))
+		}
+		if opts.Distinct || opts.Filter != nil {
+			return nil, sqerr.NewSyntaxf("DISTINCT and FILTER can not be used with %s OVER", tokens.IDName(cmd))
+		}
+		return windowFunc(tkns, ftkn, args)
+	}
+	if ftkn.TestFlags(tokens.IsWindow) {
+		return nil, sqerr.NewSyntaxf("%s must be followed by an OVER clause", tokens.IDName(cmd))
+	}
+	if len(args) > 0 {
+		exp = args[0]
+	}
+	if ftkn.TestFlags(tokens.IsAggregate) {
+		if len(args) > 1 {
+			opts.Args = args[1:]
+		}
+		return sqtables.NewAggregateFuncExpr(cmd, exp, opts), nil
+	}
+	if opts.Param != nil {
+		return sqtables.NewParamFuncExpr(cmd, exp, opts.Param), nil
+	}
+	if len(args) > 1 {
+		return sqtables.NewArgsFuncExpr(cmd, exp, args[1:]...), nil
+	}
+	return sqtables.NewFuncExpr(cmd, exp), nil
+}
+
 var exPrecedence = map[tokens.TokenID]int{
 	tokens.Or:               0,
 	tokens.And:              1,
@@ -249,6 +402,8 @@ var exPrecedence = map[tokens.TokenID]int{
 	tokens.Asterix:          4,
 	tokens.Divide:           4,
 	tokens.Modulus:          4,
+	tokens.Arrow:            5,
+	tokens.DoubleArrow:      5,
 }
 
 // GetExpr uses a Operator-precedence parser algorthim based on pseudo code from Wikipedia
@@ -318,12 +473,28 @@ func ifte(cond bool, a, b string) string {
 // listtype is one of:
 //      tokens.Values - VALUES clause for INSERT,
 //      tokens.Select - expressions for SELECT clause,
-//      tokens.Group - expressions for GROUP BY clause
+//      tokens.Group - expressions for GROUP BY clause,
+//      tokens.Merge - VALUES clause for the INSERT of a MERGE, the expressions can use columns,
+//      tokens.Returning - expressions for RETURNING clause
 func GetExprList(tkns *tokens.TokenList, terminatorID tokens.TokenID, listtype tokens.TokenID) (*sqtables.ExprList, error) {
 	var eList sqtables.ExprList
 
 	// loop to get the expressions
 	for {
+		// DEFAULT in a VALUES list is added as a nil expression so that the default of the column can be used
+		if (listtype == tokens.Values || listtype == tokens.Merge) && tkns.IsARemove(tokens.Default) {
+			eList.Add(nil)
+			if tkns.IsA(terminatorID) {
+				break
+			}
+			if !tkns.IsARemove(tokens.Comma) {
+				return nil, sqerr.NewSyntax("Comma is required to separate values")
+			}
+			if tkns.IsA(terminatorID) {
+				return nil, sqerr.NewSyntaxf("Unexpected %q before %q", ",", tokens.IDName(terminatorID))
+			}
+			continue
+		}
 		// get expression
 		exp, err := GetExpr(tkns, nil, 1, terminatorID, tokens.Comma)
 		if err != nil {
@@ -338,18 +509,22 @@ func GetExprList(tkns *tokens.TokenList, terminatorID tokens.TokenID, listtype t
 			return nil, err
 		}
 		if listtype == tokens.Values {
-			// Make sure it is a value
+			// Make sure it is a value. Functions that do not use any columns such as NOW() or GEN_RANDOM_UUID()
+			//   are evaluated for each row when the values are used
 			_, ok := exp2.(*sqtables.ValueExpr)
-			if !ok {
+			if !ok && (len(exp2.ColRefs()) > 0 || exp2.IsAggregate()) {
 				return nil, sqerr.NewSyntaxf("Expression %q did not reduce to a value", exp2.Name())
 			}
 		}
 
 		// Check for optional alias
+		hasAs := tkns.IsARemove(tokens.As)
 		if tkn := tkns.TestTkn(tokens.Ident); tkn != nil {
 			alias := tkn.(*tokens.ValueToken).Value()
 			tkns.Remove()
 			exp2.SetAlias(alias)
+		} else if hasAs {
+			return nil, sqerr.NewSyntax("Expecting an alias after AS")
 		}
 		eList.Add(exp2)
 		// Is token the terminatorID
@@ -396,3 +571,28 @@ func GetExprList(tkns *tokens.TokenList, terminatorID tokens.TokenID, listtype t
 
 	return &eList, nil
 }
+
+// parseReturning parses the rest of a RETURNING clause. The clause must be at the end of the statement.
+//   RETURNING * returns all of the columns of the table
+func parseReturning(profile *sqprofile.SQProfile, tkns *tokens.TokenList, tab *sqtables.TableDef) (*sqtables.Returning, error) {
+	var eList *sqtables.ExprList
+	var err error
+
+	if tkns.IsARemove(tokens.Asterix) {
+		eList = sqtables.NewExprList()
+		for _, col := range tab.GetCols(profile).GetRefs() {
+			colX := sqtables.NewColExpr(col)
+			colX.SetAlias(col.ColName)
+			eList.Add(colX)
+		}
+	} else {
+		eList, err = GetExprList(tkns, tokens.NilToken, tokens.Returning)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !tkns.IsEmpty() {
+		return nil, sqerr.NewSyntax("Unexpected tokens after RETURNING clause:" + tkns.String())
+	}
+	return sqtables.NewReturning(profile, tab, eList)
+}
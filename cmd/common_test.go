@@ -673,6 +673,14 @@ func TestGetExprList(t *testing.T) {
 			ExpExprTxt: "1,-20",
 			ListType:   tokens.Values,
 		},
+		{
+			TestName:   "ValueOnly with Function",
+			Terminator: tokens.From,
+			Command:    "1, TIMESTAMP(\"2020-01-02 03:04:05\") < NOW() FROM",
+			ExpErr:     "",
+			ExpExprTxt: "1,(2020-01-02 03:04:05<NOW())",
+			ListType:   tokens.Values,
+		},
 		{
 			TestName:   "ValueOnly with Invalid  Expression",
 			Terminator: tokens.From,
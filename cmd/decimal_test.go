@@ -0,0 +1,149 @@
+package cmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/cmd"
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+func TestDecimal(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	// Make sure datasets are by default in RowID order
+	sqtables.RowOrder = true
+
+	err := sq.ProcessSQFile("./testdata/decimaltests.sq")
+	if err != nil {
+		t.Fatalf("Unable to load test data: %s", err)
+	}
+
+	data := []SelectData{
+		{
+			TestName: "Select decimal columns",
+			Command:  "SELECT id, STRING(balance), STRING(rate), STRING(amount) FROM accounts",
+			ExpRows:  4,
+			ExpCols:  []string{"id", "STRING(balance)", "STRING(rate)", "STRING(amount)"},
+			ExpVals:  sqtypes.RawVals{{1, "0.10", "0.0500", "0.1"}, {2, "0.20", "0.0125", "0.2"}, {3, "1234.57", "1.0000", "12.345"}, {4, nil, nil, nil}},
+		},
+		{
+			TestName: "Exact addition",
+			Command:  "SELECT balance + DECIMAL \"0.2\" = DECIMAL \"0.3\", amount + DECIMAL \"0.2\" FROM accounts WHERE id = 1",
+			ExpRows:  1,
+			ExpCols:  []string{"((balance+0.2)=0.3)", "(amount+0.2)"},
+			ExpVals:  sqtypes.RawVals{{true, dtVal("0.3", tokens.Decimal)}},
+		},
+		{
+			TestName: "Decimal arithmetic",
+			Command:  "SELECT balance * rate, balance - 1000, balance / 3, id * rate FROM accounts WHERE id = 3",
+			ExpRows:  1,
+			ExpCols:  []string{"(balance*rate)", "(balance-1000)", "(balance/3)", "(id*rate)"},
+			ExpVals:  sqtypes.RawVals{{dtVal("1234.57", tokens.Decimal), dtVal("234.57", tokens.Decimal), dtVal("411.5233333333333333", tokens.Decimal), dtVal("3", tokens.Decimal)}},
+		},
+		{
+			TestName: "Sum and Avg are exact",
+			Command:  "SELECT sum(amount), avg(balance), min(rate), max(rate) FROM accounts WHERE id < 3",
+			ExpRows:  1,
+			ExpCols:  []string{"SUM(amount)", "AVG(balance)", "MIN(rate)", "MAX(rate)"},
+			ExpVals:  sqtypes.RawVals{{dtVal("0.3", tokens.Decimal), dtVal("0.15", tokens.Decimal), dtVal("0.0125", tokens.Decimal), dtVal("0.05", tokens.Decimal)}},
+		},
+		{
+			TestName: "Order by decimal",
+			Command:  "SELECT id, rate FROM accounts WHERE id < 4 ORDER BY rate",
+			ExpRows:  3,
+			ExpCols:  []string{"id", "rate"},
+			ExpVals:  sqtypes.RawVals{{2, dtVal("0.0125", tokens.Decimal)}, {1, dtVal("0.05", tokens.Decimal)}, {3, dtVal("1", tokens.Decimal)}},
+		},
+		{
+			TestName: "Conversion functions",
+			Command:  "SELECT DECIMAL(\"1.50\"), NUMERIC(id), FLOAT(balance), INT(balance) FROM accounts WHERE id = 3",
+			ExpRows:  1,
+			ExpCols:  []string{"1.50", "DECIMAL(id)", "FLOAT(balance)", "INT(balance)"},
+			ExpVals:  sqtypes.RawVals{{dtVal("1.5", tokens.Decimal), dtVal("3", tokens.Decimal), 1234.57, 1234}},
+		},
+		{
+			TestName: "Exact number literals",
+			Command:  "SELECT id, STRING(m) FROM ledger",
+			ExpRows:  4,
+			ExpCols:  []string{"id", "STRING(m)"},
+			ExpVals:  sqtypes.RawVals{{1, "123456789012345678.91"}, {2, "0.10"}, {3, "-0.10"}, {4, "98765432109876543.21"}},
+		},
+		{
+			TestName: "Decimal with number literal",
+			Command:  "SELECT m - 0.01, m * 1.1, 0.3 - m FROM ledger WHERE id = 2",
+			ExpRows:  1,
+			ExpCols:  []string{"(m-0.01)", "(m*1.1)", "(0.3-m)"},
+			ExpVals:  sqtypes.RawVals{{dtVal("0.09", tokens.Decimal), dtVal("0.11", tokens.Decimal), dtVal("0.2", tokens.Decimal)}},
+		},
+		{
+			TestName: "Compare decimal with literals",
+			Command:  "SELECT id FROM ledger WHERE m = 123456789012345678.91 OR m = \"-0.1\"",
+			ExpRows:  2,
+			ExpCols:  []string{"id"},
+			ExpVals:  sqtypes.RawVals{{1}, {3}},
+		},
+		{
+			TestName: "Invalid typed literal",
+			Command:  "SELECT id FROM accounts WHERE balance = DECIMAL \"1,000\"",
+			ExpErr:   "Error: Unable to Convert \"1,000\" to a DECIMAL",
+		},
+		{
+			TestName: "Type Mismatch",
+			Command:  "SELECT balance + \"1\" FROM accounts",
+			ExpErr:   "Error: Type Mismatch: 1 is not a Decimal",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testSelectFunc(profile, row))
+
+	}
+
+	errData := []struct {
+		TestName string
+		Command  string
+		ExpErr   string
+	}{
+		{
+			TestName: "Insert too many digits",
+			Command:  "INSERT INTO accounts (id, balance) VALUES (5, 123456789.5)",
+			ExpErr:   "Error: Value 123456789.5 of Column balance in Table accounts does not fit in DECIMAL(10,2)",
+		},
+		{
+			TestName: "Insert rounds into too many digits",
+			Command:  "INSERT INTO accounts (id, rate) VALUES (5, 9.99999)",
+			ExpErr:   "Error: Value 9.99999 of Column rate in Table accounts does not fit in DECIMAL(5,4)",
+		},
+		{
+			TestName: "Insert invalid string into decimal",
+			Command:  "INSERT INTO accounts (id, amount) VALUES (5, \"12,5\")",
+			ExpErr:   "Error: Unable to Convert \"12,5\" to a DECIMAL",
+		},
+		{
+			TestName: "Update too many digits",
+			Command:  "UPDATE accounts SET balance = balance * 10000000 WHERE id = 3",
+			ExpErr:   "Error: Value 12345700000.00 of Column balance in Table accounts does not fit in DECIMAL(10,2)",
+		},
+	}
+	for i, row := range errData {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName), func(t *testing.T) {
+			defer sqtest.PanicTestRecovery(t, "")
+
+			tkns := tokens.Tokenize(row.Command)
+			trans := sqtables.BeginTrans(profile, true)
+			var err error
+			if tkns.IsA(tokens.Update) {
+				_, _, err = cmd.Update(trans, tkns)
+			} else {
+				_, _, err = cmd.InsertInto(trans, tkns)
+			}
+			sqtest.CheckErr(t, err, row.ExpErr)
+		})
+	}
+}
@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/wilphi/sqsrv/sqerr"
 	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqptr"
 	"github.com/wilphi/sqsrv/sqtables"
 	"github.com/wilphi/sqsrv/sqtables/column"
 	"github.com/wilphi/sqsrv/sqtables/moniker"
+	"github.com/wilphi/sqsrv/sqtypes"
 	"github.com/wilphi/sqsrv/tokens"
 )
 
@@ -69,6 +74,7 @@ func ParseFromClause(profile *sqprofile.SQProfile, tkns *tokens.TokenList, termi
 	var err error
 	var joinExprs []sqtables.JoinInfo
 	var join *sqtables.JoinInfo
+	var tr sqtables.TableRef
 
 	//	commaTerms := append(terminators, tokens.Comma)
 
@@ -78,10 +84,14 @@ func ParseFromClause(profile *sqprofile.SQProfile, tkns *tokens.TokenList, termi
 	if tkns.IsEmpty() || tkns.TestTkn(terminators...) != nil {
 		return nil, nil, sqerr.NewSyntax("No Tables defined for query")
 	}
-	// Ident(tableName)
-	if tkn := tkns.TestTkn(tokens.Ident); tkn != nil {
-		lastTName := parseMoniker(tkns)
-		err = tables.Add(profile, sqtables.TableRef{Name: lastTName.Clone()})
+	// Ident(tableName) or (VALUES ...) alias
+	if tkns.IsA(tokens.Ident) || isValuesTable(tkns) {
+		tr, err = parseTableRef(profile, tkns)
+		if err != nil {
+			return nil, nil, err
+		}
+		lastTName := tr.Name
+		err = tables.Add(profile, tr)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -135,6 +145,9 @@ func parseMoniker(tkns *tokens.TokenList) *moniker.Moniker {
 		tkns.Remove()
 
 		// Check for an Alias
+		if tkns.IsA(tokens.As) && tkns.Peekx(1) != nil && tkns.Peekx(1).ID() == tokens.Ident {
+			tkns.Remove()
+		}
 		if tkn := tkns.TestTkn(tokens.Ident); tkn != nil {
 			alias = tkn.(*tokens.ValueToken).Value()
 			tkns.Remove()
@@ -147,6 +160,149 @@ func parseMoniker(tkns *tokens.TokenList) *moniker.Moniker {
 	return moniker.New(name, alias)
 }
 
+// newTableRef creates a reference to the named table. If the name is a view, the view is
+//   expanded into a temporary table that holds the results of its SELECT statement
+func newTableRef(profile *sqprofile.SQProfile, name *moniker.Moniker) (sqtables.TableRef, error) {
+	tr := sqtables.TableRef{Name: name.Clone()}
+
+	// Temporary tables hide views with the same name
+	if sqtables.GetTempTable(profile, name.Name()) != nil {
+		return tr, nil
+	}
+	view, err := sqtables.GetView(profile, name.Name())
+	if err != nil {
+		return tr, err
+	}
+	if view == nil {
+		// The SELECT of a view that is being created can not use the view
+		if isExpandingView(profile, name.Name()) {
+			tab, err := sqtables.GetTable(profile, name.Name())
+			if err == nil && tab == nil {
+				err = sqerr.Newf("View %s refers to itself", name.Name())
+			}
+			return tr, err
+		}
+		return tr, nil
+	}
+	tr.Table, err = expandView(profile, view)
+	return tr, err
+}
+
+// parseTableRef parses a table name with an optional alias or a VALUES list that is used as a table.
+//   If neither is found the TableRef has a nil Name
+func parseTableRef(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (sqtables.TableRef, error) {
+	if isValuesTable(tkns) {
+		return parseValuesTable(profile, tkns)
+	}
+	name := parseMoniker(tkns)
+	if name == nil {
+		return sqtables.TableRef{}, nil
+	}
+	return newTableRef(profile, name)
+}
+
+// isValuesTable returns true if the next tokens start a VALUES list used as a table
+func isValuesTable(tkns *tokens.TokenList) bool {
+	return tkns.IsA(tokens.OpenBracket) && tkns.Peekx(1) != nil && tkns.Peekx(1).ID() == tokens.Values
+}
+
+// parseValuesTable parses ( VALUES (expr, ...), ... ) [AS] alias [(col, ...)] and creates a temporary
+//   table that holds the rows. Without a list of columns they are named column1, column2, ...
+func parseValuesTable(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (sqtables.TableRef, error) {
+	var rows sqtypes.ValueMatrix
+	var eList *sqtables.ExprList
+	var err error
+
+	// Eat ( VALUES
+	tkns.Remove()
+	tkns.Remove()
+	for {
+		if !tkns.IsARemove(tokens.OpenBracket) {
+			return sqtables.TableRef{}, sqerr.NewSyntax("Expecting ( to start next row of VALUES")
+		}
+		eList, err = GetExprList(tkns, tokens.CloseBracket, tokens.Values)
+		if err != nil {
+			return sqtables.TableRef{}, err
+		}
+		if !tkns.IsARemove(tokens.CloseBracket) {
+			return sqtables.TableRef{}, sqerr.NewSyntax("Expecting ) to finish row of VALUES")
+		}
+		for _, expr := range eList.GetExprs() {
+			if expr == nil {
+				return sqtables.TableRef{}, sqerr.NewSyntax("DEFAULT can not be used in a VALUES table")
+			}
+		}
+		vals, err := eList.GetValues(profile)
+		if err != nil {
+			return sqtables.TableRef{}, err
+		}
+		if len(rows) > 0 && len(vals) != len(rows[0]) {
+			return sqtables.TableRef{}, sqerr.Newf("All rows of VALUES must have the same number of values: %d != %d", len(rows[0]), len(vals))
+		}
+		rows = append(rows, vals)
+
+		if !tkns.IsARemove(tokens.Comma) {
+			break
+		}
+	}
+	if !tkns.IsARemove(tokens.CloseBracket) {
+		return sqtables.TableRef{}, sqerr.NewSyntax("Expecting ) to finish VALUES list")
+	}
+
+	// The alias is required to name the table
+	if tkns.IsA(tokens.As) && tkns.Peekx(1) != nil && tkns.Peekx(1).ID() == tokens.Ident {
+		tkns.Remove()
+	}
+	tkn := tkns.TestTkn(tokens.Ident)
+	if tkn == nil {
+		return sqtables.TableRef{}, sqerr.NewSyntax("Expecting an alias after VALUES list")
+	}
+	name := tkn.(*tokens.ValueToken).Value()
+	tkns.Remove()
+
+	var colNames []string
+	if tkns.IsARemove(tokens.OpenBracket) {
+		colNames, err = GetIdentList(tkns, tokens.CloseBracket)
+		if err != nil {
+			return sqtables.TableRef{}, err
+		}
+	} else {
+		colNames = make([]string, len(rows[0]))
+		for i := range colNames {
+			colNames[i] = fmt.Sprintf("column%d", i+1)
+		}
+	}
+
+	// Each column must only have one type of value
+	for i, colName := range colNames {
+		if i >= len(rows[0]) {
+			break
+		}
+		var colType tokens.TokenID = tokens.Null
+		for _, row := range rows {
+			if row[i].IsNull() {
+				continue
+			}
+			if colType == tokens.Null {
+				colType = row[i].Type()
+			} else if row[i].Type() != colType {
+				return sqtables.TableRef{}, sqerr.Newf("Column %q of VALUES has values of type %s and %s", colName, tokens.IDName(colType), tokens.IDName(row[i].Type()))
+			}
+		}
+	}
+
+	data, err := sqtables.NewDataSet(profile, sqtables.NewTableList(profile, nil), sqtables.NewExprListFromValues(rows[0]))
+	if err != nil {
+		return sqtables.TableRef{}, err
+	}
+	data.Vals = rows
+	tab, err := sqtables.CreateTempTableDef(profile, name, colNames, data)
+	if err != nil {
+		return sqtables.TableRef{}, err
+	}
+	return sqtables.TableRef{Name: moniker.New(name, ""), Table: tab}, nil
+}
+
 func parseJoin(profile *sqprofile.SQProfile, tkns *tokens.TokenList, tables sqtables.TableList, lastTName *moniker.Moniker,
 	terminators ...tokens.TokenID) (*moniker.Moniker, *sqtables.JoinInfo, error) {
 
@@ -183,11 +339,14 @@ func parseJoin(profile *sqprofile.SQProfile, tkns *tokens.TokenList, tables sqta
 		joinType = tokens.Inner
 	}
 
-	tname := parseMoniker(tkns)
-	if tname == nil {
+	TableB, err = parseTableRef(profile, tkns)
+	if err != nil {
+		return nil, nil, err
+	}
+	if TableB.Name == nil {
 		return nil, nil, sqerr.NewSyntax("Expecting a tablename after JOIN")
 	}
-	TableB = sqtables.TableRef{Name: tname.Clone()}
+	tname := TableB.Name
 
 	err = tables.Add(profile, TableB)
 	if err != nil {
@@ -275,3 +434,205 @@ func validateOnClause(OnClause sqtables.Expr) error {
 
 	return nil
 }
+
+///////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// TargetJoin joins the table that is changed by an UPDATE ... FROM or a DELETE ... USING to the tables of
+//   the FROM or USING clause. The first target.col = table.col condition of the WHERE clause is used to
+//   join the target table to the other tables. If there is no such condition the target table is cross
+//   joined to a table that is used with it in another condition. The conditions of the WHERE clause that
+//   use more than one table are kept in Filter and are checked for each row of the join
+type TargetJoin struct {
+	Target sqtables.TableRef
+	Query  sqtables.Query
+	Filter sqtables.Expr
+}
+
+// NewTargetJoin creates the join of the target table to the tables and joins of a FROM or USING clause.
+//   The target table can be in the clause as well if one of them has an alias. clause is the name of
+//   the clause for error messages
+func NewTargetJoin(profile *sqprofile.SQProfile, target sqtables.TableRef, tables sqtables.TableList, joins []sqtables.JoinInfo,
+	whereExpr sqtables.Expr, clause string) (*TargetJoin, error) {
+	var err error
+	var join *sqtables.JoinInfo
+	var where sqtables.Expr
+
+	tj := TargetJoin{Target: target}
+	allTables := sqtables.NewTableList(profile, []sqtables.TableRef{tj.Target})
+	for _, tr := range tables {
+		if strings.EqualFold(tr.Name.Show(), tj.Target.Name.Show()) {
+			return nil, sqerr.Newf("Table %s can not be used in the %s clause without an alias", tj.Target.Name.Show(), clause)
+		}
+		err = allTables.Add(profile, *tr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	noJoinErr := sqerr.NewSyntaxf("Expecting a WHERE condition that joins %s to a table in the %s clause", tj.Target.Name.Show(), clause)
+	if whereExpr == nil {
+		return nil, noJoinErr
+	}
+	err = whereExpr.ValidateCols(profile, allTables)
+	if err != nil {
+		return nil, err
+	}
+	for _, cond := range splitAndExpr(whereExpr) {
+		if join == nil {
+			if name := joinedTableName(cond, tj.Target.Name); name != nil {
+				join = &sqtables.JoinInfo{TableA: tj.Target, TableB: *allTables[strings.ToLower(name.Show())], JoinType: tokens.Inner, ONClause: cond}
+				continue
+			}
+		}
+		if countTables(cond) > 1 {
+			tj.Filter = andExpr(tj.Filter, cond)
+		} else {
+			where = andExpr(where, cond)
+		}
+	}
+	if join == nil && tj.Filter != nil {
+		// A condition such as target.col > table.col can only be checked after a cross join
+		for _, cond := range splitAndExpr(tj.Filter) {
+			if name := otherTableName(cond, tj.Target.Name); name != nil {
+				join = &sqtables.JoinInfo{TableA: tj.Target, TableB: *allTables[strings.ToLower(name.Show())], JoinType: tokens.Cross}
+				break
+			}
+		}
+	}
+	if join == nil {
+		return nil, noJoinErr
+	}
+
+	tj.Query = sqtables.Query{Tables: allTables, Joins: append(joins, *join), WhereExpr: where}
+	return &tj, nil
+}
+
+// Tables returns all of the tables of the join including the target table
+func (tj *TargetJoin) Tables() sqtables.TableList {
+	return tj.Query.Tables
+}
+
+// Rows returns the joined rows that pass the Filter and the pointer to the target row for each of them.
+//   A target row is in the result once for each row of the other tables that it is joined to
+func (tj *TargetJoin) Rows(profile *sqprofile.SQProfile) (sqptr.SQPtrs, [][]sqtables.RowInterface, error) {
+	var ptrs sqptr.SQPtrs
+	var result [][]sqtables.RowInterface
+
+	names, tuples, err := tj.Query.JoinedRows(profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	targetIdx := 0
+	for i, name := range names {
+		if moniker.Equal(name, tj.Target.Name) {
+			targetIdx = i
+		}
+	}
+
+	for _, rows := range tuples {
+		if tj.Filter != nil {
+			val, err := tj.Filter.Evaluate(profile, sqtables.EvalFull, rows...)
+			if err != nil {
+				return nil, nil, err
+			}
+			if b, ok := val.(sqtypes.SQBool); !ok || !b.Bool() {
+				continue
+			}
+		}
+		ptrs = append(ptrs, rows[targetIdx].GetPtr(profile))
+		result = append(result, rows)
+	}
+	return ptrs, result, nil
+}
+
+// splitAndExpr returns the list of conditions that are combined by AND in the expression
+func splitAndExpr(exp sqtables.Expr) []sqtables.Expr {
+	opx, ok := exp.(*sqtables.OpExpr)
+	if !ok || opx.Operator != tokens.And {
+		return []sqtables.Expr{exp}
+	}
+	return append(splitAndExpr(opx.Left()), splitAndExpr(opx.Right())...)
+}
+
+// andExpr combines two conditions with an AND. If a is nil b is returned
+func andExpr(a, b sqtables.Expr) sqtables.Expr {
+	if a == nil {
+		return b
+	}
+	return sqtables.NewOpExpr(a, tokens.And, b)
+}
+
+// joinedTableName returns the name of the other table if the condition is target.col = table.col
+//   otherwise nil is returned
+func joinedTableName(cond sqtables.Expr, target *moniker.Moniker) *moniker.Moniker {
+	opx, ok := cond.(*sqtables.OpExpr)
+	if !ok || opx.Operator != tokens.Equal {
+		return nil
+	}
+	colL, lok := opx.Left().(*sqtables.ColExpr)
+	colR, rok := opx.Right().(*sqtables.ColExpr)
+	if !lok || !rok {
+		return nil
+	}
+	cl := colL.ColRef()
+	cr := colR.ColRef()
+	switch {
+	case moniker.Equal(cl.TableName, target) && !moniker.Equal(cr.TableName, target):
+		return cr.TableName
+	case moniker.Equal(cr.TableName, target) && !moniker.Equal(cl.TableName, target):
+		return cl.TableName
+	}
+	return nil
+}
+
+// otherTableName returns the name of a table other than the target that is used in the condition. Nil is
+//   returned if the condition does not use both the target and another table
+func otherTableName(cond sqtables.Expr, target *moniker.Moniker) *moniker.Moniker {
+	var other *moniker.Moniker
+	hasTarget := false
+	for _, col := range cond.ColRefs() {
+		switch {
+		case col.TableName == nil:
+		case moniker.Equal(col.TableName, target):
+			hasTarget = true
+		case other == nil:
+			other = col.TableName
+		}
+	}
+	if !hasTarget {
+		return nil
+	}
+	return other
+}
+
+// parseTargetAlias parses the optional [AS] alias after the name of the table that is changed by an
+//   UPDATE or DELETE. An identifier that is one of the keywords is not an alias
+func parseTargetAlias(tkns *tokens.TokenList, keywords ...string) string {
+	hasAs := tkns.IsA(tokens.As) && tkns.Peekx(1) != nil && tkns.Peekx(1).ID() == tokens.Ident
+	if hasAs {
+		tkns.Remove()
+	}
+	tkn := tkns.TestTkn(tokens.Ident)
+	if tkn == nil {
+		return ""
+	}
+	alias := tkn.(*tokens.ValueToken).Value()
+	for _, kw := range keywords {
+		if !hasAs && strings.EqualFold(alias, kw) {
+			return ""
+		}
+	}
+	tkns.Remove()
+	return alias
+}
+
+// countTables returns the number of different tables that are used by the expression
+func countTables(exp sqtables.Expr) int {
+	names := make(map[string]bool)
+	for _, col := range exp.ColRefs() {
+		if col.TableName != nil {
+			names[strings.ToLower(col.TableName.Show())] = true
+		}
+	}
+	return len(names)
+}
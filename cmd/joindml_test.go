@@ -0,0 +1,251 @@
+package cmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/cmd"
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+type JoinDMLData struct {
+	TestName string
+	Command  string
+	Explicit bool
+	ExpErr   string
+	ExpMsg   string
+	ExpVals  sqtypes.RawVals
+}
+
+func testJoinDMLFunc(profile *sqprofile.SQProfile, d JoinDMLData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		// Reset the data of the target table
+		_, _, err := cmd.Delete(sqtables.BeginTrans(profile, true), tokens.Tokenize("DELETE FROM jorders"))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		tkns := tokens.Tokenize("INSERT INTO jorders (id, status, qty) VALUES (1, \"new\", 10), (2, \"new\", 20), (3, \"new\", 30), (4, \"new\", 40)")
+		_, _, err = cmd.InsertInto(sqtables.BeginTrans(profile, true), tkns)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		tkns = tokens.Tokenize(d.Command)
+		dispFunc := sq.GetDispatchFunc(*tkns)
+		if dispFunc == nil {
+			t.Errorf("No function found for command %q", d.Command)
+			return
+		}
+		trans := sqtables.BeginTrans(profile, !d.Explicit)
+		msg, _, err := dispFunc(trans, tkns)
+		if d.Explicit {
+			if err != nil {
+				trans.Rollback()
+			} else {
+				err = trans.Commit()
+			}
+		}
+		if sqtest.CheckErr(t, err, d.ExpErr) && d.ExpVals == nil {
+			return
+		}
+		if err == nil && msg != d.ExpMsg {
+			t.Errorf("Actual msg %q does not match Expected msg %q", msg, d.ExpMsg)
+			return
+		}
+
+		// The whole target table is checked to make sure that only the expected changes were made
+		tab, err := sqtables.GetTable(profile, "jorders")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		ptrs, err := tab.GetRowPtrs(profile, nil, true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		data, err := tab.GetRowDataFromPtrs(profile, ptrs)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		cmpMsg := sqtypes.Compare2DValue(data.Vals, sqtypes.CreateValuesFromRaw(d.ExpVals), "Actual", "Expect", true)
+		if cmpMsg != "" {
+			t.Error(cmpMsg)
+		}
+	}
+}
+
+func TestJoinDML(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+
+	err := sq.ProcessSQFile("./testdata/joindmltests.sq")
+	if err != nil {
+		t.Errorf("Error setting up data for TestJoinDML: %s", err)
+		return
+	}
+	unchanged := sqtypes.RawVals{{1, "new", 10}, {2, "new", 20}, {3, "new", 30}, {4, "new", 40}}
+
+	data := []JoinDMLData{
+		{
+			TestName: "UPDATE FROM",
+			Command:  "UPDATE jorders SET status = s.status FROM jship s WHERE jorders.id = s.order_id",
+			ExpMsg:   "Updated 2 rows from table",
+			ExpVals:  sqtypes.RawVals{{1, "shipped", 10}, {2, "new", 20}, {3, "lost", 30}, {4, "new", 40}},
+		},
+		{
+			TestName: "UPDATE FROM reversed join with filter",
+			Command:  "UPDATE jorders SET qty = qty + s.carrier FROM jship s WHERE s.order_id = jorders.id AND s.status = \"shipped\"",
+			ExpMsg:   "Updated 1 rows from table",
+			ExpVals:  sqtypes.RawVals{{1, "new", 11}, {2, "new", 20}, {3, "new", 30}, {4, "new", 40}},
+		},
+		{
+			TestName: "UPDATE FROM condition on both tables",
+			Command:  "UPDATE jorders SET status = \"big\" FROM jship s WHERE jorders.id = s.order_id AND jorders.qty >= s.carrier * 15",
+			ExpMsg:   "Updated 1 rows from table",
+			ExpVals:  sqtypes.RawVals{{1, "new", 10}, {2, "new", 20}, {3, "big", 30}, {4, "new", 40}},
+		},
+		{
+			TestName: "UPDATE FROM with JOIN",
+			Command:  "UPDATE jorders SET status = c.name FROM jship s JOIN jcarrier c ON s.carrier = c.id WHERE jorders.id = s.order_id",
+			ExpMsg:   "Updated 2 rows from table",
+			ExpVals:  sqtypes.RawVals{{1, "ups", 10}, {2, "new", 20}, {3, "fedex", 30}, {4, "new", 40}},
+		},
+		{
+			TestName: "UPDATE FROM in a transaction",
+			Command:  "UPDATE jorders SET status = s.status FROM jship s WHERE jorders.id = s.order_id",
+			Explicit: true,
+			ExpMsg:   "Updated 2 rows from table",
+			ExpVals:  sqtypes.RawVals{{1, "shipped", 10}, {2, "new", 20}, {3, "lost", 30}, {4, "new", 40}},
+		},
+		{
+			TestName: "UPDATE FROM no rows",
+			Command:  "UPDATE jorders SET status = s.status FROM jship s WHERE jorders.id = s.order_id AND s.status = \"none\"",
+			ExpMsg:   "Updated 0 rows from table",
+			ExpVals:  unchanged,
+		},
+		{
+			TestName: "UPDATE FROM same row twice",
+			Command:  "UPDATE jorders SET status = d.status FROM jdups d WHERE jorders.id = d.order_id",
+			ExpErr:   "Error: UPDATE can not change the same row of jorders more than once",
+			ExpVals:  unchanged,
+		},
+		{
+			TestName: "UPDATE FROM without join",
+			Command:  "UPDATE jorders SET status = \"x\" FROM jship s WHERE s.status = \"lost\"",
+			ExpErr:   "Syntax Error: Expecting a WHERE condition that joins jorders to a table in the FROM clause",
+		},
+		{
+			TestName: "UPDATE FROM without WHERE",
+			Command:  "UPDATE jorders SET status = \"x\" FROM jship s",
+			ExpErr:   "Syntax Error: Expecting a WHERE condition that joins jorders to a table in the FROM clause",
+		},
+		{
+			TestName: "UPDATE FROM target table",
+			Command:  "UPDATE jorders SET status = \"x\" FROM jorders WHERE jorders.id = 1",
+			ExpErr:   "Error: Table jorders can not be used in the FROM clause without an alias",
+		},
+		{
+			TestName: "UPDATE FROM target table with an alias",
+			Command:  "UPDATE jorders SET status = o.status + \"x\" FROM jorders o WHERE jorders.id = o.id AND o.qty > 20",
+			ExpMsg:   "Updated 2 rows from table",
+			ExpVals:  sqtypes.RawVals{{1, "new", 10}, {2, "new", 20}, {3, "newx", 30}, {4, "newx", 40}},
+		},
+		{
+			TestName: "UPDATE self join with target alias",
+			Command:  "UPDATE jorders AS o SET qty = o.qty + p.qty FROM jorders p WHERE p.id = o.id - 1",
+			ExpMsg:   "Updated 3 rows from table",
+			ExpVals:  sqtypes.RawVals{{1, "new", 10}, {2, "new", 30}, {3, "new", 50}, {4, "new", 70}},
+		},
+		{
+			TestName: "UPDATE FROM non equi join",
+			Command:  "UPDATE jorders SET status = c.name FROM jcarrier c WHERE jorders.qty > c.id * 15 AND jorders.qty < c.id * 15 + 10",
+			ExpMsg:   "Updated 1 rows from table",
+			ExpVals:  sqtypes.RawVals{{1, "new", 10}, {2, "ups", 20}, {3, "new", 30}, {4, "new", 40}},
+		},
+		{
+			TestName: "UPDATE with target alias",
+			Command:  "UPDATE jorders o SET qty = o.qty + 1 WHERE o.id = 1",
+			ExpMsg:   "Updated 1 rows from table",
+			ExpVals:  sqtypes.RawVals{{1, "new", 11}, {2, "new", 20}, {3, "new", 30}, {4, "new", 40}},
+		},
+		{
+			TestName: "UPDATE FROM missing table",
+			Command:  "UPDATE jorders SET status = \"x\" FROM WHERE jorders.id = 1",
+			ExpErr:   "Syntax Error: No Tables defined for query",
+		},
+		{
+			TestName: "UPDATE FROM invalid column",
+			Command:  "UPDATE jorders SET status = s.nocol FROM jship s WHERE jorders.id = s.order_id",
+			ExpErr:   "Error: Column \"nocol\" not found in Table \"s\"",
+		},
+		{
+			TestName: "DELETE USING",
+			Command:  "DELETE FROM jorders USING jship s WHERE jorders.id = s.order_id",
+			ExpMsg:   "Deleted 2 rows from table",
+			ExpVals:  sqtypes.RawVals{{2, "new", 20}, {4, "new", 40}},
+		},
+		{
+			TestName: "DELETE USING joined twice",
+			Command:  "DELETE FROM jorders USING jdups d WHERE d.order_id = jorders.id",
+			ExpMsg:   "Deleted 1 rows from table",
+			ExpVals:  sqtypes.RawVals{{1, "new", 10}, {3, "new", 30}, {4, "new", 40}},
+		},
+		{
+			TestName: "DELETE USING with filter",
+			Command:  "DELETE FROM jorders USING jship s WHERE jorders.id = s.order_id AND s.status = \"lost\"",
+			ExpMsg:   "Deleted 1 rows from table",
+			ExpVals:  sqtypes.RawVals{{1, "new", 10}, {2, "new", 20}, {4, "new", 40}},
+		},
+		{
+			TestName: "DELETE USING no rows",
+			Command:  "DELETE FROM jorders USING jship s WHERE jorders.id = s.order_id AND s.status = \"none\"",
+			ExpMsg:   "Deleted 0 rows from table",
+			ExpVals:  unchanged,
+		},
+		{
+			TestName: "DELETE USING with target alias",
+			Command:  "DELETE FROM jorders AS o USING jship s WHERE o.id = s.order_id",
+			ExpMsg:   "Deleted 2 rows from table",
+			ExpVals:  sqtypes.RawVals{{2, "new", 20}, {4, "new", 40}},
+		},
+		{
+			TestName: "DELETE USING non equi join",
+			Command:  "DELETE FROM jorders USING jship s WHERE jorders.qty > s.carrier * 25",
+			ExpMsg:   "Deleted 2 rows from table",
+			ExpVals:  sqtypes.RawVals{{1, "new", 10}, {2, "new", 20}},
+		},
+		{
+			TestName: "DELETE with target alias",
+			Command:  "DELETE FROM jorders o WHERE o.id = 4",
+			ExpMsg:   "Deleted 1 rows from table",
+			ExpVals:  sqtypes.RawVals{{1, "new", 10}, {2, "new", 20}, {3, "new", 30}},
+		},
+		{
+			TestName: "DELETE USING without join",
+			Command:  "DELETE FROM jorders USING jship s WHERE s.status = \"lost\"",
+			ExpErr:   "Syntax Error: Expecting a WHERE condition that joins jorders to a table in the USING clause",
+		},
+		{
+			TestName: "DELETE USING RETURNING",
+			Command:  "DELETE FROM jorders USING jship s WHERE jorders.id = s.order_id RETURNING id",
+			ExpMsg:   "Deleted 2 rows from table",
+			ExpVals:  sqtypes.RawVals{{2, "new", 20}, {4, "new", 40}},
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testJoinDMLFunc(profile, row))
+
+	}
+}
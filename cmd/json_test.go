@@ -0,0 +1,184 @@
+package cmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/cmd"
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+func TestJSON(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	// Make sure datasets are by default in RowID order
+	sqtables.RowOrder = true
+
+	err := sq.ProcessSQFile("./testdata/jsontests.sq")
+	if err != nil {
+		t.Fatalf("Unable to load test data: %s", err)
+	}
+
+	data := []SelectData{
+		{
+			TestName: "Select json column",
+			Command:  "SELECT id, doc FROM docs WHERE id < 3",
+			ExpRows:  2,
+			ExpCols:  []string{"id", "doc"},
+			ExpVals:  sqtypes.RawVals{{1, sqtypes.SQJSON{Val: `{"a":{"x":"one","y":[10,20.5,true]},"b":2}`}}, {2, sqtypes.SQJSON{Val: "[1,2,3]"}}},
+		},
+		{
+			TestName: "Arrow operators",
+			Command:  "SELECT id, doc->'a', doc->>'b', doc->'a'->>'x' FROM docs",
+			ExpRows:  4,
+			ExpCols:  []string{"id", "(doc->a)", "(doc->>b)", "((doc->a)->>x)"},
+			ExpVals: sqtypes.RawVals{
+				{1, sqtypes.SQJSON{Val: `{"x":"one","y":[10,20.5,true]}`}, 2, "one"},
+				{2, nil, nil, nil},
+				{3, nil, nil, nil},
+				{4, sqtypes.SQJSON{Val: `{"x":null}`}, "text", nil},
+			},
+		},
+		{
+			TestName: "Arrow with array index",
+			Command:  "SELECT doc->>1, doc->'a'->'y'->2 FROM docs WHERE id < 3",
+			ExpRows:  2,
+			ExpCols:  []string{"(doc->>1)", "(((doc->a)->y)->2)"},
+			ExpVals:  sqtypes.RawVals{{nil, sqtypes.SQJSON{Val: "true"}}, {2, nil}},
+		},
+		{
+			TestName: "JSON comparison",
+			Command:  "SELECT id, doc->'a' = JSON('{\"x\": null}') FROM docs",
+			ExpRows:  4,
+			ExpCols:  []string{"id", "((doc->a)={\"x\":null})"},
+			ExpVals:  sqtypes.RawVals{{1, false}, {2, nil}, {3, nil}, {4, true}},
+		},
+		{
+			TestName: "JSON_EXTRACT",
+			Command:  "SELECT id, JSON_EXTRACT(doc, '$.a.y[1]'), json_extract(doc, '$.a.x') FROM docs WHERE id != 3",
+			ExpRows:  3,
+			ExpCols:  []string{"id", "JSON_EXTRACT(doc, $.a.y[1])", "JSON_EXTRACT(doc, $.a.x)"},
+			ExpVals:  sqtypes.RawVals{{1, 20.5, "one"}, {2, nil, nil}, {4, nil, nil}},
+		},
+		{
+			TestName: "JSON_EXTRACT whole document",
+			Command:  "SELECT JSON_EXTRACT(doc, '$') FROM docs WHERE id = 2",
+			ExpRows:  1,
+			ExpCols:  []string{"JSON_EXTRACT(doc, $)"},
+			ExpVals:  sqtypes.RawVals{{sqtypes.SQJSON{Val: "[1,2,3]"}}},
+		},
+		{
+			TestName: "Convert string to JSON",
+			Command:  "SELECT JSON(' { \"z\" : 1, \"a\" : [ ] } '), STRING(doc) FROM docs WHERE id = 2",
+			ExpRows:  1,
+			ExpCols:  []string{`{"a":[],"z":1}`, "STRING(doc)"},
+			ExpVals:  sqtypes.RawVals{{sqtypes.SQJSON{Val: `{"a":[],"z":1}`}, "[1,2,3]"}},
+		},
+		{
+			TestName: "JSON_ARRAYAGG",
+			Command:  "SELECT JSON_ARRAYAGG(name ORDER BY name), JSON_ARRAYAGG(doc->>'b') FROM docs",
+			ExpRows:  1,
+			ExpCols:  []string{"JSON_ARRAYAGG(name ORDER BY name)", "JSON_ARRAYAGG((doc->>b))"},
+			ExpVals:  sqtypes.RawVals{{sqtypes.SQJSON{Val: `["alpha","beta","delta","gamma"]`}, sqtypes.SQJSON{Val: `[2,"text"]`}}},
+		},
+		{
+			TestName: "JSON_OBJECTAGG",
+			Command:  "SELECT JSON_OBJECTAGG(name, doc) FROM docs WHERE id < 4",
+			ExpRows:  1,
+			ExpCols:  []string{"JSON_OBJECTAGG(name, doc)"},
+			ExpVals:  sqtypes.RawVals{{sqtypes.SQJSON{Val: `{"alpha":{"a":{"x":"one","y":[10,20.5,true]},"b":2},"beta":[1,2,3],"gamma":null}`}}},
+		},
+		{
+			TestName: "JSON aggregates with group by",
+			Command:  "SELECT id < 3, JSON_OBJECTAGG(name, id), JSON_ARRAYAGG(id ORDER BY id DESC) FROM docs GROUP BY id < 3",
+			ExpRows:  2,
+			ExpCols:  []string{"(id<3)", "JSON_OBJECTAGG(name, id)", "JSON_ARRAYAGG(id ORDER BY id DESC)"},
+			ExpVals: sqtypes.RawVals{
+				{false, sqtypes.SQJSON{Val: `{"delta":4,"gamma":3}`}, sqtypes.SQJSON{Val: "[4,3]"}},
+				{true, sqtypes.SQJSON{Val: `{"alpha":1,"beta":2}`}, sqtypes.SQJSON{Val: "[2,1]"}},
+			},
+		},
+		{
+			TestName: "Invalid JSON",
+			Command:  "SELECT JSON('{\"a\":') FROM docs",
+			ExpErr:   "Error: Unable to Convert \"{\\\"a\\\":\" to JSON",
+		},
+		{
+			TestName: "Invalid path",
+			Command:  "SELECT JSON_EXTRACT(doc, 'a.b') FROM docs",
+			ExpErr:   "Syntax Error: a.b is not a valid path for JSON_EXTRACT",
+		},
+		{
+			TestName: "Path not constant",
+			Command:  "SELECT JSON_EXTRACT(doc, name) FROM docs",
+			ExpErr:   "Syntax Error: The path for JSON_EXTRACT must be a constant",
+		},
+		{
+			TestName: "JSON_EXTRACT of string",
+			Command:  "SELECT JSON_EXTRACT(name, '$.a') FROM docs",
+			ExpErr:   "Error: JSON_EXTRACT can only be used with JSON values not STRING",
+		},
+		{
+			TestName: "Arrow with invalid key",
+			Command:  "SELECT doc->1.5 FROM docs",
+			ExpErr:   "Error: The key of -> must be a STRING or INT not FLOAT",
+		},
+		{
+			TestName: "JSON_OBJECTAGG missing value",
+			Command:  "SELECT JSON_OBJECTAGG(name) FROM docs",
+			ExpErr:   "Syntax Error: Function JSON_OBJECTAGG is missing , after the key",
+		},
+		{
+			TestName: "JSON_OBJECTAGG too many arguments",
+			Command:  "SELECT JSON_OBJECTAGG(name, id, doc) FROM docs",
+			ExpErr:   "Syntax Error: Function JSON_OBJECTAGG has too many arguments",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testSelectFunc(profile, row))
+
+	}
+
+	errData := []struct {
+		TestName string
+		Command  string
+		ExpErr   string
+	}{
+		{
+			TestName: "Insert invalid JSON",
+			Command:  "INSERT INTO docs (id, doc) VALUES (5, '{\"a\": 1,}')",
+			ExpErr:   "Error: Unable to Convert \"{\\\"a\\\": 1,}\" to JSON",
+		},
+		{
+			TestName: "Insert int into JSON",
+			Command:  "INSERT INTO docs (id, doc) VALUES (5, 1)",
+			ExpErr:   "Error: Type Mismatch: Column doc in Table docs has a type of JSON, Unable to set value of type INT",
+		},
+		{
+			TestName: "Update invalid JSON",
+			Command:  "UPDATE docs SET doc = name WHERE id = 1",
+			ExpErr:   "Error: Unable to Convert \"alpha\" to JSON",
+		},
+	}
+	for i, row := range errData {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName), func(t *testing.T) {
+			defer sqtest.PanicTestRecovery(t, "")
+
+			tkns := tokens.Tokenize(row.Command)
+			trans := sqtables.BeginTrans(profile, true)
+			var err error
+			if tkns.IsA(tokens.Update) {
+				_, _, err = cmd.Update(trans, tkns)
+			} else {
+				_, _, err = cmd.InsertInto(trans, tkns)
+			}
+			sqtest.CheckErr(t, err, row.ExpErr)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package cmd_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+type ReturningData struct {
+	TestName string
+	Command  string
+	Explicit bool
+	ExpErr   string
+	ExpCols  []string
+	ExpData  sqtypes.RawVals
+}
+
+func testReturningFunc(profile *sqprofile.SQProfile, d ReturningData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		tkns := tokens.Tokenize(d.Command)
+		dispFunc := sq.GetDispatchFunc(*tkns)
+		if dispFunc == nil {
+			t.Errorf("No function found for command %q", d.Command)
+			return
+		}
+		trans := sqtables.BeginTrans(profile, !d.Explicit)
+		_, data, err := dispFunc(trans, tkns)
+		if d.Explicit {
+			if err != nil {
+				trans.Rollback()
+			} else {
+				err = trans.Commit()
+			}
+		}
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+
+		if data == nil {
+			t.Error("No data was returned")
+			return
+		}
+		if !reflect.DeepEqual(data.GetColNames(), d.ExpCols) {
+			t.Errorf("Actual cols %v do not match Expected cols %v", data.GetColNames(), d.ExpCols)
+			return
+		}
+		if data.Len() != len(d.ExpData) {
+			t.Errorf("Actual rows %d do not match Expected rows %d", data.Len(), len(d.ExpData))
+			return
+		}
+		if data.Len() == 0 {
+			return
+		}
+		cmpMsg := sqtypes.Compare2DValue(data.Vals, sqtypes.CreateValuesFromRaw(d.ExpData), "Actual", "Expect", true)
+		if cmpMsg != "" {
+			t.Error(cmpMsg)
+		}
+	}
+}
+
+func TestReturning(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+
+	err := sq.ProcessSQFile("./testdata/returningtests.sq")
+	if err != nil {
+		t.Errorf("Error setting up data for TestReturning: %s", err)
+		return
+	}
+
+	data := []ReturningData{
+		{
+			TestName: "INSERT RETURNING *",
+			Command:  "INSERT INTO rettest (name) VALUES (\"a\"), (\"b\") RETURNING *",
+			ExpCols:  []string{"id", "name", "qty"},
+			ExpData:  sqtypes.RawVals{{1, "a", 5}, {2, "b", 5}},
+		},
+		{
+			TestName: "INSERT RETURNING expressions",
+			Command:  "INSERT INTO rettest (name, qty) VALUES (\"c\", 7) RETURNING id, qty * 2 AS dbl",
+			ExpCols:  []string{"id", "dbl"},
+			ExpData:  sqtypes.RawVals{{3, 14}},
+		},
+		{
+			TestName: "INSERT SELECT RETURNING",
+			Command:  "INSERT INTO rettest (name, qty) SELECT name, qty FROM rettest WHERE id = 1 RETURNING id, name",
+			ExpCols:  []string{"id", "name"},
+			ExpData:  sqtypes.RawVals{{4, "a"}},
+		},
+		{
+			TestName: "INSERT ON CONFLICT DO UPDATE RETURNING",
+			Command:  "INSERT INTO rettest (id, name) VALUES (1, \"z\"), (9, \"n\") ON CONFLICT (id) DO UPDATE SET name = excluded.name RETURNING id, name",
+			ExpCols:  []string{"id", "name"},
+			ExpData:  sqtypes.RawVals{{1, "z"}, {9, "n"}},
+		},
+		{
+			TestName: "INSERT ON CONFLICT DO NOTHING RETURNING",
+			Command:  "INSERT INTO rettest (id, name) VALUES (2, \"x\"), (10, \"y\") ON CONFLICT (id) DO NOTHING RETURNING id",
+			ExpCols:  []string{"id"},
+			ExpData:  sqtypes.RawVals{{10}},
+		},
+		{
+			TestName: "INSERT RETURNING no expressions",
+			Command:  "INSERT INTO rettest (name) VALUES (\"e\") RETURNING",
+			ExpErr:   "Syntax Error: No expressions defined for RETURNING",
+		},
+		{
+			TestName: "INSERT RETURNING invalid column",
+			Command:  "INSERT INTO rettest (name) VALUES (\"e\") RETURNING nocol",
+			ExpErr:   "Error: Column \"nocol\" not found in Table(s): rettest",
+		},
+		{
+			TestName: "INSERT RETURNING extra tokens",
+			Command:  "INSERT INTO rettest (name) VALUES (\"e\") RETURNING * id",
+			ExpErr:   "Syntax Error: Unexpected tokens after RETURNING clause:[IDENT=id]",
+		},
+		{
+			TestName: "UPDATE RETURNING",
+			Command:  "UPDATE rettest SET qty = qty + 1 WHERE id <= 2 RETURNING id, qty",
+			ExpCols:  []string{"id", "qty"},
+			ExpData:  sqtypes.RawVals{{1, 6}, {2, 6}},
+		},
+		{
+			TestName: "UPDATE RETURNING no rows",
+			Command:  "UPDATE rettest SET qty = 0 WHERE id = 99 RETURNING id",
+			ExpCols:  []string{"id"},
+			ExpData:  sqtypes.RawVals{},
+		},
+		{
+			TestName: "UPDATE RETURNING aggregate",
+			Command:  "UPDATE rettest SET qty = 0 RETURNING count()",
+			ExpErr:   "Syntax Error: Aggregate functions are not allowed in RETURNING clause",
+		},
+		{
+			TestName: "UPDATE RETURNING in a transaction",
+			Command:  "UPDATE rettest SET name = \"t\" WHERE id = 3 RETURNING id, name",
+			Explicit: true,
+			ExpCols:  []string{"id", "name"},
+			ExpData:  sqtypes.RawVals{{3, "t"}},
+		},
+		{
+			TestName: "DELETE RETURNING *",
+			Command:  "DELETE FROM rettest WHERE id >= 9 RETURNING *",
+			ExpCols:  []string{"id", "name", "qty"},
+			ExpData:  sqtypes.RawVals{{9, "n", 5}, {10, "y", 5}},
+		},
+		{
+			TestName: "DELETE RETURNING without WHERE",
+			Command:  "DELETE FROM rettest RETURNING id, name",
+			ExpCols:  []string{"id", "name"},
+			ExpData:  sqtypes.RawVals{{1, "z"}, {2, "b"}, {3, "t"}, {4, "a"}},
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testReturningFunc(profile, row))
+
+	}
+}
@@ -0,0 +1,160 @@
+package cmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtypes"
+)
+
+func TestSelectValues(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	// Make sure datasets are by default in RowID order
+	sqtables.RowOrder = true
+
+	err := sq.ProcessSQFile("./testdata/selectvaluestests.sq")
+	if err != nil {
+		t.Fatalf("Unable to load test data: %s", err)
+	}
+
+	data := []SelectData{
+		{
+			TestName: "Select without FROM",
+			Command:  "SELECT 1+2",
+			ExpRows:  1,
+			ExpCols:  []string{"3"},
+			ExpVals:  sqtypes.RawVals{{3}},
+		},
+		{
+			TestName: "Select without FROM multiple expressions",
+			Command:  "SELECT 1+2 AS total, \"test\" name, 2*3",
+			ExpRows:  1,
+			ExpCols:  []string{"total", "name", "6"},
+			ExpVals:  sqtypes.RawVals{{3, "test", 6}},
+		},
+		{
+			TestName: "Select without FROM function",
+			Command:  "SELECT NOW() <= NOW()",
+			ExpRows:  1,
+			ExpCols:  []string{"(NOW()<=NOW())"},
+			ExpVals:  sqtypes.RawVals{{true}},
+		},
+		{
+			TestName: "Select without FROM true WHERE",
+			Command:  "SELECT 1 WHERE 1 = 1",
+			ExpRows:  1,
+			ExpCols:  []string{"1"},
+			ExpVals:  sqtypes.RawVals{{1}},
+		},
+		{
+			TestName: "Select without FROM false WHERE",
+			Command:  "SELECT 1 WHERE 1 = 2",
+			ExpRows:  0,
+			ExpCols:  []string{"1"},
+		},
+		{
+			TestName: "Select without FROM aggregate",
+			Command:  "SELECT COUNT(*)",
+			ExpRows:  1,
+			ExpCols:  []string{"COUNT()"},
+			ExpVals:  sqtypes.RawVals{{1}},
+		},
+		{
+			TestName: "Select without FROM union",
+			Command:  "SELECT 1 UNION SELECT 2",
+			ExpRows:  2,
+			ExpCols:  []string{"1"},
+			ExpVals:  sqtypes.RawVals{{1}, {2}},
+		},
+		{
+			TestName: "Select without FROM column",
+			Command:  "SELECT id",
+			ExpErr:   "Syntax Error: Column \"id\" can not be used without a FROM clause",
+		},
+		{
+			TestName: "Select * without FROM",
+			Command:  "SELECT *",
+			ExpErr:   "Syntax Error: Expecting FROM",
+		},
+		{
+			TestName: "VALUES table",
+			Command:  "SELECT * FROM (VALUES (1, \"one\"), (2, \"two\")) AS v",
+			ExpRows:  2,
+			ExpCols:  []string{"column1", "column2"},
+			ExpVals:  sqtypes.RawVals{{1, "one"}, {2, "two"}},
+		},
+		{
+			TestName: "VALUES table with column aliases",
+			Command:  "SELECT v.num, v.word FROM (VALUES (1, \"one\"), (2, \"two\")) v (num, word) WHERE num > 1",
+			ExpRows:  1,
+			ExpCols:  []string{"v.num", "v.word"},
+			ExpVals:  sqtypes.RawVals{{2, "two"}},
+		},
+		{
+			TestName: "VALUES table with expressions and nulls",
+			Command:  "SELECT num, word FROM (VALUES (1+1, null), (3, \"three\")) AS v (num, word)",
+			ExpRows:  2,
+			ExpCols:  []string{"num", "word"},
+			ExpVals:  sqtypes.RawVals{{2, nil}, {3, "three"}},
+		},
+		{
+			TestName:    "Join to VALUES table",
+			Command:     "SELECT vprod.name, p.price FROM vprod JOIN (VALUES (1, 10), (3, 30)) p (id, price) ON vprod.id = p.id",
+			ExpRows:     2,
+			ExpCols:     []string{"vprod.name", "p.price"},
+			ExpVals:     sqtypes.RawVals{{"apple", 10}, {"plum", 30}},
+			SortResults: true,
+		},
+		{
+			TestName: "VALUES table first in join",
+			Command:  "SELECT p.price, vprod.name FROM (VALUES (2, 20)) p (id, price) LEFT OUTER JOIN vprod ON p.id = vprod.id",
+			ExpRows:  1,
+			ExpCols:  []string{"p.price", "vprod.name"},
+			ExpVals:  sqtypes.RawVals{{20, "pear"}},
+		},
+		{
+			TestName: "VALUES table missing alias",
+			Command:  "SELECT * FROM (VALUES (1, 2))",
+			ExpErr:   "Syntax Error: Expecting an alias after VALUES list",
+		},
+		{
+			TestName: "VALUES table rows of different length",
+			Command:  "SELECT * FROM (VALUES (1, 2), (3)) v",
+			ExpErr:   "Error: All rows of VALUES must have the same number of values: 2 != 1",
+		},
+		{
+			TestName: "VALUES table mixed types",
+			Command:  "SELECT * FROM (VALUES (1), (\"two\")) v",
+			ExpErr:   "Error: Column \"column1\" of VALUES has values of type INT and STRING",
+		},
+		{
+			TestName: "VALUES table wrong number of column aliases",
+			Command:  "SELECT * FROM (VALUES (1, 2)) v (a)",
+			ExpErr:   "Error: v has 2 columns available but 1 columns specified",
+		},
+		{
+			TestName: "VALUES table with DEFAULT",
+			Command:  "SELECT * FROM (VALUES (1, DEFAULT)) v",
+			ExpErr:   "Syntax Error: DEFAULT can not be used in a VALUES table",
+		},
+		{
+			TestName: "VALUES table with column",
+			Command:  "SELECT * FROM (VALUES (id)) v",
+			ExpErr:   "Syntax Error: Expression \"id\" did not reduce to a value",
+		},
+		{
+			TestName: "VALUES table missing bracket",
+			Command:  "SELECT * FROM (VALUES (1), (2) v",
+			ExpErr:   "Syntax Error: Expecting ) to finish VALUES list",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testSelectFunc(profile, row))
+
+	}
+}
@@ -0,0 +1,130 @@
+package cmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/cmd"
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+func TestSizedTypes(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	// Make sure datasets are by default in RowID order
+	sqtables.RowOrder = true
+
+	err := sq.ProcessSQFile("./testdata/sizedtests.sq")
+	if err != nil {
+		t.Fatalf("Unable to load test data: %s", err)
+	}
+
+	data := []SelectData{
+		{
+			TestName: "Select sized columns",
+			Command:  "SELECT id, code, name, qty, stock, total FROM parts",
+			ExpRows:  3,
+			ExpCols:  []string{"id", "code", "name", "qty", "stock", "total"},
+			ExpVals: sqtypes.RawVals{
+				{1, "AB  ", "bolt", 32767, -2147483648, 9223372036854775807},
+				{2, "WXYZ", "washer  ", -32768, 2147483647, 0},
+				{3, "Q   ", "héllo wörl", nil, nil, nil},
+			},
+		},
+		{
+			TestName: "Compare padded char",
+			Command:  "SELECT id FROM parts WHERE code = \"AB  \"",
+			ExpRows:  1,
+			ExpCols:  []string{"id"},
+			ExpVals:  sqtypes.RawVals{{1}},
+		},
+		{
+			TestName: "Compare char without padding",
+			Command:  "SELECT id, code FROM parts WHERE code = \"AB\" OR code > \"WXY \"",
+			ExpRows:  2,
+			ExpCols:  []string{"id", "code"},
+			ExpVals:  sqtypes.RawVals{{1, "AB  "}, {2, "WXYZ"}},
+		},
+		{
+			TestName: "Length of char",
+			Command:  "SELECT id, LENGTH(code), LENGTH(name) FROM parts",
+			ExpRows:  3,
+			ExpCols:  []string{"id", "LENGTH(code)", "LENGTH(name)"},
+			ExpVals:  sqtypes.RawVals{{1, 2, 4}, {2, 4, 8}, {3, 1, 10}},
+		},
+		{
+			TestName: "Char in an expression",
+			Command:  "SELECT code + \"-\" AS tag FROM parts WHERE id = 1",
+			ExpRows:  1,
+			ExpCols:  []string{"tag"},
+			ExpVals:  sqtypes.RawVals{{"AB-"}},
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testSelectFunc(profile, row))
+
+	}
+
+	errData := []struct {
+		TestName string
+		Command  string
+		ExpErr   string
+	}{
+		{
+			TestName: "Insert varchar too long",
+			Command:  "INSERT INTO parts (id, name) VALUES (5, \"screwdriver\")",
+			ExpErr:   "Error: Value \"screwdriver\" of Column name in Table parts does not fit in VARCHAR(10)",
+		},
+		{
+			TestName: "Insert char too long",
+			Command:  "INSERT INTO parts (id, code) VALUES (5, \"ABCDE\")",
+			ExpErr:   "Error: Value \"ABCDE\" of Column code in Table parts does not fit in CHAR(4)",
+		},
+		{
+			TestName: "Insert smallint too large",
+			Command:  "INSERT INTO parts (id, qty) VALUES (5, 32768)",
+			ExpErr:   "Error: Value 32768 of Column qty in Table parts is out of range for SMALLINT",
+		},
+		{
+			TestName: "Insert integer too small",
+			Command:  "INSERT INTO parts (id, stock) VALUES (5, -2147483649)",
+			ExpErr:   "Error: Value -2147483649 of Column stock in Table parts is out of range for INTEGER",
+		},
+		{
+			TestName: "Insert string into smallint",
+			Command:  "INSERT INTO parts (id, qty) VALUES (5, \"12\")",
+			ExpErr:   "Error: Type Mismatch: Column qty in Table parts has a type of INT, Unable to set value of type STRING",
+		},
+		{
+			TestName: "Update smallint out of range",
+			Command:  "UPDATE parts SET qty = qty + 1 WHERE id = 1",
+			ExpErr:   "Error: Value 32768 of Column qty in Table parts is out of range for SMALLINT",
+		},
+		{
+			TestName: "Update varchar too long",
+			Command:  "UPDATE parts SET name = name + \"s\" WHERE id = 3",
+			ExpErr:   "Error: Value \"héllo wörls\" of Column name in Table parts does not fit in VARCHAR(10)",
+		},
+	}
+	for i, row := range errData {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName), func(t *testing.T) {
+			defer sqtest.PanicTestRecovery(t, "")
+
+			tkns := tokens.Tokenize(row.Command)
+			trans := sqtables.BeginTrans(profile, true)
+			var err error
+			if tkns.IsA(tokens.Update) {
+				_, _, err = cmd.Update(trans, tkns)
+			} else {
+				_, _, err = cmd.InsertInto(trans, tkns)
+			}
+			sqtest.CheckErr(t, err, row.ExpErr)
+		})
+	}
+}
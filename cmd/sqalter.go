@@ -0,0 +1,372 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/wilphi/sqsrv/redo"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtables/column"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// AlterTable changes the structure of a table. The rows of the table are changed to fit the new structure.
+//	  This function will always return a nil dataset
+func AlterTable(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *sqtables.DataSet, error) {
+	var tableName string
+
+	if !trans.Auto() {
+		return "", nil, sqerr.New("DDL statements cannot be executed within a transaction")
+	}
+
+	log.Debug("ALTER TABLE command")
+
+	// Eat the ALTER TABLE tokens if they are there
+	tkns.IsARemove(tokens.Alter)
+	tkns.IsARemove(tokens.Table)
+
+	// make sure the next token is an Ident
+	if tkn := tkns.TestTkn(tokens.Ident); tkn != nil {
+		tableName = strings.ToLower(tkn.(*tokens.ValueToken).Value())
+		tkns.Remove()
+	} else {
+		return "", nil, sqerr.NewSyntax("Expecting name of table to Alter")
+	}
+
+	tab, err := sqtables.GetTable(trans.Profile(), tableName)
+	if err != nil {
+		return "", nil, err
+	}
+	if tab == nil {
+		return "", nil, sqerr.Newf("Table %q does not exist", tableName)
+	}
+
+	switch {
+	case tkns.IsAKeywordRemove("ADD"):
+		if isTableConstraint(tkns) {
+			err = alterAddCheck(trans, tkns, tab)
+		} else {
+			tkns.IsAKeywordRemove("COLUMN")
+			err = alterAddColumn(trans, tkns, tab)
+		}
+	case tkns.IsARemove(tokens.Drop):
+		if tkns.IsARemove(tokens.Constraint) {
+			err = alterDropConstraint(trans, tkns, tab)
+		} else if tkns.IsA(tokens.Primary) {
+			err = sqerr.New("ALTER TABLE DROP PRIMARY KEY is not supported, only CHECK constraints can be dropped")
+		} else {
+			tkns.IsAKeywordRemove("COLUMN")
+			err = alterDropColumn(trans, tkns, tab)
+		}
+	case tkns.IsAKeywordRemove("RENAME"):
+		if tkns.IsAKeywordRemove("TO") {
+			tableName, err = alterRenameTable(trans, tkns, tableName)
+		} else {
+			tkns.IsAKeywordRemove("COLUMN")
+			err = alterRenameColumn(trans, tkns, tab)
+		}
+	case tkns.IsARemove(tokens.Alter):
+		tkns.IsAKeywordRemove("COLUMN")
+		err = alterColumnType(trans, tkns, tab)
+	default:
+		err = sqerr.NewSyntax("Expecting ADD, DROP, RENAME or ALTER after the name of the table")
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	return tableName, nil, nil
+}
+
+// alterAddColumn processes ADD [COLUMN] column definition. The column is added to the end of the table
+func alterAddColumn(trans sqtables.Transaction, tkns *tokens.TokenList, tab *sqtables.TableDef) error {
+	tableName := tab.GetName(trans.Profile())
+	stmt := CreateTableStmt{TableName: tableName}
+
+	col, err := columnDefinition(tkns, &stmt)
+	if err != nil {
+		return err
+	}
+	if !tkns.IsEmpty() {
+		return sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+	}
+	if tab.FindColDef(trans.Profile(), col.ColName) != nil {
+		return sqerr.Newf("Column %s already exists in table %s", col.ColName, tableName)
+	}
+
+	// The sequence of an identity column must exist before its values can be added to the rows
+	for _, seq := range stmt.Sequences {
+		err = createSequence(trans, seq.SeqName, seq.Start, seq.Increment, tableName)
+		if err != nil {
+			return err
+		}
+	}
+	err = tab.AddColumn(trans.Profile(), col)
+	if err != nil {
+		for _, seq := range stmt.Sequences {
+			dropSequence(trans, seq.SeqName)
+		}
+		return err
+	}
+	err = redo.Send(redo.NewAlterTable(tableName, redo.AlterAddColumn, col.ColName, "", "", col))
+	if err != nil {
+		return err
+	}
+
+	// CHECK constraints of the column are added after the column
+	for _, con := range stmt.Constraints {
+		err = addCheck(trans, tab, con.(*sqtables.Check))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isTableConstraint returns true if the next tokens start a table constraint instead of a column
+func isTableConstraint(tkns *tokens.TokenList) bool {
+	return tkns.TestTkn(tokens.Check, tokens.Constraint, tokens.Primary, tokens.Unique, tokens.Foreign, tokens.Index) != nil
+}
+
+// alterAddCheck processes ADD [CONSTRAINT name] CHECK (expr). All rows of the table must satisfy the
+//   constraint. PRIMARY KEY, UNIQUE, FOREIGN KEY and INDEX constraints can not be added to an existing table
+func alterAddCheck(trans sqtables.Transaction, tkns *tokens.TokenList, tab *sqtables.TableDef) error {
+	typeTkn := tkns.Peek()
+	if typeTkn.ID() == tokens.Constraint && tkns.Peekx(2) != nil {
+		typeTkn = tkns.Peekx(2)
+	}
+	switch typeTkn.ID() {
+	case tokens.Primary, tokens.Foreign:
+		return sqerr.Newf("ALTER TABLE ADD %s KEY is not supported, only CHECK constraints can be added", tokens.IDName(typeTkn.ID()))
+	case tokens.Unique, tokens.Index:
+		return sqerr.Newf("ALTER TABLE ADD %s is not supported, only CHECK constraints can be added", tokens.IDName(typeTkn.ID()))
+	}
+
+	con, err := checkClause(tkns, "")
+	if err != nil {
+		return err
+	}
+	if !tkns.IsEmpty() {
+		return sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+	}
+
+	chk := con.(*sqtables.Check)
+	if chk.Name == "" {
+		// Find the next unused name for the constraint
+		tableName := tab.GetName(trans.Profile())
+		for n := 1; chk.Name == "" || tab.HasConstraint(trans.Profile(), chk.Name); n++ {
+			chk.Name = fmt.Sprintf("%s_check%d", tableName, n)
+		}
+	}
+	return addCheck(trans, tab, chk)
+}
+
+// addCheck adds a CHECK constraint to the table and records it in the transaction log
+func addCheck(trans sqtables.Transaction, tab *sqtables.TableDef, chk *sqtables.Check) error {
+	err := tab.AddConstraint(trans.Profile(), chk)
+	if err != nil {
+		return err
+	}
+	return redo.Send(redo.NewAlterTable(tab.GetName(trans.Profile()), redo.AlterAddCheck, chk.Name, "", chk.Text, column.Def{}))
+}
+
+// alterDropConstraint processes the rest of DROP CONSTRAINT name
+func alterDropConstraint(trans sqtables.Transaction, tkns *tokens.TokenList, tab *sqtables.TableDef) error {
+	tkn := tkns.TestTkn(tokens.Ident)
+	if tkn == nil {
+		return sqerr.NewSyntax("Expecting name of constraint to Drop")
+	}
+	name := strings.ToLower(tkn.(*tokens.ValueToken).Value())
+	tkns.Remove()
+	if !tkns.IsEmpty() {
+		return sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+	}
+
+	err := tab.DropConstraint(trans.Profile(), name)
+	if err != nil {
+		return err
+	}
+	return redo.Send(redo.NewAlterTable(tab.GetName(trans.Profile()), redo.AlterDropConstraint, name, "", "", column.Def{}))
+}
+
+// alterDropColumn processes DROP [COLUMN] name. The sequence of an identity column is dropped with the column
+func alterDropColumn(trans sqtables.Transaction, tkns *tokens.TokenList, tab *sqtables.TableDef) error {
+	colName, err := alterColName(trans, tkns, tab, "Drop")
+	if err != nil {
+		return err
+	}
+	if !tkns.IsEmpty() {
+		return sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+	}
+
+	seqName, err := identitySequence(trans, tab, colName)
+	if err != nil {
+		return err
+	}
+	tableName := tab.GetName(trans.Profile())
+	err = tab.DropColumn(trans.Profile(), colName)
+	if err != nil {
+		return err
+	}
+	err = redo.Send(redo.NewAlterTable(tableName, redo.AlterDropColumn, colName, "", "", column.Def{}))
+	if err != nil {
+		return err
+	}
+	if seqName != "" {
+		err = dropSequence(trans, seqName)
+	}
+	return err
+}
+
+// alterRenameColumn processes RENAME [COLUMN] name TO newname
+func alterRenameColumn(trans sqtables.Transaction, tkns *tokens.TokenList, tab *sqtables.TableDef) error {
+	colName, err := alterColName(trans, tkns, tab, "Rename")
+	if err != nil {
+		return err
+	}
+	if !tkns.IsAKeywordRemove("TO") {
+		return sqerr.NewSyntaxf("Expecting TO after the name of column %s", colName)
+	}
+	tkn := tkns.TestTkn(tokens.Ident)
+	if tkn == nil {
+		return sqerr.NewSyntaxf("Expecting the new name of column %s", colName)
+	}
+	newName := tkn.(*tokens.ValueToken).Value()
+	tkns.Remove()
+	if !tkns.IsEmpty() {
+		return sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+	}
+
+	err = tab.RenameColumn(trans.Profile(), colName, newName)
+	if err != nil {
+		return err
+	}
+	return redo.Send(redo.NewAlterTable(tab.GetName(trans.Profile()), redo.AlterRenameColumn, colName, newName, "", column.Def{}))
+}
+
+// alterRenameTable processes the rest of RENAME TO newname and returns the new name of the table
+func alterRenameTable(trans sqtables.Transaction, tkns *tokens.TokenList, tableName string) (string, error) {
+	tkn := tkns.TestTkn(tokens.Ident)
+	if tkn == nil {
+		return "", sqerr.NewSyntaxf("Expecting the new name of table %s", tableName)
+	}
+	newName := strings.ToLower(tkn.(*tokens.ValueToken).Value())
+	tkns.Remove()
+	if !tkns.IsEmpty() {
+		return "", sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+	}
+
+	err := sqtables.RenameTable(trans.Profile(), tableName, newName)
+	if err != nil {
+		return "", err
+	}
+	return newName, redo.Send(redo.NewAlterTable(tableName, redo.AlterRenameTable, "", newName, "", column.Def{}))
+}
+
+// alterColumnType processes ALTER [COLUMN] name [SET DATA] TYPE type. The values in the column are
+//   converted to the new type
+func alterColumnType(trans sqtables.Transaction, tkns *tokens.TokenList, tab *sqtables.TableDef) error {
+	colName, err := alterColName(trans, tkns, tab, "Alter")
+	if err != nil {
+		return err
+	}
+	if tkns.IsARemove(tokens.Set) && !tkns.IsAKeywordRemove("DATA") {
+		return sqerr.NewSyntaxf("Expecting DATA after SET for column %s", colName)
+	}
+	if !tkns.IsAKeywordRemove("TYPE") {
+		return sqerr.NewSyntaxf("Expecting TYPE after the name of column %s", colName)
+	}
+	col, err := columnType(tkns, colName)
+	if err != nil {
+		return err
+	}
+	if !tkns.IsEmpty() {
+		return sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+	}
+
+	seqName, err := identitySequence(trans, tab, colName)
+	if err != nil {
+		return err
+	}
+	if seqName != "" && col.ColType != tokens.Int {
+		return sqerr.Newf("Column %s is an IDENTITY and must be an INT", colName)
+	}
+
+	// The DEFAULT must give a value of the new type. The DEFAULT of an identity is already an INT
+	col.Default = tab.FindColDef(trans.Profile(), colName).Default
+	if col.Default != "" && seqName == "" {
+		col.Default, err = convertDefault(trans, tab.GetName(trans.Profile()), col)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tab.AlterColumnType(trans.Profile(), col)
+	if err != nil {
+		return err
+	}
+	return redo.Send(redo.NewAlterTable(tab.GetName(trans.Profile()), redo.AlterColumnType, colName, "", "", col))
+}
+
+// convertDefault returns the DEFAULT of the column for the new type of the column. If the DEFAULT gives
+//   a value of a different type it is wrapped in the conversion function of the new type. An error is
+//   returned if the value can not be converted or does not fit in the column
+func convertDefault(trans sqtables.Transaction, tableName string, col column.Def) (string, error) {
+	expr, err := GetExpr(tokens.Tokenize(col.Default), nil, 1)
+	if err != nil {
+		return "", err
+	}
+	val, err := expr.Evaluate(trans.Profile(), false)
+	if err != nil {
+		return "", err
+	}
+
+	def := col.Default
+	if !val.IsNull() && val.Type() != col.ColType {
+		val, err = val.Convert(col.ColType)
+		if err != nil {
+			return "", sqerr.Newf("DEFAULT %s of column %s can not be converted to %s", col.Default, col.ColName, col.TypeName())
+		}
+		def = tokens.Tokenize(fmt.Sprintf("%s(%s)", tokens.IDName(col.ColType), col.Default)).SQL()
+	}
+	_, err = sqtables.ColValue(&col, tableName, val)
+	if err != nil {
+		return "", err
+	}
+	return def, nil
+}
+
+// alterColName removes and returns the name of an existing column of the table
+func alterColName(trans sqtables.Transaction, tkns *tokens.TokenList, tab *sqtables.TableDef, action string) (string, error) {
+	tkn := tkns.TestTkn(tokens.Ident)
+	if tkn == nil {
+		return "", sqerr.NewSyntaxf("Expecting name of column to %s", action)
+	}
+	colName := tkn.(*tokens.ValueToken).Value()
+	tkns.Remove()
+	if tab.FindColDef(trans.Profile(), colName) == nil {
+		return "", sqerr.Newf("Column %s does not exist in table %s", colName, tab.GetName(trans.Profile()))
+	}
+	return colName, nil
+}
+
+// identitySequence returns the name of the sequence owned by the table that gives the values of an
+//   identity column. If the column is not an identity column a blank name is returned
+func identitySequence(trans sqtables.Transaction, tab *sqtables.TableDef, colName string) (string, error) {
+	col := tab.FindColDef(trans.Profile(), colName)
+	if col == nil || col.Default == "" {
+		return "", nil
+	}
+	seqs, err := sqtables.OwnedSequences(trans.Profile(), tab.GetName(trans.Profile()))
+	if err != nil {
+		return "", err
+	}
+	for _, seq := range seqs {
+		if col.Default == tokens.Tokenize(fmt.Sprintf("NEXTVAL(%q)", seq)).SQL() {
+			return seq, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,459 @@
+package cmd_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/wilphi/sqsrv/cmd"
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+type AlterTableData struct {
+	TestName     string
+	Command      string
+	ExpErr       string
+	ExpTableName string
+	ExpStr       string
+	Insert       string
+	Query        string
+	ExpCols      []string
+	ExpVals      sqtypes.RawVals
+	ManualTrans  bool
+}
+
+func testAlterTableFunc(profile *sqprofile.SQProfile, d AlterTableData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		tkns := tokens.Tokenize(d.Command)
+		trans := sqtables.BeginTrans(profile, !d.ManualTrans)
+		tname, data, err := cmd.AlterTable(trans, tkns)
+		if d.ManualTrans {
+			trans.Rollback()
+		}
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+
+		if data != nil {
+			t.Error("Alter Table function should always return nil data")
+			return
+		}
+		if tname != d.ExpTableName {
+			t.Errorf("TableName: %q was the expected return, but actual value is: %q", d.ExpTableName, tname)
+			return
+		}
+
+		if d.ExpStr != "" {
+			tab, err := sqtables.GetTable(profile, tname)
+			if err != nil || tab == nil {
+				t.Errorf("Table %s was not found by using GetTable", tname)
+				return
+			}
+			actStr := tab.String(profile)
+			if actStr != d.ExpStr {
+				t.Errorf("Altered table did not match expected: \nActual: %s\nExpected: %s", actStr, d.ExpStr)
+				return
+			}
+		}
+
+		if d.Insert != "" {
+			_, _, err = cmd.InsertInto(sqtables.BeginTrans(profile, true), tokens.Tokenize(d.Insert))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		if d.Query == "" {
+			return
+		}
+		_, data, err = cmd.Select(sqtables.BeginTrans(profile, true), tokens.Tokenize(d.Query))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(data.GetColNames(), d.ExpCols) {
+			t.Errorf("Expected Cols (%v) do not match actual cols (%v)", d.ExpCols, data.GetColNames())
+			return
+		}
+		msg := sqtypes.Compare2DValue(data.Vals, sqtypes.CreateValuesFromRaw(d.ExpVals), "Actual", "Expect", false)
+		if msg != "" {
+			t.Error(msg)
+		}
+	}
+}
+
+func TestAlterTable(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	// Make sure datasets are by default in RowID order
+	sqtables.RowOrder = true
+
+	err := sq.ProcessSQFile("./testdata/altertests.sq")
+	if err != nil {
+		t.Fatalf("Unable to load test data: %s", err)
+	}
+
+	data := []AlterTableData{
+		{
+			TestName:    "Alter in Transaction",
+			Command:     "ALTER TABLE altitems DROP COLUMN price",
+			ExpErr:      "Error: DDL statements cannot be executed within a transaction",
+			ManualTrans: true,
+		},
+		{
+			TestName: "Missing table name",
+			Command:  "ALTER TABLE",
+			ExpErr:   "Syntax Error: Expecting name of table to Alter",
+		},
+		{
+			TestName: "Invalid table",
+			Command:  "ALTER TABLE notatable DROP COLUMN price",
+			ExpErr:   "Error: Table \"notatable\" does not exist",
+		},
+		{
+			TestName: "Missing action",
+			Command:  "ALTER TABLE altitems price",
+			ExpErr:   "Syntax Error: Expecting ADD, DROP, RENAME or ALTER after the name of the table",
+		},
+		{
+			TestName:     "Add Column with default",
+			Command:      "ALTER TABLE altitems ADD COLUMN qty int DEFAULT 5 NOT NULL",
+			ExpTableName: "altitems",
+			ExpStr:       "altitems\n--------------------------------------\n\t{id, INT NOT NULL}\n\t{name, STRING}\n\t{price, FLOAT}\n\t{qty, INT NOT NULL DEFAULT 5}\n",
+			Query:        "SELECT id, qty FROM altitems",
+			ExpCols:      []string{"id", "qty"},
+			ExpVals:      sqtypes.RawVals{{1, 5}, {2, 5}, {3, 5}},
+		},
+		{
+			TestName:     "Add Column without COLUMN",
+			Command:      "ALTER TABLE altitems ADD note string",
+			ExpTableName: "altitems",
+			Query:        "SELECT id, note FROM altitems",
+			ExpCols:      []string{"id", "note"},
+			ExpVals:      sqtypes.RawVals{{1, nil}, {2, nil}, {3, nil}},
+		},
+		{
+			TestName: "Add Column NOT NULL without default",
+			Command:  "ALTER TABLE altitems ADD flag bool NOT NULL",
+			ExpErr:   "Error: Column \"flag\" in Table \"altitems\" can not be NULL",
+		},
+		{
+			TestName: "Add Column that exists",
+			Command:  "ALTER TABLE altitems ADD COLUMN name string",
+			ExpErr:   "Error: Column name already exists in table altitems",
+		},
+		{
+			TestName: "Add Column invalid type",
+			Command:  "ALTER TABLE altitems ADD COLUMN flag",
+			ExpErr:   "Syntax Error: Expecting column type",
+		},
+		{
+			TestName: "Add Column extra tokens",
+			Command:  "ALTER TABLE altitems ADD COLUMN flag bool flag2",
+			ExpErr:   "Syntax Error: Unexpected tokens after SQL command:[IDENT=flag2]",
+		},
+		{
+			TestName:     "Add Column with CHECK",
+			Command:      "ALTER TABLE altitems ADD COLUMN disc int DEFAULT 0 CHECK (disc >= 0)",
+			ExpTableName: "altitems",
+			ExpStr: "altitems\n--------------------------------------\n\t{id, INT NOT NULL}\n\t{name, STRING}\n\t{price, FLOAT}\n\t{qty, INT NOT NULL DEFAULT 5}\n\t{note, STRING}\n\t{disc, INT DEFAULT 0}\n" +
+				"--------------------------------------\n\tCONSTRAINT altitems_disc_check CHECK (disc >= 0)\n",
+		},
+		{
+			TestName:     "Add Identity Column",
+			Command:      "ALTER TABLE altcodes ADD COLUMN seq serial",
+			ExpTableName: "altcodes",
+			Query:        "SELECT id, seq FROM altcodes",
+			ExpCols:      []string{"id", "seq"},
+			ExpVals:      sqtypes.RawVals{{1, 1}, {2, 2}},
+		},
+		{
+			TestName:     "Add CHECK",
+			Command:      "ALTER TABLE altitems ADD CHECK (price > 0.0)",
+			ExpTableName: "altitems",
+		},
+		{
+			TestName:     "Add named CHECK",
+			Command:      "ALTER TABLE altitems ADD CONSTRAINT qty_max CHECK (qty < 10)",
+			ExpTableName: "altitems",
+			ExpStr: "altitems\n--------------------------------------\n\t{id, INT NOT NULL}\n\t{name, STRING}\n\t{price, FLOAT}\n\t{qty, INT NOT NULL DEFAULT 5}\n\t{note, STRING}\n\t{disc, INT DEFAULT 0}\n" +
+				"--------------------------------------\n\tCONSTRAINT altitems_disc_check CHECK (disc >= 0)\n" +
+				"\tCONSTRAINT altitems_check1 CHECK (price > 0.0)\n\tCONSTRAINT qty_max CHECK (qty < 10)\n",
+		},
+		{
+			TestName: "Add CHECK that rows violate",
+			Command:  "ALTER TABLE altitems ADD CONSTRAINT price_max CHECK (price < 2.0)",
+			ExpErr:   "Error: Row violates CHECK constraint price_max on table altitems",
+		},
+		{
+			TestName: "Add CHECK with duplicate name",
+			Command:  "ALTER TABLE altitems ADD CONSTRAINT qty_max CHECK (qty < 20)",
+			ExpErr:   "Syntax Error: The table altitems cannot have more than one constraint named qty_max",
+		},
+		{
+			TestName:     "Rename Column used by CHECK",
+			Command:      "ALTER TABLE altitems RENAME COLUMN qty TO quantity",
+			ExpTableName: "altitems",
+			ExpStr: "altitems\n--------------------------------------\n\t{id, INT NOT NULL}\n\t{name, STRING}\n\t{price, FLOAT}\n\t{quantity, INT NOT NULL DEFAULT 5}\n\t{note, STRING}\n\t{disc, INT DEFAULT 0}\n" +
+				"--------------------------------------\n\tCONSTRAINT altitems_disc_check CHECK (disc >= 0)\n" +
+				"\tCONSTRAINT altitems_check1 CHECK (price > 0.0)\n\tCONSTRAINT qty_max CHECK (quantity < 10)\n",
+			Query:   "SELECT id, quantity FROM altitems WHERE id = 1",
+			ExpCols: []string{"id", "quantity"},
+			ExpVals: sqtypes.RawVals{{1, 5}},
+		},
+		{
+			TestName: "Rename Column to existing",
+			Command:  "ALTER TABLE altitems RENAME name TO note",
+			ExpErr:   "Error: Column note already exists in table altitems",
+		},
+		{
+			TestName: "Rename Column missing TO",
+			Command:  "ALTER TABLE altitems RENAME name note",
+			ExpErr:   "Syntax Error: Expecting TO after the name of column name",
+		},
+		{
+			TestName: "Rename invalid Column",
+			Command:  "ALTER TABLE altitems RENAME COLUMN nocol TO note2",
+			ExpErr:   "Error: Column nocol does not exist in table altitems",
+		},
+		{
+			TestName: "Drop Column used by CHECK",
+			Command:  "ALTER TABLE altitems DROP COLUMN quantity",
+			ExpErr:   "Error: Column quantity is used by the constraint CONSTRAINT qty_max CHECK (quantity < 10) and can not be dropped",
+		},
+		{
+			TestName:     "Drop Constraint",
+			Command:      "ALTER TABLE altitems DROP CONSTRAINT qty_max",
+			ExpTableName: "altitems",
+		},
+		{
+			TestName: "Drop invalid Constraint",
+			Command:  "ALTER TABLE altitems DROP CONSTRAINT qty_max",
+			ExpErr:   "Error: Constraint qty_max does not exist on table altitems",
+		},
+		{
+			TestName:     "Drop Column",
+			Command:      "ALTER TABLE altitems DROP COLUMN quantity",
+			ExpTableName: "altitems",
+			ExpStr: "altitems\n--------------------------------------\n\t{id, INT NOT NULL}\n\t{name, STRING}\n\t{price, FLOAT}\n\t{note, STRING}\n\t{disc, INT DEFAULT 0}\n" +
+				"--------------------------------------\n\tCONSTRAINT altitems_disc_check CHECK (disc >= 0)\n" +
+				"\tCONSTRAINT altitems_check1 CHECK (price > 0.0)\n",
+			Query:   "SELECT * FROM altitems",
+			ExpCols: []string{"id", "name", "price", "note", "disc"},
+			ExpVals: sqtypes.RawVals{{1, "a", 1.5, nil, 0}, {2, "b", 2.5, nil, 0}, {3, nil, nil, nil, 0}},
+		},
+		{
+			TestName: "Drop invalid Column",
+			Command:  "ALTER TABLE altitems DROP COLUMN quantity",
+			ExpErr:   "Error: Column quantity does not exist in table altitems",
+		},
+		{
+			TestName: "Drop only Column",
+			Command:  "ALTER TABLE altrename DROP id",
+			ExpErr:   "Error: Column id is the only column in table altrename and can not be dropped",
+		},
+		{
+			TestName:     "Alter Column Type",
+			Command:      "ALTER TABLE altitems ALTER COLUMN id TYPE string",
+			ExpTableName: "altitems",
+			Query:        "SELECT id, name FROM altitems WHERE id = \"2\"",
+			ExpCols:      []string{"id", "name"},
+			ExpVals:      sqtypes.RawVals{{"2", "b"}},
+		},
+		{
+			TestName:     "Alter Column SET DATA TYPE",
+			Command:      "ALTER TABLE altitems ALTER id SET DATA TYPE int",
+			ExpTableName: "altitems",
+			Query:        "SELECT id, name FROM altitems WHERE id = 2",
+			ExpCols:      []string{"id", "name"},
+			ExpVals:      sqtypes.RawVals{{2, "b"}},
+		},
+		{
+			TestName:     "Alter Column Type used by CHECK",
+			Command:      "ALTER TABLE altitems ALTER COLUMN price TYPE decimal(5,1)",
+			ExpTableName: "altitems",
+			ExpStr: "altitems\n--------------------------------------\n\t{id, INT NOT NULL}\n\t{name, STRING}\n\t{price, DECIMAL(5,1)}\n\t{note, STRING}\n\t{disc, INT DEFAULT 0}\n" +
+				"--------------------------------------\n\tCONSTRAINT altitems_disc_check CHECK (disc >= 0)\n" +
+				"\tCONSTRAINT altitems_check1 CHECK (price > 0.0)\n",
+		},
+		{
+			TestName: "Alter Column Type invalid conversion",
+			Command:  "ALTER TABLE altcodes ALTER COLUMN code TYPE int",
+			ExpErr:   "Error: Unable to Convert \"x\" to an INT",
+		},
+		{
+			TestName: "Alter Column Type does not fit",
+			Command:  "ALTER TABLE altcodes ALTER COLUMN code TYPE char(1)",
+			ExpErr:   "Error: Value \"10\" of Column code in Table altcodes does not fit in CHAR(1)",
+		},
+		{
+			TestName: "Alter Column Type missing TYPE",
+			Command:  "ALTER TABLE altcodes ALTER COLUMN code int",
+			ExpErr:   "Syntax Error: Expecting TYPE after the name of column code",
+		},
+		{
+			TestName: "Alter Identity Column Type",
+			Command:  "ALTER TABLE altident ALTER COLUMN id TYPE string",
+			ExpErr:   "Error: Column id is an IDENTITY and must be an INT",
+		},
+		{
+			TestName:     "Drop Identity Column",
+			Command:      "ALTER TABLE altident DROP COLUMN id",
+			ExpTableName: "altident",
+			Query:        "SELECT * FROM altident",
+			ExpCols:      []string{"name"},
+			ExpVals:      sqtypes.RawVals{{"x"}, {"y"}},
+		},
+		{
+			TestName: "Rename Table to existing",
+			Command:  "ALTER TABLE altrename RENAME TO altexists",
+			ExpErr:   "Error: Invalid Name: Table altexists already exists",
+		},
+		{
+			TestName:     "Rename Table",
+			Command:      "ALTER TABLE altcodes RENAME TO AltNewCodes",
+			ExpTableName: "altnewcodes",
+			ExpStr:       "altnewcodes\n--------------------------------------\n\t{id, INT NOT NULL}\n\t{code, STRING}\n\t{seq, INT NOT NULL DEFAULT NEXTVAL ( \"altcodes_seq_seq\" )}\n",
+			Query:        "SELECT id, code FROM altnewcodes",
+			ExpCols:      []string{"id", "code"},
+			ExpVals:      sqtypes.RawVals{{1, "10"}, {2, "x"}},
+		},
+		{
+			TestName: "Renamed Table no longer exists",
+			Command:  "ALTER TABLE altcodes DROP COLUMN code",
+			ExpErr:   "Error: Table \"altcodes\" does not exist",
+		},
+		{
+			TestName:     "Add Column to table with Primary Key",
+			Command:      "ALTER TABLE altpk ADD COLUMN note string DEFAULT \"n\"",
+			ExpTableName: "altpk",
+			Query:        "SELECT id, note FROM altpk",
+			ExpCols:      []string{"id", "note"},
+			ExpVals:      sqtypes.RawVals{{1, "n"}, {2, "n"}, {3, "n"}},
+		},
+		{
+			TestName:     "Rename Primary Key Column",
+			Command:      "ALTER TABLE altpk RENAME COLUMN id TO pkid",
+			ExpTableName: "altpk",
+			Query:        "SELECT pkid, name FROM altpk",
+			ExpCols:      []string{"pkid", "name"},
+			ExpVals:      sqtypes.RawVals{{1, "a"}, {2, "b"}, {3, "c"}},
+		},
+		{
+			TestName:     "Alter Primary Key Column Type",
+			Command:      "ALTER TABLE altpk ALTER COLUMN pkid TYPE string",
+			ExpTableName: "altpk",
+			Query:        "SELECT pkid, name FROM altpk WHERE pkid = \"2\"",
+			ExpCols:      []string{"pkid", "name"},
+			ExpVals:      sqtypes.RawVals{{"2", "b"}},
+		},
+		{
+			TestName: "Add CHECK to table with Primary Key that rows violate",
+			Command:  "ALTER TABLE altpk ADD CONSTRAINT qty_small CHECK (qty < 15)",
+			ExpErr:   "Error: Row violates CHECK constraint qty_small on table altpk",
+		},
+		{
+			TestName:     "Drop Column from table with Primary Key",
+			Command:      "ALTER TABLE altpk DROP COLUMN note",
+			ExpTableName: "altpk",
+			ExpStr:       "altpk\n--------------------------------------\n\t{pkid, STRING NOT NULL}\n\t{name, STRING}\n\t{qty, INT}\n--------------------------------------\n\tPRIMARY KEY (pkid)\n",
+			Query:        "SELECT * FROM altpk",
+			ExpCols:      []string{"pkid", "name", "qty"},
+			ExpVals:      sqtypes.RawVals{{"1", "a", 10}, {"2", "b", 20}, {"3", "c", 30}},
+		},
+		{
+			TestName: "Alter Primary Key Column Type to duplicates",
+			Command:  "ALTER TABLE altpkf ALTER COLUMN k TYPE int",
+			ExpErr:   "Error: Index Creation - Index is not unique",
+		},
+		{
+			TestName:     "Failed Alter leaves table unchanged",
+			Command:      "ALTER TABLE altpkf ADD COLUMN qty int",
+			ExpTableName: "altpkf",
+			ExpStr:       "altpkf\n--------------------------------------\n\t{k, FLOAT NOT NULL}\n\t{name, STRING}\n\t{qty, INT}\n--------------------------------------\n\tPRIMARY KEY (k)\n",
+			Query:        "SELECT k, name FROM altpkf",
+			ExpCols:      []string{"k", "name"},
+			ExpVals:      sqtypes.RawVals{{1.2, "a"}, {1.4, "b"}},
+		},
+		{
+			TestName:     "Alter Column Type converts DEFAULT",
+			Command:      "ALTER TABLE altdef ALTER COLUMN amt TYPE string",
+			ExpTableName: "altdef",
+			ExpStr:       "altdef\n--------------------------------------\n\t{id, INT NOT NULL}\n\t{amt, STRING DEFAULT STRING ( 5 )}\n\t{code, STRING DEFAULT \"x1\"}\n\t{qty, STRING DEFAULT \"7\"}\n",
+			Insert:       "INSERT INTO altdef (id) VALUES (2)",
+			Query:        "SELECT id, amt FROM altdef",
+			ExpCols:      []string{"id", "amt"},
+			ExpVals:      sqtypes.RawVals{{1, "5"}, {2, "5"}},
+		},
+		{
+			TestName:     "Alter Column Type converts string DEFAULT",
+			Command:      "ALTER TABLE altdef ALTER COLUMN qty TYPE int",
+			ExpTableName: "altdef",
+			Insert:       "INSERT INTO altdef (id) VALUES (3)",
+			Query:        "SELECT id, qty FROM altdef",
+			ExpCols:      []string{"id", "qty"},
+			ExpVals:      sqtypes.RawVals{{1, 7}, {2, 7}, {3, 7}},
+		},
+		{
+			TestName: "Alter Column Type DEFAULT can not be converted",
+			Command:  "ALTER TABLE altdef ALTER COLUMN code TYPE int",
+			ExpErr:   "Error: DEFAULT \"x1\" of column code can not be converted to INT",
+		},
+		{
+			TestName: "Alter Column Type DEFAULT does not fit",
+			Command:  "ALTER TABLE altdef ALTER COLUMN code TYPE char(1)",
+			ExpErr:   "Error: Value \"x1\" of Column code in Table altdef does not fit in CHAR(1)",
+		},
+		{
+			TestName: "Add Primary Key not supported",
+			Command:  "ALTER TABLE altuniq ADD PRIMARY KEY (id)",
+			ExpErr:   "Error: ALTER TABLE ADD PRIMARY KEY is not supported, only CHECK constraints can be added",
+		},
+		{
+			TestName: "Add named Unique not supported",
+			Command:  "ALTER TABLE altuniq ADD CONSTRAINT uq_id UNIQUE uq_id (id)",
+			ExpErr:   "Error: ALTER TABLE ADD UNIQUE is not supported, only CHECK constraints can be added",
+		},
+		{
+			TestName: "Add Unique not supported",
+			Command:  "ALTER TABLE altuniq ADD UNIQUE uq_id (id)",
+			ExpErr:   "Error: ALTER TABLE ADD UNIQUE is not supported, only CHECK constraints can be added",
+		},
+		{
+			TestName: "Add Foreign Key not supported",
+			Command:  "ALTER TABLE altuniq ADD FOREIGN KEY fk_id (id)",
+			ExpErr:   "Error: ALTER TABLE ADD FOREIGN KEY is not supported, only CHECK constraints can be added",
+		},
+		{
+			TestName: "Add Index not supported",
+			Command:  "ALTER TABLE altuniq ADD INDEX (id)",
+			ExpErr:   "Error: ALTER TABLE ADD INDEX is not supported, only CHECK constraints can be added",
+		},
+		{
+			TestName: "Drop Primary Key not supported",
+			Command:  "ALTER TABLE altpkf DROP PRIMARY KEY",
+			ExpErr:   "Error: ALTER TABLE DROP PRIMARY KEY is not supported, only CHECK constraints can be dropped",
+		},
+		{
+			TestName: "Drop Unique not supported",
+			Command:  "ALTER TABLE altuniq DROP CONSTRAINT uq_code",
+			ExpErr:   "Error: Constraint uq_code on table altuniq is a UNIQUE constraint, only CHECK constraints can be dropped",
+		},
+		{
+			TestName: "Rename Table missing name",
+			Command:  "ALTER TABLE altrename RENAME TO",
+			ExpErr:   "Syntax Error: Expecting the new name of table altrename",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testAlterTableFunc(profile, row))
+	}
+}
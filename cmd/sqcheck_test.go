@@ -0,0 +1,87 @@
+package cmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/cmd"
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtypes"
+)
+
+func TestCheckConstraints(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	// Make sure datasets are by default in RowID order
+	sqtables.RowOrder = true
+
+	err := sq.ProcessSQFile("./testdata/checktests.sq")
+	if err != nil {
+		t.Fatalf("Unable to load test data: %s", err)
+	}
+
+	data := []ViewData{
+		{
+			TestName: "Insert valid rows",
+			Command:  "INSERT INTO chkitems (id, price, qty, status) VALUES (3, 0.0, 99, \"new\")",
+			Exec:     cmd.InsertInto,
+			ExpMsg:   "1 rows inserted into chkitems",
+		},
+		{
+			TestName: "Insert NULL passes check",
+			Command:  "INSERT INTO chkitems (id) VALUES (4)",
+			Exec:     cmd.InsertInto,
+			ExpMsg:   "1 rows inserted into chkitems",
+		},
+		{
+			TestName: "Insert violates column check",
+			Command:  "INSERT INTO chkitems (id, price) VALUES (5, -1.0)",
+			Exec:     cmd.InsertInto,
+			ExpErr:   "Error: Row violates CHECK constraint chkitems_price_check on table chkitems",
+		},
+		{
+			TestName: "Insert violates named check",
+			Command:  "INSERT INTO chkitems (id, status) VALUES (5, \"old\")",
+			Exec:     cmd.InsertInto,
+			ExpErr:   "Error: Row violates CHECK constraint item_status on table chkitems",
+		},
+		{
+			TestName: "Insert violates table check in second row",
+			Command:  "INSERT INTO chkitems (id, qty) VALUES (5, 1), (6, 100)",
+			Exec:     cmd.InsertInto,
+			ExpErr:   "Error: Row violates CHECK constraint chkitems_check1 on table chkitems",
+		},
+		{
+			TestName: "No rows inserted after violation",
+			Command:  "SELECT id FROM chkitems",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"id"},
+			ExpVals:  sqtypes.RawVals{{1}, {2}, {3}, {4}},
+		},
+		{
+			TestName: "Update valid",
+			Command:  "UPDATE chkitems SET status = \"done\" WHERE id = 1",
+			Exec:     cmd.Update,
+			ExpMsg:   "Updated 1 rows from table",
+		},
+		{
+			TestName: "Update violates check",
+			Command:  "UPDATE chkitems SET price = price - 2.0 WHERE id < 3",
+			Exec:     cmd.Update,
+			ExpErr:   "Error: Row violates CHECK constraint chkitems_price_check on table chkitems",
+		},
+		{
+			TestName: "No rows updated after violation",
+			Command:  "SELECT id, price, status FROM chkitems WHERE id < 3",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"id", "price", "status"},
+			ExpVals:  sqtypes.RawVals{{1, 1.5, "done"}, {2, 2.0, "done"}},
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testViewFunc(profile, row))
+	}
+}
@@ -1,18 +1,34 @@
 package cmd
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	log "github.com/sirupsen/logrus"
+	"github.com/wilphi/sqsrv/redo"
 	"github.com/wilphi/sqsrv/sqerr"
 	"github.com/wilphi/sqsrv/sqtables"
 	"github.com/wilphi/sqsrv/sqtables/column"
+	"github.com/wilphi/sqsrv/sqtypes"
 	"github.com/wilphi/sqsrv/tokens"
 )
 
-// CreateTableStmt -
+// CreateTableStmt - Query is the SELECT statement of a CREATE TABLE ... AS SELECT. The columns of
+//   the table are taken from its result
 type CreateTableStmt struct {
 	TableName   string
 	Cols        []column.Def
 	Constraints []sqtables.Constraint
+	Sequences   []CreateSequenceStmt
+	Query       *sqtables.Query
+}
+
+func init() {
+	// CHECK constraints are recreated from the SQL text of their expressions when the database is loaded
+	sqtables.ParseExprHook = func(sql string) (sqtables.Expr, error) {
+		return GetExpr(tokens.Tokenize(sql), nil, 0)
+	}
 }
 
 // CreateTable - Wraps CreateTableFromTokens
@@ -26,6 +42,10 @@ func CreateTable(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *s
 	if err != nil {
 		return "", nil, err
 	}
+	if tableStmt.Query != nil {
+		msg, err := executeCreateTableAs(trans, tableStmt)
+		return msg, nil, err
+	}
 	//	msg, err := CreateTableFromTokens(profile, tkns)
 	msg, err := executeCreateTable(trans, tableStmt)
 
@@ -34,7 +54,6 @@ func CreateTable(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *s
 
 // ParseCreateTable - Creates a table from array of tokens that represent a CREATE TABLE statement
 func ParseCreateTable(trans sqtables.Transaction, tkns *tokens.TokenList) (*CreateTableStmt, error) {
-	var err error
 	var stmt CreateTableStmt
 
 	log.Debug("CREATE TABLE command")
@@ -51,6 +70,18 @@ func ParseCreateTable(trans sqtables.Transaction, tkns *tokens.TokenList) (*Crea
 		return nil, sqerr.NewSyntax("Expecting name of table to create")
 	}
 
+	if tkns.IsARemove(tokens.As) {
+		if !tkns.IsA(tokens.Select) {
+			return nil, sqerr.NewSyntax("Expecting SELECT after AS in CREATE TABLE")
+		}
+		q, err := SelectParse(trans.Profile(), tkns)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Query = q
+		return &stmt, nil
+	}
+
 	if !tkns.IsARemove(tokens.OpenBracket) {
 		return nil, sqerr.NewSyntax("Expecting ( after name of table")
 	}
@@ -67,26 +98,23 @@ func ParseCreateTable(trans sqtables.Transaction, tkns *tokens.TokenList) (*Crea
 		if i > 0 && !isHangingComma {
 			return nil, sqerr.NewSyntax("Comma is required to separate columns")
 		}
-		// Ident(colName), Ident(typeVal), opt [opt NOT, NULL],  opt comma
-		if tkn := tkns.TestTkn(tokens.Ident); tkn != nil {
-			cName := tkn.(*tokens.ValueToken).Value()
-			tkns.Remove()
-			typeTkn := tkns.TestTkn(tokens.AllTypes...)
-			if typeTkn == nil {
-				return nil, sqerr.NewSyntax("Expecting column type")
+		// Table CHECK constraints can be given with the columns
+		if tkns.IsA(tokens.Check) || tkns.IsA(tokens.Constraint) {
+			con, err := checkClause(tkns, "")
+			if err != nil {
+				return nil, err
 			}
-			tkns.Remove()
-			// Check for optional NOT NULL or NULL
-			isNot := tkns.IsARemove(tokens.Not)
-
-			isNull := tkns.IsARemove(tokens.Null)
-
-			if isNot && !isNull {
-				// if there is a NOT there must be a NULL
-				return nil, sqerr.NewSyntax("Expecting a NULL after NOT in Column definition")
+			stmt.Constraints = append(stmt.Constraints, con)
+			i++
+			isHangingComma = tkns.IsARemove(tokens.Comma)
+			continue
+		}
+		// Ident(colName), Ident(typeVal), opt [opt NOT, NULL],  opt comma
+		if tkns.IsA(tokens.Ident) {
+			col, err := columnDefinition(tkns, &stmt)
+			if err != nil {
+				return nil, err
 			}
-
-			col := column.NewDef(cName, typeTkn.ID(), isNot)
 			stmt.Cols = append(stmt.Cols, col)
 			i++
 
@@ -102,10 +130,20 @@ func ParseCreateTable(trans sqtables.Transaction, tkns *tokens.TokenList) (*Crea
 	}
 
 	if tkns.IsARemove(tokens.Comma) {
-		stmt.Constraints, err = constraintClauses(tkns)
+		cons, err := constraintClauses(tkns)
 		if err != nil {
 			return nil, err
 		}
+		stmt.Constraints = append(stmt.Constraints, cons...)
+	}
+
+	// Name the table CHECK constraints that were not given a name
+	n := 0
+	for _, con := range stmt.Constraints {
+		if chk, ok := con.(*sqtables.Check); ok && chk.Name == "" {
+			n++
+			chk.Name = fmt.Sprintf("%s_check%d", strings.ToLower(stmt.TableName), n)
+		}
 	}
 	if !tkns.IsEmpty() {
 		return nil, sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
@@ -114,9 +152,338 @@ func ParseCreateTable(trans sqtables.Transaction, tkns *tokens.TokenList) (*Crea
 	return &stmt, nil
 }
 
+// columnDefinition processes the definition of a column: name type [options] [[NOT] NULL] [options]
+func columnDefinition(tkns *tokens.TokenList, stmt *CreateTableStmt) (column.Def, error) {
+	var col column.Def
+
+	tkn := tkns.TestTkn(tokens.Ident)
+	if tkn == nil {
+		return col, sqerr.NewSyntax("Expecting name of column")
+	}
+	cName := tkn.(*tokens.ValueToken).Value()
+	tkns.Remove()
+
+	// SERIAL is an INT column with values from a sequence
+	isSerial := false
+	if tkns.IsAKeywordRemove("SERIAL") {
+		col = column.NewDef(cName, tokens.Int, false)
+		isSerial = true
+	} else {
+		var err error
+		col, err = columnType(tkns, cName)
+		if err != nil {
+			return col, err
+		}
+	}
+
+	if isSerial {
+		err := identityColumn(stmt, &col, CreateSequenceStmt{Start: 1, Increment: 1})
+		if err != nil {
+			return col, err
+		}
+	}
+
+	// Check for optional DEFAULT or GENERATED AS IDENTITY that can be before or after NOT NULL
+	err := columnOptions(tkns, stmt, &col)
+	if err != nil {
+		return col, err
+	}
+
+	// Check for optional NOT NULL or NULL
+	isNot := tkns.IsARemove(tokens.Not)
+
+	isNull := tkns.IsARemove(tokens.Null)
+
+	if isNot && !isNull {
+		// if there is a NOT there must be a NULL
+		return col, sqerr.NewSyntax("Expecting a NULL after NOT in Column definition")
+	}
+	if isNull && !isNot && isIdentity(stmt, &col) {
+		return col, sqerr.NewSyntaxf("Column %s is an IDENTITY and can not be NULL", col.ColName)
+	}
+	col.IsNotNull = col.IsNotNull || isNot
+
+	err = columnOptions(tkns, stmt, &col)
+	return col, err
+}
+
+// columnType processes the type of a column including the size of DECIMAL, VARCHAR and CHAR types
+func columnType(tkns *tokens.TokenList, cName string) (column.Def, error) {
+	var err error
+	var col column.Def
+
+	tkns.UseKeyword(tokens.IsType)
+	typeTkn := tkns.TestTkn(tokens.AllTypes...)
+	if typeTkn == nil {
+		typeTkn = tkns.TestTkn(tokens.Varchar, tokens.Char, tokens.SmallInt, tokens.Integer, tokens.BigInt)
+	}
+	if typeTkn == nil {
+		return col, sqerr.NewSyntax("Expecting column type")
+	}
+	tkns.Remove()
+	precision, scale := 0, 0
+	if typeTkn.ID() == tokens.Decimal && tkns.IsA(tokens.OpenBracket) {
+		precision, scale, err = decimalSize(tkns)
+		if err != nil {
+			return col, err
+		}
+	}
+	if typeTkn.ID() == tokens.Varchar || typeTkn.ID() == tokens.Char {
+		precision, err = stringLength(tkns, typeTkn.ID())
+		if err != nil {
+			return col, err
+		}
+	}
+	if column.IsSizedType(typeTkn.ID()) {
+		col = column.NewSizedDef(cName, typeTkn.ID(), precision, false)
+	} else {
+		col = column.NewDef(cName, typeTkn.ID(), false)
+		col.Precision, col.Scale = precision, scale
+	}
+	return col, nil
+}
+
+// columnOptions processes the optional DEFAULT, GENERATED {ALWAYS | BY DEFAULT} AS IDENTITY and
+//   [CONSTRAINT name] CHECK (expr) clauses of a column definition
+func columnOptions(tkns *tokens.TokenList, stmt *CreateTableStmt, col *column.Def) error {
+	for {
+		var err error
+		switch {
+		case tkns.IsA(tokens.Default):
+			if isIdentity(stmt, col) {
+				return sqerr.NewSyntaxf("Column %s can not have both a DEFAULT and be an IDENTITY", col.ColName)
+			}
+			err = columnDefault(tkns, stmt.TableName, col)
+		case tkns.IsAKeywordRemove("GENERATED"):
+			err = columnIdentity(tkns, stmt, col)
+		case tkns.IsA(tokens.Check) || tkns.IsA(tokens.Constraint):
+			var con sqtables.Constraint
+			con, err = checkClause(tkns, strings.ToLower(stmt.TableName+"_"+col.ColName+"_check"))
+			if err == nil {
+				stmt.Constraints = append(stmt.Constraints, con)
+			}
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// columnIdentity processes the rest of GENERATED {ALWAYS | BY DEFAULT} AS IDENTITY [(sequence options)]
+//   after the GENERATED
+func columnIdentity(tkns *tokens.TokenList, stmt *CreateTableStmt, col *column.Def) error {
+	isAlways := tkns.IsAKeywordRemove("ALWAYS")
+	if !isAlways && !(tkns.IsARemove(tokens.By) && tkns.IsARemove(tokens.Default)) {
+		return sqerr.NewSyntaxf("Expecting ALWAYS or BY DEFAULT after GENERATED for column %s", col.ColName)
+	}
+	if !tkns.IsARemove(tokens.As) || !tkns.IsAKeywordRemove("IDENTITY") {
+		return sqerr.NewSyntaxf("Expecting AS IDENTITY after GENERATED for column %s", col.ColName)
+	}
+	seq := CreateSequenceStmt{Start: 1, Increment: 1}
+	if tkns.IsARemove(tokens.OpenBracket) {
+		err := sequenceOptions(tkns, &seq)
+		if err != nil {
+			return err
+		}
+		if !tkns.IsARemove(tokens.CloseBracket) {
+			return sqerr.NewSyntaxf("Expecting ) after the IDENTITY options for column %s", col.ColName)
+		}
+	}
+	col.IsGenerated = isAlways
+	return identityColumn(stmt, col, seq)
+}
+
+// identityColumn makes col an identity column. Its values come from a sequence that is created with the table
+func identityColumn(stmt *CreateTableStmt, col *column.Def, seq CreateSequenceStmt) error {
+	if col.ColType != tokens.Int {
+		return sqerr.NewSyntaxf("Column %s must be an INT to be an IDENTITY", col.ColName)
+	}
+	if isIdentity(stmt, col) {
+		return sqerr.NewSyntaxf("Column %s is already an IDENTITY", col.ColName)
+	}
+	if col.Default != "" {
+		return sqerr.NewSyntaxf("Column %s can not have both a DEFAULT and be an IDENTITY", col.ColName)
+	}
+	seq.SeqName = identitySeqName(stmt.TableName, col.ColName)
+	col.Default = tokens.Tokenize(fmt.Sprintf("NEXTVAL(%q)", seq.SeqName)).SQL()
+	col.IsNotNull = true
+	stmt.Sequences = append(stmt.Sequences, seq)
+	return nil
+}
+
+// isIdentity returns true if col has already been made an identity column
+func isIdentity(stmt *CreateTableStmt, col *column.Def) bool {
+	name := identitySeqName(stmt.TableName, col.ColName)
+	for _, seq := range stmt.Sequences {
+		if seq.SeqName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// identitySeqName returns the name of the sequence used by an identity column
+func identitySeqName(tableName, colName string) string {
+	return strings.ToLower(tableName + "_" + colName + "_seq")
+}
+
+// columnDefault processes the optional DEFAULT expression of a column definition. The expression is kept as
+//   SQL text in the column.Def so that it is evaluated each time a row is inserted
+func columnDefault(tkns *tokens.TokenList, tableName string, col *column.Def) error {
+	if !tkns.IsARemove(tokens.Default) {
+		return nil
+	}
+	if col.Default != "" {
+		return sqerr.NewSyntaxf("Column %s has more than one DEFAULT", col.ColName)
+	}
+
+	// Keep the tokens of the expression to create the SQL text
+	exprTkns := copyTokens(tkns)
+	exp, err := GetExpr(tkns, nil, 1, tokens.Comma, tokens.CloseBracket)
+	if err != nil {
+		return err
+	}
+	if exp == nil {
+		return sqerr.NewSyntaxf("Expecting an expression after DEFAULT for column %s", col.ColName)
+	}
+	if len(exp.ColRefs()) > 0 || exp.IsAggregate() {
+		return sqerr.NewSyntaxf("The DEFAULT for column %s can not use columns or aggregate functions", col.ColName)
+	}
+
+	// Make sure a constant value can be stored in the column. Functions such as NOW() are checked
+	//   when a row is inserted
+	exp, err = exp.Reduce()
+	if err != nil {
+		return err
+	}
+	if v, ok := exp.(*sqtables.ValueExpr); ok {
+		val, _ := v.Evaluate(nil, false)
+		_, err = sqtables.ColValue(col, tableName, val)
+		if err != nil {
+			return err
+		}
+	}
+	col.Default = tokens.CreateList(exprTkns[:len(exprTkns)-tkns.Len()]).SQL()
+	return nil
+}
+
+// copyTokens returns a copy of the remaining tokens so that the SQL text of an expression can be
+//   created after it has been parsed
+func copyTokens(tkns *tokens.TokenList) []tokens.Token {
+	saved := make([]tokens.Token, tkns.Len())
+	for i := range saved {
+		saved[i] = tkns.Peekx(i)
+	}
+	return saved
+}
+
+// checkClause processes [CONSTRAINT name] CHECK (expr). defName is the name of the constraint
+//   if CONSTRAINT is not given
+func checkClause(tkns *tokens.TokenList, defName string) (sqtables.Constraint, error) {
+	name := defName
+	if tkns.IsARemove(tokens.Constraint) {
+		tkn := tkns.TestTkn(tokens.Ident)
+		if tkn == nil {
+			return nil, sqerr.NewSyntax("Missing a name for the constraint")
+		}
+		name = tkn.(*tokens.ValueToken).Value()
+		tkns.Remove()
+	}
+	if !tkns.IsARemove(tokens.Check) {
+		return nil, sqerr.NewSyntaxf("Expecting CHECK after the name of constraint %s", name)
+	}
+	if !tkns.IsARemove(tokens.OpenBracket) {
+		return nil, sqerr.NewSyntax("Expecting ( after CHECK")
+	}
+
+	// Keep the tokens of the expression to create the SQL text
+	exprTkns := copyTokens(tkns)
+	exp, err := GetExpr(tkns, nil, 0, tokens.CloseBracket)
+	if err != nil {
+		return nil, err
+	}
+	if exp == nil {
+		return nil, sqerr.NewSyntax("Expecting an expression after CHECK (")
+	}
+	text := tokens.CreateList(exprTkns[:len(exprTkns)-tkns.Len()]).SQL()
+	if !tkns.IsARemove(tokens.CloseBracket) {
+		return nil, sqerr.NewSyntax("Expecting ) after the expression of CHECK")
+	}
+	return sqtables.NewCheck(name, exp, text), nil
+}
+
+// decimalSize processes the optional (precision [, scale]) that follows DECIMAL in a column definition
+func decimalSize(tkns *tokens.TokenList) (precision, scale int, err error) {
+	var ok bool
+
+	tkns.Remove()
+	precision, ok = sizeNum(tkns)
+	if !ok {
+		return 0, 0, sqerr.NewSyntax("Expecting the precision of DECIMAL")
+	}
+	if precision < 1 || precision > sqtypes.MaxDecimalPrecision {
+		return 0, 0, sqerr.NewSyntaxf("The precision of DECIMAL must be between 1 and %d", sqtypes.MaxDecimalPrecision)
+	}
+	if tkns.IsARemove(tokens.Comma) {
+		scale, ok = sizeNum(tkns)
+		if !ok || scale > precision {
+			return 0, 0, sqerr.NewSyntax("The scale of DECIMAL must be between 0 and the precision")
+		}
+	}
+	if !tkns.IsARemove(tokens.CloseBracket) {
+		return 0, 0, sqerr.NewSyntax("Expecting ) after the precision and scale of DECIMAL")
+	}
+	return precision, scale, nil
+}
+
+// stringLength processes the optional (length) that follows VARCHAR or CHAR in a column definition. A VARCHAR
+//   without a length can have any length and a CHAR without a length has a length of 1
+func stringLength(tkns *tokens.TokenList, typeID tokens.TokenID) (int, error) {
+	if !tkns.IsARemove(tokens.OpenBracket) {
+		if typeID == tokens.Char {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	length, ok := sizeNum(tkns)
+	if !ok || length < 1 {
+		return 0, sqerr.NewSyntaxf("The length of %s must be a number greater than 0", tokens.IDName(typeID))
+	}
+	if !tkns.IsARemove(tokens.CloseBracket) {
+		return 0, sqerr.NewSyntaxf("Expecting ) after the length of %s", tokens.IDName(typeID))
+	}
+	return length, nil
+}
+
+// sizeNum removes and returns the non-negative integer at the start of tkns
+func sizeNum(tkns *tokens.TokenList) (int, bool) {
+	tkn := tkns.TestTkn(tokens.Num)
+	if tkn == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(tkn.(*tokens.ValueToken).Value())
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	tkns.Remove()
+	return n, true
+}
+
 func executeCreateTable(trans sqtables.Transaction, stmt *CreateTableStmt) (string, error) {
 
 	log.Debug("Creating table ", stmt.TableName)
+	// Make sure the sequences of the identity columns can be created before creating the table
+	for _, seq := range stmt.Sequences {
+		s, err := sqtables.GetSequence(trans.Profile(), seq.SeqName)
+		if err != nil {
+			return "", err
+		}
+		if s != nil {
+			return "", sqerr.Newf("Invalid Name: Sequence %s already exists", seq.SeqName)
+		}
+	}
 	table := sqtables.CreateTableDef(stmt.TableName, stmt.Cols)
 	err := table.AddConstraints(trans.Profile(), stmt.Constraints)
 	if err != nil {
@@ -127,12 +494,94 @@ func executeCreateTable(trans sqtables.Transaction, stmt *CreateTableStmt) (stri
 		return "", err
 	}
 
-	//	err = redo.Send(redo.NewCreateDDL(ableName, cols))
+	// The CHECK constraints are logged with the table so that they are recreated on recovery
+	var checks []sqtables.DBCheck
+	for _, con := range stmt.Constraints {
+		if chk, ok := con.(*sqtables.Check); ok {
+			checks = append(checks, sqtables.DBCheck{Name: chk.Name, Text: chk.Text})
+		}
+	}
+	err = redo.Send(redo.NewCreateDDL(stmt.TableName, stmt.Cols, checks...))
+	if err != nil {
+		return "", err
+	}
+
+	for _, seq := range stmt.Sequences {
+		err = createSequence(trans, seq.SeqName, seq.Start, seq.Increment, stmt.TableName)
+		if err != nil {
+			return "", err
+		}
+	}
 
 	log.Trace(table)
 	return stmt.TableName, err
 }
 
+// executeCreateTableAs creates a table from the result of the SELECT statement and adds the rows of the
+//   result to it. The type of each column is inferred from the result. If the rows can not be added the
+//   table is dropped
+func executeCreateTableAs(trans sqtables.Transaction, stmt *CreateTableStmt) (string, error) {
+	profile := trans.Profile()
+
+	log.Debug("Creating table ", stmt.TableName, " from SELECT")
+	data, err := SelectExecute(profile, stmt.Query)
+	if err != nil {
+		return "", err
+	}
+	cols, err := sqtables.ColsFromDataSet(stmt.TableName, nil, data)
+	if err != nil {
+		return "", err
+	}
+	for _, col := range cols {
+		if !isColName(col.ColName) {
+			return "", sqerr.Newf("Column %q is not a valid column name, use AS to give it a name", col.ColName)
+		}
+		if !isColType(col.ColType) {
+			return "", sqerr.Newf("Unable to determine the type of column %s", col.ColName)
+		}
+	}
+
+	table := sqtables.CreateTableDef(stmt.TableName, cols)
+	err = sqtables.CreateTable(profile, table)
+	if err != nil {
+		return "", err
+	}
+	tableName := table.GetName(profile)
+
+	rows, err := sqtables.NewDataSet(profile, sqtables.NewTableListFromTableDef(profile, table), sqtables.ColsToExpr(table.GetCols(profile)))
+	if err == nil {
+		rows.Vals = data.Vals
+		_, err = table.AddRows(trans, rows)
+	}
+	if err != nil {
+		sqtables.DropTable(profile, tableName)
+		return "", err
+	}
+
+	err = redo.Send(redo.NewCreateDDL(tableName, cols))
+	if err != nil {
+		return "", err
+	}
+
+	return tableName, nil
+}
+
+// isColName returns true if the name can be used as the name of a column
+func isColName(name string) bool {
+	tkns := tokens.Tokenize(name)
+	return tkns.Len() == 1 && tkns.IsA(tokens.Ident)
+}
+
+// isColType returns true if the token is the type of a column
+func isColType(id tokens.TokenID) bool {
+	for _, typ := range tokens.AllTypes {
+		if typ == id {
+			return true
+		}
+	}
+	return false
+}
+
 func constraintClauses(tkns *tokens.TokenList) ([]sqtables.Constraint, error) {
 	var cons []sqtables.Constraint
 	var name string
@@ -196,6 +645,13 @@ func constraintClauses(tkns *tokens.TokenList) ([]sqtables.Constraint, error) {
 				return nil, err
 			}
 			cons = append(cons, sqtables.NewForeignKey(name, cols))
+		case tokens.Check, tokens.Constraint:
+			// Process Check constraint
+			con, err := checkClause(tkns, "")
+			if err != nil {
+				return nil, err
+			}
+			cons = append(cons, con)
 		case tokens.Index:
 			// Process Index
 			tkns.Remove()
@@ -214,7 +670,7 @@ func constraintClauses(tkns *tokens.TokenList) ([]sqtables.Constraint, error) {
 		}
 	}
 	if isHangingComma {
-		return nil, sqerr.NewSyntax("Expecting a constraint clause (Primary Key, Foreign, Index, Unique) after comma")
+		return nil, sqerr.NewSyntax("Expecting a constraint clause (Primary Key, Foreign, Index, Unique, Check) after comma")
 	}
 	return cons, nil
 }
@@ -5,9 +5,11 @@ import (
 	"testing"
 
 	"github.com/wilphi/sqsrv/cmd"
+	"github.com/wilphi/sqsrv/sq"
 	"github.com/wilphi/sqsrv/sqprofile"
 	"github.com/wilphi/sqsrv/sqtables"
 	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
 	"github.com/wilphi/sqsrv/tokens"
 )
 
@@ -143,7 +145,7 @@ func TestCreateTable(t *testing.T) {
 		{
 			TestName:     "CREATE TABLE with missing constraint",
 			Command:      "CREATE TABLE createpk (col1 int not null, col2 string not null, col3 bool), ",
-			ExpErr:       "Syntax Error: Expecting a constraint clause (Primary Key, Foreign, Index, Unique) after comma",
+			ExpErr:       "Syntax Error: Expecting a constraint clause (Primary Key, Foreign, Index, Unique, Check) after comma",
 			ExpTableName: "createpk",
 			ExpStr:       "",
 		},
@@ -289,6 +291,158 @@ func TestCreateTable(t *testing.T) {
 			ExpErr:       "Syntax Error: Index Constraint not fully implemented",
 			ExpTableName: "createidx",
 		},
+		{
+			TestName:     "CREATE TABLE Decimal",
+			Command:      "CREATE TABLE createdec (col1 decimal(10,2) not null, col2 numeric(5), col3 decimal)",
+			ExpErr:       "",
+			ExpTableName: "createdec",
+			ExpStr:       "createdec\n--------------------------------------\n\t{col1, DECIMAL(10,2) NOT NULL}\n\t{col2, DECIMAL(5,0)}\n\t{col3, DECIMAL}\n",
+		},
+		{
+			TestName:     "CREATE TABLE Decimal missing precision",
+			Command:      "CREATE TABLE createdec2 (col1 decimal())",
+			ExpErr:       "Syntax Error: Expecting the precision of DECIMAL",
+			ExpTableName: "createdec2",
+		},
+		{
+			TestName:     "CREATE TABLE Decimal zero precision",
+			Command:      "CREATE TABLE createdec2 (col1 decimal(0))",
+			ExpErr:       "Syntax Error: The precision of DECIMAL must be between 1 and 1000",
+			ExpTableName: "createdec2",
+		},
+		{
+			TestName:     "CREATE TABLE Decimal scale too large",
+			Command:      "CREATE TABLE createdec2 (col1 decimal(5,6))",
+			ExpErr:       "Syntax Error: The scale of DECIMAL must be between 0 and the precision",
+			ExpTableName: "createdec2",
+		},
+		{
+			TestName:     "CREATE TABLE Decimal missing )",
+			Command:      "CREATE TABLE createdec2 (col1 decimal(5,2 , col2 int)",
+			ExpErr:       "Syntax Error: Expecting ) after the precision and scale of DECIMAL",
+			ExpTableName: "createdec2",
+		},
+		{
+			TestName:     "CREATE TABLE Sized types",
+			Command:      "CREATE TABLE createsized (col1 varchar(20) not null, col2 char(3), col3 char, col4 varchar, col5 smallint, col6 integer, col7 bigint)",
+			ExpErr:       "",
+			ExpTableName: "createsized",
+			ExpStr: "createsized\n--------------------------------------\n\t{col1, VARCHAR(20) NOT NULL}\n\t{col2, CHAR(3)}\n\t{col3, CHAR(1)}\n" +
+				"\t{col4, VARCHAR}\n\t{col5, SMALLINT}\n\t{col6, INTEGER}\n\t{col7, BIGINT}\n",
+		},
+		{
+			TestName:     "CREATE TABLE Varchar zero length",
+			Command:      "CREATE TABLE createsized2 (col1 varchar(0))",
+			ExpErr:       "Syntax Error: The length of VARCHAR must be a number greater than 0",
+			ExpTableName: "createsized2",
+		},
+		{
+			TestName:     "CREATE TABLE Char missing length",
+			Command:      "CREATE TABLE createsized2 (col1 char())",
+			ExpErr:       "Syntax Error: The length of CHAR must be a number greater than 0",
+			ExpTableName: "createsized2",
+		},
+		{
+			TestName:     "CREATE TABLE Varchar missing )",
+			Command:      "CREATE TABLE createsized2 (col1 varchar(5, col2 int)",
+			ExpErr:       "Syntax Error: Expecting ) after the length of VARCHAR",
+			ExpTableName: "createsized2",
+		},
+		{
+			TestName:     "CREATE TABLE Defaults",
+			Command:      "CREATE TABLE createdef (col1 int default 5 not null, col2 varchar(10) not null default 'it''s', col3 timestamp default now(), col4 uuid default gen_random_uuid(), col5 float default -1.5 * 2.0, col6 bool default null)",
+			ExpErr:       "",
+			ExpTableName: "createdef",
+			ExpStr: "createdef\n--------------------------------------\n\t{col1, INT NOT NULL DEFAULT 5}\n\t{col2, VARCHAR(10) NOT NULL DEFAULT \"it's\"}\n" +
+				"\t{col3, TIMESTAMP DEFAULT NOW ( )}\n\t{col4, UUID DEFAULT GEN_RANDOM_UUID ( )}\n\t{col5, FLOAT DEFAULT - 1.5 * 2.0}\n\t{col6, BOOL DEFAULT NULL}\n",
+		},
+		{
+			TestName:     "CREATE TABLE Default missing expression",
+			Command:      "CREATE TABLE createdef2 (col1 int default, col2 int)",
+			ExpErr:       "Syntax Error: Expecting an expression after DEFAULT for column col1",
+			ExpTableName: "createdef2",
+		},
+		{
+			TestName:     "CREATE TABLE Default twice",
+			Command:      "CREATE TABLE createdef2 (col1 int default 1 not null default 2)",
+			ExpErr:       "Syntax Error: Column col1 has more than one DEFAULT",
+			ExpTableName: "createdef2",
+		},
+		{
+			TestName:     "CREATE TABLE Default with column",
+			Command:      "CREATE TABLE createdef2 (col1 int default col2 + 1, col2 int)",
+			ExpErr:       "Syntax Error: The DEFAULT for column col1 can not use columns or aggregate functions",
+			ExpTableName: "createdef2",
+		},
+		{
+			TestName:     "CREATE TABLE Default wrong type",
+			Command:      "CREATE TABLE createdef2 (col1 int default \"abc\")",
+			ExpErr:       "Error: Type Mismatch: Column col1 in Table createdef2 has a type of INT, Unable to set value of type STRING",
+			ExpTableName: "createdef2",
+		},
+		{
+			TestName:     "CREATE TABLE Default does not fit",
+			Command:      "CREATE TABLE createdef2 (col1 char(2) default \"abc\")",
+			ExpErr:       "Error: Value \"abc\" of Column col1 in Table createdef2 does not fit in CHAR(2)",
+			ExpTableName: "createdef2",
+		},
+		{
+			TestName:     "CREATE TABLE Checks",
+			Command:      "CREATE TABLE createchk (price float not null check (price >= 0.0), qty int constraint qty_pos check (qty > 0), status string, check (status = \"new\" or status = \"done\")), check (qty * 2 < 100)",
+			ExpErr:       "",
+			ExpTableName: "createchk",
+			ExpStr: "createchk\n--------------------------------------\n\t{price, FLOAT NOT NULL}\n\t{qty, INT}\n\t{status, STRING}\n--------------------------------------\n" +
+				"\tCONSTRAINT createchk_price_check CHECK (price >= 0.0)\n\tCONSTRAINT qty_pos CHECK (qty > 0)\n" +
+				"\tCONSTRAINT createchk_check1 CHECK (status = \"new\" OR status = \"done\")\n\tCONSTRAINT createchk_check2 CHECK (qty * 2 < 100)\n",
+		},
+		{
+			TestName:     "CREATE TABLE Check missing (",
+			Command:      "CREATE TABLE createchk2 (col1 int check col1 > 0)",
+			ExpErr:       "Syntax Error: Expecting ( after CHECK",
+			ExpTableName: "createchk2",
+		},
+		{
+			TestName:     "CREATE TABLE Check missing expression",
+			Command:      "CREATE TABLE createchk2 (col1 int check ())",
+			ExpErr:       "Syntax Error: Expecting an expression after CHECK (",
+			ExpTableName: "createchk2",
+		},
+		{
+			TestName:     "CREATE TABLE Check missing )",
+			Command:      "CREATE TABLE createchk2 (col1 int check (col1 > 0 col2)",
+			ExpErr:       "Syntax Error: Expecting ) after the expression of CHECK",
+			ExpTableName: "createchk2",
+		},
+		{
+			TestName:     "CREATE TABLE Constraint missing name",
+			Command:      "CREATE TABLE createchk2 (col1 int constraint check (col1 > 0))",
+			ExpErr:       "Syntax Error: Missing a name for the constraint",
+			ExpTableName: "createchk2",
+		},
+		{
+			TestName:     "CREATE TABLE Constraint missing CHECK",
+			Command:      "CREATE TABLE createchk2 (col1 int, constraint pos (col1 > 0))",
+			ExpErr:       "Syntax Error: Expecting CHECK after the name of constraint pos",
+			ExpTableName: "createchk2",
+		},
+		{
+			TestName:     "CREATE TABLE Check invalid column",
+			Command:      "CREATE TABLE createchk2 (col1 int check (col2 > 0))",
+			ExpErr:       "Error: Column \"col2\" not found in Table(s): createchk2",
+			ExpTableName: "createchk2",
+		},
+		{
+			TestName:     "CREATE TABLE Check aggregate",
+			Command:      "CREATE TABLE createchk2 (col1 int check (sum(col1) > 0))",
+			ExpErr:       "Syntax Error: CHECK constraint createchk2_col1_check can not use aggregate functions",
+			ExpTableName: "createchk2",
+		},
+		{
+			TestName:     "CREATE TABLE Check duplicate name",
+			Command:      "CREATE TABLE createchk2 (col1 int constraint pos check (col1 > 0), col2 int constraint pos check (col2 > 0))",
+			ExpErr:       "Syntax Error: The table createchk2 cannot have more than one constraint named pos",
+			ExpTableName: "createchk2",
+		},
 	}
 
 	for i, row := range data {
@@ -298,3 +452,147 @@ func TestCreateTable(t *testing.T) {
 	}
 
 }
+
+type CreateTableAsData struct {
+	TestName     string
+	Command      string
+	ExpErr       string
+	ExpTableName string
+	ExpStr       string
+	ExpVals      sqtypes.RawVals
+}
+
+func testCreateTableAsFunc(profile *sqprofile.SQProfile, d CreateTableAsData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		tkns := tokens.Tokenize(d.Command)
+		trans := sqtables.BeginTrans(profile, true)
+		tname, _, err := cmd.CreateTable(trans, tkns)
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			// A failed CREATE TABLE ... AS must not leave the table behind
+			if d.ExpTableName != "" {
+				tab, err := sqtables.GetTable(profile, d.ExpTableName)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if tab != nil {
+					t.Errorf("Table %s exists after a failed CREATE TABLE", d.ExpTableName)
+				}
+			}
+			return
+		}
+		if tname != d.ExpTableName {
+			t.Errorf("TableName: %q was the expected return, but actual value is: %q", d.ExpTableName, tname)
+			return
+		}
+
+		tab, err := sqtables.GetTable(profile, tname)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		actStr := tab.String(profile)
+		if actStr != d.ExpStr {
+			t.Errorf("Created table did not match expected: \nActual: %s\nExpected: %s", actStr, d.ExpStr)
+			return
+		}
+		ptrs, err := tab.GetRowPtrs(profile, nil, true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		data, err := tab.GetRowDataFromPtrs(profile, ptrs)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(d.ExpVals) == 0 {
+			if data.Len() != 0 {
+				t.Errorf("Created table has %d rows, expected no rows", data.Len())
+			}
+			return
+		}
+		msg := sqtypes.Compare2DValue(data.Vals, sqtypes.CreateValuesFromRaw(d.ExpVals), "Actual", "Expect", true)
+		if msg != "" {
+			t.Error(msg)
+		}
+	}
+}
+
+func TestCreateTableAs(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+
+	err := sq.ProcessSQFile("./testdata/ctastests.sq")
+	if err != nil {
+		t.Errorf("Error setting up data for TestCreateTableAs: %s", err)
+		return
+	}
+
+	data := []CreateTableAsData{
+		{
+			TestName:     "CREATE TABLE AS missing SELECT",
+			Command:      "CREATE TABLE ctasmissing AS VALUES (1)",
+			ExpErr:       "Syntax Error: Expecting SELECT after AS in CREATE TABLE",
+			ExpTableName: "ctasmissing",
+		},
+		{
+			TestName:     "CREATE TABLE AS all columns",
+			Command:      "CREATE TABLE ctascopy AS SELECT * FROM ctasitems",
+			ExpTableName: "ctascopy",
+			ExpStr:       "ctascopy\n--------------------------------------\n\t{id, INT}\n\t{name, STRING}\n\t{price, FLOAT}\n\t{active, BOOL}\n",
+			ExpVals:      sqtypes.RawVals{{1, "apple", 1.5, true}, {2, "pear", 2.25, false}, {3, "plum", 0.75, true}},
+		},
+		{
+			TestName:     "CREATE TABLE AS with alias and where",
+			Command:      "CREATE TABLE ctasactive AS SELECT id, name AS fruit, price * 2.0 AS double FROM ctasitems WHERE active = true",
+			ExpTableName: "ctasactive",
+			ExpStr:       "ctasactive\n--------------------------------------\n\t{id, INT}\n\t{fruit, STRING}\n\t{double, FLOAT}\n",
+			ExpVals:      sqtypes.RawVals{{1, "apple", 3.0}, {3, "plum", 1.5}},
+		},
+		{
+			TestName:     "CREATE TABLE AS aggregate",
+			Command:      "CREATE TABLE ctascount AS SELECT count() AS num, sum(price) AS total FROM ctasitems",
+			ExpTableName: "ctascount",
+			ExpStr:       "ctascount\n--------------------------------------\n\t{num, INT}\n\t{total, FLOAT}\n",
+			ExpVals:      sqtypes.RawVals{{3, 4.5}},
+		},
+		{
+			TestName:     "CREATE TABLE AS no rows",
+			Command:      "CREATE TABLE ctasempty AS SELECT id, name FROM ctasitems WHERE id > 10",
+			ExpTableName: "ctasempty",
+			ExpStr:       "ctasempty\n--------------------------------------\n\t{id, INT}\n\t{name, STRING}\n",
+			ExpVals:      sqtypes.RawVals{},
+		},
+		{
+			TestName: "CREATE TABLE AS existing table",
+			Command:  "CREATE TABLE ctasitems AS SELECT id FROM ctasitems",
+			ExpErr:   "Error: Invalid Name: Table ctasitems already exists",
+		},
+		{
+			TestName:     "CREATE TABLE AS expression without name",
+			Command:      "CREATE TABLE ctasnoname AS SELECT id, price * 2.0 FROM ctasitems",
+			ExpErr:       "Error: Column \"(price*2)\" is not a valid column name, use AS to give it a name",
+			ExpTableName: "ctasnoname",
+		},
+		{
+			TestName:     "CREATE TABLE AS duplicate column",
+			Command:      "CREATE TABLE ctasdup AS SELECT id, name AS id FROM ctasitems",
+			ExpErr:       "Error: Column \"id\" is defined more than once in ctasdup",
+			ExpTableName: "ctasdup",
+		},
+		{
+			TestName:     "CREATE TABLE AS invalid table",
+			Command:      "CREATE TABLE ctasbad AS SELECT id FROM notatable",
+			ExpErr:       "Error: Table \"notatable\" does not exist",
+			ExpTableName: "ctasbad",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testCreateTableAsFunc(profile, row))
+
+	}
+}
@@ -8,24 +8,43 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/wilphi/sqsrv/sqerr"
 	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtables/moniker"
 	"github.com/wilphi/sqsrv/tokens"
 )
 
+// DeleteStmt contains the info required to execute a DELETE statement. Join is the join of the table to
+//   the tables of the USING clause if there is one
+type DeleteStmt struct {
+	Table     *sqtables.TableDef
+	WhereExpr sqtables.Expr
+	Join      *TargetJoin
+	Returning *sqtables.Returning
+}
+
 // Delete -
 func Delete(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *sqtables.DataSet, error) {
-	tab, whereExpr, err := ParseDelete(trans, tkns)
+	stmt, err := ParseDelete(trans, tkns)
 	if err != nil {
 		return "", nil, err
 	}
-	nRows, err := ExecuteDelete(trans, tab, whereExpr)
-	return fmt.Sprintf("Deleted %d rows from table", nRows), nil, err
+	nRows, err := ExecuteDelete(trans, stmt)
+	msg := fmt.Sprintf("Deleted %d rows from table", nRows)
+	if err != nil || stmt.Returning == nil {
+		return msg, nil, err
+	}
+	data, err := stmt.Returning.GetData(trans.Profile())
+	return msg, data, err
 }
 
-// ParseDelete - takes a list of tokens returns number of rows deleted from table, error
-func ParseDelete(trans sqtables.Transaction, tkns *tokens.TokenList) (*sqtables.TableDef, sqtables.Expr, error) {
+// ParseDelete - takes a list of tokens returns the delete statement, error
+func ParseDelete(trans sqtables.Transaction, tkns *tokens.TokenList) (*DeleteStmt, error) {
 	var tableName string
 	var tab *sqtables.TableDef
 	var whereExpr sqtables.Expr
+	var join *TargetJoin
+	var returning *sqtables.Returning
+	var usingTables sqtables.TableList
+	var usingJoins []sqtables.JoinInfo
 	var tkn tokens.Token
 	var err error
 
@@ -33,57 +52,89 @@ func ParseDelete(trans sqtables.Transaction, tkns *tokens.TokenList) (*sqtables.
 
 	//eat Delete token
 	if !tkns.IsARemove(tokens.Delete) {
-		return nil, nil, sqerr.NewSyntax("Expecting DELETE")
+		return nil, sqerr.NewSyntax("Expecting DELETE")
 	}
 
 	// eat the From
 	if !tkns.IsARemove(tokens.From) {
 		// no FROM
-		return nil, nil, sqerr.NewSyntax("Expecting FROM")
+		return nil, sqerr.NewSyntax("Expecting FROM")
 	}
 
 	//expecting Ident (tablename)
 	if tkn = tkns.TestTkn(tokens.Ident); tkn == nil {
-		return nil, nil, sqerr.NewSyntax("Expecting table name in Delete statement")
+		return nil, sqerr.NewSyntax("Expecting table name in Delete statement")
 	}
 	tableName = tkn.(*tokens.ValueToken).Value()
 	tkns.Remove()
+	alias := parseTargetAlias(tkns, "USING")
 
 	// get the TableDef
 	tab, err = sqtables.GetTable(trans.Profile(), tableName)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	if tab == nil {
-		return nil, nil, sqerr.New("Table " + tableName + " does not exist for delete statement")
+		return nil, sqerr.New("Table " + tableName + " does not exist for delete statement")
+	}
+	target := sqtables.TableRef{Name: moniker.New(tableName, alias), Table: tab}
+
+	// Optional Using clause
+	hasUsing := tkns.IsAKeywordRemove("USING")
+	if hasUsing {
+		usingTables, usingJoins, err = ParseFromClause(trans.Profile(), tkns, tokens.Where, tokens.Returning)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Optional Where clause processing goes here
 	if tkns.IsARemove(tokens.Where) {
-		whereExpr, err = ParseWhereClause(tkns, false, tokens.Order)
+		whereExpr, err = ParseWhereClause(tkns, hasUsing, tokens.Order, tokens.Returning)
 
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
-		err = whereExpr.ValidateCols(trans.Profile(), sqtables.NewTableListFromTableDef(trans.Profile(), tab))
-		if err != nil {
-			return nil, nil, err
+		if !hasUsing {
+			err = whereExpr.ValidateCols(trans.Profile(), sqtables.NewTableList(trans.Profile(), []sqtables.TableRef{target}))
+			if err != nil {
+				return nil, err
+			}
 		}
 
 	}
+	if hasUsing {
+		join, err = NewTargetJoin(trans.Profile(), target, usingTables, usingJoins, whereExpr, "USING")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if tkns.IsARemove(tokens.Returning) {
+		returning, err = parseReturning(trans.Profile(), tkns, tab)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if !tkns.IsEmpty() {
-		return nil, nil, sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+		return nil, sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
 	}
 
-	return tab, whereExpr, nil
+	return &DeleteStmt{Table: tab, WhereExpr: whereExpr, Join: join, Returning: returning}, nil
 }
 
 // ExecuteDelete -
-func ExecuteDelete(trans sqtables.Transaction, tab *sqtables.TableDef, whereExpr sqtables.Expr) (numRows int, err error) {
+func ExecuteDelete(trans sqtables.Transaction, stmt *DeleteStmt) (numRows int, err error) {
 	var rowsDeleted sqptr.SQPtrs
 	numRows = -1
 
-	rowsDeleted, err = tab.DeleteRows(trans, whereExpr)
+	if stmt.Returning != nil {
+		trans = stmt.Returning.Watch(trans)
+	}
+	if stmt.Join != nil {
+		rowsDeleted, err = deleteJoinedRows(trans, stmt)
+	} else {
+		rowsDeleted, err = stmt.Table.DeleteRows(trans, stmt.WhereExpr)
+	}
 	if err != nil {
 		return
 	}
@@ -95,3 +146,28 @@ func ExecuteDelete(trans sqtables.Transaction, tab *sqtables.TableDef, whereExpr
 	*/
 	return len(rowsDeleted), nil
 }
+
+// deleteJoinedRows deletes each row of the table that is joined to the tables of the USING clause. A row
+//   that is joined to more than one row is only deleted once
+func deleteJoinedRows(trans sqtables.Transaction, stmt *DeleteStmt) (sqptr.SQPtrs, error) {
+	err := trans.AddLock(stmt.Table)
+	if err != nil {
+		trans.RollbackIfAuto()
+		return nil, err
+	}
+	joinPtrs, _, err := stmt.Join.Rows(trans.Profile())
+	if err != nil {
+		trans.RollbackIfAuto()
+		return nil, err
+	}
+
+	var ptrs sqptr.SQPtrs
+	found := make(map[sqptr.SQPtr]bool)
+	for _, ptr := range joinPtrs {
+		if !found[ptr] {
+			found[ptr] = true
+			ptrs = append(ptrs, ptr)
+		}
+	}
+	return ptrs, stmt.Table.DeleteRowsFromPtrs(trans, ptrs)
+}
@@ -222,7 +222,7 @@ func TestDelete(t *testing.T) {
 		},
 		{
 			TestName:  "Delete FROM table Extra stuff ",
-			Command:   "Delete FROM deltest extra stuff",
+			Command:   "Delete FROM deltest d extra stuff",
 			TableName: "deltest",
 			ExpErr:    "Syntax Error: Unexpected tokens after SQL command:[IDENT=extra] [IDENT=stuff]",
 			ExpVals: sqtypes.RawVals{
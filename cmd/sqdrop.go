@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/wilphi/sqsrv/redo"
 	"github.com/wilphi/sqsrv/sqerr"
 	"github.com/wilphi/sqsrv/sqtables"
 	"github.com/wilphi/sqsrv/tokens"
@@ -42,7 +43,22 @@ func DropTable(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *sqt
 	if err != nil {
 		return "", nil, err
 	}
-	//	err = redo.Send(redo.NewDropDDL(tableName))
+	err = redo.Send(redo.NewDropDDL(tableName))
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The sequences of identity columns are dropped with the table
+	seqs, err := sqtables.OwnedSequences(trans.Profile(), tableName)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, seq := range seqs {
+		err = dropSequence(trans, seq)
+		if err != nil {
+			return "", nil, err
+		}
+	}
 
 	return tableName, nil, err
 }
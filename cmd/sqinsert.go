@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/wilphi/sqsrv/sqerr"
@@ -12,11 +13,23 @@ import (
 	"github.com/wilphi/sqsrv/tokens"
 )
 
-// InsertStmt - structure to store decoded Insert Statement
+// InsertStmt - structure to store decoded Insert Statement. nVals is the number of columns that are
+//   given values in the statement, the rest of the columns in data are set by their defaults. generated
+//   marks the GENERATED ALWAYS columns that can only be set by their defaults. isSelect is true if the
+//   values come from a SELECT statement. conflict is the ON CONFLICT clause and returning is the
+//   RETURNING clause if there is one. nUpdated is the number of rows changed by ON CONFLICT DO UPDATE
 type InsertStmt struct {
 	tkns      *tokens.TokenList
 	tableName string
 	data      *sqtables.DataSet
+	nVals     int
+	defaults  []sqtables.Expr
+	generated []bool
+	colTypes  []tokens.TokenID
+	isSelect  bool
+	conflict  *sqtables.OnConflict
+	returning *sqtables.Returning
+	nUpdated  int
 }
 
 // InsertInto -
@@ -32,11 +45,24 @@ func InsertInto(trans sqtables.Transaction, tl *tokens.TokenList) (string, *sqta
 
 	if err != nil {
 		trans.RollbackIfAuto()
-		return fmt.Sprintf("%d rows inserted into %s", i, ins.tableName), nil, err
+		return ins.message(i), nil, err
 	}
 	err = trans.CommitIfAuto()
+	var data *sqtables.DataSet
+	if err == nil && ins.returning != nil {
+		data, err = ins.returning.GetData(trans.Profile())
+	}
+
+	return ins.message(i), data, err
+}
 
-	return fmt.Sprintf("%d rows inserted into %s", i, ins.tableName), nil, err
+// message returns the result of the insert. The rows changed by ON CONFLICT DO UPDATE are counted
+//   separately from the rows inserted
+func (ins *InsertStmt) message(nRows int) string {
+	if ins.conflict != nil && ins.conflict.IsUpdate() {
+		return fmt.Sprintf("%d rows inserted into %s, %d rows updated", nRows, ins.tableName, ins.nUpdated)
+	}
+	return fmt.Sprintf("%d rows inserted into %s", nRows, ins.tableName)
 }
 
 // Parse translates the command string into an internal representation of the insert statment
@@ -60,13 +86,15 @@ func (ins *InsertStmt) Parse(profile *sqprofile.SQProfile) error {
 		return sqerr.NewSyntax("Expecting name of table for insert")
 	}
 
-	if !ins.tkns.IsARemove(tokens.OpenBracket) {
-		return sqerr.NewSyntax("Expecting ( after name of table")
-	}
-
-	colNames, err = GetIdentList(ins.tkns, tokens.CloseBracket)
-	if err != nil {
-		return err
+	// The column list is optional
+	hasCols := ins.tkns.IsARemove(tokens.OpenBracket)
+	if hasCols {
+		colNames, err = GetIdentList(ins.tkns, tokens.CloseBracket)
+		if err != nil {
+			return err
+		}
+	} else if !ins.tkns.IsA(tokens.Values) && !ins.tkns.IsA(tokens.Default) && !ins.tkns.IsA(tokens.Select) {
+		return sqerr.NewSyntax("Expecting (, VALUES or SELECT after name of table")
 	}
 	tab, err := sqtables.GetTable(profile, ins.tableName)
 	if err != nil {
@@ -75,16 +103,54 @@ func (ins *InsertStmt) Parse(profile *sqprofile.SQProfile) error {
 	if tab == nil {
 		return sqerr.New("Table " + ins.tableName + " does not exist")
 	}
+	if !hasCols {
+		// Without a column list the values are in the order of the columns in the table
+		colNames = tab.GetColNames(profile)
+	}
 
-	ins.data, err = sqtables.NewDataSet(profile, sqtables.NewTableListFromTableDef(profile, tab), sqtables.ColsToExpr(column.NewListNames(colNames)))
+	err = ins.setDefaults(profile, tab, colNames)
 	if err != nil {
 		return err
 	}
 
 	//Values section
-	err = ins.getInsertValues()
-	if err != nil {
-		return err
+	if ins.tkns.IsARemove(tokens.Default) {
+		if hasCols {
+			return sqerr.NewSyntax("DEFAULT VALUES can not be used with a list of columns")
+		}
+		if !ins.tkns.IsARemove(tokens.Values) {
+			return sqerr.NewSyntax("Expecting VALUES after DEFAULT")
+		}
+		var vals []sqtypes.Value
+		vals, err = ins.fillDefaults(profile, make([]sqtypes.Value, ins.nVals))
+		if err != nil {
+			return err
+		}
+		ins.data.Vals = append(ins.data.Vals, vals)
+	} else if ins.tkns.IsA(tokens.Select) {
+		err = ins.getSelectValues(profile)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = ins.getInsertValues(profile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ins.tkns.IsARemove(tokens.On) {
+		err = ins.parseOnConflict(profile, tab)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ins.tkns.IsARemove(tokens.Returning) {
+		ins.returning, err = parseReturning(profile, ins.tkns, tab)
+		if err != nil {
+			return err
+		}
 	}
 
 	if ins.tkns.Len() != 0 {
@@ -95,7 +161,7 @@ func (ins *InsertStmt) Parse(profile *sqprofile.SQProfile) error {
 }
 
 // parse the values clause of the insert statement
-func (ins *InsertStmt) getInsertValues() error {
+func (ins *InsertStmt) getInsertValues(profile *sqprofile.SQProfile) error {
 
 	var vals []sqtypes.Value
 	var err error
@@ -109,7 +175,7 @@ func (ins *InsertStmt) getInsertValues() error {
 	}
 
 	for {
-		vals, err = ins.getValuesRow()
+		vals, err = ins.getValuesRow(profile)
 		if err != nil {
 			return err
 		}
@@ -123,8 +189,117 @@ func (ins *InsertStmt) getInsertValues() error {
 	return nil
 }
 
+// getSelectValues runs the SELECT statement that gives the values of the insert. Each row of the
+//   result is a row to be inserted
+func (ins *InsertStmt) getSelectValues(profile *sqprofile.SQProfile) error {
+	q, err := SelectParse(profile, splitSelect(ins.tkns))
+	if err != nil {
+		return err
+	}
+	data, err := SelectExecute(profile, q)
+	if err != nil {
+		return err
+	}
+	if data.NumCols() != ins.nVals {
+		return sqerr.Newf("The Number of Columns (%d) does not match the number of Values (%d)", ins.nVals, data.NumCols())
+	}
+
+	ins.isSelect = true
+	for _, vals := range data.Vals {
+		row, err := ins.fillDefaults(profile, vals)
+		if err != nil {
+			return err
+		}
+		ins.data.Vals = append(ins.data.Vals, row)
+	}
+	return nil
+}
+
+// parseOnConflict parses the rest of ON CONFLICT [(cols)] DO NOTHING or ON CONFLICT (cols) DO UPDATE
+//   SET col = expr, ... [WHERE expr]
+func (ins *InsertStmt) parseOnConflict(profile *sqprofile.SQProfile, tab *sqtables.TableDef) error {
+	var err error
+	con := sqtables.OnConflict{}
+
+	if !ins.tkns.IsAKeywordRemove("CONFLICT") {
+		return sqerr.NewSyntax("Expecting CONFLICT after ON")
+	}
+	if ins.tkns.IsARemove(tokens.OpenBracket) {
+		con.Cols, err = GetIdentList(ins.tkns, tokens.CloseBracket)
+		if err != nil {
+			return err
+		}
+	}
+	if !ins.tkns.IsAKeywordRemove("DO") {
+		return sqerr.NewSyntax("Expecting DO after ON CONFLICT")
+	}
+
+	switch {
+	case ins.tkns.IsAKeywordRemove("NOTHING"):
+	case ins.tkns.IsARemove(tokens.Update):
+		if len(con.Cols) == 0 {
+			return sqerr.NewSyntax("ON CONFLICT DO UPDATE requires a list of columns")
+		}
+		if !ins.tkns.IsARemove(tokens.Set) {
+			return sqerr.NewSyntax("Expecting SET after DO UPDATE")
+		}
+		con.SetCols, con.SetExprs, err = parseSetList(profile, ins.tkns, tab)
+		if err != nil {
+			return err
+		}
+		if ins.tkns.IsARemove(tokens.Where) {
+			con.WhereExpr, err = ParseWhereClause(ins.tkns, false)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return sqerr.NewSyntax("Expecting NOTHING or UPDATE after DO")
+	}
+
+	err = con.Validate(profile, tab)
+	if err != nil {
+		return err
+	}
+	ins.conflict = &con
+	return nil
+}
+
+// splitSelect removes and returns the tokens of the SELECT statement of an insert. The statement ends at
+//   an ON CONFLICT or RETURNING that is not within brackets or at the end of the tokens
+func splitSelect(tkns *tokens.TokenList) *tokens.TokenList {
+	depth := 0
+	last := -1
+	for i := 0; i < tkns.Len() && last < 0; i++ {
+		switch tkns.Peekx(i).ID() {
+		case tokens.OpenBracket:
+			depth++
+		case tokens.CloseBracket:
+			depth--
+		case tokens.On:
+			if vtkn, ok := tkns.Peekx(i + 1).(*tokens.ValueToken); ok && depth == 0 && strings.EqualFold(vtkn.Value(), "CONFLICT") {
+				last = i
+			}
+		case tokens.Returning:
+			if depth == 0 {
+				last = i
+			}
+		}
+	}
+	if last < 0 {
+		last = tkns.Len()
+	}
+
+	first := tokens.NewTokenList()
+	for i := 0; i < last; i++ {
+		first.Add(tkns.Peek())
+		tkns.Remove()
+	}
+	return first
+}
+
 // parse an individual row in the Values clause
-func (ins *InsertStmt) getValuesRow() ([]sqtypes.Value, error) {
+func (ins *InsertStmt) getValuesRow(profile *sqprofile.SQProfile) ([]sqtypes.Value, error) {
 	var vals []sqtypes.Value
 	vals = make([]sqtypes.Value, ins.data.NumCols())
 
@@ -139,9 +314,87 @@ func (ins *InsertStmt) getValuesRow() ([]sqtypes.Value, error) {
 	if !ins.tkns.IsARemove(tokens.CloseBracket) {
 		return nil, sqerr.NewSyntax("Expecting ) to finish row of VALUES")
 	}
+	// Number literals for DECIMAL columns are converted exactly
+	exprs := eList.GetExprs()
+	for i := 0; i < len(exprs) && i < len(ins.colTypes); i++ {
+		exprs[i] = sqtables.LiteralForType(exprs[i], ins.colTypes[i])
+	}
+
+	vals, err = eList.GetValues(profile)
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) != ins.nVals {
+		return nil, sqerr.Newf("The Number of Columns (%d) does not match the number of Values (%d)", ins.nVals, len(vals))
+	}
+	return ins.fillDefaults(profile, vals)
+}
+
+// setDefaults creates the data set for the insert. The columns of the table that are not in colNames but
+//   have a default are added to the end of the data set so that they are set by the default
+func (ins *InsertStmt) setDefaults(profile *sqprofile.SQProfile, tab *sqtables.TableDef, colNames []string) error {
+	var err error
+
+	ins.nVals = len(colNames)
+	allNames := append([]string{}, colNames...)
+	for _, name := range tab.GetColNames(profile) {
+		col := tab.FindColDef(profile, name)
+		if col.Default != "" && !containsString(colNames, name) {
+			allNames = append(allNames, name)
+		}
+	}
+
+	ins.data, err = sqtables.NewDataSet(profile, sqtables.NewTableListFromTableDef(profile, tab), sqtables.ColsToExpr(column.NewListNames(allNames)))
+	if err != nil {
+		return err
+	}
+
+	// The default expressions are kept to be evaluated for each row
+	ins.defaults = make([]sqtables.Expr, len(allNames))
+	ins.generated = make([]bool, len(allNames))
+	ins.colTypes = make([]tokens.TokenID, len(allNames))
+	for i, name := range allNames {
+		col := tab.FindColDef(profile, name)
+		if col == nil {
+			continue
+		}
+		ins.colTypes[i] = col.ColType
+		if col.Default == "" {
+			continue
+		}
+		ins.generated[i] = col.IsGenerated
+		ins.defaults[i], err = GetExpr(tokens.Tokenize(col.Default), nil, 1)
+		if err != nil {
+			return err
+		}
+		ins.defaults[i] = sqtables.LiteralForType(ins.defaults[i], col.ColType)
+	}
+	return nil
+}
 
-	vals, err = eList.GetValues()
-	return vals, err
+// fillDefaults returns the values of a row with the defaults of the columns added. A nil value is
+//   DEFAULT in the VALUES list. If a column does not have a default its value is NULL
+func (ins *InsertStmt) fillDefaults(profile *sqprofile.SQProfile, vals []sqtypes.Value) ([]sqtypes.Value, error) {
+	row := make([]sqtypes.Value, len(ins.defaults))
+	copy(row, vals)
+	for i, def := range ins.defaults {
+		if row[i] != nil {
+			if ins.generated[i] {
+				return nil, sqerr.Newf("Column %s is GENERATED ALWAYS and can not be given a value", ins.data.GetColNames()[i])
+			}
+			continue
+		}
+		if def == nil {
+			row[i] = sqtypes.NewSQNull()
+			continue
+		}
+		v, err := def.Evaluate(profile, false)
+		if err != nil {
+			return nil, err
+		}
+		row[i] = v
+	}
+	return row, nil
 }
 
 func (ins *InsertStmt) executeInsert(trans sqtables.Transaction) (int, error) {
@@ -154,10 +407,28 @@ func (ins *InsertStmt) executeInsert(trans sqtables.Transaction) (int, error) {
 		return 0, sqerr.New("Table " + ins.tableName + " does not exist")
 	}
 
-	nRows, err := tab.AddRows(trans, ins.data)
+	if ins.returning != nil {
+		trans = ins.returning.Watch(trans)
+	}
+	var nRows int
+	if ins.conflict != nil {
+		nRows, ins.nUpdated, err = tab.UpsertRows(trans, ins.data, ins.conflict)
+	} else {
+		nRows, err = tab.AddRows(trans, ins.data)
+	}
 	if err != nil {
 		return 0, err
 	}
 	//	err = redo.Send(redo.NewInsertRows(ins.tableName, ins.data.GetColNames(), ins.data.Vals, ins.data.Ptrs))
 	return nRows, err
 }
+
+// containsString returns true if the list contains the value
+func containsString(list []string, val string) bool {
+	for _, v := range list {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
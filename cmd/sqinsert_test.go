@@ -88,6 +88,12 @@ func TestInsertInto(t *testing.T) {
 		t.Errorf("Error setting up table for TestInsertInto: %s", err)
 		return
 	}
+	tkns = tokens.Tokenize("CREATE TABLE insdef (id int not null, status string default \"new\" not null, qty int default 1, note string)")
+	defTableName, _, err := cmd.CreateTable(trans, tkns)
+	if err != nil {
+		t.Errorf("Error setting up table for TestInsertInto: %s", err)
+		return
+	}
 
 	data := []InsertIntoData{
 		/*		{
@@ -276,6 +282,108 @@ func TestInsertInto(t *testing.T) {
 			ExpVals:   sqtypes.RawVals{{123, "With Cols Test", true, -3.145}},
 			TableName: tableName,
 		},
+		{
+			TestName:  "INSERT without Column list",
+			Command:   "INSERT INTO instest VALUES (123, \"No Cols Test\", true, 1.5), (456, null, false, 2.5)",
+			ExpErr:    "",
+			ExpVals:   sqtypes.RawVals{{123, "No Cols Test", true, 1.5}, {456, nil, false, 2.5}},
+			TableName: tableName,
+		},
+		{
+			TestName:  "INSERT without Column list too few values",
+			Command:   "INSERT INTO instest VALUES (123, \"No Cols Test\")",
+			ExpErr:    "Error: The Number of Columns (4) does not match the number of Values (2)",
+			TableName: tableName,
+		},
+		{
+			TestName: "INSERT missing ( or VALUES",
+			Command:  "INSERT INTO instest FROM",
+			ExpErr:   "Syntax Error: Expecting (, VALUES or SELECT after name of table",
+		},
+		{
+			TestName:  "INSERT Defaults for missing columns",
+			Command:   "INSERT INTO insdef (id) VALUES (1), (2)",
+			ExpErr:    "",
+			ExpVals:   sqtypes.RawVals{{1, "new", 1, nil}, {2, "new", 1, nil}},
+			TableName: defTableName,
+		},
+		{
+			TestName:  "INSERT DEFAULT in values",
+			Command:   "INSERT INTO insdef (id, status, qty) VALUES (3, DEFAULT, 5), (4, \"old\", DEFAULT)",
+			ExpErr:    "",
+			ExpVals:   sqtypes.RawVals{{3, "new", 5, nil}, {4, "old", 1, nil}},
+			TableName: defTableName,
+		},
+		{
+			TestName:  "INSERT DEFAULT without Column list",
+			Command:   "INSERT INTO insdef VALUES (5, DEFAULT, DEFAULT, DEFAULT)",
+			ExpErr:    "",
+			ExpVals:   sqtypes.RawVals{{5, "new", 1, nil}},
+			TableName: defTableName,
+		},
+		{
+			TestName: "INSERT DEFAULT missing comma",
+			Command:  "INSERT INTO insdef VALUES (5, DEFAULT DEFAULT, DEFAULT)",
+			ExpErr:   "Syntax Error: Comma is required to separate values",
+		},
+		{
+			TestName: "INSERT DEFAULT extra comma",
+			Command:  "INSERT INTO insdef VALUES (5, DEFAULT,)",
+			ExpErr:   "Syntax Error: Unexpected \",\" before \")\"",
+		},
+		{
+			TestName:  "INSERT DEFAULT VALUES",
+			Command:   "INSERT INTO instest DEFAULT VALUES",
+			ExpErr:    "",
+			ExpVals:   sqtypes.RawVals{{nil, nil, nil, nil}},
+			TableName: tableName,
+		},
+		{
+			TestName: "INSERT DEFAULT VALUES not null",
+			Command:  "INSERT INTO insdef DEFAULT VALUES",
+			ExpErr:   "Error: Column \"id\" in Table \"insdef\" can not be NULL",
+		},
+		{
+			TestName: "INSERT DEFAULT VALUES with columns",
+			Command:  "INSERT INTO insdef (id) DEFAULT VALUES",
+			ExpErr:   "Syntax Error: DEFAULT VALUES can not be used with a list of columns",
+		},
+		{
+			TestName: "INSERT DEFAULT missing VALUES",
+			Command:  "INSERT INTO insdef DEFAULT (1)",
+			ExpErr:   "Syntax Error: Expecting VALUES after DEFAULT",
+		},
+		{
+			TestName:  "INSERT SELECT with Column list",
+			Command:   "INSERT INTO insdef (id, note) SELECT id, status FROM insdef WHERE id < 3 ORDER BY id",
+			ExpErr:    "",
+			ExpVals:   sqtypes.RawVals{{1, "new", 1, "new"}, {2, "new", 1, "new"}},
+			TableName: defTableName,
+		},
+		{
+			TestName:  "INSERT SELECT without Column list",
+			Command:   "INSERT INTO instest SELECT id, status, false, 1.5 FROM insdef WHERE id = 5",
+			ExpErr:    "",
+			ExpVals:   sqtypes.RawVals{{5, "new", false, 1.5}},
+			TableName: tableName,
+		},
+		{
+			TestName:  "INSERT SELECT too few columns",
+			Command:   "INSERT INTO instest SELECT id FROM insdef",
+			ExpErr:    "Error: The Number of Columns (4) does not match the number of Values (1)",
+			TableName: tableName,
+		},
+		{
+			TestName:  "INSERT SELECT invalid table",
+			Command:   "INSERT INTO instest (col1) SELECT id FROM notatable",
+			ExpErr:    "Error: Table \"notatable\" does not exist",
+			TableName: tableName,
+		},
+		{
+			TestName: "INSERT SELECT with extra tokens",
+			Command:  "INSERT INTO instest (col1) SELECT id FROM insdef VALUES (1)",
+			ExpErr:   "Syntax Error: Unexpected end of From clause at VALUES",
+		},
 	}
 	for i, row := range data {
 		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
@@ -284,3 +392,214 @@ func TestInsertInto(t *testing.T) {
 	}
 
 }
+
+type InsertOnConflictData struct {
+	TestName string
+	Command  string
+	ExpErr   string
+	ExpMsg   string
+	ExpVals  sqtypes.RawVals
+}
+
+func testInsertOnConflictFunc(profile *sqprofile.SQProfile, tab *sqtables.TableDef, d InsertOnConflictData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		tkns := tokens.Tokenize(d.Command)
+		trans := sqtables.BeginTrans(profile, true)
+		msg, _, err := cmd.InsertInto(trans, tkns)
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+		if msg != d.ExpMsg {
+			t.Errorf("Actual msg %q does not match Expected msg %q", msg, d.ExpMsg)
+			return
+		}
+
+		// The whole table is checked to make sure that nothing else was changed
+		ptrs, err := tab.GetRowPtrs(profile, nil, true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		data, err := tab.GetRowDataFromPtrs(profile, ptrs)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		cmpMsg := sqtypes.Compare2DValue(data.Vals, sqtypes.CreateValuesFromRaw(d.ExpVals), "Actual", "Expect", true)
+		if cmpMsg != "" {
+			t.Error(cmpMsg)
+		}
+	}
+}
+
+func TestInsertOnConflict(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	//make sure table exists for testing
+	tkns := tokens.Tokenize("CREATE TABLE upserttest (id int not null, code string not null, qty int, note string), PRIMARY KEY (id), UNIQUE upsertcode (code)")
+	trans := sqtables.BeginTrans(profile, true)
+	tableName, _, err := cmd.CreateTable(trans, tkns)
+	if err != nil {
+		t.Errorf("Error setting up table for TestInsertOnConflict: %s", err)
+		return
+	}
+	tkns = tokens.Tokenize("CREATE TABLE upsertnokey (id int, qty int)")
+	trans = sqtables.BeginTrans(profile, true)
+	_, _, err = cmd.CreateTable(trans, tkns)
+	if err != nil {
+		t.Errorf("Error setting up table for TestInsertOnConflict: %s", err)
+		return
+	}
+	tkns = tokens.Tokenize("INSERT INTO upserttest (id, code, qty, note) VALUES (1, \"a\", 10, \"first\"), (2, \"b\", 20, \"second\")")
+	trans = sqtables.BeginTrans(profile, true)
+	_, _, err = cmd.InsertInto(trans, tkns)
+	if err != nil {
+		t.Errorf("Error setting up data for TestInsertOnConflict: %s", err)
+		return
+	}
+	tab, err := sqtables.GetTable(profile, tableName)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	initVals := sqtypes.RawVals{{1, "a", 10, "first"}, {2, "b", 20, "second"}}
+	data := []InsertOnConflictData{
+		{
+			TestName: "Missing CONFLICT",
+			Command:  "INSERT INTO upserttest (id, code) VALUES (1, \"a\") ON DO NOTHING",
+			ExpErr:   "Syntax Error: Expecting CONFLICT after ON",
+		},
+		{
+			TestName: "Missing DO",
+			Command:  "INSERT INTO upserttest (id, code) VALUES (1, \"a\") ON CONFLICT (id) NOTHING",
+			ExpErr:   "Syntax Error: Expecting DO after ON CONFLICT",
+		},
+		{
+			TestName: "Missing NOTHING or UPDATE",
+			Command:  "INSERT INTO upserttest (id, code) VALUES (1, \"a\") ON CONFLICT (id) DO",
+			ExpErr:   "Syntax Error: Expecting NOTHING or UPDATE after DO",
+		},
+		{
+			TestName: "DO UPDATE without columns",
+			Command:  "INSERT INTO upserttest (id, code) VALUES (1, \"a\") ON CONFLICT DO UPDATE SET qty = 1",
+			ExpErr:   "Syntax Error: ON CONFLICT DO UPDATE requires a list of columns",
+		},
+		{
+			TestName: "DO UPDATE missing SET",
+			Command:  "INSERT INTO upserttest (id, code) VALUES (1, \"a\") ON CONFLICT (id) DO UPDATE qty = 1",
+			ExpErr:   "Syntax Error: Expecting SET after DO UPDATE",
+		},
+		{
+			TestName: "Columns do not match a constraint",
+			Command:  "INSERT INTO upserttest (id, code) VALUES (1, \"a\") ON CONFLICT (qty) DO NOTHING",
+			ExpErr:   "Error: There is no PRIMARY KEY or UNIQUE constraint on table upserttest that matches the ON CONFLICT columns",
+		},
+		{
+			TestName: "Table without a key",
+			Command:  "INSERT INTO upsertnokey (id, qty) VALUES (1, 1) ON CONFLICT DO NOTHING",
+			ExpErr:   "Error: ON CONFLICT requires a PRIMARY KEY or UNIQUE constraint on table upsertnokey",
+		},
+		{
+			TestName: "Invalid SET column",
+			Command:  "INSERT INTO upserttest (id, code) VALUES (1, \"a\") ON CONFLICT (id) DO UPDATE SET amount = 1",
+			ExpErr:   "Syntax Error: Invalid Column name: amount does not exist in Table upserttest",
+		},
+		{
+			TestName: "Ambiguous column in SET",
+			Command:  "INSERT INTO upserttest (id, code) VALUES (1, \"a\") ON CONFLICT (id) DO UPDATE SET qty = qty + 1",
+			ExpErr:   "Error: Column \"qty\" found in multiple tables, add tablename to differentiate",
+		},
+		{
+			TestName: "Extra tokens",
+			Command:  "INSERT INTO upserttest (id, code) VALUES (1, \"a\") ON CONFLICT (id) DO NOTHING (1)",
+			ExpErr:   "Syntax Error: Unexpected tokens after the values section: ( [NUM=1] )",
+		},
+		{
+			TestName: "DO NOTHING on primary key",
+			Command:  "INSERT INTO upserttest (id, code, qty) VALUES (1, \"z\", 99), (3, \"c\", 30) ON CONFLICT (id) DO NOTHING",
+			ExpMsg:   "1 rows inserted into upserttest",
+			ExpVals:  append(initVals, sqtypes.RawVals{{3, "c", 30, nil}}...),
+		},
+		{
+			TestName: "DO NOTHING on any key",
+			Command:  "INSERT INTO upserttest (id, code, qty) VALUES (4, \"a\", 99), (3, \"z\", 99) ON CONFLICT DO NOTHING",
+			ExpMsg:   "0 rows inserted into upserttest",
+			ExpVals:  append(initVals, sqtypes.RawVals{{3, "c", 30, nil}}...),
+		},
+		{
+			TestName: "DO NOTHING within the statement",
+			Command:  "INSERT INTO upserttest (id, code, qty) VALUES (4, \"d\", 40), (4, \"e\", 50) ON CONFLICT (id) DO NOTHING",
+			ExpMsg:   "1 rows inserted into upserttest",
+			ExpVals:  append(initVals, sqtypes.RawVals{{3, "c", 30, nil}, {4, "d", 40, nil}}...),
+		},
+		{
+			TestName: "DO UPDATE with EXCLUDED",
+			Command:  "INSERT INTO upserttest (id, code, qty) VALUES (1, \"a\", 5), (5, \"e\", 50) ON CONFLICT (id) DO UPDATE SET qty = upserttest.qty + excluded.qty, note = \"updated\"",
+			ExpMsg:   "1 rows inserted into upserttest, 1 rows updated",
+			ExpVals:  sqtypes.RawVals{{1, "a", 15, "updated"}, {2, "b", 20, "second"}, {3, "c", 30, nil}, {4, "d", 40, nil}, {5, "e", 50, nil}},
+		},
+		{
+			TestName: "DO UPDATE on unique constraint",
+			Command:  "INSERT INTO upserttest (id, code, qty) VALUES (9, \"b\", 1) ON CONFLICT (code) DO UPDATE SET qty = excluded.qty",
+			ExpMsg:   "0 rows inserted into upserttest, 1 rows updated",
+			ExpVals:  sqtypes.RawVals{{1, "a", 15, "updated"}, {2, "b", 1, "second"}, {3, "c", 30, nil}, {4, "d", 40, nil}, {5, "e", 50, nil}},
+		},
+		{
+			TestName: "DO UPDATE with WHERE",
+			Command:  "INSERT INTO upserttest (id, code, qty) VALUES (3, \"c\", 5), (4, \"d\", 5) ON CONFLICT (id) DO UPDATE SET qty = excluded.qty WHERE upserttest.qty > 35",
+			ExpMsg:   "0 rows inserted into upserttest, 1 rows updated",
+			ExpVals:  sqtypes.RawVals{{1, "a", 15, "updated"}, {2, "b", 1, "second"}, {3, "c", 30, nil}, {4, "d", 5, nil}, {5, "e", 50, nil}},
+		},
+		{
+			TestName: "DO UPDATE same row twice",
+			Command:  "INSERT INTO upserttest (id, code, qty) VALUES (6, \"f\", 1), (2, \"b\", 2), (2, \"b\", 3) ON CONFLICT (id) DO UPDATE SET qty = excluded.qty",
+			ExpErr:   "Error: ON CONFLICT DO UPDATE can not change the same row more than once",
+		},
+		{
+			TestName: "Failed statement changes nothing",
+			Command:  "INSERT INTO upserttest (id, code, qty) VALUES (6, \"f\", 1) ON CONFLICT (id) DO NOTHING",
+			ExpMsg:   "1 rows inserted into upserttest",
+			ExpVals:  sqtypes.RawVals{{1, "a", 15, "updated"}, {2, "b", 1, "second"}, {3, "c", 30, nil}, {4, "d", 5, nil}, {5, "e", 50, nil}, {6, "f", 1, nil}},
+		},
+		{
+			TestName: "INSERT SELECT DO UPDATE",
+			Command:  "INSERT INTO upserttest (id, code, qty) SELECT id, code, qty FROM upserttest WHERE id < 3 ON CONFLICT (id) DO UPDATE SET qty = excluded.qty * 2",
+			ExpMsg:   "0 rows inserted into upserttest, 2 rows updated",
+			ExpVals:  sqtypes.RawVals{{1, "a", 30, "updated"}, {2, "b", 2, "second"}, {3, "c", 30, nil}, {4, "d", 5, nil}, {5, "e", 50, nil}, {6, "f", 1, nil}},
+		},
+		{
+			TestName: "DO UPDATE to an existing key",
+			Command:  "INSERT INTO upserttest (id, code) VALUES (1, \"q\") ON CONFLICT (id) DO UPDATE SET id = 6",
+			ExpErr:   "Error: ON CONFLICT DO UPDATE would create a duplicate value for (id) in table upserttest",
+		},
+		{
+			TestName: "DO UPDATE to an existing unique value",
+			Command:  "INSERT INTO upserttest (id, code) VALUES (1, \"q\") ON CONFLICT (id) DO UPDATE SET code = \"b\"",
+			ExpErr:   "Error: ON CONFLICT DO UPDATE would create a duplicate value for (code) in table upserttest",
+		},
+		{
+			TestName: "DO UPDATE to a key inserted by the statement",
+			Command:  "INSERT INTO upserttest (id, code, qty) VALUES (7, \"g\", 1), (1, \"a\", 1) ON CONFLICT (id) DO UPDATE SET id = excluded.qty + 6",
+			ExpErr:   "Error: ON CONFLICT DO UPDATE would create a duplicate value for (id) in table upserttest",
+		},
+		{
+			TestName: "DO UPDATE to a key updated by the statement",
+			Command:  "INSERT INTO upserttest (id, code) VALUES (1, \"a\"), (2, \"b\") ON CONFLICT (id) DO UPDATE SET id = 9",
+			ExpErr:   "Error: ON CONFLICT DO UPDATE would create a duplicate value for (id) in table upserttest",
+		},
+		{
+			TestName: "DO UPDATE changes the key",
+			Command:  "INSERT INTO upserttest (id, code) VALUES (1, \"q\") ON CONFLICT (id) DO UPDATE SET id = 8",
+			ExpMsg:   "0 rows inserted into upserttest, 1 rows updated",
+			ExpVals:  sqtypes.RawVals{{2, "b", 2, "second"}, {3, "c", 30, nil}, {4, "d", 5, nil}, {5, "e", 50, nil}, {6, "f", 1, nil}, {8, "a", 30, "updated"}},
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testInsertOnConflictFunc(profile, tab, row))
+
+	}
+}
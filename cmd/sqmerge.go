@@ -0,0 +1,498 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqptr"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtables/moniker"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// MergeStmt contains the info required to execute a MERGE statement. Every row of the source is either
+//   matched to the rows of the target that make the ON condition true or it is not matched
+type MergeStmt struct {
+	TableName string
+	Table     *sqtables.TableDef
+	Target    sqtables.TableRef
+	Source    sqtables.TableRef
+	On        sqtables.Expr
+	Clauses   []*MergeClause
+}
+
+// MergeClause is a WHEN [NOT] MATCHED clause of a MERGE statement. Action is Update, Delete, Insert or
+//   NilToken for DO NOTHING. Cond is the optional AND condition of the clause
+type MergeClause struct {
+	Matched  bool
+	Cond     sqtables.Expr
+	Action   tokens.TokenID
+	SetCols  []string
+	SetExprs sqtables.ExprList
+	Values   *sqtables.ExprList
+	ins      *InsertStmt
+}
+
+// Merge implements the SQL command MERGE INTO target USING source ON cond WHEN [NOT] MATCHED THEN ...
+func Merge(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *sqtables.DataSet, error) {
+	stmt, err := parseMerge(trans.Profile(), tkns)
+	if err != nil {
+		return "", nil, err
+	}
+	return executeMerge(trans, stmt)
+}
+
+func parseMerge(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (*MergeStmt, error) {
+	var err error
+	var stmt MergeStmt
+	var alias string
+
+	log.Debug("Merge statement...")
+
+	// Eat the MERGE INTO tokens
+	tkns.IsARemove(tokens.Merge)
+	if !tkns.IsARemove(tokens.Into) {
+		return nil, sqerr.NewSyntax("Expecting INTO after MERGE")
+	}
+
+	// Target table with an optional alias
+	tkn := tkns.TestTkn(tokens.Ident)
+	if tkn == nil {
+		return nil, sqerr.NewSyntax("Expecting name of table to Merge into")
+	}
+	stmt.TableName = strings.ToLower(tkn.(*tokens.ValueToken).Value())
+	tkns.Remove()
+	if tkns.IsARemove(tokens.As) && !tkns.IsA(tokens.Ident) {
+		return nil, sqerr.NewSyntaxf("Expecting an alias after AS for table %s", stmt.TableName)
+	}
+	if tkn := tkns.TestTkn(tokens.Ident); tkn != nil && !tkns.IsAKeyword("USING") {
+		alias = tkn.(*tokens.ValueToken).Value()
+		tkns.Remove()
+	}
+	stmt.Table, err = sqtables.GetTable(profile, stmt.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.Table == nil {
+		return nil, sqerr.Newf("Table %q does not exist", stmt.TableName)
+	}
+	stmt.Target = sqtables.TableRef{Name: moniker.New(stmt.TableName, alias), Table: stmt.Table}
+
+	// Source table
+	if !tkns.IsAKeywordRemove("USING") {
+		return nil, sqerr.NewSyntax("Expecting USING after the name of the table to Merge into")
+	}
+	stmt.Source, err = parseMergeSource(profile, tkns, stmt.TableName)
+	if err != nil {
+		return nil, err
+	}
+	err = stmt.Source.Validate(profile)
+	if err != nil {
+		return nil, err
+	}
+	tables := sqtables.NewTableList(profile, nil)
+	for _, tr := range []sqtables.TableRef{stmt.Target, stmt.Source} {
+		err = tables.Add(profile, tr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The ON condition ends at the first WHEN
+	if !tkns.IsARemove(tokens.On) {
+		return nil, sqerr.NewSyntax("Expecting ON after the name of the table after USING")
+	}
+	onTkns := splitAtKeyword(tkns, "WHEN")
+	onExpr, err := ParseWhereClause(onTkns, true)
+	if err != nil {
+		return nil, err
+	}
+	if !onTkns.IsEmpty() {
+		return nil, sqerr.NewSyntax("Unexpected tokens after the ON condition:" + onTkns.String())
+	}
+	err = onExpr.ValidateCols(profile, tables)
+	if err != nil {
+		return nil, err
+	}
+	stmt.On = onExpr
+
+	// A NOT MATCHED clause can only use the source table
+	srcTables := sqtables.NewTableList(profile, []sqtables.TableRef{stmt.Source})
+	for tkns.IsAKeywordRemove("WHEN") {
+		clause, err := parseMergeClause(profile, splitAtKeyword(tkns, "WHEN"), &stmt, tables, srcTables)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Clauses = append(stmt.Clauses, clause)
+	}
+	if len(stmt.Clauses) == 0 {
+		return nil, sqerr.NewSyntax("Expecting WHEN after the ON condition")
+	}
+	if !tkns.IsEmpty() {
+		return nil, sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+	}
+
+	return &stmt, nil
+}
+
+// parseMergeSource parses the source of a MERGE. It is a table name, a VALUES list or a SELECT statement
+//   in brackets. A VALUES list or SELECT statement must have an alias to name it
+func parseMergeSource(profile *sqprofile.SQProfile, tkns *tokens.TokenList, tableName string) (sqtables.TableRef, error) {
+	if isValuesTable(tkns) {
+		return parseValuesTable(profile, tkns)
+	}
+	if tkns.IsA(tokens.OpenBracket) && tkns.Peekx(1) != nil && tkns.Peekx(1).ID() == tokens.Select {
+		body, err := getBracketTokens(tkns)
+		if err != nil {
+			return sqtables.TableRef{}, err
+		}
+		if tkns.IsA(tokens.As) && tkns.Peekx(1) != nil && tkns.Peekx(1).ID() == tokens.Ident {
+			tkns.Remove()
+		}
+		tkn := tkns.TestTkn(tokens.Ident)
+		if tkn == nil {
+			return sqtables.TableRef{}, sqerr.NewSyntax("Expecting an alias after SELECT statement in USING")
+		}
+		name := tkn.(*tokens.ValueToken).Value()
+		tkns.Remove()
+
+		var colNames []string
+		if tkns.IsARemove(tokens.OpenBracket) {
+			colNames, err = GetIdentList(tkns, tokens.CloseBracket)
+			if err != nil {
+				return sqtables.TableRef{}, err
+			}
+		}
+		tab, err := derivedTable(profile, name, colNames, body)
+		if err != nil {
+			return sqtables.TableRef{}, err
+		}
+		return sqtables.TableRef{Name: moniker.New(name, ""), Table: tab}, nil
+	}
+
+	srcName := parseMoniker(tkns)
+	if srcName == nil {
+		return sqtables.TableRef{}, sqerr.NewSyntax("Expecting name of table after USING")
+	}
+	if strings.EqualFold(srcName.Name(), tableName) {
+		return sqtables.TableRef{}, sqerr.Newf("The table after USING must not be the table %s that is merged into", tableName)
+	}
+	return newTableRef(profile, srcName)
+}
+
+// parseMergeClause parses the rest of a WHEN [NOT] MATCHED [AND cond] THEN action clause
+func parseMergeClause(profile *sqprofile.SQProfile, tkns *tokens.TokenList, stmt *MergeStmt, tables, srcTables sqtables.TableList) (*MergeClause, error) {
+	var err error
+	clause := MergeClause{Matched: !tkns.IsARemove(tokens.Not)}
+
+	matchName := "MATCHED"
+	if !clause.Matched {
+		matchName = "NOT MATCHED"
+		tables = srcTables
+	}
+	if !tkns.IsAKeywordRemove("MATCHED") {
+		return nil, sqerr.NewSyntax("Expecting MATCHED or NOT MATCHED after WHEN")
+	}
+
+	if tkns.IsARemove(tokens.And) {
+		clause.Cond, err = ParseWhereClause(splitAtKeyword(tkns, "THEN"), false)
+		if err != nil {
+			return nil, err
+		}
+		err = clause.Cond.ValidateCols(profile, tables)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !tkns.IsAKeywordRemove("THEN") {
+		return nil, sqerr.NewSyntaxf("Expecting THEN after WHEN %s", matchName)
+	}
+
+	switch {
+	case tkns.IsAKeywordRemove("DO"):
+		if !tkns.IsAKeywordRemove("NOTHING") {
+			return nil, sqerr.NewSyntax("Expecting NOTHING after DO")
+		}
+		clause.Action = tokens.NilToken
+	case clause.Matched && tkns.IsARemove(tokens.Update):
+		if !tkns.IsARemove(tokens.Set) {
+			return nil, sqerr.NewSyntax("Expecting SET after UPDATE")
+		}
+		clause.SetCols, clause.SetExprs, err = parseSetList(profile, tkns, stmt.Table)
+		if err != nil {
+			return nil, err
+		}
+		err = clause.SetExprs.ValidateCols(profile, tables)
+		if err != nil {
+			return nil, err
+		}
+		clause.Action = tokens.Update
+	case clause.Matched && tkns.IsARemove(tokens.Delete):
+		clause.Action = tokens.Delete
+	case !clause.Matched && tkns.IsARemove(tokens.Insert):
+		err = clause.parseInsert(profile, tkns, stmt.Table, tables)
+		if err != nil {
+			return nil, err
+		}
+		clause.Action = tokens.Insert
+	default:
+		if clause.Matched {
+			return nil, sqerr.NewSyntax("Expecting UPDATE, DELETE or DO NOTHING after WHEN MATCHED THEN")
+		}
+		return nil, sqerr.NewSyntax("Expecting INSERT or DO NOTHING after WHEN NOT MATCHED THEN")
+	}
+
+	if !tkns.IsEmpty() {
+		return nil, sqerr.NewSyntaxf("Unexpected tokens after WHEN %s clause:%s", matchName, tkns.String())
+	}
+	return &clause, nil
+}
+
+// parseInsert parses the rest of INSERT [(cols)] VALUES (exprs). The expressions are evaluated for
+//   each row of the source that is not matched
+func (clause *MergeClause) parseInsert(profile *sqprofile.SQProfile, tkns *tokens.TokenList, tab *sqtables.TableDef, tables sqtables.TableList) error {
+	var err error
+	var colNames []string
+
+	if tkns.IsARemove(tokens.OpenBracket) {
+		colNames, err = GetIdentList(tkns, tokens.CloseBracket)
+		if err != nil {
+			return err
+		}
+	} else {
+		colNames = tab.GetColNames(profile)
+	}
+	clause.ins = &InsertStmt{tableName: tab.GetName(profile)}
+	err = clause.ins.setDefaults(profile, tab, colNames)
+	if err != nil {
+		return err
+	}
+
+	if !tkns.IsARemove(tokens.Values) {
+		return sqerr.NewSyntax("Expecting VALUES after INSERT")
+	}
+	if !tkns.IsARemove(tokens.OpenBracket) {
+		return sqerr.NewSyntax("Expecting ( after keyword VALUES")
+	}
+	clause.Values, err = GetExprList(tkns, tokens.CloseBracket, tokens.Merge)
+	if err != nil {
+		return err
+	}
+	if !tkns.IsARemove(tokens.CloseBracket) {
+		return sqerr.NewSyntax("Expecting ) to finish VALUES")
+	}
+	if clause.Values.Len() != clause.ins.nVals {
+		return sqerr.Newf("The Number of Columns (%d) does not match the number of Values (%d)", clause.ins.nVals, clause.Values.Len())
+	}
+	for _, ex := range clause.Values.GetExprs() {
+		// DEFAULT is a nil expression
+		if ex == nil {
+			continue
+		}
+		err = ex.ValidateCols(profile, tables)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitAtKeyword removes and returns the tokens before the first non reserved keyword that is not
+//   within brackets. If the keyword is not found all of the tokens are returned
+func splitAtKeyword(tkns *tokens.TokenList, word string) *tokens.TokenList {
+	depth := 0
+	last := -1
+	for i := 0; i < tkns.Len() && last < 0; i++ {
+		switch tkn := tkns.Peekx(i).(type) {
+		case *tokens.ValueToken:
+			if depth == 0 && tkn.ID() == tokens.Ident && strings.EqualFold(tkn.Value(), word) {
+				last = i
+			}
+		default:
+			if tkn.ID() == tokens.OpenBracket {
+				depth++
+			} else if tkn.ID() == tokens.CloseBracket {
+				depth--
+			}
+		}
+	}
+	if last < 0 {
+		last = tkns.Len()
+	}
+
+	first := tokens.NewTokenList()
+	for i := 0; i < last; i++ {
+		first.Add(tkns.Peek())
+		tkns.Remove()
+	}
+	return first
+}
+
+// executeMerge matches each row of the source to the rows of the target and applies the first clause
+//   that fits each pair of rows. The ON condition can be any expression so it is checked for every
+//   pair. The changes are made in a single transaction
+func executeMerge(trans sqtables.Transaction, stmt *MergeStmt) (string, *sqtables.DataSet, error) {
+	var err error
+	var delPtrs sqptr.SQPtrs
+	nUpdated := 0
+	profile := trans.Profile()
+
+	// An automatic transaction would be committed by each change so a transaction that is committed
+	//   once at the end is used instead
+	mtrans := trans
+	if trans.Auto() {
+		mtrans = sqtables.BeginTrans(profile, false)
+	}
+	fail := func(err error) (string, *sqtables.DataSet, error) {
+		if trans.Auto() {
+			mtrans.Rollback()
+		}
+		return "", nil, err
+	}
+
+	err = mtrans.AddLock(stmt.Table)
+	if err != nil {
+		return fail(err)
+	}
+	tuples, err := stmt.matchRows(profile)
+	if err != nil {
+		return fail(err)
+	}
+
+	changed := make(map[sqptr.SQPtr]bool)
+	for _, rows := range tuples {
+		ptr := rows[1].GetPtr(profile)
+		clause, err := stmt.findClause(profile, ptr != 0, rows)
+		if err != nil {
+			return fail(err)
+		}
+		if clause == nil || clause.Action == tokens.NilToken {
+			continue
+		}
+		if clause.Matched {
+			if changed[ptr] {
+				return fail(sqerr.New("MERGE can not change the same row more than once"))
+			}
+			changed[ptr] = true
+		}
+
+		switch clause.Action {
+		case tokens.Update:
+			vals, err := clause.SetExprs.Evaluate(profile, sqtables.EvalFull, rows...)
+			if err != nil {
+				return fail(err)
+			}
+			err = stmt.Table.UpdateRowsFromPtrs(mtrans, sqptr.SQPtrs{ptr}, clause.SetCols, sqtables.NewExprListFromValues(vals))
+			if err != nil {
+				return fail(err)
+			}
+			nUpdated++
+		case tokens.Delete:
+			delPtrs = append(delPtrs, ptr)
+		case tokens.Insert:
+			vals := make([]sqtypes.Value, clause.Values.Len())
+			for i, ex := range clause.Values.GetExprs() {
+				if ex == nil {
+					continue
+				}
+				vals[i], err = ex.Evaluate(profile, sqtables.EvalFull, rows...)
+				if err != nil {
+					return fail(err)
+				}
+			}
+			vals, err = clause.ins.fillDefaults(profile, vals)
+			if err != nil {
+				return fail(err)
+			}
+			clause.ins.data.Vals = append(clause.ins.data.Vals, vals)
+		}
+	}
+
+	if len(delPtrs) > 0 {
+		err = stmt.Table.DeleteRowsFromPtrs(mtrans, delPtrs)
+		if err != nil {
+			return fail(err)
+		}
+	}
+	nInserted := 0
+	for _, clause := range stmt.Clauses {
+		if clause.Action != tokens.Insert || clause.ins.data.Len() == 0 {
+			continue
+		}
+		n, err := stmt.Table.AddRows(mtrans, clause.ins.data)
+		if err != nil {
+			return fail(err)
+		}
+		nInserted += n
+	}
+
+	if trans.Auto() {
+		err = mtrans.Commit()
+		if err != nil {
+			return fail(err)
+		}
+	}
+	return fmt.Sprintf("Merged into %s: %d rows inserted, %d rows updated, %d rows deleted", stmt.TableName, nInserted, nUpdated, len(delPtrs)), nil, nil
+}
+
+// matchRows returns a source row and a target row for each pair of rows that makes the ON condition
+//   true. A source row that does not match any target row is returned with a row of NULLs that has a
+//   pointer of 0
+func (stmt *MergeStmt) matchRows(profile *sqprofile.SQProfile) ([][]sqtables.RowInterface, error) {
+	var tuples [][]sqtables.RowInterface
+
+	srcQ := sqtables.Query{Tables: sqtables.NewTableList(profile, []sqtables.TableRef{stmt.Source})}
+	_, srcRows, err := srcQ.JoinedRows(profile)
+	if err != nil {
+		return nil, err
+	}
+	targetQ := sqtables.Query{Tables: sqtables.NewTableList(profile, []sqtables.TableRef{stmt.Target})}
+	_, targetRows, err := targetQ.JoinedRows(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, src := range srcRows {
+		matched := false
+		for _, target := range targetRows {
+			rows := []sqtables.RowInterface{src[0], target[0]}
+			val, err := stmt.On.Evaluate(profile, sqtables.EvalFull, rows...)
+			if err != nil {
+				return nil, err
+			}
+			if b, ok := val.(sqtypes.SQBool); ok && b.Bool() {
+				tuples = append(tuples, rows)
+				matched = true
+			}
+		}
+		if !matched {
+			tuples = append(tuples, []sqtables.RowInterface{src[0], &sqtables.NullRow{TableName: stmt.Target.Name}})
+		}
+	}
+	return tuples, nil
+}
+
+// findClause returns the first clause for a matched or not matched row that has a true condition. If
+//   no clause fits the row nil is returned
+func (stmt *MergeStmt) findClause(profile *sqprofile.SQProfile, matched bool, rows []sqtables.RowInterface) (*MergeClause, error) {
+	for _, clause := range stmt.Clauses {
+		if clause.Matched != matched {
+			continue
+		}
+		if clause.Cond == nil {
+			return clause, nil
+		}
+		val, err := clause.Cond.Evaluate(profile, sqtables.EvalFull, rows...)
+		if err != nil {
+			return nil, err
+		}
+		if b, ok := val.(sqtypes.SQBool); ok && b.Bool() {
+			return clause, nil
+		}
+	}
+	return nil, nil
+}
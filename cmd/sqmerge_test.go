@@ -0,0 +1,235 @@
+package cmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/cmd"
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+type MergeData struct {
+	TestName string
+	Command  string
+	ExpErr   string
+	ExpMsg   string
+	ExpVals  sqtypes.RawVals
+}
+
+func testMergeFunc(profile *sqprofile.SQProfile, d MergeData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		tkns := tokens.Tokenize(d.Command)
+		trans := sqtables.BeginTrans(profile, true)
+		msg, _, err := cmd.Merge(trans, tkns)
+		if sqtest.CheckErr(t, err, d.ExpErr) && d.ExpVals == nil {
+			return
+		}
+		if msg != d.ExpMsg {
+			t.Errorf("Actual msg %q does not match Expected msg %q", msg, d.ExpMsg)
+			return
+		}
+
+		// The whole target table is checked to make sure that only the expected changes were made
+		tab, err := sqtables.GetTable(profile, "mtarget")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		ptrs, err := tab.GetRowPtrs(profile, nil, true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		data, err := tab.GetRowDataFromPtrs(profile, ptrs)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		cmpMsg := sqtypes.Compare2DValue(data.Vals, sqtypes.CreateValuesFromRaw(d.ExpVals), "Actual", "Expect", true)
+		if cmpMsg != "" {
+			t.Error(cmpMsg)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+
+	err := sq.ProcessSQFile("./testdata/mergetests.sq")
+	if err != nil {
+		t.Errorf("Error setting up data for TestMerge: %s", err)
+		return
+	}
+
+	data := []MergeData{
+		{
+			TestName: "MERGE missing INTO",
+			Command:  "MERGE mtarget USING mstage ON mtarget.id = mstage.id WHEN MATCHED THEN DELETE",
+			ExpErr:   "Syntax Error: Expecting INTO after MERGE",
+		},
+		{
+			TestName: "MERGE missing table",
+			Command:  "MERGE INTO",
+			ExpErr:   "Syntax Error: Expecting name of table to Merge into",
+		},
+		{
+			TestName: "MERGE invalid table",
+			Command:  "MERGE INTO notatable USING mstage ON notatable.id = mstage.id WHEN MATCHED THEN DELETE",
+			ExpErr:   "Error: Table \"notatable\" does not exist",
+		},
+		{
+			TestName: "MERGE missing USING",
+			Command:  "MERGE INTO mtarget t mstage ON t.id = mstage.id WHEN MATCHED THEN DELETE",
+			ExpErr:   "Syntax Error: Expecting USING after the name of the table to Merge into",
+		},
+		{
+			TestName: "MERGE invalid source",
+			Command:  "MERGE INTO mtarget USING notatable ON mtarget.id = notatable.id WHEN MATCHED THEN DELETE",
+			ExpErr:   "Error: Table \"notatable\" does not exist",
+		},
+		{
+			TestName: "MERGE source is target",
+			Command:  "MERGE INTO mtarget t USING mtarget s ON t.id = s.id WHEN MATCHED THEN DELETE",
+			ExpErr:   "Error: The table after USING must not be the table mtarget that is merged into",
+		},
+		{
+			TestName: "MERGE missing ON",
+			Command:  "MERGE INTO mtarget USING mstage WHEN MATCHED THEN DELETE",
+			ExpErr:   "Syntax Error: Expecting ON after the name of the table after USING",
+		},
+		{
+			TestName: "MERGE ON invalid column",
+			Command:  "MERGE INTO mtarget USING mstage ON mtarget.id = mstage.nocol WHEN MATCHED THEN DELETE",
+			ExpErr:   "Error: Column \"nocol\" not found in Table \"mstage\"",
+		},
+		{
+			TestName: "MERGE SELECT missing alias",
+			Command:  "MERGE INTO mtarget USING (SELECT id FROM mstage) ON mtarget.id = mstage.id WHEN MATCHED THEN DELETE",
+			ExpErr:   "Syntax Error: Expecting an alias after SELECT statement in USING",
+		},
+		{
+			TestName: "MERGE VALUES missing alias",
+			Command:  "MERGE INTO mtarget USING (VALUES (1, 2)) ON mtarget.id = 1 WHEN MATCHED THEN DELETE",
+			ExpErr:   "Syntax Error: Expecting an alias after VALUES list",
+		},
+		{
+			TestName: "MERGE missing WHEN",
+			Command:  "MERGE INTO mtarget USING mstage ON mtarget.id = mstage.id",
+			ExpErr:   "Syntax Error: Expecting WHEN after the ON condition",
+		},
+		{
+			TestName: "MERGE missing MATCHED",
+			Command:  "MERGE INTO mtarget USING mstage ON mtarget.id = mstage.id WHEN THEN DELETE",
+			ExpErr:   "Syntax Error: Expecting MATCHED or NOT MATCHED after WHEN",
+		},
+		{
+			TestName: "MERGE missing THEN",
+			Command:  "MERGE INTO mtarget USING mstage ON mtarget.id = mstage.id WHEN NOT MATCHED INSERT VALUES (1, \"x\", 1)",
+			ExpErr:   "Syntax Error: Expecting THEN after WHEN NOT MATCHED",
+		},
+		{
+			TestName: "MERGE MATCHED INSERT",
+			Command:  "MERGE INTO mtarget USING mstage ON mtarget.id = mstage.id WHEN MATCHED THEN INSERT VALUES (1, \"x\", 1)",
+			ExpErr:   "Syntax Error: Expecting UPDATE, DELETE or DO NOTHING after WHEN MATCHED THEN",
+		},
+		{
+			TestName: "MERGE NOT MATCHED UPDATE",
+			Command:  "MERGE INTO mtarget USING mstage ON mtarget.id = mstage.id WHEN NOT MATCHED THEN UPDATE SET qty = 1",
+			ExpErr:   "Syntax Error: Expecting INSERT or DO NOTHING after WHEN NOT MATCHED THEN",
+		},
+		{
+			TestName: "MERGE NOT MATCHED uses target",
+			Command:  "MERGE INTO mtarget USING mstage ON mtarget.id = mstage.id WHEN NOT MATCHED THEN INSERT VALUES (mtarget.id, \"x\", 1)",
+			ExpErr:   "Error: Table mtarget not found in table list",
+		},
+		{
+			TestName: "MERGE INSERT wrong number of values",
+			Command:  "MERGE INTO mtarget USING mstage ON mtarget.id = mstage.id WHEN NOT MATCHED THEN INSERT (id, qty) VALUES (mstage.id)",
+			ExpErr:   "Error: The Number of Columns (2) does not match the number of Values (1)",
+		},
+		{
+			TestName: "MERGE DO missing NOTHING",
+			Command:  "MERGE INTO mtarget USING mstage ON mtarget.id = mstage.id WHEN MATCHED THEN DO",
+			ExpErr:   "Syntax Error: Expecting NOTHING after DO",
+		},
+		{
+			TestName: "MERGE extra tokens",
+			Command:  "MERGE INTO mtarget USING mstage ON mtarget.id = mstage.id WHEN MATCHED THEN DELETE mstage",
+			ExpErr:   "Syntax Error: Unexpected tokens after WHEN MATCHED clause:[IDENT=mstage]",
+		},
+		{
+			TestName: "MERGE update, delete and insert",
+			Command: "MERGE INTO mtarget t USING mstage s ON t.id = s.id " +
+				"WHEN MATCHED AND s.del = true THEN DELETE " +
+				"WHEN MATCHED THEN UPDATE SET name = s.name, qty = t.qty + s.qty " +
+				"WHEN NOT MATCHED THEN INSERT (id, name, qty) VALUES (s.id, s.name, s.qty)",
+			ExpMsg:  "Merged into mtarget: 1 rows inserted, 1 rows updated, 1 rows deleted",
+			ExpVals: sqtypes.RawVals{{1, "a", 10}, {2, "B", 45}, {4, "d", 40}},
+		},
+		{
+			TestName: "MERGE no clause applies",
+			Command: "MERGE INTO mtarget USING mstage ON mtarget.id = mstage.id " +
+				"WHEN MATCHED THEN DO NOTHING " +
+				"WHEN NOT MATCHED AND mstage.qty > 100 THEN INSERT VALUES (mstage.id, mstage.name, 0)",
+			ExpMsg:  "Merged into mtarget: 0 rows inserted, 0 rows updated, 0 rows deleted",
+			ExpVals: sqtypes.RawVals{{1, "a", 10}, {2, "B", 45}, {4, "d", 40}},
+		},
+		{
+			TestName: "MERGE same row twice",
+			Command: "MERGE INTO mtarget USING mdups ON mtarget.id = mdups.id " +
+				"WHEN MATCHED THEN UPDATE SET qty = mdups.qty " +
+				"WHEN NOT MATCHED THEN INSERT (id, qty) VALUES (mdups.id, mdups.qty)",
+			ExpErr:  "Error: MERGE can not change the same row more than once",
+			ExpVals: sqtypes.RawVals{{1, "a", 10}, {2, "B", 45}, {4, "d", 40}},
+		},
+		{
+			TestName: "MERGE insert without columns",
+			Command:  "MERGE INTO mtarget USING mstage ON mtarget.id = mstage.id WHEN NOT MATCHED THEN INSERT VALUES (mstage.id, mstage.name, mstage.qty + 1)",
+			ExpMsg:   "Merged into mtarget: 1 rows inserted, 0 rows updated, 0 rows deleted",
+			ExpVals:  sqtypes.RawVals{{1, "a", 10}, {2, "B", 45}, {3, "C", 1}, {4, "d", 40}},
+		},
+		{
+			TestName: "MERGE ON with an expression",
+			Command: "MERGE INTO mtarget t USING mstage s ON t.id = s.id AND s.qty > 20 " +
+				"WHEN MATCHED THEN UPDATE SET qty = s.qty " +
+				"WHEN NOT MATCHED THEN DO NOTHING",
+			ExpMsg:  "Merged into mtarget: 0 rows inserted, 2 rows updated, 0 rows deleted",
+			ExpVals: sqtypes.RawVals{{1, "a", 10}, {2, "B", 25}, {3, "C", 1}, {4, "d", 40}},
+		},
+		{
+			TestName: "MERGE ON not an equal join",
+			Command:  "MERGE INTO mtarget USING mstage ON mtarget.id > mstage.id AND mstage.del = true WHEN MATCHED THEN DELETE",
+			ExpMsg:   "Merged into mtarget: 0 rows inserted, 0 rows updated, 1 rows deleted",
+			ExpVals:  sqtypes.RawVals{{1, "a", 10}, {2, "B", 25}, {3, "C", 1}},
+		},
+		{
+			TestName: "MERGE using SELECT",
+			Command: "MERGE INTO mtarget USING (SELECT id, qty * 2 AS qty FROM mstage WHERE del = false) AS s ON mtarget.id = s.id " +
+				"WHEN MATCHED THEN UPDATE SET qty = s.qty " +
+				"WHEN NOT MATCHED THEN INSERT (id, qty) VALUES (s.id, s.qty)",
+			ExpMsg:  "Merged into mtarget: 1 rows inserted, 1 rows updated, 0 rows deleted",
+			ExpVals: sqtypes.RawVals{{1, "a", 10}, {2, "B", 50}, {3, "C", 1}, {4, nil, 80}},
+		},
+		{
+			TestName: "MERGE using VALUES",
+			Command: "MERGE INTO mtarget USING (VALUES (5, \"e\", 50), (1, \"A\", 11)) AS v (id, name, qty) ON mtarget.id = v.id " +
+				"WHEN MATCHED THEN UPDATE SET name = v.name " +
+				"WHEN NOT MATCHED THEN INSERT VALUES (v.id, v.name, v.qty)",
+			ExpMsg:  "Merged into mtarget: 1 rows inserted, 1 rows updated, 0 rows deleted",
+			ExpVals: sqtypes.RawVals{{1, "A", 10}, {2, "B", 50}, {3, "C", 1}, {4, nil, 80}, {5, "e", 50}},
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testMergeFunc(profile, row))
+
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"sort"
+	"strconv"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/wilphi/sqsrv/sqerr"
@@ -24,6 +25,7 @@ func Select(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *sqtabl
 	if err != nil {
 		return "", nil, err
 	}
+	data.PadChars()
 	return fmt.Sprintf("%d rows found", data.Len()), data, err
 }
 
@@ -34,6 +36,7 @@ func SelectParse(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (*sqtable
 	var err error
 
 	var isAsterix = false
+	var hasOffset = false
 
 	log.Info("SELECT statement...")
 	q := sqtables.Query{}
@@ -56,22 +59,29 @@ func SelectParse(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (*sqtable
 
 	} else {
 		// get the column list
-		q.EList, err = GetExprList(tkns, tokens.From, tokens.Select)
+		q.EList, err = GetExprList(tkns, tokens.NilToken, tokens.Select)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	// Get the FROM clause
-	if !tkns.IsA(tokens.From) {
-		// no FROM
-		return nil, sqerr.NewSyntax("Expecting FROM")
-	}
-	tkns.Remove()
-	//	q.Tables, err = GetTableList(profile, tkns, tokens.Where, tokens.Order, tokens.Group, tokens.Having)
-	q.Tables, q.Joins, err = ParseFromClause(profile, tkns, tokens.Where, tokens.Order, tokens.Group, tokens.Having)
-	if err != nil {
-		return nil, err
+	// Get the FROM clause. Without a FROM the expressions are evaluated once
+	if tkns.IsA(tokens.From) {
+		tkns.Remove()
+		q.Tables, q.Joins, err = ParseFromClause(profile, tkns, tokens.Where, tokens.Order, tokens.Group, tokens.Having, tokens.Limit, tokens.Offset, tokens.Fetch, tokens.Union, tokens.Intersect, tokens.Except)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if isAsterix {
+			return nil, sqerr.NewSyntax("Expecting FROM")
+		}
+		for _, expr := range q.EList.GetExprs() {
+			if cols := expr.ColRefs(); len(cols) > 0 {
+				return nil, sqerr.NewSyntaxf("Column %q can not be used without a FROM clause", cols[0].ColName)
+			}
+		}
+		q.Tables = sqtables.NewTableList(profile, nil)
 	}
 	// get the cols in the table
 	// Once we have the table name we can generate the column list
@@ -111,7 +121,7 @@ func SelectParse(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (*sqtable
 				return nil, sqerr.NewSyntax("Duplicate where clause, only one allowed")
 			}
 			tkns.Remove()
-			q.WhereExpr, err = ParseWhereClause(tkns, false, tokens.Order, tokens.Group, tokens.Having)
+			q.WhereExpr, err = ParseWhereClause(tkns, false, tokens.Order, tokens.Group, tokens.Having, tokens.Limit, tokens.Offset, tokens.Fetch, tokens.Union, tokens.Intersect, tokens.Except)
 			if err != nil {
 				return nil, err
 			}
@@ -127,11 +137,12 @@ func SelectParse(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (*sqtable
 				return nil, sqerr.NewSyntax("Duplicate group by clause, only one allowed")
 			}
 			tkns.Remove()
-			groupBy, err := GroupByClause(tkns)
+			groupBy, sets, err := GroupByClause(tkns)
 			if err != nil {
 				return nil, err
 			}
 			q.GroupBy = groupBy
+			q.GroupingSets = sets
 			err = q.GroupBy.ValidateCols(profile, q.Tables)
 			if err != nil {
 				return nil, err
@@ -157,11 +168,82 @@ func SelectParse(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (*sqtable
 			if q.HavingExpr != nil {
 				return nil, sqerr.NewSyntax("Duplicate Having clause, only one allowed")
 			}
-			q.HavingExpr, err = HavingClause(tkns, tokens.Order, tokens.Group, tokens.Where)
+			q.HavingExpr, err = HavingClause(tkns, tokens.Order, tokens.Group, tokens.Where, tokens.Limit, tokens.Offset, tokens.Fetch, tokens.Union, tokens.Intersect, tokens.Except)
 			if err != nil {
 				return nil, err
 			}
 		}
+
+		// Optional Limit clause processing
+		if tkns.IsA(tokens.Limit) {
+			clauseProcessed = true
+			if q.HasLimit {
+				return nil, sqerr.NewSyntax("Duplicate LIMIT or FETCH clause, only one allowed")
+			}
+			tkns.Remove()
+			q.Limit, err = getRowCount(tkns, "LIMIT")
+			if err != nil {
+				return nil, err
+			}
+			q.HasLimit = true
+		}
+
+		// Optional Offset clause processing
+		if tkns.IsA(tokens.Offset) {
+			clauseProcessed = true
+			if hasOffset {
+				return nil, sqerr.NewSyntax("Duplicate OFFSET clause, only one allowed")
+			}
+			tkns.Remove()
+			q.Offset, err = getRowCount(tkns, "OFFSET")
+			if err != nil {
+				return nil, err
+			}
+			// Optional ROW or ROWS
+			if !tkns.IsAKeywordRemove("ROW") {
+				tkns.IsAKeywordRemove("ROWS")
+			}
+			hasOffset = true
+		}
+
+		// Optional Fetch clause processing
+		if tkns.IsA(tokens.Fetch) {
+			clauseProcessed = true
+			if q.HasLimit {
+				return nil, sqerr.NewSyntax("Duplicate LIMIT or FETCH clause, only one allowed")
+			}
+			tkns.Remove()
+			q.Limit, err = fetchClause(tkns)
+			if err != nil {
+				return nil, err
+			}
+			q.HasLimit = true
+		}
+
+		// Optional UNION, INTERSECT or EXCEPT. The rest of the tokens are another SELECT
+		if tkn := tkns.TestTkn(tokens.Union, tokens.Intersect, tokens.Except); tkn != nil {
+			if q.OrderBy != nil || q.HasLimit || hasOffset {
+				return nil, sqerr.NewSyntaxf("ORDER BY, LIMIT, OFFSET and FETCH are only allowed after the last SELECT when using %s", tkn.Name())
+			}
+			tkns.Remove()
+			setOp := sqtables.SetOp{Op: tkn.ID(), All: tkns.IsARemove(tokens.All)}
+			if !tkns.IsA(tokens.Select) {
+				return nil, sqerr.NewSyntaxf("Expecting SELECT after %s", tkn.Name())
+			}
+			setOp.Query, err = SelectParse(profile, tkns)
+			if err != nil {
+				return nil, err
+			}
+			if q.EList.Len() != setOp.Query.EList.Len() {
+				return nil, sqerr.NewSyntaxf("Each SELECT in %s must have the same number of columns: %d != %d", tkn.Name(), q.EList.Len(), setOp.Query.EList.Len())
+			}
+			// The trailing clauses of the last SELECT apply to the combined result
+			next := setOp.Query
+			q.SetOps = append([]sqtables.SetOp{setOp}, next.SetOps...)
+			q.OrderBy, q.HasLimit, q.Limit, q.Offset = next.OrderBy, next.HasLimit, next.Limit, next.Offset
+			next.SetOps, next.OrderBy, next.HasLimit, next.Limit, next.Offset = nil, nil, false, 0, 0
+			break
+		}
 	}
 
 	if !tkns.IsEmpty() {
@@ -172,9 +254,84 @@ func SelectParse(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (*sqtable
 
 }
 
+// getRowCount gets the non negative integer that follows a LIMIT or OFFSET
+func getRowCount(tkns *tokens.TokenList, clause string) (int, error) {
+	tkn := tkns.TestTkn(tokens.Num)
+	if tkn == nil {
+		return 0, sqerr.NewSyntaxf("%s must be followed by a non-negative integer", clause)
+	}
+	n, err := strconv.Atoi(tkn.(*tokens.ValueToken).Value())
+	if err != nil || n < 0 {
+		return 0, sqerr.NewSyntaxf("%s must be followed by a non-negative integer", clause)
+	}
+	tkns.Remove()
+	return n, nil
+}
+
+// fetchClause processes FETCH {FIRST|NEXT} [n] {ROW|ROWS} ONLY. The FETCH token has already been removed
+func fetchClause(tkns *tokens.TokenList) (int, error) {
+	var err error
+
+	if !tkns.IsAKeywordRemove("FIRST") && !tkns.IsAKeywordRemove("NEXT") {
+		return 0, sqerr.NewSyntax("FETCH must be followed by FIRST or NEXT")
+	}
+	// the row count is optional and defaults to 1
+	n := 1
+	if !tkns.IsAKeyword("ROW") && !tkns.IsAKeyword("ROWS") {
+		n, err = getRowCount(tkns, "FETCH FIRST")
+		if err != nil {
+			return 0, err
+		}
+	}
+	if !tkns.IsAKeywordRemove("ROW") && !tkns.IsAKeywordRemove("ROWS") {
+		return 0, sqerr.NewSyntax("Expecting ROW or ROWS in FETCH clause")
+	}
+	if !tkns.IsAKeywordRemove("ONLY") {
+		return 0, sqerr.NewSyntax("Expecting ONLY at end of FETCH clause")
+	}
+	return n, nil
+}
+
 // SelectExecute executes the select command against the data to return the result
 func SelectExecute(profile *sqprofile.SQProfile, q *sqtables.Query) (*sqtables.DataSet, error) {
 
+	data, err := getSelectRows(profile, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(q.SetOps) > 0 {
+		data, err = executeSetOps(profile, data, q.SetOps)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if q.OrderBy != nil || len(q.OrderBy) > 0 {
+		err = data.SetOrder(q.OrderBy)
+		if err != nil {
+			return nil, err
+		}
+		// Only the rows up to the end of the limit need to be sorted
+		if q.HasLimit {
+			data.SetLimit(q.Offset + q.Limit)
+		}
+		err = data.Sort()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if q.HasLimit {
+		data.Slice(q.Offset, q.Limit)
+	} else if q.Offset > 0 {
+		data.Slice(q.Offset, -1)
+	}
+	return data, nil
+}
+
+// getSelectRows gets the rows for a single SELECT without any ordering or limits
+func getSelectRows(profile *sqprofile.SQProfile, q *sqtables.Query) (*sqtables.DataSet, error) {
 	data, err := q.GetRowData(profile)
 	if err != nil {
 		return nil, err
@@ -184,13 +341,33 @@ func SelectExecute(profile *sqprofile.SQProfile, q *sqtables.Query) (*sqtables.D
 	if q.IsDistinct {
 		data.Distinct()
 	}
+	return data, nil
+}
 
-	if q.OrderBy != nil || len(q.OrderBy) > 0 {
-		err = data.SetOrder(q.OrderBy)
+// executeSetOps combines the results of the queries in setOps with data.
+//   INTERSECT has a higher precedence than UNION and EXCEPT which are processed left to right
+func executeSetOps(profile *sqprofile.SQProfile, data *sqtables.DataSet, setOps []sqtables.SetOp) (*sqtables.DataSet, error) {
+	results := []*sqtables.DataSet{data}
+	var ops []sqtables.SetOp
+
+	for _, setOp := range setOps {
+		d2, err := getSelectRows(profile, setOp.Query)
 		if err != nil {
 			return nil, err
 		}
-		err = data.Sort()
+		if setOp.Op == tokens.Intersect {
+			err = results[len(results)-1].SetOperation(setOp.Op, setOp.All, d2)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		results = append(results, d2)
+		ops = append(ops, setOp)
+	}
+
+	for i, setOp := range ops {
+		err := data.SetOperation(setOp.Op, setOp.All, results[i+1])
 		if err != nil {
 			return nil, err
 		}
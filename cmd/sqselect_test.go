@@ -128,7 +128,7 @@ func TestSelect(t *testing.T) {
 		},
 		{
 			TestName: "SELECT missing comma",
-			Command:  "SELECT col1",
+			Command:  "SELECT col1 col2 col3 FROM seltest",
 			ExpErr:   "Syntax Error: Comma is required to separate expressions",
 			ExpRows:  0,
 			ExpCols:  []string{},
@@ -137,7 +137,7 @@ func TestSelect(t *testing.T) {
 		{
 			TestName: "SELECT missing FROM",
 			Command:  "SELECT col1, col2, col3",
-			ExpErr:   "Syntax Error: Comma is required to separate expressions",
+			ExpErr:   "Syntax Error: Column \"col1\" can not be used without a FROM clause",
 			ExpRows:  0,
 			ExpCols:  []string{},
 			ExpVals:  nil,
@@ -935,6 +935,18 @@ func TestSelect(t *testing.T) {
 			},
 		},
 
+		{
+			TestName: "Select with table alias self JOIN",
+			Command:  "select a.name, b.name from city a JOIN city b ON a.country = b.country where a.name = \"Joliette\" order by b.name",
+			ExpErr:   "",
+			ExpRows:  2,
+			ExpCols:  []string{"a.name", "b.name"},
+			ExpVals: sqtypes.RawVals{
+				{"Joliette", "Joliette"},
+				{"Joliette", "Tofino"},
+			},
+		},
+
 		{
 			TestName: "Select with table alias DOUBLE CROSS JOIN",
 			Command:  "select p.firstname, c.name, cn.name from city c CROSS JOIN country cn CROSS JOIN person p where c.name = \"Joliette\" AND  p.firstname = \"Yvone\" order by cn.name",
@@ -957,6 +969,492 @@ func TestSelect(t *testing.T) {
 				{"Joliette", "Canada"},
 			},
 		},
+		{
+			TestName: "Select ORDER BY with LIMIT",
+			Command:  "SELECT * FROM seltest ORDER BY col1 desc LIMIT 2",
+			ExpErr:   "",
+			ExpRows:  2,
+			ExpCols:  []string{"col1", "col2", "col3"},
+			ExpVals:  sqtypes.RawVals{{789, "Seltest 3", false}, {456, "Seltest 2", true}},
+		},
+		{
+			TestName: "Select ORDER BY with LIMIT and OFFSET",
+			Command:  "SELECT * FROM seltest ORDER BY col1 LIMIT 1 OFFSET 1",
+			ExpErr:   "",
+			ExpRows:  1,
+			ExpCols:  []string{"col1", "col2", "col3"},
+			ExpVals:  sqtypes.RawVals{{456, "Seltest 2", true}},
+		},
+		{
+			TestName: "Select OFFSET before LIMIT",
+			Command:  "SELECT * FROM seltest ORDER BY col1 OFFSET 2 LIMIT 5",
+			ExpErr:   "",
+			ExpRows:  1,
+			ExpCols:  []string{"col1", "col2", "col3"},
+			ExpVals:  sqtypes.RawVals{{789, "Seltest 3", false}},
+		},
+		{
+			TestName: "Select OFFSET without LIMIT",
+			Command:  "SELECT * FROM seltest ORDER BY col1 OFFSET 1 ROWS",
+			ExpErr:   "",
+			ExpRows:  2,
+			ExpCols:  []string{"col1", "col2", "col3"},
+			ExpVals:  sqtypes.RawVals{{456, "Seltest 2", true}, {789, "Seltest 3", false}},
+		},
+		{
+			TestName: "Select OFFSET past end",
+			Command:  "SELECT * FROM seltest ORDER BY col1 LIMIT 5 OFFSET 10",
+			ExpErr:   "",
+			ExpRows:  0,
+			ExpCols:  []string{"col1", "col2", "col3"},
+			ExpVals:  sqtypes.RawVals{},
+		},
+		{
+			TestName: "Select LIMIT 0",
+			Command:  "SELECT * FROM seltest ORDER BY col1 LIMIT 0",
+			ExpErr:   "",
+			ExpRows:  0,
+			ExpCols:  []string{"col1", "col2", "col3"},
+			ExpVals:  sqtypes.RawVals{},
+		},
+		{
+			TestName: "Select WHERE with LIMIT no ORDER BY",
+			Command:  "SELECT col1 FROM seltest WHERE col3 = true LIMIT 1",
+			ExpErr:   "",
+			ExpRows:  1,
+			ExpCols:  []string{"col1"},
+			ExpVals:  sqtypes.RawVals{{123}},
+		},
+		{
+			TestName: "Select FETCH FIRST",
+			Command:  "SELECT * FROM seltest ORDER BY col1 OFFSET 1 ROW FETCH FIRST 1 ROWS ONLY",
+			ExpErr:   "",
+			ExpRows:  1,
+			ExpCols:  []string{"col1", "col2", "col3"},
+			ExpVals:  sqtypes.RawVals{{456, "Seltest 2", true}},
+		},
+		{
+			TestName: "Select FETCH NEXT ROW ONLY",
+			Command:  "SELECT * FROM seltest ORDER BY col1 desc FETCH NEXT ROW ONLY",
+			ExpErr:   "",
+			ExpRows:  1,
+			ExpCols:  []string{"col1", "col2", "col3"},
+			ExpVals:  sqtypes.RawVals{{789, "Seltest 3", false}},
+		},
+		{
+			TestName: "Select LIMIT missing count",
+			Command:  "SELECT * FROM seltest LIMIT",
+			ExpErr:   "Syntax Error: LIMIT must be followed by a non-negative integer",
+		},
+		{
+			TestName: "Select LIMIT negative count",
+			Command:  "SELECT * FROM seltest LIMIT -1",
+			ExpErr:   "Syntax Error: LIMIT must be followed by a non-negative integer",
+		},
+		{
+			TestName: "Select LIMIT float count",
+			Command:  "SELECT * FROM seltest LIMIT 1.5",
+			ExpErr:   "Syntax Error: LIMIT must be followed by a non-negative integer",
+		},
+		{
+			TestName: "Select OFFSET missing count",
+			Command:  "SELECT * FROM seltest OFFSET ROWS",
+			ExpErr:   "Syntax Error: OFFSET must be followed by a non-negative integer",
+		},
+		{
+			TestName: "Select duplicate LIMIT",
+			Command:  "SELECT * FROM seltest LIMIT 1 LIMIT 2",
+			ExpErr:   "Syntax Error: Duplicate LIMIT or FETCH clause, only one allowed",
+		},
+		{
+			TestName: "Select LIMIT and FETCH",
+			Command:  "SELECT * FROM seltest LIMIT 1 FETCH FIRST 2 ROWS ONLY",
+			ExpErr:   "Syntax Error: Duplicate LIMIT or FETCH clause, only one allowed",
+		},
+		{
+			TestName: "Select duplicate OFFSET",
+			Command:  "SELECT * FROM seltest OFFSET 1 OFFSET 2",
+			ExpErr:   "Syntax Error: Duplicate OFFSET clause, only one allowed",
+		},
+		{
+			TestName: "Select FETCH missing FIRST",
+			Command:  "SELECT * FROM seltest FETCH 2 ROWS ONLY",
+			ExpErr:   "Syntax Error: FETCH must be followed by FIRST or NEXT",
+		},
+		{
+			TestName: "Select FETCH missing ROWS",
+			Command:  "SELECT * FROM seltest FETCH FIRST 2 ONLY",
+			ExpErr:   "Syntax Error: Expecting ROW or ROWS in FETCH clause",
+		},
+		{
+			TestName: "Select FETCH missing ONLY",
+			Command:  "SELECT * FROM seltest FETCH FIRST 2 ROWS",
+			ExpErr:   "Syntax Error: Expecting ONLY at end of FETCH clause",
+		},
+		{
+			TestName: "Select Multitable Group By with LIMIT",
+			Command:  "select city.name, country.short, count() from city JOIN country ON city.country = country.name INNER JOIN person ON city.cityid = person.cityid group by city.name, country.short having count() > 3 order by country.short, city.name limit 3 offset 2",
+			ExpErr:   "",
+			ExpRows:  3, ExpCols: []string{"city.name", "country.short", "COUNT()"},
+			ExpVals: sqtypes.RawVals{
+				{"Chester", "USA", 4},
+				{"Hampton", "USA", 5},
+				{"Largo", "USA", 4},
+			},
+		},
+		{
+			TestName: "Select UNION",
+			Command:  "SELECT first FROM names UNION SELECT last FROM names ORDER BY first",
+			ExpErr:   "",
+			ExpRows:  7,
+			ExpCols:  []string{"first"},
+			ExpVals:  sqtypes.RawVals{{"Biden"}, {"Brown"}, {"Fred"}, {"Hammer"}, {"Joe"}, {"Johnson"}, {"Sue"}},
+		},
+		{
+			TestName: "Select UNION ALL",
+			Command:  "SELECT first FROM names UNION ALL SELECT last FROM names ORDER BY first",
+			ExpErr:   "",
+			ExpRows:  10,
+			ExpCols:  []string{"first"},
+			ExpVals:  sqtypes.RawVals{{"Biden"}, {"Brown"}, {"Fred"}, {"Fred"}, {"Hammer"}, {"Joe"}, {"Johnson"}, {"Johnson"}, {"Sue"}, {"Sue"}},
+		},
+		{
+			TestName: "Select UNION with LIMIT",
+			Command:  "SELECT first FROM names UNION SELECT last FROM names ORDER BY first DESC LIMIT 2",
+			ExpErr:   "",
+			ExpRows:  2,
+			ExpCols:  []string{"first"},
+			ExpVals:  sqtypes.RawVals{{"Sue"}, {"Johnson"}},
+		},
+		{
+			TestName: "Select UNION ALL multiple columns",
+			Command:  "SELECT * FROM seltest UNION ALL SELECT * FROM seltest WHERE col1 = 123 ORDER BY col1",
+			ExpErr:   "",
+			ExpRows:  4,
+			ExpCols:  []string{"col1", "col2", "col3"},
+			ExpVals:  sqtypes.RawVals{{123, "With Cols Test", true}, {123, "With Cols Test", true}, {456, "Seltest 2", true}, {789, "Seltest 3", false}},
+		},
+		{
+			TestName: "Select INTERSECT",
+			Command:  "SELECT first FROM names WHERE age >= 20 INTERSECT SELECT first FROM names WHERE age < 21",
+			ExpErr:   "",
+			ExpRows:  2,
+			ExpCols:  []string{"first"},
+			ExpVals:  sqtypes.RawVals{{"Fred"}, {"Sue"}},
+		},
+		{
+			TestName: "Select INTERSECT ALL",
+			Command:  "SELECT first FROM names INTERSECT ALL SELECT first FROM names WHERE age < 21",
+			ExpErr:   "",
+			ExpRows:  3,
+			ExpCols:  []string{"first"},
+			ExpVals:  sqtypes.RawVals{{"Fred"}, {"Fred"}, {"Sue"}},
+		},
+		{
+			TestName: "Select EXCEPT",
+			Command:  "SELECT first FROM names EXCEPT SELECT first FROM names WHERE age = 78",
+			ExpErr:   "",
+			ExpRows:  2,
+			ExpCols:  []string{"first"},
+			ExpVals:  sqtypes.RawVals{{"Fred"}, {"Sue"}},
+		},
+		{
+			TestName: "Select EXCEPT ALL",
+			Command:  "SELECT first FROM names EXCEPT ALL SELECT first FROM names WHERE age = 20",
+			ExpErr:   "",
+			ExpRows:  3,
+			ExpCols:  []string{"first"},
+			ExpVals:  sqtypes.RawVals{{"Fred"}, {"Joe"}, {"Sue"}},
+		},
+		{
+			TestName: "Select INTERSECT before UNION",
+			Command:  "SELECT first FROM names WHERE age = 78 UNION SELECT first FROM names WHERE age = 10 INTERSECT SELECT first FROM names WHERE age = 20 ORDER BY first",
+			ExpErr:   "",
+			ExpRows:  2,
+			ExpCols:  []string{"first"},
+			ExpVals:  sqtypes.RawVals{{"Fred"}, {"Joe"}},
+		},
+		{
+			TestName: "Select UNION then EXCEPT",
+			Command:  "SELECT first FROM names UNION SELECT last FROM names EXCEPT SELECT last FROM names WHERE age = 20 ORDER BY first",
+			ExpErr:   "",
+			ExpRows:  5,
+			ExpCols:  []string{"first"},
+			ExpVals:  sqtypes.RawVals{{"Biden"}, {"Brown"}, {"Fred"}, {"Joe"}, {"Sue"}},
+		},
+		{
+			TestName: "Select UNION column count mismatch",
+			Command:  "SELECT first, last FROM names UNION SELECT first FROM names",
+			ExpErr:   "Syntax Error: Each SELECT in UNION must have the same number of columns: 2 != 1",
+		},
+		{
+			TestName: "Select UNION type mismatch",
+			Command:  "SELECT first FROM names UNION SELECT age FROM names",
+			ExpErr:   "Error: Type Mismatch: Column 1 in UNION has types STRING and INT",
+		},
+		{
+			TestName: "Select UNION type mismatch with no rows",
+			Command:  "SELECT age FROM names WHERE age > 1000 UNION SELECT first FROM names",
+			ExpErr:   "Error: Type Mismatch: Column 1 in UNION has types INT and STRING",
+		},
+		{
+			TestName: "Select UNION INT and FLOAT",
+			Command:  "SELECT age FROM names WHERE age = 10 UNION SELECT 1.5 ORDER BY age",
+			ExpErr:   "",
+			ExpRows:  2,
+			ExpCols:  []string{"age"},
+			ExpVals:  sqtypes.RawVals{{1.5}, {10.0}},
+		},
+		{
+			TestName: "Select EXCEPT FLOAT and INT",
+			Command:  "SELECT FLOAT(age) FROM names EXCEPT SELECT age FROM names WHERE age != 10",
+			ExpErr:   "",
+			ExpRows:  1,
+			ExpCols:  []string{"FLOAT(age)"},
+			ExpVals:  sqtypes.RawVals{{10.0}},
+		},
+		{
+			TestName: "Select ORDER BY before UNION",
+			Command:  "SELECT first FROM names ORDER BY first UNION SELECT last FROM names",
+			ExpErr:   "Syntax Error: ORDER BY, LIMIT, OFFSET and FETCH are only allowed after the last SELECT when using UNION",
+		},
+		{
+			TestName: "Select LIMIT before EXCEPT",
+			Command:  "SELECT first FROM names LIMIT 2 EXCEPT SELECT last FROM names",
+			ExpErr:   "Syntax Error: ORDER BY, LIMIT, OFFSET and FETCH are only allowed after the last SELECT when using EXCEPT",
+		},
+		{
+			TestName: "Select UNION missing SELECT",
+			Command:  "SELECT first FROM names UNION",
+			ExpErr:   "Syntax Error: Expecting SELECT after UNION",
+		},
+		{
+			TestName: "Select aggregate DISTINCT",
+			Command:  "SELECT count(DISTINCT first), count(*), count(DISTINCT age), sum(DISTINCT age), avg(DISTINCT age) FROM names",
+			ExpRows:  1,
+			ExpCols:  []string{"COUNT(DISTINCT first)", "COUNT()", "COUNT(DISTINCT age)", "SUM(DISTINCT age)", "AVG(DISTINCT age)"},
+			ExpVals:  sqtypes.RawVals{{3, 5, 4, 129, 32.25}},
+		},
+		{
+			TestName: "Select aggregate DISTINCT group by",
+			Command:  "SELECT last, count(DISTINCT first) FROM names GROUP BY last",
+			ExpRows:  4,
+			ExpCols:  []string{"last", "COUNT(DISTINCT first)"},
+			ExpVals:  sqtypes.RawVals{{"Biden", 1}, {"Brown", 1}, {"Hammer", 1}, {"Johnson", 2}},
+		},
+		{
+			TestName: "Select aggregate FILTER",
+			Command:  "SELECT count(*) FILTER (WHERE age > 15), count() FILTER (WHERE first = \"Fred\"), sum(age) FILTER (WHERE last = \"Johnson\"), max(age) FILTER (WHERE age < 0) FROM names",
+			ExpRows:  1,
+			ExpCols:  []string{"COUNT() FILTER (WHERE (age>15))", "COUNT() FILTER (WHERE (first=Fred))", "SUM(age) FILTER (WHERE (last=Johnson))", "MAX(age) FILTER (WHERE (age<0))"},
+			ExpVals:  sqtypes.RawVals{{4, 2, 30, nil}},
+		},
+		{
+			TestName: "Select aggregate FILTER group by",
+			Command:  "SELECT first, count() FILTER (WHERE age >= 20) AS adults, count(DISTINCT last) FILTER (WHERE age < 21) AS names FROM names GROUP BY first",
+			ExpRows:  3,
+			ExpCols:  []string{"first", "adults", "names"},
+			ExpVals:  sqtypes.RawVals{{"Fred", 1, 2}, {"Joe", 1, 0}, {"Sue", 2, 1}},
+		},
+		{
+			TestName: "Select aggregate FILTER in HAVING",
+			Command:  "SELECT first, count() FROM names GROUP BY first HAVING count() FILTER (WHERE age > 15) > 1",
+			ExpRows:  1,
+			ExpCols:  []string{"first", "COUNT()"},
+			ExpVals:  sqtypes.RawVals{{"Sue", 2}},
+		},
+		{
+			TestName: "Select FILTER as a name",
+			Command:  "SELECT first filter, int(age) filter FROM names WHERE last = \"Biden\"",
+			ExpRows:  1,
+			ExpCols:  []string{"filter", "filter"},
+			ExpVals:  sqtypes.RawVals{{"Joe", 78}},
+		},
+		{
+			TestName: "Select DISTINCT without expression",
+			Command:  "SELECT count(DISTINCT) FROM names",
+			ExpErr:   "Syntax Error: Function COUNT is missing an expression after DISTINCT",
+		},
+		{
+			TestName: "Select COUNT(*) with expression",
+			Command:  "SELECT count(* age) FROM names",
+			ExpErr:   "Syntax Error: Expecting ) after COUNT(*",
+		},
+		{
+			TestName: "Select FILTER non aggregate",
+			Command:  "SELECT int(age) FILTER (WHERE age > 1) FROM names",
+			ExpErr:   "Syntax Error: FILTER can only be used with aggregate functions not INT",
+		},
+		{
+			TestName: "Select FILTER missing WHERE",
+			Command:  "SELECT count() FILTER age > 1 FROM names",
+			ExpErr:   "Syntax Error: Expecting (WHERE after FILTER in COUNT",
+		},
+		{
+			TestName: "Select FILTER empty WHERE",
+			Command:  "SELECT count() FILTER (WHERE) FROM names",
+			ExpErr:   "Syntax Error: Expecting an expression after WHERE in FILTER of COUNT",
+		},
+		{
+			TestName: "Select FILTER missing )",
+			Command:  "SELECT count() FILTER (WHERE age > 1 FROM names",
+			ExpErr:   "Syntax Error: Expecting ) after FILTER of COUNT",
+		},
+		{
+			TestName: "Select FILTER with aggregate",
+			Command:  "SELECT count() FILTER (WHERE count() > 1) FROM names",
+			ExpErr:   "Syntax Error: Aggregate functions (COUNT) are not allowed in Where clause",
+		},
+		{
+			TestName: "Select DISTINCT with OVER",
+			Command:  "SELECT count(DISTINCT age) OVER () FROM names",
+			ExpErr:   "Syntax Error: DISTINCT and FILTER can not be used with COUNT OVER",
+		},
+		{
+			TestName: "Select variance",
+			Command:  "SELECT var_pop(age), var_samp(age), variance(age) FROM names",
+			ExpRows:  1,
+			ExpCols:  []string{"VAR_POP(age)", "VAR_SAMP(age)", "VARIANCE(age)"},
+			ExpVals:  sqtypes.RawVals{{596.96, 746.2, 746.2}},
+		},
+		{
+			TestName: "Select variance and standard deviation group by",
+			Command:  "SELECT first, var_samp(age), var_pop(age), stddev_pop(age), stddev_samp(age) FROM names GROUP BY first",
+			ExpRows:  3,
+			ExpCols:  []string{"first", "VAR_SAMP(age)", "VAR_POP(age)", "STDDEV_POP(age)", "STDDEV_SAMP(age)"},
+			ExpVals:  sqtypes.RawVals{{"Fred", 50.0, 25.0, 5.0, 7.0710678118654755}, {"Joe", nil, 0.0, 0.0, nil}, {"Sue", 0.5, 0.25, 0.5, 0.7071067811865476}},
+		},
+		{
+			TestName: "Select MEDIAN and PERCENTILE",
+			Command:  "SELECT median(age), percentile_cont(0.25) WITHIN GROUP (ORDER BY age), percentile_cont(0.75) WITHIN GROUP (ORDER BY age DESC), percentile_disc(0.5) WITHIN GROUP (ORDER BY age), percentile_disc(0.9) WITHIN GROUP (ORDER BY age DESC) FROM names",
+			ExpRows:  1,
+			ExpCols:  []string{"MEDIAN(age)", "PERCENTILE_CONT(0.25) WITHIN GROUP (ORDER BY age)", "PERCENTILE_CONT(0.75) WITHIN GROUP (ORDER BY age DESC)", "PERCENTILE_DISC(0.5) WITHIN GROUP (ORDER BY age)", "PERCENTILE_DISC(0.9) WITHIN GROUP (ORDER BY age DESC)"},
+			ExpVals:  sqtypes.RawVals{{20.0, 20.0, 20.0, 20, 10}},
+		},
+		{
+			TestName: "Select MEDIAN group by",
+			Command:  "SELECT last, median(age) FROM names GROUP BY last",
+			ExpRows:  4,
+			ExpCols:  []string{"last", "MEDIAN(age)"},
+			ExpVals:  sqtypes.RawVals{{"Biden", 78.0}, {"Brown", 21.0}, {"Hammer", 20.0}, {"Johnson", 15.0}},
+		},
+		{
+			TestName: "Select PERCENTILE_DISC of strings",
+			Command:  "SELECT percentile_disc(0) WITHIN GROUP (ORDER BY first) FROM names",
+			ExpRows:  1,
+			ExpCols:  []string{"PERCENTILE_DISC(0) WITHIN GROUP (ORDER BY first)"},
+			ExpVals:  sqtypes.RawVals{{"Fred"}},
+		},
+		{
+			TestName: "Select STRING_AGG",
+			Command:  "SELECT string_agg(first, \",\" ORDER BY age DESC, first), string_agg(DISTINCT first, \"/\") FROM names",
+			ExpRows:  1,
+			ExpCols:  []string{"STRING_AGG(first, ',' ORDER BY age DESC, first)", "STRING_AGG(DISTINCT first, '/')"},
+			ExpVals:  sqtypes.RawVals{{"Joe,Sue,Fred,Sue,Fred", "Fred/Joe/Sue"}},
+		},
+		{
+			TestName: "Select STRING_AGG group by",
+			Command:  "SELECT last, string_agg(first, \"; \" ORDER BY age) AS firsts FROM names GROUP BY last HAVING string_agg(first, \"\") FILTER (WHERE age > 15) = \"Sue\"",
+			ExpRows:  2,
+			ExpCols:  []string{"last", "firsts"},
+			ExpVals:  sqtypes.RawVals{{"Brown", "Sue"}, {"Johnson", "Fred; Sue"}},
+		},
+		{
+			TestName: "Select STDDEV of strings",
+			Command:  "SELECT stddev(first) FROM names",
+			ExpErr:   "Error: STDDEV can only be used with numeric values not STRING",
+		},
+		{
+			TestName: "Select STRING_AGG missing separator",
+			Command:  "SELECT string_agg(first) FROM names",
+			ExpErr:   "Syntax Error: Function STRING_AGG is missing a separator",
+		},
+		{
+			TestName: "Select STRING_AGG column separator",
+			Command:  "SELECT string_agg(first, last) FROM names",
+			ExpErr:   "Syntax Error: The separator for STRING_AGG must be a constant",
+		},
+		{
+			TestName: "Select PERCENTILE_CONT invalid fraction",
+			Command:  "SELECT percentile_cont(1.5) WITHIN GROUP (ORDER BY age) FROM names",
+			ExpErr:   "Syntax Error: The fraction for PERCENTILE_CONT must be a number between 0 and 1",
+		},
+		{
+			TestName: "Select PERCENTILE_CONT missing WITHIN GROUP",
+			Command:  "SELECT percentile_cont(0.5) FROM names",
+			ExpErr:   "Syntax Error: Expecting WITHIN GROUP (ORDER BY after PERCENTILE_CONT",
+		},
+		{
+			TestName: "Select PERCENTILE_DISC two ORDER BY",
+			Command:  "SELECT percentile_disc(0.5) WITHIN GROUP (ORDER BY age, first) FROM names",
+			ExpErr:   "Syntax Error: WITHIN GROUP of PERCENTILE_DISC must have one ORDER BY expression",
+		},
+		{
+			TestName: "Select PERCENTILE_CONT of strings",
+			Command:  "SELECT percentile_cont(0.5) WITHIN GROUP (ORDER BY first) FROM names",
+			ExpErr:   "Error: PERCENTILE_CONT can only be used with numeric values not STRING",
+		},
+		{
+			TestName: "Select STRING_AGG with OVER",
+			Command:  "SELECT string_agg(first, \",\") OVER () FROM names",
+			ExpErr:   "Syntax Error: STRING_AGG can not be used as a window function",
+		},
+		{
+			TestName: "Select GROUP BY ROLLUP",
+			Command:  "SELECT first, last, count(), grouping(first), grouping(last) FROM names GROUP BY ROLLUP(first, last) ORDER BY first, last",
+			ExpRows:  9,
+			ExpCols:  []string{"first", "last", "COUNT()", "GROUPING(first)", "GROUPING(last)"},
+			ExpVals: sqtypes.RawVals{
+				{"Fred", "Hammer", 1, 0, 0},
+				{"Fred", "Johnson", 1, 0, 0},
+				{"Fred", nil, 2, 0, 1},
+				{"Joe", "Biden", 1, 0, 0},
+				{"Joe", nil, 1, 0, 1},
+				{"Sue", "Brown", 1, 0, 0},
+				{"Sue", "Johnson", 1, 0, 0},
+				{"Sue", nil, 2, 0, 1},
+				{nil, nil, 5, 1, 1},
+			},
+		},
+		{
+			TestName: "Select GROUP BY CUBE",
+			Command:  "SELECT last, sum(age), grouping(last) FROM names WHERE first = \"Sue\" GROUP BY CUBE(last) ORDER BY last",
+			ExpRows:  3,
+			ExpCols:  []string{"last", "SUM(age)", "GROUPING(last)"},
+			ExpVals:  sqtypes.RawVals{{"Brown", 21, 0}, {"Johnson", 20, 0}, {nil, 41, 1}},
+		},
+		{
+			TestName: "Select GROUP BY GROUPING SETS",
+			Command:  "SELECT first, last, count() FROM names GROUP BY GROUPING SETS ((first), (last)) ORDER BY first, last",
+			ExpRows:  7,
+			ExpCols:  []string{"first", "last", "COUNT()"},
+			ExpVals: sqtypes.RawVals{
+				{"Fred", nil, 2},
+				{"Joe", nil, 1},
+				{"Sue", nil, 2},
+				{nil, "Biden", 1},
+				{nil, "Brown", 1},
+				{nil, "Hammer", 1},
+				{nil, "Johnson", 2},
+			},
+		},
+		{
+			TestName: "Select GROUP BY ROLLUP HAVING GROUPING",
+			Command:  "SELECT first, max(age) FROM names GROUP BY ROLLUP(first) HAVING grouping(first) = 1",
+			ExpRows:  1,
+			ExpCols:  []string{"first", "MAX(age)"},
+			ExpVals:  sqtypes.RawVals{{nil, 78}},
+		},
+		{
+			TestName: "Select GROUPING not in GROUP BY",
+			Command:  "SELECT first, grouping(last) FROM names GROUP BY first",
+			ExpErr:   "Syntax Error: last is not in the group by clause for GROUPING(last)",
+		},
+		{
+			TestName: "Select GROUPING without GROUP BY",
+			Command:  "SELECT grouping(first) FROM names",
+			ExpErr:   "Syntax Error: first is not in the group by clause for GROUPING(first)",
+		},
 		/* - This is an issue but deferred
 		{
 			TestName: "Select Multitable complex aggregate expression",
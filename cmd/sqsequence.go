@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/wilphi/sqsrv/redo"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// CreateSequenceStmt -
+type CreateSequenceStmt struct {
+	SeqName   string
+	Start     int
+	Increment int
+}
+
+// CreateSequence creates a named sequence that gives out a new value each time NEXTVAL is called
+//	  This function will always return a nil dataset
+func CreateSequence(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *sqtables.DataSet, error) {
+
+	if !trans.Auto() {
+		return "", nil, sqerr.New("DDL statements cannot be executed within a transaction")
+	}
+
+	stmt, err := ParseCreateSequence(tkns)
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = createSequence(trans, stmt.SeqName, stmt.Start, stmt.Increment, "")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return stmt.SeqName, nil, nil
+}
+
+// ParseCreateSequence - parses the tokens of a CREATE SEQUENCE name [START [WITH] n] [INCREMENT [BY] n] statement
+func ParseCreateSequence(tkns *tokens.TokenList) (*CreateSequenceStmt, error) {
+	var err error
+	stmt := CreateSequenceStmt{Start: 1, Increment: 1}
+
+	log.Debug("CREATE SEQUENCE command")
+	tkns.IsARemove(tokens.Create)
+	tkns.IsARemove(tokens.Sequence)
+
+	// make sure the next token is an Ident
+	if tkn := tkns.TestTkn(tokens.Ident); tkn != nil {
+		stmt.SeqName = strings.ToLower(tkn.(*tokens.ValueToken).Value())
+		tkns.Remove()
+	} else {
+		return nil, sqerr.NewSyntax("Expecting name of sequence to create")
+	}
+
+	err = sequenceOptions(tkns, &stmt)
+	if err != nil {
+		return nil, err
+	}
+	if !tkns.IsEmpty() {
+		return nil, sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+	}
+
+	return &stmt, nil
+}
+
+// sequenceOptions processes the optional START [WITH] n and INCREMENT [BY] n of a sequence
+func sequenceOptions(tkns *tokens.TokenList, stmt *CreateSequenceStmt) error {
+	var err error
+
+	hasStart, hasIncrement := false, false
+	for {
+		switch {
+		case tkns.IsAKeywordRemove("START"):
+			if hasStart {
+				return sqerr.NewSyntax("START has already been set for the sequence")
+			}
+			hasStart = true
+			tkns.IsARemove(tokens.With)
+			stmt.Start, err = sequenceNum(tkns, "START")
+		case tkns.IsAKeywordRemove("INCREMENT"):
+			if hasIncrement {
+				return sqerr.NewSyntax("INCREMENT has already been set for the sequence")
+			}
+			hasIncrement = true
+			tkns.IsARemove(tokens.By)
+			stmt.Increment, err = sequenceNum(tkns, "INCREMENT")
+			if err == nil && stmt.Increment == 0 {
+				err = sqerr.NewSyntax("INCREMENT can not be zero")
+			}
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sequenceNum removes and returns the optionally signed integer that follows START or INCREMENT
+func sequenceNum(tkns *tokens.TokenList, clause string) (int, error) {
+	sign := ""
+	if tkns.IsARemove(tokens.Minus) {
+		sign = "-"
+	}
+	tkn := tkns.TestTkn(tokens.Num)
+	if tkn == nil {
+		return 0, sqerr.NewSyntaxf("%s must be followed by an integer", clause)
+	}
+	n, err := strconv.Atoi(sign + tkn.(*tokens.ValueToken).Value())
+	if err != nil {
+		return 0, sqerr.NewSyntaxf("%s must be followed by an integer", clause)
+	}
+	tkns.Remove()
+	return n, nil
+}
+
+// createSequence adds a sequence to the catalog and records it in the transaction log
+func createSequence(trans sqtables.Transaction, name string, start, increment int, owner string) error {
+	err := sqtables.CreateSequence(trans.Profile(), sqtables.CreateSequenceDef(name, start, increment, owner))
+	if err != nil {
+		return err
+	}
+	return redo.Send(redo.NewCreateSequence(name, start, increment, owner))
+}
+
+// DropSequence removes a sequence from the database.
+//	  This function will always return a nil dataset
+func DropSequence(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *sqtables.DataSet, error) {
+	var seqName string
+
+	if !trans.Auto() {
+		return "", nil, sqerr.New("DDL statements cannot be executed within a transaction")
+	}
+
+	log.Debug("DROP SEQUENCE command")
+
+	// Eat the DROP SEQUENCE tokens if they are there
+	tkns.IsARemove(tokens.Drop)
+	tkns.IsARemove(tokens.Sequence)
+
+	// make sure the next token is an Ident
+	if tkn := tkns.TestTkn(tokens.Ident); tkn != nil {
+		seqName = strings.ToLower(tkn.(*tokens.ValueToken).Value())
+		tkns.Remove()
+	} else {
+		return "", nil, sqerr.NewSyntax("Expecting name of sequence to Drop")
+	}
+
+	if !tkns.IsEmpty() {
+		return "", nil, sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+	}
+
+	seq, err := sqtables.GetSequence(trans.Profile(), seqName)
+	if err != nil {
+		return "", nil, err
+	}
+	if seq != nil && seq.GetOwner() != "" {
+		return "", nil, sqerr.Newf("Sequence %s is used by table %s and can not be dropped", seqName, seq.GetOwner())
+	}
+
+	err = dropSequence(trans, seqName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return seqName, nil, nil
+}
+
+// dropSequence removes a sequence from the catalog and records it in the transaction log
+func dropSequence(trans sqtables.Transaction, name string) error {
+	err := sqtables.DropSequence(trans.Profile(), name)
+	if err != nil {
+		return err
+	}
+	return redo.Send(redo.NewDropSequence(name))
+}
+
+// sequenceFunc processes the rest of NEXTVAL, CURRVAL or SETVAL after the ( name [, value [, is_called]])
+func sequenceFunc(tkns *tokens.TokenList, ftkn tokens.Token) (sqtables.Expr, error) {
+	cmd := ftkn.ID()
+	name, err := constArg(tkns, cmd, "sequence name", tokens.Comma, tokens.CloseBracket)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := name.(sqtypes.SQString); !ok {
+		return nil, sqerr.NewSyntaxf("The sequence name for %s must be a string", ftkn.Name())
+	}
+
+	var args []sqtables.Expr
+	if cmd == tokens.SetVal {
+		for tkns.IsARemove(tokens.Comma) {
+			if len(args) == 2 {
+				return nil, sqerr.NewSyntaxf("Function %s has too many arguments", ftkn.Name())
+			}
+			exp, err := GetExpr(tkns, nil, 0, tokens.Comma, tokens.CloseBracket)
+			if err != nil {
+				return nil, err
+			}
+			if exp == nil {
+				return nil, sqerr.NewSyntaxf("Function %s is missing an expression after ,", ftkn.Name())
+			}
+			args = append(args, exp)
+		}
+		if len(args) == 0 {
+			return nil, sqerr.NewSyntax("Function SETVAL is missing , after the sequence name")
+		}
+	}
+	if !tkns.IsARemove(tokens.CloseBracket) {
+		return nil, sqerr.NewSyntaxf("Function %s is missing ) after the sequence name", ftkn.Name())
+	}
+	return sqtables.NewSequenceFuncExpr(cmd, name.String(), args...), nil
+}
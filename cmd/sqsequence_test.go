@@ -0,0 +1,375 @@
+package cmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/cmd"
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtypes"
+)
+
+func TestSequences(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	// Make sure datasets are by default in RowID order
+	sqtables.RowOrder = true
+
+	err := sq.ProcessSQFile("./testdata/sequencetests.sq")
+	if err != nil {
+		t.Fatalf("Unable to load test data: %s", err)
+	}
+
+	data := []ViewData{
+		{
+			TestName: "Create Sequence",
+			Command:  "CREATE SEQUENCE seqa",
+			Exec:     cmd.CreateSequence,
+			ExpMsg:   "seqa",
+		},
+		{
+			TestName: "Create Sequence with options",
+			Command:  "CREATE SEQUENCE SeqB START WITH 100 INCREMENT BY -10",
+			Exec:     cmd.CreateSequence,
+			ExpMsg:   "seqb",
+		},
+		{
+			TestName: "Duplicate Sequence",
+			Command:  "CREATE SEQUENCE seqa START 5",
+			Exec:     cmd.CreateSequence,
+			ExpErr:   "Error: Invalid Name: Sequence seqa already exists",
+		},
+		{
+			TestName: "Create Sequence START without value",
+			Command:  "CREATE SEQUENCE seqz START",
+			Exec:     cmd.CreateSequence,
+			ExpErr:   "Syntax Error: START must be followed by an integer",
+		},
+		{
+			TestName: "Create Sequence zero increment",
+			Command:  "CREATE SEQUENCE seqz INCREMENT BY 0",
+			Exec:     cmd.CreateSequence,
+			ExpErr:   "Syntax Error: INCREMENT can not be zero",
+		},
+		{
+			TestName: "Create Sequence START twice",
+			Command:  "CREATE SEQUENCE seqz START 1 START 2",
+			Exec:     cmd.CreateSequence,
+			ExpErr:   "Syntax Error: START has already been set for the sequence",
+		},
+		{
+			TestName: "Create Sequence extra tokens",
+			Command:  "CREATE SEQUENCE seqz MAXVALUE 10",
+			Exec:     cmd.CreateSequence,
+			ExpErr:   "Syntax Error: Unexpected tokens after SQL command:[IDENT=MAXVALUE] [NUM=10]",
+		},
+		{
+			TestName:    "Create Sequence in Transaction",
+			Command:     "CREATE SEQUENCE seqz",
+			Exec:        cmd.CreateSequence,
+			ExpErr:      "Error: DDL statements cannot be executed within a transaction",
+			ManualTrans: true,
+		},
+		{
+			TestName: "CURRVAL before NEXTVAL",
+			Command:  "SELECT CURRVAL(\"seqa\") FROM seqone",
+			Exec:     cmd.Select,
+			ExpErr:   "Error: CURRVAL of sequence seqa is not yet defined",
+		},
+		{
+			TestName: "NEXTVAL first value",
+			Command:  "SELECT NEXTVAL(\"seqa\") FROM seqone",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"NEXTVAL(seqa)"},
+			ExpVals:  sqtypes.RawVals{{1}},
+		},
+		{
+			TestName: "NEXTVAL second value",
+			Command:  "SELECT NEXTVAL(\"SEQA\") FROM seqone",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"NEXTVAL(seqa)"},
+			ExpVals:  sqtypes.RawVals{{2}},
+		},
+		{
+			TestName: "CURRVAL",
+			Command:  "SELECT CURRVAL(\"seqa\") AS cur FROM seqone",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"cur"},
+			ExpVals:  sqtypes.RawVals{{2}},
+		},
+		{
+			TestName: "NEXTVAL with negative increment",
+			Command:  "SELECT NEXTVAL(\"seqb\"), CURRVAL(\"seqb\") FROM seqone",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"NEXTVAL(seqb)", "CURRVAL(seqb)"},
+			ExpVals:  sqtypes.RawVals{{100, 100}},
+		},
+		{
+			TestName: "NEXTVAL with negative increment again",
+			Command:  "SELECT NEXTVAL(\"seqb\") FROM seqone",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"NEXTVAL(seqb)"},
+			ExpVals:  sqtypes.RawVals{{90}},
+		},
+		{
+			TestName: "SETVAL",
+			Command:  "SELECT SETVAL(\"seqa\", 10) FROM seqone",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"SETVAL(seqa, 10)"},
+			ExpVals:  sqtypes.RawVals{{10}},
+		},
+		{
+			TestName: "NEXTVAL after SETVAL",
+			Command:  "SELECT NEXTVAL(\"seqa\") FROM seqone",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"NEXTVAL(seqa)"},
+			ExpVals:  sqtypes.RawVals{{11}},
+		},
+		{
+			TestName: "SETVAL not called",
+			Command:  "SELECT SETVAL(\"seqa\", 20, false) FROM seqone",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"SETVAL(seqa, 20, false)"},
+			ExpVals:  sqtypes.RawVals{{20}},
+		},
+		{
+			TestName: "NEXTVAL after SETVAL not called",
+			Command:  "SELECT NEXTVAL(\"seqa\") FROM seqone",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"NEXTVAL(seqa)"},
+			ExpVals:  sqtypes.RawVals{{20}},
+		},
+		{
+			TestName: "SETVAL with a string",
+			Command:  "SELECT SETVAL(\"seqa\", \"x\") FROM seqone",
+			Exec:     cmd.Select,
+			ExpErr:   "Error: Type Mismatch: The value for SETVAL must be an INT not x",
+		},
+		{
+			TestName: "SETVAL missing value",
+			Command:  "SELECT SETVAL(\"seqa\") FROM seqone",
+			Exec:     cmd.Select,
+			ExpErr:   "Syntax Error: Function SETVAL is missing , after the sequence name",
+		},
+		{
+			TestName: "SETVAL too many args",
+			Command:  "SELECT SETVAL(\"seqa\", 1, true, 2) FROM seqone",
+			Exec:     cmd.Select,
+			ExpErr:   "Syntax Error: Function SETVAL has too many arguments",
+		},
+		{
+			TestName: "NEXTVAL with extra arg",
+			Command:  "SELECT NEXTVAL(\"seqa\", 1) FROM seqone",
+			Exec:     cmd.Select,
+			ExpErr:   "Syntax Error: Function NEXTVAL is missing ) after the sequence name",
+		},
+		{
+			TestName: "NEXTVAL of column",
+			Command:  "SELECT NEXTVAL(id) FROM seqone",
+			Exec:     cmd.Select,
+			ExpErr:   "Syntax Error: The sequence name for NEXTVAL must be a constant",
+		},
+		{
+			TestName: "NEXTVAL of number",
+			Command:  "SELECT NEXTVAL(1) FROM seqone",
+			Exec:     cmd.Select,
+			ExpErr:   "Syntax Error: The sequence name for NEXTVAL must be a string",
+		},
+		{
+			TestName: "NEXTVAL invalid sequence",
+			Command:  "SELECT NEXTVAL(\"nosuch\") FROM seqone",
+			Exec:     cmd.Select,
+			ExpErr:   "Error: Sequence nosuch does not exist",
+		},
+		{
+			TestName: "Insert NEXTVAL",
+			Command:  "INSERT INTO seqone (id) VALUES (NEXTVAL(\"seqa\")), (NEXTVAL(\"seqa\"))",
+			Exec:     cmd.InsertInto,
+			ExpMsg:   "2 rows inserted into seqone",
+		},
+		{
+			TestName: "Select inserted NEXTVAL",
+			Command:  "SELECT id FROM seqone",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"id"},
+			ExpVals:  sqtypes.RawVals{{1}, {21}, {22}},
+		},
+		{
+			TestName: "Insert SERIAL",
+			Command:  "INSERT INTO seqorders (item) VALUES (\"a\"), (\"b\")",
+			Exec:     cmd.InsertInto,
+			ExpMsg:   "2 rows inserted into seqorders",
+		},
+		{
+			TestName: "Insert SERIAL with value",
+			Command:  "INSERT INTO seqorders (id, item) VALUES (10, \"c\")",
+			Exec:     cmd.InsertInto,
+			ExpMsg:   "1 rows inserted into seqorders",
+		},
+		{
+			TestName: "Insert SERIAL with DEFAULT",
+			Command:  "INSERT INTO seqorders VALUES (DEFAULT, \"d\")",
+			Exec:     cmd.InsertInto,
+			ExpMsg:   "1 rows inserted into seqorders",
+		},
+		{
+			TestName: "Select SERIAL",
+			Command:  "SELECT id, item FROM seqorders",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"id", "item"},
+			ExpVals:  sqtypes.RawVals{{1, "a"}, {2, "b"}, {10, "c"}, {3, "d"}},
+		},
+		{
+			TestName: "Insert GENERATED ALWAYS",
+			Command:  "INSERT INTO seqgen (name) VALUES (\"x\"), (\"y\")",
+			Exec:     cmd.InsertInto,
+			ExpMsg:   "2 rows inserted into seqgen",
+		},
+		{
+			TestName: "Insert GENERATED ALWAYS with DEFAULT",
+			Command:  "INSERT INTO seqgen VALUES (DEFAULT, \"z\")",
+			Exec:     cmd.InsertInto,
+			ExpMsg:   "1 rows inserted into seqgen",
+		},
+		{
+			TestName: "Insert GENERATED ALWAYS with value",
+			Command:  "INSERT INTO seqgen (id, name) VALUES (1, \"w\")",
+			Exec:     cmd.InsertInto,
+			ExpErr:   "Error: Column id is GENERATED ALWAYS and can not be given a value",
+		},
+		{
+			TestName: "Update GENERATED ALWAYS",
+			Command:  "UPDATE seqgen SET id = 1",
+			Exec:     cmd.Update,
+			ExpErr:   "Error: Column id is GENERATED ALWAYS and can not be updated",
+		},
+		{
+			TestName: "Select GENERATED ALWAYS",
+			Command:  "SELECT id, name FROM seqgen",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"id", "name"},
+			ExpVals:  sqtypes.RawVals{{5, "x"}, {10, "y"}, {15, "z"}},
+		},
+		{
+			TestName: "Create Table BY DEFAULT AS IDENTITY",
+			Command:  "CREATE TABLE seqbydef (id int not null GENERATED BY DEFAULT AS IDENTITY, name string)",
+			Exec:     cmd.CreateTable,
+			ExpMsg:   "seqbydef",
+		},
+		{
+			TestName: "Create Table SERIAL",
+			Command:  "CREATE TABLE seqdup (id serial)",
+			Exec:     cmd.CreateTable,
+			ExpMsg:   "seqdup",
+		},
+		{
+			TestName: "Create Table identity sequence exists",
+			Command:  "CREATE TABLE SeqDup (id serial)",
+			Exec:     cmd.CreateTable,
+			ExpErr:   "Error: Invalid Name: Sequence seqdup_id_seq already exists",
+		},
+		{
+			TestName: "Create Table identity not an INT",
+			Command:  "CREATE TABLE seqbad (id string GENERATED ALWAYS AS IDENTITY)",
+			Exec:     cmd.CreateTable,
+			ExpErr:   "Syntax Error: Column id must be an INT to be an IDENTITY",
+		},
+		{
+			TestName: "Create Table identity with DEFAULT",
+			Command:  "CREATE TABLE seqbad (id serial DEFAULT 1)",
+			Exec:     cmd.CreateTable,
+			ExpErr:   "Syntax Error: Column id can not have both a DEFAULT and be an IDENTITY",
+		},
+		{
+			TestName: "Create Table DEFAULT with identity",
+			Command:  "CREATE TABLE seqbad (id int DEFAULT 1 GENERATED ALWAYS AS IDENTITY)",
+			Exec:     cmd.CreateTable,
+			ExpErr:   "Syntax Error: Column id can not have both a DEFAULT and be an IDENTITY",
+		},
+		{
+			TestName: "Create Table identity twice",
+			Command:  "CREATE TABLE seqbad (id serial GENERATED ALWAYS AS IDENTITY)",
+			Exec:     cmd.CreateTable,
+			ExpErr:   "Syntax Error: Column id is already an IDENTITY",
+		},
+		{
+			TestName: "Create Table identity NULL",
+			Command:  "CREATE TABLE seqbad (id serial NULL)",
+			Exec:     cmd.CreateTable,
+			ExpErr:   "Syntax Error: Column id is an IDENTITY and can not be NULL",
+		},
+		{
+			TestName: "Create Table GENERATED missing ALWAYS",
+			Command:  "CREATE TABLE seqbad (id int GENERATED AS IDENTITY)",
+			Exec:     cmd.CreateTable,
+			ExpErr:   "Syntax Error: Expecting ALWAYS or BY DEFAULT after GENERATED for column id",
+		},
+		{
+			TestName: "Create Table GENERATED missing IDENTITY",
+			Command:  "CREATE TABLE seqbad (id int GENERATED ALWAYS AS)",
+			Exec:     cmd.CreateTable,
+			ExpErr:   "Syntax Error: Expecting AS IDENTITY after GENERATED for column id",
+		},
+		{
+			TestName: "Create Table IDENTITY options missing )",
+			Command:  "CREATE TABLE seqbad (id int GENERATED ALWAYS AS IDENTITY (START 2, name string)",
+			Exec:     cmd.CreateTable,
+			ExpErr:   "Syntax Error: Expecting ) after the IDENTITY options for column id",
+		},
+		{
+			TestName: "Drop Sequence owned by Table",
+			Command:  "DROP SEQUENCE seqorders_id_seq",
+			Exec:     cmd.DropSequence,
+			ExpErr:   "Error: Sequence seqorders_id_seq is used by table seqorders and can not be dropped",
+		},
+		{
+			TestName: "Drop Table with identity",
+			Command:  "DROP TABLE seqgen",
+			Exec:     cmd.DropTable,
+			ExpMsg:   "seqgen",
+		},
+		{
+			TestName: "Sequence dropped with Table",
+			Command:  "SELECT NEXTVAL(\"seqgen_id_seq\") FROM seqone",
+			Exec:     cmd.Select,
+			ExpErr:   "Error: Sequence seqgen_id_seq does not exist",
+		},
+		{
+			TestName:    "Drop Sequence in Transaction",
+			Command:     "DROP SEQUENCE seqb",
+			Exec:        cmd.DropSequence,
+			ExpErr:      "Error: DDL statements cannot be executed within a transaction",
+			ManualTrans: true,
+		},
+		{
+			TestName: "Drop Sequence",
+			Command:  "DROP SEQUENCE seqb",
+			Exec:     cmd.DropSequence,
+			ExpMsg:   "seqb",
+		},
+		{
+			TestName: "Drop Sequence again",
+			Command:  "DROP SEQUENCE seqb",
+			Exec:     cmd.DropSequence,
+			ExpErr:   "Error: Invalid Name: Sequence seqb does not exist",
+		},
+		{
+			TestName: "Drop Sequence no name",
+			Command:  "DROP SEQUENCE",
+			Exec:     cmd.DropSequence,
+			ExpErr:   "Syntax Error: Expecting name of sequence to Drop",
+		},
+		{
+			TestName: "Drop Sequence extra tokens",
+			Command:  "DROP SEQUENCE seqa seqb",
+			Exec:     cmd.DropSequence,
+			ExpErr:   "Syntax Error: Unexpected tokens after SQL command:[IDENT=seqb]",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testViewFunc(profile, row))
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/wilphi/sqsrv/redo"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// TruncateTable removes all rows from a table. Unlike DELETE the rows are not deleted one at a time,
+//	  the table is emptied as a single change. This function will always return a nil dataset
+func TruncateTable(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *sqtables.DataSet, error) {
+	var tableName string
+
+	if !trans.Auto() {
+		return "", nil, sqerr.New("DDL statements cannot be executed within a transaction")
+	}
+
+	log.Debug("TRUNCATE TABLE command")
+
+	// Eat the TRUNCATE TABLE tokens if they are there
+	tkns.IsARemove(tokens.Truncate)
+	tkns.IsARemove(tokens.Table)
+
+	// make sure the next token is an Ident
+	if tkn := tkns.TestTkn(tokens.Ident); tkn != nil {
+		tableName = strings.ToLower(tkn.(*tokens.ValueToken).Value())
+		tkns.Remove()
+	} else {
+		return "", nil, sqerr.NewSyntax("Expecting name of table to Truncate")
+	}
+
+	if !tkns.IsEmpty() {
+		return "", nil, sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+	}
+
+	err := sqtables.TruncateTable(trans.Profile(), tableName)
+	if err != nil {
+		return "", nil, err
+	}
+	err = redo.Send(redo.NewTruncateTable(tableName))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return tableName, nil, nil
+}
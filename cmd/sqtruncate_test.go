@@ -0,0 +1,161 @@
+package cmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/cmd"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+type TruncateData struct {
+	TestName    string
+	Command     string
+	ExpErr      string
+	TableName   string
+	ManualTrans bool
+}
+
+func testTruncateFunc(profile *sqprofile.SQProfile, d TruncateData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+		tkns := tokens.Tokenize(d.Command)
+		trans := sqtables.BeginTrans(profile, !d.ManualTrans)
+		_, data, err := cmd.TruncateTable(trans, tkns)
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+		if data != nil {
+			t.Error("Truncate Table function should always return nil data")
+			return
+		}
+		tab, err := sqtables.GetTable(profile, d.TableName)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if tab == nil {
+			t.Errorf("Truncate table removed the table %s", d.TableName)
+			return
+		}
+		cnt, err := tab.RawCount(profile)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if cnt != 0 {
+			t.Errorf("Truncate table did not remove all rows from %s, %d rows left", d.TableName, cnt)
+			return
+		}
+
+		// Make sure that the table can still be used
+		trans = sqtables.BeginTrans(profile, true)
+		_, _, err = cmd.InsertInto(trans, tokens.Tokenize(fmt.Sprintf("INSERT INTO %s (col1, col2) VALUES (1, \"after\")", d.TableName)))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		cnt, err = tab.RowCount(profile)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if cnt != 1 {
+			t.Errorf("Table %s has %d rows after insert, expected 1", d.TableName, cnt)
+		}
+	}
+}
+
+func TestTruncateTable(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+
+	//make sure table exists for testing
+	tkns := tokens.Tokenize("CREATE TABLE truncatetest (col1 int, col2 string)")
+	trans := sqtables.BeginTrans(profile, true)
+	_, _, err := cmd.CreateTable(trans, tkns)
+	if err != nil {
+		t.Errorf("Error setting up table for %s: %s", t.Name(), err)
+		return
+	}
+
+	tkns = tokens.Tokenize("CREATE VIEW truncateview AS SELECT col1 FROM truncatetest")
+	trans = sqtables.BeginTrans(profile, true)
+	_, _, err = cmd.CreateView(trans, tkns)
+	if err != nil {
+		t.Errorf("Error setting up view for %s: %s", t.Name(), err)
+		return
+	}
+
+	testData := "INSERT INTO truncatetest (col1, col2) VALUES " +
+		fmt.Sprintf("(%d, %q),", 123, "Truncate 1") +
+		fmt.Sprintf("(%d, %q),", 456, "Truncate 2") +
+		fmt.Sprintf("(%d, %q)", 789, "Truncate 3")
+
+	tkns = tokens.Tokenize(testData)
+	trans = sqtables.BeginTrans(profile, true)
+	if _, _, err := cmd.InsertInto(trans, tkns); err != nil {
+		t.Errorf("Unexpected Error setting up test for %s: %s", t.Name(), err.Error())
+		return
+	}
+
+	data := []TruncateData{
+		{
+			TestName:  "Truncate only",
+			Command:   "TRUNCATE",
+			TableName: "",
+			ExpErr:    "Syntax Error: Expecting name of table to Truncate",
+		},
+		{
+			TestName:  "Truncate invalid table",
+			Command:   "TRUNCATE TABLE NotATable",
+			TableName: "NotATable",
+			ExpErr:    "Error: Invalid Name: Table notatable does not exist",
+		},
+		{
+			TestName:  "Truncate a view",
+			Command:   "TRUNCATE TABLE truncateview",
+			TableName: "truncateview",
+			ExpErr:    "Error: Invalid Name: truncateview is a view and can not be truncated",
+		},
+		{
+			TestName:  "Truncate system table",
+			Command:   "TRUNCATE _truncatetest",
+			TableName: "_truncatetest",
+			ExpErr:    "Error: Invalid Name: _truncatetest - Unable to truncate system tables",
+		},
+		{
+			TestName:    "Truncate with manual transaction",
+			Command:     "TRUNCATE TABLE truncatetest",
+			TableName:   "truncatetest",
+			ExpErr:      "Error: DDL statements cannot be executed within a transaction",
+			ManualTrans: true,
+		},
+		{
+			TestName:  "Truncate with extra stuff",
+			Command:   "TRUNCATE TABLE truncatetest extra stuff",
+			TableName: "truncatetest",
+			ExpErr:    "Syntax Error: Unexpected tokens after SQL command:[IDENT=extra] [IDENT=stuff]",
+		},
+		{
+			TestName:  "Truncate Table with Data Rows",
+			Command:   "TRUNCATE TABLE truncatetest",
+			TableName: "truncatetest",
+			ExpErr:    "",
+		},
+		{
+			TestName:  "Truncate without TABLE",
+			Command:   "TRUNCATE truncatetest",
+			TableName: "truncatetest",
+			ExpErr:    "",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testTruncateFunc(profile, row))
+
+	}
+}
@@ -5,17 +5,24 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqptr"
 	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtables/moniker"
 	"github.com/wilphi/sqsrv/tokens"
 )
 
-//UpdateStmt contains the info required to execute an UPDATE statement
+//UpdateStmt contains the info required to execute an UPDATE statement. Join is the join of the table to
+//   the tables of the FROM clause if there is one
 type UpdateStmt struct {
 	TableName string
+	Alias     string
 	Table     *sqtables.TableDef
 	SetCols   []string
 	SetExprs  sqtables.ExprList
 	WhereExpr sqtables.Expr
+	Join      *TargetJoin
+	Returning *sqtables.Returning
 }
 
 // Update implements the SQL command UPDATE
@@ -25,15 +32,17 @@ func Update(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *sqtabl
 		return "", nil, err
 	}
 	msg, err := executeUpdate(trans, stmt)
-	return msg, nil, err
+	if err != nil || stmt.Returning == nil {
+		return msg, nil, err
+	}
+	data, err := stmt.Returning.GetData(trans.Profile())
+	return msg, data, err
 }
 
 func parseUpdate(trans sqtables.Transaction, tkns *tokens.TokenList) (*UpdateStmt, error) {
 	var err error
 	var stmt UpdateStmt
 
-	colCheck := make(map[string]bool)
-
 	log.Debug("Update statement...")
 
 	// Eat Update Token
@@ -47,6 +56,7 @@ func parseUpdate(trans sqtables.Transaction, tkns *tokens.TokenList) (*UpdateStm
 	stmt.TableName = tkn.(*tokens.ValueToken).Value()
 
 	tkns.Remove()
+	stmt.Alias = parseTargetAlias(tkns)
 	stmt.Table, err = sqtables.GetTable(trans.Profile(), stmt.TableName)
 	if err != nil {
 		return nil, err
@@ -61,6 +71,77 @@ func parseUpdate(trans sqtables.Transaction, tkns *tokens.TokenList) (*UpdateStm
 		return nil, sqerr.NewSyntax("Expecting SET")
 	}
 
+	stmt.SetCols, stmt.SetExprs, err = parseSetList(trans.Profile(), tkns, stmt.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional From Clause
+	var fromTables sqtables.TableList
+	var fromJoins []sqtables.JoinInfo
+	hasFrom := tkns.IsARemove(tokens.From)
+	if hasFrom {
+		fromTables, fromJoins, err = ParseFromClause(trans.Profile(), tkns, tokens.Where, tokens.Returning)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Optional Where Clause
+	if tkns.Len() > 0 && tkns.IsA(tokens.Where) {
+		tkns.Remove()
+		stmt.WhereExpr, err = ParseWhereClause(tkns, hasFrom)
+		if err != nil {
+			return nil, err
+		}
+		if !hasFrom {
+			err = stmt.WhereExpr.ValidateCols(trans.Profile(), stmt.tables(trans.Profile()))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if hasFrom {
+		stmt.Join, err = NewTargetJoin(trans.Profile(), stmt.target(), fromTables, fromJoins, stmt.WhereExpr, "FROM")
+		if err != nil {
+			return nil, err
+		}
+		err = stmt.SetExprs.ValidateCols(trans.Profile(), stmt.Join.Tables())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if tkns.IsARemove(tokens.Returning) {
+		stmt.Returning, err = parseReturning(trans.Profile(), tkns, stmt.Table)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !tkns.IsEmpty() {
+		return nil, sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+	}
+
+	return &stmt, nil
+}
+
+// target returns the reference to the table that is updated including its alias
+func (stmt *UpdateStmt) target() sqtables.TableRef {
+	return sqtables.TableRef{Name: moniker.New(stmt.TableName, stmt.Alias), Table: stmt.Table}
+}
+
+// tables returns a TableList with the table that is updated
+func (stmt *UpdateStmt) tables(profile *sqprofile.SQProfile) sqtables.TableList {
+	return sqtables.NewTableList(profile, []sqtables.TableRef{stmt.target()})
+}
+
+// parseSetList parses the col = expression list of a SET clause. The list ends at the first token that
+//   is not part of the list such as WHERE or FROM or at the end of the tokens
+func parseSetList(profile *sqprofile.SQProfile, tkns *tokens.TokenList, tab *sqtables.TableDef) (cols []string, exprs sqtables.ExprList, err error) {
+	colCheck := make(map[string]bool)
+
 	isValidSetExpression := false
 	// col = value
 	for {
@@ -71,70 +152,120 @@ func parseUpdate(trans sqtables.Transaction, tkns *tokens.TokenList) (*UpdateStm
 		// Identifier first
 		if tkn := tkns.TestTkn(tokens.Ident); tkn != nil {
 			colName := tkn.(*tokens.ValueToken).Value()
-			cd := stmt.Table.FindColDef(trans.Profile(), colName)
+			cd := tab.FindColDef(profile, colName)
 			if cd == nil {
-				return nil, sqerr.NewSyntaxf("Invalid Column name: %s does not exist in Table %s", colName, stmt.TableName)
+				return nil, exprs, sqerr.NewSyntaxf("Invalid Column name: %s does not exist in Table %s", colName, tab.GetName(profile))
+			}
+			if cd.IsGenerated {
+				return nil, exprs, sqerr.Newf("Column %s is GENERATED ALWAYS and can not be updated", colName)
 			}
 			tkns.Remove()
 			// Then an EQUAL sign
 			if !tkns.IsA(tokens.Equal) {
-				return nil, sqerr.NewSyntaxf("Expecting = after column name %s in UPDATE SET", colName)
+				return nil, exprs, sqerr.NewSyntaxf("Expecting = after column name %s in UPDATE SET", colName)
 			}
 			tkns.Remove()
 
 			// Get a value/expression
 			ex, err := GetExpr(tkns, nil, 0, tokens.Where, tokens.Comma)
 			if err != nil {
-				return nil, err
+				return nil, exprs, err
 			}
 			if ex == nil {
-				return nil, sqerr.NewSyntaxf("Expecting an expression in SET clause after %s =", colName)
+				return nil, exprs, sqerr.NewSyntaxf("Expecting an expression in SET clause after %s =", colName)
 			}
 			if _, ok := colCheck[colName]; ok {
-				return nil, sqerr.NewSyntaxf("%s is set more than once", colName)
+				return nil, exprs, sqerr.NewSyntaxf("%s is set more than once", colName)
 			}
 			colCheck[colName] = true
-			stmt.SetCols = append(stmt.SetCols, colName)
-			stmt.SetExprs.Add(ex)
+			cols = append(cols, colName)
+			exprs.Add(sqtables.LiteralForType(ex, cd.ColType))
 			isValidSetExpression = true
 			if tkns.IsA(tokens.Comma) {
 				tkns.Remove()
 			} else {
 				break
 			}
+		} else {
+			break
 		}
 
 	}
 	if !isValidSetExpression {
-		return nil, sqerr.NewSyntax("Expecting valid SET expression")
-	}
-	// Optional Where Clause
-	if tkns.Len() > 0 && tkns.IsA(tokens.Where) {
-		tkns.Remove()
-		stmt.WhereExpr, err = ParseWhereClause(tkns, false)
-		if err != nil {
-			return nil, err
-		}
-		err = stmt.WhereExpr.ValidateCols(trans.Profile(), sqtables.NewTableListFromTableDef(trans.Profile(), stmt.Table))
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if !tkns.IsEmpty() {
-		return nil, sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+		return nil, exprs, sqerr.NewSyntax("Expecting valid SET expression")
 	}
-
-	return &stmt, nil
+	return cols, exprs, nil
 }
 
 func executeUpdate(trans sqtables.Transaction, stmt *UpdateStmt) (string, error) {
-	err := stmt.SetExprs.ValidateCols(trans.Profile(), sqtables.NewTableListFromTableDef(trans.Profile(), stmt.Table))
+	if stmt.Join != nil {
+		return executeUpdateJoin(trans, stmt)
+	}
+	err := stmt.SetExprs.ValidateCols(trans.Profile(), stmt.tables(trans.Profile()))
 	if err != nil {
 		return "", err
 	}
+	if stmt.Returning != nil {
+		trans = stmt.Returning.Watch(trans)
+	}
 	l, err := stmt.Table.UpdateRows(trans, stmt.WhereExpr, stmt.SetCols, &stmt.SetExprs)
 	//err = redo.Send(redo.NewUpdateRows(tableName, setCols, &setExprs, ptrs))
 
 	return fmt.Sprintf("Updated %d rows from table", l), err
 }
+
+// executeUpdateJoin updates each row of the table that is joined to the tables of the FROM clause. The
+//   SET expressions are evaluated with the joined rows. The changes are made in a single transaction
+func executeUpdateJoin(trans sqtables.Transaction, stmt *UpdateStmt) (string, error) {
+	profile := trans.Profile()
+
+	// An automatic transaction would be committed by each change so a transaction that is committed
+	//   once at the end is used instead
+	utrans := trans
+	if trans.Auto() {
+		utrans = sqtables.BeginTrans(profile, false)
+	}
+	if stmt.Returning != nil {
+		utrans = stmt.Returning.Watch(utrans)
+	}
+	fail := func(err error) (string, error) {
+		if trans.Auto() {
+			utrans.Rollback()
+		}
+		return "", err
+	}
+
+	err := utrans.AddLock(stmt.Table)
+	if err != nil {
+		return fail(err)
+	}
+	ptrs, tuples, err := stmt.Join.Rows(profile)
+	if err != nil {
+		return fail(err)
+	}
+
+	changed := make(map[sqptr.SQPtr]bool)
+	for i, rows := range tuples {
+		if changed[ptrs[i]] {
+			return fail(sqerr.Newf("UPDATE can not change the same row of %s more than once", stmt.TableName))
+		}
+		changed[ptrs[i]] = true
+
+		vals, err := stmt.SetExprs.Evaluate(profile, sqtables.EvalFull, rows...)
+		if err != nil {
+			return fail(err)
+		}
+		err = stmt.Table.UpdateRowsFromPtrs(utrans, sqptr.SQPtrs{ptrs[i]}, stmt.SetCols, sqtables.NewExprListFromValues(vals))
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	if trans.Auto() {
+		err = utrans.Commit()
+		if err != nil {
+			return fail(err)
+		}
+	}
+	return fmt.Sprintf("Updated %d rows from table", len(ptrs)), nil
+}
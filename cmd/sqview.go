@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/wilphi/sqsrv/redo"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// CreateViewStmt -
+type CreateViewStmt struct {
+	ViewName string
+	Cols     []string
+	Query    string
+}
+
+// CreateView stores a SELECT statement as a view that can be used in a FROM clause like a table
+//	  This function will always return a nil dataset
+func CreateView(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *sqtables.DataSet, error) {
+
+	if !trans.Auto() {
+		return "", nil, sqerr.New("DDL statements cannot be executed within a transaction")
+	}
+
+	stmt, err := ParseCreateView(trans.Profile(), tkns)
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = sqtables.CreateView(trans.Profile(), sqtables.CreateViewDef(stmt.ViewName, stmt.Cols, stmt.Query))
+	if err != nil {
+		return "", nil, err
+	}
+	err = redo.Send(redo.NewCreateView(stmt.ViewName, stmt.Cols, stmt.Query))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return stmt.ViewName, nil, nil
+}
+
+// ParseCreateView - parses the tokens of a CREATE VIEW name [(col, ...)] AS SELECT ... statement.
+//   The SELECT statement is validated but not executed
+func ParseCreateView(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (*CreateViewStmt, error) {
+	var err error
+	var stmt CreateViewStmt
+
+	log.Debug("CREATE VIEW command")
+	tkns.IsARemove(tokens.Create)
+	tkns.IsARemove(tokens.View)
+
+	// make sure the next token is an Ident
+	if tkn := tkns.TestTkn(tokens.Ident); tkn != nil {
+		stmt.ViewName = strings.ToLower(tkn.(*tokens.ValueToken).Value())
+		tkns.Remove()
+	} else {
+		return nil, sqerr.NewSyntax("Expecting name of view to create")
+	}
+
+	// Optional list of column names
+	if tkns.IsARemove(tokens.OpenBracket) {
+		stmt.Cols, err = GetIdentList(tkns, tokens.CloseBracket)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !tkns.IsARemove(tokens.As) {
+		return nil, sqerr.NewSyntaxf("Expecting AS after %s", stmt.ViewName)
+	}
+	if !tkns.IsA(tokens.Select) {
+		return nil, sqerr.NewSyntax("Expecting SELECT after AS in CREATE VIEW")
+	}
+	stmt.Query = tkns.SQL()
+
+	// The SELECT must not use the view, even through another view
+	startExpandView(profile, stmt.ViewName)
+	q, err := SelectParse(profile, tkns)
+	endExpandView(profile, stmt.ViewName)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.Cols != nil && len(stmt.Cols) != q.EList.Len() {
+		return nil, sqerr.Newf("%s has %d columns available but %d columns specified", stmt.ViewName, q.EList.Len(), len(stmt.Cols))
+	}
+
+	return &stmt, nil
+}
+
+// DropView removes a view from the database. Tables used by the view are not affected. A view that
+//   is used by another view can not be dropped.
+//	  This function will always return a nil dataset
+func DropView(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *sqtables.DataSet, error) {
+	var viewName string
+
+	if !trans.Auto() {
+		return "", nil, sqerr.New("DDL statements cannot be executed within a transaction")
+	}
+
+	log.Debug("DROP VIEW command")
+
+	// Eat the DROP VIEW tokens if they are there
+	tkns.IsARemove(tokens.Drop)
+	tkns.IsARemove(tokens.View)
+
+	// make sure the next token is an Ident
+	if tkn := tkns.TestTkn(tokens.Ident); tkn != nil {
+		viewName = strings.ToLower(tkn.(*tokens.ValueToken).Value())
+		tkns.Remove()
+	} else {
+		return "", nil, sqerr.NewSyntax("Expecting name of view to Drop")
+	}
+
+	if !tkns.IsEmpty() {
+		return "", nil, sqerr.NewSyntax("Unexpected tokens after SQL command:" + tkns.String())
+	}
+
+	depName, err := dependentView(trans.Profile(), viewName)
+	if err != nil {
+		return "", nil, err
+	}
+	if depName != "" {
+		return "", nil, sqerr.Newf("View %s can not be dropped because it is used by view %s", viewName, depName)
+	}
+
+	err = sqtables.DropView(trans.Profile(), viewName)
+	if err != nil {
+		return "", nil, err
+	}
+	err = redo.Send(redo.NewDropView(viewName))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return viewName, nil, nil
+}
+
+// dependentView returns the name of a view that uses the named view in its SELECT statement. If the
+//   name is not a view or no view uses it an empty string is returned. A view that can no longer be
+//   parsed is skipped
+func dependentView(profile *sqprofile.SQProfile, name string) (string, error) {
+	if view, err := sqtables.GetView(profile, name); err != nil || view == nil {
+		return "", err
+	}
+	vNames, err := sqtables.CatalogViews(profile)
+	if err != nil {
+		return "", err
+	}
+	for _, vName := range vNames {
+		if vName == name {
+			continue
+		}
+		view, err := sqtables.GetView(profile, vName)
+		if err != nil {
+			return "", err
+		}
+		if view == nil {
+			continue
+		}
+		q, err := SelectParse(profile, tokens.Tokenize(view.GetQuery()))
+		if err != nil {
+			continue
+		}
+		if usesTableName(q, name) {
+			return vName, nil
+		}
+	}
+	return "", nil
+}
+
+// usesTableName returns true if the query or one of its set operations uses a table or view with the name
+func usesTableName(q *sqtables.Query, name string) bool {
+	for _, tr := range q.Tables {
+		if strings.EqualFold(tr.Name.Name(), name) {
+			return true
+		}
+	}
+	for _, setOp := range q.SetOps {
+		if usesTableName(setOp.Query, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandView runs the SELECT statement of a view and returns the results as a temporary table
+func expandView(profile *sqprofile.SQProfile, view *sqtables.ViewDef) (*sqtables.TableDef, error) {
+	if isExpandingView(profile, view.GetName()) {
+		return nil, sqerr.Newf("View %s refers to itself", view.GetName())
+	}
+	startExpandView(profile, view.GetName())
+	defer endExpandView(profile, view.GetName())
+
+	return derivedTable(profile, view.GetName(), view.GetCols(), tokens.Tokenize(view.GetQuery()))
+}
+
+// expandingViews holds the names of the views that each profile is expanding so that a view that
+//   refers back to itself is found instead of being expanded forever
+var expandingViews = struct {
+	sync.Mutex
+	profiles map[int64]map[string]int
+}{profiles: make(map[int64]map[string]int)}
+
+// startExpandView marks the view as being expanded by the profile
+func startExpandView(profile *sqprofile.SQProfile, name string) {
+	expandingViews.Lock()
+	defer expandingViews.Unlock()
+
+	views := expandingViews.profiles[profile.GetID()]
+	if views == nil {
+		views = make(map[string]int)
+		expandingViews.profiles[profile.GetID()] = views
+	}
+	views[name]++
+}
+
+// endExpandView marks the view as no longer being expanded by the profile
+func endExpandView(profile *sqprofile.SQProfile, name string) {
+	expandingViews.Lock()
+	defer expandingViews.Unlock()
+
+	views := expandingViews.profiles[profile.GetID()]
+	views[name]--
+	if views[name] <= 0 {
+		delete(views, name)
+	}
+	if len(views) == 0 {
+		delete(expandingViews.profiles, profile.GetID())
+	}
+}
+
+// isExpandingView returns true if the profile is expanding the view
+func isExpandingView(profile *sqprofile.SQProfile, name string) bool {
+	expandingViews.Lock()
+	defer expandingViews.Unlock()
+
+	return expandingViews.profiles[profile.GetID()][name] > 0
+}
@@ -0,0 +1,294 @@
+package cmd_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/wilphi/sqsrv/cmd"
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+type ViewData struct {
+	TestName    string
+	Command     string
+	Exec        func(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *sqtables.DataSet, error)
+	ExpErr      string
+	ExpMsg      string
+	ExpCols     []string
+	ExpVals     sqtypes.RawVals
+	ManualTrans bool
+}
+
+func testViewFunc(profile *sqprofile.SQProfile, d ViewData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		tkns := tokens.Tokenize(d.Command)
+		trans := sqtables.BeginTrans(profile, !d.ManualTrans)
+		msg, data, err := d.Exec(trans, tkns)
+		if d.ManualTrans {
+			trans.Rollback()
+		}
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+
+		if d.ExpMsg != "" && msg != d.ExpMsg {
+			t.Errorf("Expected message %q does not match actual %q", d.ExpMsg, msg)
+			return
+		}
+		if d.ExpVals == nil {
+			return
+		}
+		if data == nil {
+			t.Error("Dataset returned is nil")
+			return
+		}
+		if !reflect.DeepEqual(data.GetColNames(), d.ExpCols) {
+			t.Errorf("Expected Cols (%v) do not match actual cols (%v)", d.ExpCols, data.GetColNames())
+			return
+		}
+		msg = sqtypes.Compare2DValue(data.Vals, sqtypes.CreateValuesFromRaw(d.ExpVals), "Actual", "Expect", false)
+		if msg != "" {
+			t.Error(msg)
+			return
+		}
+	}
+}
+
+func TestViews(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	// Make sure datasets are by default in RowID order
+	sqtables.RowOrder = true
+
+	err := sq.ProcessSQFile("./testdata/viewtests.sq")
+	if err != nil {
+		t.Fatalf("Unable to load test data: %s", err)
+	}
+
+	data := []ViewData{
+		{
+			TestName: "Create View",
+			Command:  "CREATE VIEW vemp AS SELECT id, name FROM viewemp WHERE boss = 1",
+			Exec:     cmd.CreateView,
+			ExpMsg:   "vemp",
+		},
+		{
+			TestName: "Select from View",
+			Command:  "SELECT name FROM vemp ORDER BY name",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"name"},
+			ExpVals:  sqtypes.RawVals{{"VP Dev"}, {"VP Sales"}},
+		},
+		{
+			TestName: "Create View with column list",
+			Command:  "create view VBoss (empid, bossid) as select id, boss from viewemp where id > 1",
+			Exec:     cmd.CreateView,
+			ExpMsg:   "vboss",
+		},
+		{
+			TestName: "Join table and View",
+			Command:  "SELECT viewemp.name, b.empid FROM viewemp JOIN vboss b ON viewemp.id = b.bossid WHERE b.empid = 4",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"viewemp.name", "b.empid"},
+			ExpVals:  sqtypes.RawVals{{"VP Dev", 4}},
+		},
+		{
+			TestName: "View of a View",
+			Command:  "CREATE VIEW vcount AS SELECT bossid, count() AS reports FROM vboss GROUP BY bossid",
+			Exec:     cmd.CreateView,
+			ExpMsg:   "vcount",
+		},
+		{
+			TestName: "Select from View of a View",
+			Command:  "SELECT bossid, reports FROM vcount ORDER BY bossid",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"bossid", "reports"},
+			ExpVals:  sqtypes.RawVals{{1, 2}, {2, 1}, {3, 1}, {4, 1}},
+		},
+		{
+			TestName: "View sees changes to table",
+			Command:  "INSERT INTO viewemp (id, name, boss) VALUES (7, \"VP HR\", 1)",
+			Exec:     cmd.InsertInto,
+		},
+		{
+			TestName: "Select changed data from View",
+			Command:  "SELECT empid FROM vboss WHERE bossid = 1 ORDER BY empid",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"empid"},
+			ExpVals:  sqtypes.RawVals{{2}, {3}, {7}},
+		},
+		{
+			TestName: "Common table expression hides View",
+			Command:  "WITH vemp AS (SELECT id FROM viewemp WHERE id = 5) SELECT id FROM vemp",
+			Exec:     cmd.With,
+			ExpCols:  []string{"id"},
+			ExpVals:  sqtypes.RawVals{{5}},
+		},
+		{
+			TestName: "Duplicate View",
+			Command:  "CREATE VIEW vemp AS SELECT id FROM viewemp",
+			Exec:     cmd.CreateView,
+			ExpErr:   "Error: Invalid Name: View vemp already exists",
+		},
+		{
+			TestName: "View with name of Table",
+			Command:  "CREATE VIEW viewemp AS SELECT id FROM viewemp",
+			Exec:     cmd.CreateView,
+			ExpErr:   "Error: Invalid Name: Table viewemp already exists",
+		},
+		{
+			TestName: "Table with name of View",
+			Command:  "CREATE TABLE vemp (col1 int)",
+			Exec:     cmd.CreateTable,
+			ExpErr:   "Error: Invalid Name: View vemp already exists",
+		},
+		{
+			TestName: "Column list mismatch",
+			Command:  "CREATE VIEW vbad (a) AS SELECT id, name FROM viewemp",
+			Exec:     cmd.CreateView,
+			ExpErr:   "Error: vbad has 2 columns available but 1 columns specified",
+		},
+		{
+			TestName: "Invalid Select",
+			Command:  "CREATE VIEW vbad AS SELECT id FROM notatable",
+			Exec:     cmd.CreateView,
+			ExpErr:   "Error: Table \"notatable\" does not exist",
+		},
+		{
+			TestName: "Missing AS",
+			Command:  "CREATE VIEW vbad SELECT id FROM viewemp",
+			Exec:     cmd.CreateView,
+			ExpErr:   "Syntax Error: Expecting AS after vbad",
+		},
+		{
+			TestName: "Missing SELECT",
+			Command:  "CREATE VIEW vbad AS viewemp",
+			Exec:     cmd.CreateView,
+			ExpErr:   "Syntax Error: Expecting SELECT after AS in CREATE VIEW",
+		},
+		{
+			TestName: "Missing View name",
+			Command:  "CREATE VIEW AS SELECT id FROM viewemp",
+			Exec:     cmd.CreateView,
+			ExpErr:   "Syntax Error: Expecting name of view to create",
+		},
+		{
+			TestName:    "Create View in Transaction",
+			Command:     "CREATE VIEW vbad AS SELECT id FROM viewemp",
+			Exec:        cmd.CreateView,
+			ExpErr:      "Error: DDL statements cannot be executed within a transaction",
+			ManualTrans: true,
+		},
+		{
+			TestName: "Drop Table on a View",
+			Command:  "DROP TABLE vemp",
+			Exec:     cmd.DropTable,
+			ExpErr:   "Error: Invalid Name: vemp is a view, use DROP VIEW to remove it",
+		},
+		{
+			TestName: "Drop View on a Table",
+			Command:  "DROP VIEW viewemp",
+			Exec:     cmd.DropView,
+			ExpErr:   "Error: Invalid Name: viewemp is a table, use DROP TABLE to remove it",
+		},
+		{
+			TestName: "Drop View",
+			Command:  "DROP VIEW vemp",
+			Exec:     cmd.DropView,
+			ExpMsg:   "vemp",
+		},
+		{
+			TestName: "Select from dropped View",
+			Command:  "SELECT name FROM vemp",
+			Exec:     cmd.Select,
+			ExpErr:   "Error: Table \"vemp\" does not exist",
+		},
+		{
+			TestName: "Drop View that does not exist",
+			Command:  "DROP VIEW vemp",
+			Exec:     cmd.DropView,
+			ExpErr:   "Error: Invalid Name: View vemp does not exist",
+		},
+		{
+			TestName: "Drop View missing name",
+			Command:  "DROP VIEW",
+			Exec:     cmd.DropView,
+			ExpErr:   "Syntax Error: Expecting name of view to Drop",
+		},
+		{
+			TestName: "Drop View extra tokens",
+			Command:  "DROP VIEW vboss vcount",
+			Exec:     cmd.DropView,
+			ExpErr:   "Syntax Error: Unexpected tokens after SQL command:[IDENT=vcount]",
+		},
+		{
+			TestName:    "Drop View in Transaction",
+			Command:     "DROP VIEW vboss",
+			Exec:        cmd.DropView,
+			ExpErr:      "Error: DDL statements cannot be executed within a transaction",
+			ManualTrans: true,
+		},
+		{
+			TestName: "Drop View used by another View",
+			Command:  "DROP VIEW vboss",
+			Exec:     cmd.DropView,
+			ExpErr:   "Error: View vboss can not be dropped because it is used by view vcount",
+		},
+		{
+			TestName: "View used by another View is not dropped",
+			Command:  "SELECT bossid, reports FROM vcount WHERE bossid = 1",
+			Exec:     cmd.Select,
+			ExpCols:  []string{"bossid", "reports"},
+			ExpVals:  sqtypes.RawVals{{1, 3}},
+		},
+		{
+			TestName: "Drop View that uses another View",
+			Command:  "DROP VIEW vcount",
+			Exec:     cmd.DropView,
+			ExpMsg:   "vcount",
+		},
+		{
+			TestName: "Drop View after the View that used it",
+			Command:  "DROP VIEW vboss",
+			Exec:     cmd.DropView,
+			ExpMsg:   "vboss",
+		},
+		{
+			TestName: "View that refers to itself",
+			Command:  "CREATE VIEW vself AS SELECT * FROM vself",
+			Exec:     cmd.CreateView,
+			ExpErr:   "Error: View vself refers to itself",
+		},
+		{
+			TestName: "View of a Table",
+			Command:  "CREATE VIEW vdev AS SELECT id, name FROM vtab",
+			Exec:     cmd.CreateView,
+			ExpMsg:   "vdev",
+		},
+		{
+			TestName: "Drop Table used by a View",
+			Command:  "DROP TABLE vtab",
+			Exec:     cmd.DropTable,
+			ExpMsg:   "vtab",
+		},
+		{
+			TestName: "View that refers to itself through another View",
+			Command:  "CREATE VIEW vtab AS SELECT id, name FROM vdev",
+			Exec:     cmd.CreateView,
+			ExpErr:   "Error: View vtab refers to itself",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testViewFunc(profile, row))
+
+	}
+}
@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// maxRecursion is the maximum number of iterations allowed when evaluating a WITH RECURSIVE expression
+var maxRecursion = 100
+
+// SetMaxRecursion sets the maximum number of iterations allowed when evaluating a WITH RECURSIVE expression
+func SetMaxRecursion(n int) {
+	maxRecursion = n
+}
+
+// With processes a list of common table expressions followed by a SELECT, INSERT, UPDATE or DELETE.
+//   Each common table expression is a temporary table that is only visible to the statement
+func With(trans sqtables.Transaction, tkns *tokens.TokenList) (string, *sqtables.DataSet, error) {
+	profile := trans.Profile()
+
+	log.Info("WITH statement...")
+	// The removal of the scope is deferred before withClause starts so that an error or panic part way
+	//   through the list does not leave any of the tables visible to the profile
+	scope := &cteScope{profile: profile}
+	defer scope.remove()
+	err := withClause(scope, tkns)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch {
+	case tkns.IsA(tokens.Select):
+		return Select(trans, tkns)
+	case tkns.IsA(tokens.Insert):
+		return InsertInto(trans, tkns)
+	case tkns.IsA(tokens.Update):
+		return Update(trans, tkns)
+	case tkns.IsA(tokens.Delete):
+		return Delete(trans, tkns)
+	}
+	return "", nil, sqerr.NewSyntax("Expecting SELECT, INSERT, UPDATE or DELETE after WITH clause")
+}
+
+// cteScope holds the common table expressions of a WITH statement that have been added to the profile
+type cteScope struct {
+	profile *sqprofile.SQProfile
+	names   []string
+}
+
+// add makes a temporary table visible to the profile until the scope is removed
+func (s *cteScope) add(name string, tab *sqtables.TableDef) error {
+	err := sqtables.AddTempTable(s.profile, tab)
+	if err != nil {
+		return err
+	}
+	s.names = append(s.names, name)
+	return nil
+}
+
+// remove takes the temporary tables away from the profile in the reverse order they were added
+func (s *cteScope) remove() {
+	for i := len(s.names) - 1; i >= 0; i-- {
+		sqtables.RemoveTempTable(s.profile, s.names[i])
+	}
+	s.names = nil
+}
+
+// withClause processes WITH [RECURSIVE] name [(col, ...)] AS (SELECT ...) [, ...]
+//   Each expression is added as a temporary table to the scope.
+func withClause(scope *cteScope, tkns *tokens.TokenList) error {
+	var colNames []string
+	var err error
+
+	profile := scope.profile
+	if !tkns.IsARemove(tokens.With) {
+		return sqerr.NewInternal("WITH Token not found")
+	}
+	isRecursive := tkns.IsARemove(tokens.Recursive)
+
+	for {
+		tkn := tkns.TestTkn(tokens.Ident)
+		if tkn == nil {
+			return sqerr.NewSyntax("Expecting name of common table expression")
+		}
+		name := strings.ToLower(tkn.(*tokens.ValueToken).Value())
+		tkns.Remove()
+		for _, n := range scope.names {
+			if n == name {
+				return sqerr.NewSyntaxf("Duplicate common table expression %s", name)
+			}
+		}
+
+		// Optional list of column names
+		colNames = nil
+		if tkns.IsARemove(tokens.OpenBracket) {
+			colNames, err = GetIdentList(tkns, tokens.CloseBracket)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !tkns.IsARemove(tokens.As) {
+			return sqerr.NewSyntaxf("Expecting AS after %s", name)
+		}
+		body, err := getBracketTokens(tkns)
+		if err != nil {
+			return err
+		}
+
+		var tab *sqtables.TableDef
+		if isRecursive {
+			tab, err = recursiveTable(profile, name, colNames, body)
+		} else {
+			tab, err = derivedTable(profile, name, colNames, body)
+		}
+		if err != nil {
+			return err
+		}
+		err = scope.add(name, tab)
+		if err != nil {
+			return err
+		}
+
+		if !tkns.IsARemove(tokens.Comma) {
+			break
+		}
+	}
+	return nil
+}
+
+// derivedTable creates a temporary table from the results of a SELECT statement
+func derivedTable(profile *sqprofile.SQProfile, name string, colNames []string, tkns *tokens.TokenList) (*sqtables.TableDef, error) {
+	q, err := SelectParse(profile, tkns)
+	if err != nil {
+		return nil, err
+	}
+	data, err := SelectExecute(profile, q)
+	if err != nil {
+		return nil, err
+	}
+	return sqtables.CreateTempTableDef(profile, name, colNames, data)
+}
+
+// recursiveTable creates a temporary table from a recursive SELECT statement. The statement is split
+//   at the last UNION into the anchor and the recursive part. The recursive part is evaluated against the
+//   rows found in the previous iteration until no new rows are found.
+func recursiveTable(profile *sqprofile.SQProfile, name string, colNames []string, tkns *tokens.TokenList) (*sqtables.TableDef, error) {
+	anchorTkns, recTkns, isAll := splitLastUnion(tkns)
+	if recTkns == nil {
+		return derivedTable(profile, name, colNames, tkns)
+	}
+
+	anchorQ, err := SelectParse(profile, anchorTkns)
+	if err != nil {
+		return nil, err
+	}
+	result, err := SelectExecute(profile, anchorQ)
+	if err != nil {
+		return nil, err
+	}
+	if !isAll {
+		result.Distinct()
+	}
+
+	tab, err := sqtables.CreateTempTableDef(profile, name, colNames, result)
+	if err != nil {
+		return nil, err
+	}
+	// The table must be visible while the recursive part is parsed and evaluated
+	err = sqtables.AddTempTable(profile, tab)
+	if err != nil {
+		return nil, err
+	}
+	defer sqtables.RemoveTempTable(profile, name)
+
+	recQ, err := SelectParse(profile, recTkns)
+	if err != nil {
+		return nil, err
+	}
+	selfRef := refersToTable(recQ, tab)
+
+	for depth := 0; ; depth++ {
+		newRows, err := getSelectRows(profile, recQ)
+		if err != nil {
+			return nil, err
+		}
+		err = result.CheckCompatible(tokens.Union, newRows)
+		if err != nil {
+			return nil, err
+		}
+		if !isAll {
+			// Only rows that have not been seen before are kept
+			err = newRows.SetOperation(tokens.Except, false, result)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if newRows.Len() == 0 {
+			break
+		}
+		result.Vals = append(result.Vals, newRows.Vals...)
+		if !selfRef {
+			break
+		}
+		if depth >= maxRecursion {
+			return nil, sqerr.Newf("WITH RECURSIVE %s exceeded the maximum recursion depth of %d", name, maxRecursion)
+		}
+		err = tab.SetTempRows(profile, newRows.Vals)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tab.SetTempRows(profile, result.Vals)
+	if err != nil {
+		return nil, err
+	}
+	return tab, nil
+}
+
+// refersToTable checks to see if the query or any query it is combined with uses the table
+func refersToTable(q *sqtables.Query, tab *sqtables.TableDef) bool {
+	for _, tr := range q.Tables {
+		if tr.Table == tab {
+			return true
+		}
+	}
+	for _, setOp := range q.SetOps {
+		if refersToTable(setOp.Query, tab) {
+			return true
+		}
+	}
+	return false
+}
+
+// getBracketTokens removes the tokens between an open bracket and its matching close bracket from
+//   the list. The brackets are removed but not returned
+func getBracketTokens(tkns *tokens.TokenList) (*tokens.TokenList, error) {
+	if !tkns.IsARemove(tokens.OpenBracket) {
+		return nil, sqerr.NewSyntax("Expecting (")
+	}
+	inner := tokens.NewTokenList()
+	depth := 0
+	for !tkns.IsEmpty() {
+		switch tkns.Peek().ID() {
+		case tokens.OpenBracket:
+			depth++
+		case tokens.CloseBracket:
+			if depth == 0 {
+				tkns.Remove()
+				return inner, nil
+			}
+			depth--
+		}
+		inner.Add(tkns.Peek())
+		tkns.Remove()
+	}
+	return nil, sqerr.NewSyntax("'(' does not have a matching ')'")
+}
+
+// splitLastUnion splits a token list at the last UNION that is not within brackets. If there
+//   is no UNION then the second list will be nil
+func splitLastUnion(tkns *tokens.TokenList) (*tokens.TokenList, *tokens.TokenList, bool) {
+	depth := 0
+	last := -1
+	for i := 0; i < tkns.Len(); i++ {
+		switch tkns.Peekx(i).ID() {
+		case tokens.OpenBracket:
+			depth++
+		case tokens.CloseBracket:
+			depth--
+		case tokens.Union:
+			if depth == 0 {
+				last = i
+			}
+		}
+	}
+	if last < 0 {
+		return tkns, nil, false
+	}
+
+	first := tokens.NewTokenList()
+	for i := 0; i < last; i++ {
+		first.Add(tkns.Peek())
+		tkns.Remove()
+	}
+	// remove the UNION
+	tkns.Remove()
+	isAll := tkns.IsARemove(tokens.All)
+	return first, tkns, isAll
+}
@@ -0,0 +1,251 @@
+package cmd_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/wilphi/sqsrv/cmd"
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+type WithData struct {
+	TestName     string
+	Command      string
+	ExpErr       string
+	ExpMsg       string
+	ExpCols      []string
+	ExpVals      sqtypes.RawVals
+	MaxRecursion int
+}
+
+func testWithFunc(profile *sqprofile.SQProfile, d WithData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		if d.MaxRecursion > 0 {
+			cmd.SetMaxRecursion(d.MaxRecursion)
+			defer cmd.SetMaxRecursion(100)
+		}
+		tkns := tokens.Tokenize(d.Command)
+		trans := sqtables.BeginTrans(profile, true)
+		msg, data, err := cmd.With(trans, tkns)
+
+		// the common table expressions must not be visible after the statement even if it failed
+		for _, name := range []string{"j", "sub", "cnt", "r", "withemp"} {
+			if tab := sqtables.GetTempTable(profile, name); tab != nil {
+				t.Errorf("Temporary table %s is still visible after WITH statement", name)
+				return
+			}
+		}
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+
+		if d.ExpMsg != "" && msg != d.ExpMsg {
+			t.Errorf("Expected message %q does not match actual %q", d.ExpMsg, msg)
+			return
+		}
+		if d.ExpVals == nil {
+			return
+		}
+		if data == nil {
+			t.Error("Dataset returned from WITH is nil")
+			return
+		}
+		if !reflect.DeepEqual(data.GetColNames(), d.ExpCols) {
+			t.Errorf("Expected Cols (%v) do not match actual cols (%v)", d.ExpCols, data.GetColNames())
+			return
+		}
+		msg = sqtypes.Compare2DValue(data.Vals, sqtypes.CreateValuesFromRaw(d.ExpVals), "Actual", "Expect", false)
+		if msg != "" {
+			t.Error(msg)
+			return
+		}
+	}
+}
+
+func TestWith(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	// Make sure datasets are by default in RowID order
+	sqtables.RowOrder = true
+
+	err := sq.ProcessSQFile("./testdata/withtests.sq")
+	if err != nil {
+		t.Fatalf("Unable to load test data: %s", err)
+	}
+
+	data := []WithData{
+		{
+			TestName: "Simple CTE",
+			Command:  "WITH j AS (SELECT id, name FROM withemp WHERE boss = 1) SELECT name FROM j ORDER BY name",
+			ExpCols:  []string{"name"},
+			ExpVals:  sqtypes.RawVals{{"VP Dev"}, {"VP Sales"}},
+		},
+		{
+			TestName: "CTE with column list",
+			Command:  "WITH j (empid, boss) AS (SELECT id, boss FROM withemp) SELECT empid FROM j WHERE boss = 3",
+			ExpCols:  []string{"empid"},
+			ExpVals:  sqtypes.RawVals{{4}},
+		},
+		{
+			TestName: "CTE with expression alias",
+			Command:  "WITH j AS (SELECT boss, count() AS reports FROM withemp WHERE id > 1 GROUP BY boss) SELECT boss, reports FROM j ORDER BY boss",
+			ExpCols:  []string{"boss", "reports"},
+			ExpVals:  sqtypes.RawVals{{1, 2}, {2, 1}, {3, 1}, {4, 1}},
+		},
+		{
+			TestName: "Multiple CTEs with join",
+			Command:  "WITH j AS (SELECT id, name FROM withemp WHERE boss = 1), sub AS (SELECT id, name, boss FROM withemp) SELECT j.name, sub.name FROM j JOIN sub ON j.id = sub.boss ORDER BY j.name",
+			ExpCols:  []string{"j.name", "sub.name"},
+			ExpVals:  sqtypes.RawVals{{"VP Dev", "Manager"}, {"VP Sales", "Sales Rep"}},
+		},
+		{
+			TestName: "CTE refers to previous CTE",
+			Command:  "WITH j AS (SELECT id, name FROM withemp WHERE boss = 1), sub AS (SELECT name FROM j WHERE id = 3) SELECT * FROM sub",
+			ExpCols:  []string{"name"},
+			ExpVals:  sqtypes.RawVals{{"VP Dev"}},
+		},
+		{
+			TestName: "CTE hides table",
+			Command:  "WITH withemp AS (SELECT id, name FROM withemp WHERE id = 5) SELECT * FROM withemp",
+			ExpCols:  []string{"id", "name"},
+			ExpVals:  sqtypes.RawVals{{5, "Developer"}},
+		},
+		{
+			TestName: "CTE with table alias",
+			Command:  "WITH j AS (SELECT id, name FROM withemp WHERE boss = 1) SELECT x.name FROM j AS x WHERE x.id = 2",
+			ExpCols:  []string{"x.name"},
+			ExpVals:  sqtypes.RawVals{{"VP Sales"}},
+		},
+		{
+			TestName: "Recursive CTE org chart",
+			Command:  "WITH RECURSIVE sub (id, name, lvl) AS (SELECT id, name, 1 FROM withemp WHERE id = 3 UNION ALL SELECT withemp.id, withemp.name, sub.lvl + 1 FROM withemp JOIN sub ON withemp.boss = sub.id) SELECT * FROM sub ORDER BY id",
+			ExpCols:  []string{"id", "name", "lvl"},
+			ExpVals:  sqtypes.RawVals{{3, "VP Dev", 1}, {4, "Manager", 2}, {5, "Developer", 3}},
+		},
+		{
+			TestName: "Recursive CTE counter",
+			Command:  "WITH RECURSIVE cnt (n) AS (SELECT id FROM withemp WHERE id = 1 UNION ALL SELECT n + 1 FROM cnt WHERE n < 5) SELECT n FROM cnt",
+			ExpCols:  []string{"n"},
+			ExpVals:  sqtypes.RawVals{{1}, {2}, {3}, {4}, {5}},
+		},
+		{
+			TestName: "Recursive CTE with cycle and UNION",
+			Command:  "WITH RECURSIVE r (node) AS (SELECT src FROM withgraph WHERE src = 1 UNION SELECT withgraph.dst FROM withgraph JOIN r ON withgraph.src = r.node) SELECT node FROM r ORDER BY node",
+			ExpCols:  []string{"node"},
+			ExpVals:  sqtypes.RawVals{{1}, {2}, {3}, {4}},
+		},
+		{
+			TestName:     "Recursive CTE with cycle and UNION ALL",
+			Command:      "WITH RECURSIVE r (node) AS (SELECT src FROM withgraph WHERE src = 1 UNION ALL SELECT withgraph.dst FROM withgraph JOIN r ON withgraph.src = r.node) SELECT node FROM r",
+			ExpErr:       "Error: WITH RECURSIVE r exceeded the maximum recursion depth of 10",
+			MaxRecursion: 10,
+		},
+		{
+			TestName: "Recursive CTE not self referencing",
+			Command:  "WITH RECURSIVE j AS (SELECT id FROM withemp WHERE id = 1 UNION ALL SELECT id FROM withemp WHERE id = 2) SELECT id FROM j",
+			ExpCols:  []string{"id"},
+			ExpVals:  sqtypes.RawVals{{1}, {2}},
+		},
+		{
+			TestName: "Recursive CTE type mismatch",
+			Command:  "WITH RECURSIVE j (n) AS (SELECT id FROM withemp WHERE id = 1 UNION ALL SELECT name FROM withemp JOIN j ON withemp.id = j.n) SELECT n FROM j",
+			ExpErr:   "Error: Type Mismatch: Column 1 in UNION has types INT and STRING",
+		},
+		{
+			TestName: "RECURSIVE without UNION",
+			Command:  "WITH RECURSIVE j AS (SELECT id FROM withemp WHERE id = 1) SELECT id FROM j",
+			ExpCols:  []string{"id"},
+			ExpVals:  sqtypes.RawVals{{1}},
+		},
+		{
+			TestName: "WITH before DELETE",
+			Command:  "WITH j AS (SELECT id FROM withemp) DELETE FROM withgraph WHERE src = 99",
+			ExpMsg:   "Deleted 0 rows from table",
+		},
+		{
+			TestName: "Missing CTE name",
+			Command:  "WITH AS (SELECT id FROM withemp) SELECT * FROM j",
+			ExpErr:   "Syntax Error: Expecting name of common table expression",
+		},
+		{
+			TestName: "Missing AS",
+			Command:  "WITH j (SELECT id FROM withemp) SELECT * FROM j",
+			ExpErr:   "Syntax Error: Expecting name of column",
+		},
+		{
+			TestName: "Missing AS after column list",
+			Command:  "WITH j (id) (SELECT id FROM withemp) SELECT * FROM j",
+			ExpErr:   "Syntax Error: Expecting AS after j",
+		},
+		{
+			TestName: "Missing open bracket",
+			Command:  "WITH j AS SELECT id FROM withemp SELECT * FROM j",
+			ExpErr:   "Syntax Error: Expecting (",
+		},
+		{
+			TestName: "Missing close bracket",
+			Command:  "WITH j AS (SELECT int(id FROM withemp SELECT * FROM j",
+			ExpErr:   "Syntax Error: '(' does not have a matching ')'",
+		},
+		{
+			TestName: "Missing statement",
+			Command:  "WITH j AS (SELECT id FROM withemp)",
+			ExpErr:   "Syntax Error: Expecting SELECT, INSERT, UPDATE or DELETE after WITH clause",
+		},
+		{
+			TestName: "Duplicate CTE name",
+			Command:  "WITH j AS (SELECT id FROM withemp), j AS (SELECT id FROM withemp) SELECT * FROM j",
+			ExpErr:   "Syntax Error: Duplicate common table expression j",
+		},
+		{
+			TestName: "Column list mismatch",
+			Command:  "WITH j (a) AS (SELECT id, name FROM withemp) SELECT * FROM j",
+			ExpErr:   "Error: j has 2 columns available but 1 columns specified",
+		},
+		{
+			TestName: "Duplicate column names",
+			Command:  "WITH j AS (SELECT id, withemp.id FROM withemp) SELECT * FROM j",
+			ExpErr:   "Error: Column \"id\" is defined more than once in j",
+		},
+		{
+			TestName: "Error in CTE",
+			Command:  "WITH j AS (SELECT id FROM notatable) SELECT * FROM j",
+			ExpErr:   "Error: Table \"notatable\" does not exist",
+		},
+		{
+			TestName: "Error in second CTE removes first",
+			Command:  "WITH j AS (SELECT id FROM withemp), sub AS (SELECT x FROM j) SELECT * FROM j",
+			ExpErr:   "Error: Column \"x\" not found in Table(s): j",
+		},
+		{
+			TestName: "Error in statement after CTEs",
+			Command:  "WITH j AS (SELECT id FROM withemp), sub AS (SELECT id FROM j) SELECT x FROM sub",
+			ExpErr:   "Error: Column \"x\" not found in Table(s): sub",
+		},
+		{
+			TestName: "Error in recursive part of CTE",
+			Command:  "WITH RECURSIVE j AS (SELECT id FROM withemp), r AS (SELECT 1 UNION SELECT x FROM r) SELECT * FROM j",
+			ExpErr:   "Error: Column \"x\" not found in Table(s): r",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testWithFunc(profile, row))
+
+	}
+	t.Run("CTE not visible after error", func(t *testing.T) {
+		for _, name := range []string{"j", "sub"} {
+			if tab := sqtables.GetTempTable(profile, name); tab != nil {
+				t.Errorf("Temporary table %s is still visible after WITH statement", name)
+			}
+		}
+	})
+}
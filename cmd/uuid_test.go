@@ -0,0 +1,133 @@
+package cmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/sq"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtypes"
+)
+
+func TestUUID(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	// Make sure datasets are by default in RowID order
+	sqtables.RowOrder = true
+
+	err := sq.ProcessSQFile("./testdata/uuidtests.sq")
+	if err != nil {
+		t.Fatalf("Unable to load test data: %s", err)
+	}
+
+	data := []SelectData{
+		{
+			TestName: "Select uuid column",
+			Command:  "SELECT id, STRING(ext) FROM accounts_ext WHERE id < 3",
+			ExpRows:  2,
+			ExpCols:  []string{"id", "STRING(ext)"},
+			ExpVals:  sqtypes.RawVals{{1, "b0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"}, {2, "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"}},
+		},
+		{
+			TestName: "Compare with uuid literal",
+			Command:  "SELECT id, ext = UUID \"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11\" FROM accounts_ext WHERE id < 3",
+			ExpRows:  2,
+			ExpCols:  []string{"id", "(ext=a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11)"},
+			ExpVals:  sqtypes.RawVals{{1, false}, {2, true}},
+		},
+		{
+			TestName: "Order by uuid",
+			Command:  "SELECT name, ext FROM accounts_ext WHERE id < 3 ORDER BY ext",
+			ExpRows:  2,
+			ExpCols:  []string{"name", "ext"},
+			ExpVals: sqtypes.RawVals{
+				{"alpha", sqtypes.SQUUID{Val: [16]byte{0xa0, 0xee, 0xbc, 0x99, 0x9c, 0x0b, 0x4e, 0xf8, 0xbb, 0x6d, 0x6b, 0xb9, 0xbd, 0x38, 0x0a, 0x11}}},
+				{"beta", sqtypes.SQUUID{Val: [16]byte{0xb0, 0xee, 0xbc, 0x99, 0x9c, 0x0b, 0x4e, 0xf8, 0xbb, 0x6d, 0x6b, 0xb9, 0xbd, 0x38, 0x0a, 0x11}}},
+			},
+		},
+		{
+			TestName: "Random uuids are different",
+			Command:  "SELECT COUNT(DISTINCT ext) FROM accounts_ext WHERE id != 3",
+			ExpRows:  1,
+			ExpCols:  []string{"COUNT(DISTINCT ext)"},
+			ExpVals:  sqtypes.RawVals{{3}},
+		},
+		{
+			TestName: "Each call is a new uuid",
+			Command:  "SELECT id, GEN_RANDOM_UUID() = GEN_RANDOM_UUID() FROM accounts_ext WHERE id = 1",
+			ExpRows:  1,
+			ExpCols:  []string{"id", "(GEN_RANDOM_UUID()=GEN_RANDOM_UUID())"},
+			ExpVals:  sqtypes.RawVals{{1, false}},
+		},
+		{
+			TestName: "Convert uuid",
+			Command:  "SELECT BLOB(ext), UUID(BLOB(ext)) = ext, UUID(\"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11\") = ext FROM accounts_ext WHERE id = 2",
+			ExpRows:  1,
+			ExpCols:  []string{"BLOB(ext)", "(UUID(BLOB(ext))=ext)", "(a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11=ext)"},
+			ExpVals: sqtypes.RawVals{
+				{[]byte{0xa0, 0xee, 0xbc, 0x99, 0x9c, 0x0b, 0x4e, 0xf8, 0xbb, 0x6d, 0x6b, 0xb9, 0xbd, 0x38, 0x0a, 0x11}, true, true},
+			},
+		},
+		{
+			TestName: "Invalid uuid literal",
+			Command:  "SELECT id FROM accounts_ext WHERE ext = UUID \"a0eebc99\"",
+			ExpErr:   "Error: Unable to Convert \"a0eebc99\" to a UUID",
+		},
+		{
+			TestName: "Compare uuid with string",
+			Command:  "SELECT id FROM accounts_ext WHERE ext = \"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11\"",
+			ExpRows:  1,
+			ExpCols:  []string{"id"},
+			ExpVals:  sqtypes.RawVals{{2}},
+		},
+		{
+			TestName: "Compare string with uuid",
+			Command:  "SELECT id FROM accounts_ext WHERE \"b0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11\" != ext ORDER BY id",
+			ExpRows:  2,
+			ExpCols:  []string{"id"},
+			ExpVals:  sqtypes.RawVals{{2}, {4}},
+		},
+		{
+			TestName: "Compare uuid with invalid string",
+			Command:  "SELECT id FROM accounts_ext WHERE ext = \"a0eebc99\"",
+			ExpErr:   "Error: Unable to Convert \"a0eebc99\" to a UUID",
+		},
+		{
+			TestName: "Compare uuid expression with string",
+			Command:  "SELECT id FROM accounts_ext WHERE UUID(BLOB(ext)) = \"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11\"",
+			ExpErr:   "Error: Type Mismatch: a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11 is not a UUID",
+		},
+		{
+			TestName: "Insert uuid string",
+			Command:  "SELECT STRING(id) FROM sessions WHERE name = \"string\"",
+			ExpRows:  1,
+			ExpCols:  []string{"STRING(id)"},
+			ExpVals:  sqtypes.RawVals{{"c0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"}},
+		},
+		{
+			TestName: "gen_random_uuid with argument",
+			Command:  "SELECT GEN_RANDOM_UUID(id) FROM accounts_ext",
+			ExpErr:   "Syntax Error: \"GEN_RANDOM_UUID\" is not a valid function",
+		},
+		{
+			TestName: "gen_random_uuid as a default",
+			Command:  "SELECT COUNT(DISTINCT id), COUNT() FROM sessions",
+			ExpRows:  1,
+			ExpCols:  []string{"COUNT(DISTINCT id)", "COUNT()"},
+			ExpVals:  sqtypes.RawVals{{6, 6}},
+		},
+		{
+			TestName: "Default is not used when a value is given",
+			Command:  "SELECT name FROM sessions WHERE id = UUID \"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11\"",
+			ExpRows:  1,
+			ExpCols:  []string{"name"},
+			ExpVals:  sqtypes.RawVals{{"fixed"}},
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testSelectFunc(profile, row))
+
+	}
+}
@@ -22,6 +22,24 @@ const (
 	TMUpdateRows
 	TMDeleteRows
 	TMDropDDL
+	TMCreateView
+	TMDropView
+	TMCreateSequence
+	TMDropSequence
+	TMSetSequence
+	TMAlterTable
+	TMTruncateTable
+)
+
+// Types of change to a table recorded by an AlterTable statement
+const (
+	AlterAddColumn = iota + 1
+	AlterDropColumn
+	AlterRenameColumn
+	AlterColumnType
+	AlterAddCheck
+	AlterDropConstraint
+	AlterRenameTable
 )
 
 func init() {
@@ -30,6 +48,18 @@ func init() {
 	sqbin.RegisterType("TMUpdateRows", TMUpdateRows)
 	sqbin.RegisterType("TMDeleteRows", TMDeleteRows)
 	sqbin.RegisterType("TMDropDDL", TMDropDDL)
+	sqbin.RegisterType("TMCreateView", TMCreateView)
+	sqbin.RegisterType("TMDropView", TMDropView)
+	sqbin.RegisterType("TMCreateSequence", TMCreateSequence)
+	sqbin.RegisterType("TMDropSequence", TMDropSequence)
+	sqbin.RegisterType("TMSetSequence", TMSetSequence)
+	sqbin.RegisterType("TMAlterTable", TMAlterTable)
+	sqbin.RegisterType("TMTruncateTable", TMTruncateTable)
+
+	// Changes to the value of a sequence are recorded as they happen
+	sqtables.SequenceLog = func(name string, value int, isCalled bool) error {
+		return Send(NewSetSequence(name, value, isCalled))
+	}
 }
 
 // LogStatement - Interface to represent each type of redo statement
@@ -46,10 +76,12 @@ func CreateLogMsg(resp chan error, stmt LogStatement) LogMsg {
 	return LogMsg{buffer: enc.Bytes(), respond: resp}
 }
 
-// CreateDDL - Transaction Recording for Create Statement
+// CreateDDL - Transaction Recording for Create Statement. Checks are the CHECK constraints of the
+//   table stored as the SQL text of their expressions
 type CreateDDL struct {
 	TableName string
 	Cols      []column.Def
+	Checks    []sqtables.DBCheck
 }
 
 // Encode uses sqbin.Codec to return a binary encoded version of the statement
@@ -62,6 +94,11 @@ func (c *CreateDDL) Encode() *sqbin.Codec {
 
 	enc.WriteString(c.TableName)
 	encColDef(enc, c.Cols)
+	enc.WriteInt(len(c.Checks))
+	for _, chk := range c.Checks {
+		enc.WriteString(chk.Name)
+		enc.WriteString(chk.Text)
+	}
 	return enc
 }
 
@@ -71,12 +108,41 @@ func (c *CreateDDL) Decode(dec *sqbin.Codec) {
 
 	c.TableName = dec.ReadString()
 	c.Cols = decColDef(dec)
+
+	// Statements logged before CHECK constraints were recorded end after the columns
+	if dec.Len() == 0 {
+		return
+	}
+	n := dec.ReadInt()
+	if n > 0 {
+		c.Checks = make([]sqtables.DBCheck, n)
+	}
+	for i := 0; i < n; i++ {
+		c.Checks[i].Name = dec.ReadString()
+		c.Checks[i].Text = dec.ReadString()
+	}
 }
 
 // Recreate - reprocess the recorded transaction log SQL statement to restore the database
 func (c *CreateDDL) Recreate(profile *sqprofile.SQProfile) error {
 
 	table := sqtables.CreateTableDef(c.TableName, c.Cols)
+
+	// CHECK constraints are recreated from the SQL text of their expressions
+	var cons []sqtables.Constraint
+	for _, dbCheck := range c.Checks {
+		chk, err := sqtables.ParseCheck(dbCheck.Name, dbCheck.Text)
+		if err != nil {
+			return err
+		}
+		cons = append(cons, chk)
+	}
+	if len(cons) > 0 {
+		err := table.AddConstraints(profile, cons)
+		if err != nil {
+			return err
+		}
+	}
 	err := sqtables.CreateTable(profile, table)
 
 	profile.VerifyNoLocks()
@@ -88,9 +154,9 @@ func (c *CreateDDL) Identify(ID uint64) string {
 	return fmt.Sprintf("#%d - CREATE TABLE %s", ID, c.TableName)
 }
 
-// NewCreateDDL returns a logstatement that is a CREATE TABLE
-func NewCreateDDL(name string, cols []column.Def) *CreateDDL {
-	return &CreateDDL{TableName: name, Cols: cols}
+// NewCreateDDL returns a logstatement that is a CREATE TABLE with the given CHECK constraints
+func NewCreateDDL(name string, cols []column.Def, checks ...sqtables.DBCheck) *CreateDDL {
+	return &CreateDDL{TableName: name, Cols: cols, Checks: checks}
 }
 
 // InsertRows - Redo recording for Insert statement
@@ -335,6 +401,20 @@ func DecodeStatement(dec *sqbin.Codec) LogStatement {
 		stmt = &DeleteRows{}
 	case TMDropDDL:
 		stmt = &DropDDL{}
+	case TMCreateView:
+		stmt = &CreateView{}
+	case TMDropView:
+		stmt = &DropView{}
+	case TMCreateSequence:
+		stmt = &CreateSequence{}
+	case TMDropSequence:
+		stmt = &DropSequence{}
+	case TMSetSequence:
+		stmt = &SetSequence{}
+	case TMAlterTable:
+		stmt = &AlterTable{}
+	case TMTruncateTable:
+		stmt = &TruncateTable{}
 	default:
 		if DecodeStatementHook != nil {
 			stmt = DecodeStatementHook(tm)
@@ -434,3 +514,372 @@ func (d *DropDDL) Identify(ID uint64) string {
 func NewDropDDL(name string) *DropDDL {
 	return &DropDDL{TableName: name}
 }
+
+// CreateView - Transaction Recording for Create View Statement
+type CreateView struct {
+	ViewName string
+	Cols     []string
+	Query    string
+}
+
+// Encode uses sqbin.Codec to return a binary encoded version of the statement
+func (c *CreateView) Encode() *sqbin.Codec {
+	enc := sqbin.NewCodec(nil)
+	// Identify the type of logstatment
+	enc.WriteTypeMarker(TMCreateView)
+
+	enc.WriteString(c.ViewName)
+	enc.WriteBool(c.Cols != nil)
+	if c.Cols != nil {
+		enc.WriteArrayString(c.Cols)
+	}
+	enc.WriteString(c.Query)
+	return enc
+}
+
+// Decode uses sqbin.Codec to return a binary encoded version of the statement
+func (c *CreateView) Decode(dec *sqbin.Codec) {
+	dec.ReadTypeMarker(TMCreateView)
+
+	c.ViewName = dec.ReadString()
+	c.Cols = nil
+	if dec.ReadBool() {
+		c.Cols = dec.ReadArrayString()
+	}
+	c.Query = dec.ReadString()
+}
+
+// Recreate - reprocess the recorded transaction log SQL statement to restore the database
+func (c *CreateView) Recreate(profile *sqprofile.SQProfile) error {
+
+	err := sqtables.CreateView(profile, sqtables.CreateViewDef(c.ViewName, c.Cols, c.Query))
+
+	profile.VerifyNoLocks()
+	return err
+}
+
+// Identify - returns a short string to identify the transaction log statement
+func (c *CreateView) Identify(ID uint64) string {
+	return fmt.Sprintf("#%d - CREATE VIEW %s", ID, c.ViewName)
+}
+
+// NewCreateView returns a logstatement that is a CREATE VIEW
+func NewCreateView(name string, cols []string, query string) *CreateView {
+	return &CreateView{ViewName: name, Cols: cols, Query: query}
+}
+
+// DropView - Transaction Recording for Drop View Statement
+type DropView struct {
+	ViewName string
+}
+
+// Encode uses sqbin.Codec to return a binary encoded version of the statement
+func (d *DropView) Encode() *sqbin.Codec {
+	enc := sqbin.NewCodec(nil)
+	// Identify the type of logstatment
+	enc.WriteTypeMarker(TMDropView)
+
+	enc.WriteString(d.ViewName)
+	return enc
+}
+
+// Decode uses sqbin.Codec to return a binary encoded version of the statement
+func (d *DropView) Decode(dec *sqbin.Codec) {
+	dec.ReadTypeMarker(TMDropView)
+
+	d.ViewName = dec.ReadString()
+}
+
+// Recreate - reprocess the recorded transaction log SQL statement to restore the database
+func (d *DropView) Recreate(profile *sqprofile.SQProfile) error {
+
+	err := sqtables.DropView(profile, d.ViewName)
+
+	profile.VerifyNoLocks()
+	return err
+}
+
+// Identify - returns a short string to identify the transaction log statement
+func (d *DropView) Identify(ID uint64) string {
+	return fmt.Sprintf("#%d - DROP VIEW %s", ID, d.ViewName)
+}
+
+// NewDropView returns a logstatement that is a DROP VIEW
+func NewDropView(name string) *DropView {
+	return &DropView{ViewName: name}
+}
+
+// CreateSequence - Transaction Recording for Create Sequence Statement
+type CreateSequence struct {
+	SeqName   string
+	Start     int
+	Increment int
+	Owner     string
+}
+
+// Encode uses sqbin.Codec to return a binary encoded version of the statement
+func (c *CreateSequence) Encode() *sqbin.Codec {
+	enc := sqbin.NewCodec(nil)
+	// Identify the type of logstatment
+	enc.WriteTypeMarker(TMCreateSequence)
+
+	enc.WriteString(c.SeqName)
+	enc.WriteInt(c.Start)
+	enc.WriteInt(c.Increment)
+	enc.WriteString(c.Owner)
+	return enc
+}
+
+// Decode uses sqbin.Codec to return a binary encoded version of the statement
+func (c *CreateSequence) Decode(dec *sqbin.Codec) {
+	dec.ReadTypeMarker(TMCreateSequence)
+
+	c.SeqName = dec.ReadString()
+	c.Start = dec.ReadInt()
+	c.Increment = dec.ReadInt()
+	c.Owner = dec.ReadString()
+}
+
+// Recreate - reprocess the recorded transaction log SQL statement to restore the database
+func (c *CreateSequence) Recreate(profile *sqprofile.SQProfile) error {
+
+	err := sqtables.CreateSequence(profile, sqtables.CreateSequenceDef(c.SeqName, c.Start, c.Increment, c.Owner))
+
+	profile.VerifyNoLocks()
+	return err
+}
+
+// Identify - returns a short string to identify the transaction log statement
+func (c *CreateSequence) Identify(ID uint64) string {
+	return fmt.Sprintf("#%d - CREATE SEQUENCE %s", ID, c.SeqName)
+}
+
+// NewCreateSequence returns a logstatement that is a CREATE SEQUENCE
+func NewCreateSequence(name string, start, increment int, owner string) *CreateSequence {
+	return &CreateSequence{SeqName: name, Start: start, Increment: increment, Owner: owner}
+}
+
+// DropSequence - Transaction Recording for Drop Sequence Statement
+type DropSequence struct {
+	SeqName string
+}
+
+// Encode uses sqbin.Codec to return a binary encoded version of the statement
+func (d *DropSequence) Encode() *sqbin.Codec {
+	enc := sqbin.NewCodec(nil)
+	// Identify the type of logstatment
+	enc.WriteTypeMarker(TMDropSequence)
+
+	enc.WriteString(d.SeqName)
+	return enc
+}
+
+// Decode uses sqbin.Codec to return a binary encoded version of the statement
+func (d *DropSequence) Decode(dec *sqbin.Codec) {
+	dec.ReadTypeMarker(TMDropSequence)
+
+	d.SeqName = dec.ReadString()
+}
+
+// Recreate - reprocess the recorded transaction log SQL statement to restore the database
+func (d *DropSequence) Recreate(profile *sqprofile.SQProfile) error {
+
+	err := sqtables.DropSequence(profile, d.SeqName)
+
+	profile.VerifyNoLocks()
+	return err
+}
+
+// Identify - returns a short string to identify the transaction log statement
+func (d *DropSequence) Identify(ID uint64) string {
+	return fmt.Sprintf("#%d - DROP SEQUENCE %s", ID, d.SeqName)
+}
+
+// NewDropSequence returns a logstatement that is a DROP SEQUENCE
+func NewDropSequence(name string) *DropSequence {
+	return &DropSequence{SeqName: name}
+}
+
+// SetSequence - Transaction Recording for a change to the value of a sequence. NEXTVAL records
+//   the end of each block of values it reserves so a recovered sequence never repeats a value
+type SetSequence struct {
+	SeqName  string
+	Value    int
+	IsCalled bool
+}
+
+// Encode uses sqbin.Codec to return a binary encoded version of the statement
+func (s *SetSequence) Encode() *sqbin.Codec {
+	enc := sqbin.NewCodec(nil)
+	// Identify the type of logstatment
+	enc.WriteTypeMarker(TMSetSequence)
+
+	enc.WriteString(s.SeqName)
+	enc.WriteInt(s.Value)
+	enc.WriteBool(s.IsCalled)
+	return enc
+}
+
+// Decode uses sqbin.Codec to return a binary encoded version of the statement
+func (s *SetSequence) Decode(dec *sqbin.Codec) {
+	dec.ReadTypeMarker(TMSetSequence)
+
+	s.SeqName = dec.ReadString()
+	s.Value = dec.ReadInt()
+	s.IsCalled = dec.ReadBool()
+}
+
+// Recreate - reprocess the recorded transaction log SQL statement to restore the database
+func (s *SetSequence) Recreate(profile *sqprofile.SQProfile) error {
+
+	seq, err := sqtables.GetSequence(profile, s.SeqName)
+	if err == nil {
+		if seq == nil {
+			err = sqerr.Newf("Sequence %s does not exist", s.SeqName)
+		} else {
+			seq.SetState(s.Value, s.IsCalled)
+		}
+	}
+
+	profile.VerifyNoLocks()
+	return err
+}
+
+// Identify - returns a short string to identify the transaction log statement
+func (s *SetSequence) Identify(ID uint64) string {
+	return fmt.Sprintf("#%d - SETVAL %s %d", ID, s.SeqName, s.Value)
+}
+
+// NewSetSequence returns a logstatement that sets the value of a sequence
+func NewSetSequence(name string, value int, isCalled bool) *SetSequence {
+	return &SetSequence{SeqName: name, Value: value, IsCalled: isCalled}
+}
+
+// AlterTable - Transaction Recording for Alter Table Statement. Action is the type of change.
+//   Name is the column or constraint that is changed and NewName is its new name for a rename.
+//   Col is the definition of an added column or the new type of a column and Text is the SQL
+//   text of an added CHECK constraint
+type AlterTable struct {
+	TableName string
+	Action    int
+	Name      string
+	NewName   string
+	Text      string
+	Col       column.Def
+}
+
+// Encode uses sqbin.Codec to return a binary encoded version of the statement
+func (a *AlterTable) Encode() *sqbin.Codec {
+	enc := sqbin.NewCodec(nil)
+	// Identify the type of logstatment
+	enc.WriteTypeMarker(TMAlterTable)
+
+	enc.WriteString(a.TableName)
+	enc.WriteInt(a.Action)
+	enc.WriteString(a.Name)
+	enc.WriteString(a.NewName)
+	enc.WriteString(a.Text)
+	a.Col.Encode(enc)
+	return enc
+}
+
+// Decode uses sqbin.Codec to return a binary encoded version of the statement
+func (a *AlterTable) Decode(dec *sqbin.Codec) {
+	dec.ReadTypeMarker(TMAlterTable)
+
+	a.TableName = dec.ReadString()
+	a.Action = dec.ReadInt()
+	a.Name = dec.ReadString()
+	a.NewName = dec.ReadString()
+	a.Text = dec.ReadString()
+	a.Col.Decode(dec)
+}
+
+// Recreate - reprocess the recorded transaction log SQL statement to restore the database
+func (a *AlterTable) Recreate(profile *sqprofile.SQProfile) error {
+	// make sure there is a valid table
+	tab, err := sqtables.GetTable(profile, a.TableName)
+	if err != nil {
+		return err
+	}
+	if tab == nil {
+		return sqerr.New("Table " + a.TableName + " does not exist")
+	}
+
+	switch a.Action {
+	case AlterAddColumn:
+		err = tab.AddColumn(profile, a.Col)
+	case AlterDropColumn:
+		err = tab.DropColumn(profile, a.Name)
+	case AlterRenameColumn:
+		err = tab.RenameColumn(profile, a.Name, a.NewName)
+	case AlterColumnType:
+		err = tab.AlterColumnType(profile, a.Col)
+	case AlterAddCheck:
+		var chk sqtables.Constraint
+		chk, err = sqtables.ParseCheck(a.Name, a.Text)
+		if err == nil {
+			err = tab.AddConstraint(profile, chk)
+		}
+	case AlterDropConstraint:
+		err = tab.DropConstraint(profile, a.Name)
+	case AlterRenameTable:
+		err = sqtables.RenameTable(profile, a.TableName, a.NewName)
+	default:
+		err = sqerr.NewInternalf("Unknown ALTER TABLE action %d", a.Action)
+	}
+
+	profile.VerifyNoLocks()
+	return err
+}
+
+// Identify - returns a short string to identify the transaction log statement
+func (a *AlterTable) Identify(ID uint64) string {
+	return fmt.Sprintf("#%d - ALTER TABLE %s", ID, a.TableName)
+}
+
+// NewAlterTable returns a logstatement that is an ALTER TABLE
+func NewAlterTable(tableName string, action int, name, newName, text string, col column.Def) *AlterTable {
+	return &AlterTable{TableName: tableName, Action: action, Name: name, NewName: newName, Text: text, Col: col}
+}
+
+// TruncateTable - Transaction Recording for Truncate Table Statement
+type TruncateTable struct {
+	TableName string
+}
+
+// Encode uses sqbin.Codec to return a binary encoded version of the statement
+func (t *TruncateTable) Encode() *sqbin.Codec {
+	enc := sqbin.NewCodec(nil)
+	// Identify the type of logstatment
+	enc.WriteTypeMarker(TMTruncateTable)
+
+	enc.WriteString(t.TableName)
+	return enc
+}
+
+// Decode uses sqbin.Codec to return a binary encoded version of the statement
+func (t *TruncateTable) Decode(dec *sqbin.Codec) {
+	dec.ReadTypeMarker(TMTruncateTable)
+
+	t.TableName = dec.ReadString()
+}
+
+// Recreate - reprocess the recorded transaction log SQL statement to restore the database
+func (t *TruncateTable) Recreate(profile *sqprofile.SQProfile) error {
+
+	err := sqtables.TruncateTable(profile, t.TableName)
+
+	profile.VerifyNoLocks()
+	return err
+}
+
+// Identify - returns a short string to identify the transaction log statement
+func (t *TruncateTable) Identify(ID uint64) string {
+	return fmt.Sprintf("#%d - TRUNCATE TABLE %s", ID, t.TableName)
+}
+
+// NewTruncateTable returns a logstatement that is a TRUNCATE TABLE
+func NewTruncateTable(name string) *TruncateTable {
+	return &TruncateTable{TableName: name}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/wilphi/assertions"
 	"github.com/wilphi/sqsrv/cmd"
 	"github.com/wilphi/sqsrv/redo"
+	"github.com/wilphi/sqsrv/sqbin"
 	"github.com/wilphi/sqsrv/sqprofile"
 	"github.com/wilphi/sqsrv/sqptr"
 	"github.com/wilphi/sqsrv/sqtables"
@@ -24,6 +25,8 @@ type CreateData struct {
 	ID        uint64
 	identstr  string
 	Cols      []column.Def
+	Checks    []sqtables.DBCheck
+	ExpStr    string
 }
 
 func TestCreate(t *testing.T) {
@@ -38,6 +41,27 @@ func TestCreate(t *testing.T) {
 			},
 			ID: 123,
 		},
+		{
+			TestName:  "Recreate table with defaults from redo",
+			TableName: "RedoCreateDefault",
+			Cols: []column.Def{
+				{ColName: "col1", ColType: tokens.Int, Idx: 1, IsNotNull: true, Default: "5"},
+				{ColName: "col2", ColType: tokens.UUID, Idx: 2, IsNotNull: false, Default: "GEN_RANDOM_UUID ( )"},
+			},
+			ID: 124,
+		},
+		{
+			TestName:  "Recreate table with CHECK constraints from redo",
+			TableName: "redocreatecheck",
+			Cols: []column.Def{
+				{ColName: "col1", ColType: tokens.Int, Idx: 1, IsNotNull: true},
+				{ColName: "col2", ColType: tokens.String, Idx: 2, IsNotNull: false},
+			},
+			Checks: []sqtables.DBCheck{{Name: "col1_pos", Text: "col1 > 0"}, {Name: "col2_len", Text: "LENGTH ( col2 ) < 5"}},
+			ExpStr: "redocreatecheck\n--------------------------------------\n\t{col1, INT NOT NULL}\n\t{col2, STRING}\n" +
+				"--------------------------------------\n\tCONSTRAINT col1_pos CHECK (col1 > 0)\n\tCONSTRAINT col2_len CHECK (LENGTH ( col2 ) < 5)\n",
+			ID: 125,
+		},
 	}
 
 	for i, row := range data {
@@ -51,7 +75,7 @@ func testCreateFunc(d CreateData) func(*testing.T) {
 	return func(t *testing.T) {
 		defer sqtest.PanicTestRecovery(t, "")
 
-		s := redo.NewCreateDDL(d.TableName, d.Cols)
+		s := redo.NewCreateDDL(d.TableName, d.Cols, d.Checks...)
 
 		// Test identity string
 		idstr := fmt.Sprintf("#%d - CREATE TABLE %s", d.ID, d.TableName)
@@ -112,6 +136,28 @@ func testCreateFunc(d CreateData) func(*testing.T) {
 			t.Error("Columns do not match expected")
 			return
 		}
+		if d.ExpStr != "" && tab.String(profile) != d.ExpStr {
+			t.Errorf("Recreated table did not match expected: \nActual: %s\nExpected: %s", tab.String(profile), d.ExpStr)
+		}
+	}
+}
+
+func TestCreateWithoutChecks(t *testing.T) {
+	defer sqtest.PanicTestRecovery(t, "")
+
+	// A CREATE TABLE logged before CHECK constraints were recorded ends after the columns
+	cols := []column.Def{{ColName: "col1", ColType: tokens.Int, Idx: 1, IsNotNull: false}}
+	enc := sqbin.NewCodec(nil)
+	enc.WriteTypeMarker(redo.TMCreateDDL)
+	enc.WriteString("redocreateold")
+	enc.WriteInt(len(cols))
+	for _, col := range cols {
+		col.Encode(enc)
+	}
+
+	res := redo.DecodeStatement(enc)
+	if !reflect.DeepEqual(res, redo.NewCreateDDL("redocreateold", cols)) {
+		t.Error("Decoded Statement does not match initial values")
 	}
 }
 
@@ -677,6 +723,503 @@ func testDropTableFunc(d DropTableData) func(*testing.T) {
 	}
 }
 
+type ViewData struct {
+	TestName  string
+	Stmt      redo.LogStatement
+	ViewName  string
+	ID        uint64
+	Identstr  string
+	ExpExists bool
+	ExpErr    string
+}
+
+func TestViews(t *testing.T) {
+	data := []ViewData{
+		{
+			TestName:  "Recreate CREATE VIEW from redo",
+			Stmt:      redo.NewCreateView("testredoview", nil, "SELECT col1 FROM testredoview_t"),
+			ViewName:  "testredoview",
+			ID:        123,
+			Identstr:  "#123 - CREATE VIEW testredoview",
+			ExpExists: true,
+		},
+		{
+			TestName:  "Recreate CREATE VIEW with cols from redo",
+			Stmt:      redo.NewCreateView("testredoview2", []string{"a", "b"}, "SELECT col1 , col2 FROM testredoview_t"),
+			ViewName:  "testredoview2",
+			ID:        124,
+			Identstr:  "#124 - CREATE VIEW testredoview2",
+			ExpExists: true,
+		},
+		{
+			TestName:  "Recreate CREATE VIEW duplicate",
+			Stmt:      redo.NewCreateView("testredoview", nil, "SELECT col1 FROM testredoview_t"),
+			ViewName:  "testredoview",
+			ID:        125,
+			Identstr:  "#125 - CREATE VIEW testredoview",
+			ExpExists: true,
+			ExpErr:    "Error: Invalid Name: View testredoview already exists",
+		},
+		{
+			TestName: "Recreate DROP VIEW from redo",
+			Stmt:     redo.NewDropView("testredoview"),
+			ViewName: "testredoview",
+			ID:       126,
+			Identstr: "#126 - DROP VIEW testredoview",
+		},
+		{
+			TestName: "Recreate DROP VIEW invalid view",
+			Stmt:     redo.NewDropView("testredoview"),
+			ViewName: "testredoview",
+			ID:       127,
+			Identstr: "#127 - DROP VIEW testredoview",
+			ExpErr:   "Error: Invalid Name: View testredoview does not exist",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testViewFunc(row))
+
+	}
+}
+
+func testViewFunc(d ViewData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		// Test Identify
+		if d.Identstr != d.Stmt.Identify(d.ID) {
+			t.Errorf("Identity string (%s) does not match expected (%s)", d.Stmt.Identify(d.ID), d.Identstr)
+			return
+		}
+
+		// test DecodeStatment
+		cdr := d.Stmt.Encode()
+		resStmt := redo.DecodeStatement(cdr)
+		if !reflect.DeepEqual(d.Stmt, resStmt) {
+			t.Error("Decoded Statement does not match initial values")
+			return
+		}
+
+		// Test recreate
+		profile := sqprofile.CreateSQProfile()
+		err := d.Stmt.Recreate(profile)
+		// When an error is expected the catalog is still checked to make sure it has not changed
+		if sqtest.CheckErr(t, err, d.ExpErr) && d.ExpErr == "" {
+			return
+		}
+
+		v, err := sqtables.GetView(profile, d.ViewName)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if (v != nil) != d.ExpExists {
+			t.Errorf("View %s exists = %t, expected %t", d.ViewName, v != nil, d.ExpExists)
+			return
+		}
+	}
+}
+
+type SequenceData struct {
+	TestName  string
+	Stmt      redo.LogStatement
+	SeqName   string
+	ID        uint64
+	Identstr  string
+	ExpExists bool
+	ExpValue  int
+	ExpCalled bool
+	ExpErr    string
+}
+
+func TestSequences(t *testing.T) {
+	data := []SequenceData{
+		{
+			TestName:  "Recreate CREATE SEQUENCE from redo",
+			Stmt:      redo.NewCreateSequence("testredoseq", 10, 5, ""),
+			SeqName:   "testredoseq",
+			ID:        130,
+			Identstr:  "#130 - CREATE SEQUENCE testredoseq",
+			ExpExists: true,
+			ExpValue:  10,
+		},
+		{
+			TestName:  "Recreate CREATE SEQUENCE with owner from redo",
+			Stmt:      redo.NewCreateSequence("testredoseq_t_id_seq", 1, 1, "testredoseq_t"),
+			SeqName:   "testredoseq_t_id_seq",
+			ID:        131,
+			Identstr:  "#131 - CREATE SEQUENCE testredoseq_t_id_seq",
+			ExpExists: true,
+			ExpValue:  1,
+		},
+		{
+			TestName:  "Recreate CREATE SEQUENCE duplicate",
+			Stmt:      redo.NewCreateSequence("testredoseq", 1, 1, ""),
+			SeqName:   "testredoseq",
+			ID:        132,
+			Identstr:  "#132 - CREATE SEQUENCE testredoseq",
+			ExpExists: true,
+			ExpValue:  10,
+			ExpErr:    "Error: Invalid Name: Sequence testredoseq already exists",
+		},
+		{
+			TestName:  "Recreate SETVAL from redo",
+			Stmt:      redo.NewSetSequence("testredoseq", 165, true),
+			SeqName:   "testredoseq",
+			ID:        133,
+			Identstr:  "#133 - SETVAL testredoseq 165",
+			ExpExists: true,
+			ExpValue:  165,
+			ExpCalled: true,
+		},
+		{
+			TestName: "Recreate SETVAL invalid sequence",
+			Stmt:     redo.NewSetSequence("testredonoseq", 5, false),
+			SeqName:  "testredonoseq",
+			ID:       134,
+			Identstr: "#134 - SETVAL testredonoseq 5",
+			ExpErr:   "Error: Sequence testredonoseq does not exist",
+		},
+		{
+			TestName: "Recreate DROP SEQUENCE from redo",
+			Stmt:     redo.NewDropSequence("testredoseq"),
+			SeqName:  "testredoseq",
+			ID:       135,
+			Identstr: "#135 - DROP SEQUENCE testredoseq",
+		},
+		{
+			TestName: "Recreate DROP SEQUENCE invalid sequence",
+			Stmt:     redo.NewDropSequence("testredoseq"),
+			SeqName:  "testredoseq",
+			ID:       136,
+			Identstr: "#136 - DROP SEQUENCE testredoseq",
+			ExpErr:   "Error: Invalid Name: Sequence testredoseq does not exist",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testSequenceFunc(row))
+
+	}
+}
+
+func testSequenceFunc(d SequenceData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		// Test Identify
+		if d.Identstr != d.Stmt.Identify(d.ID) {
+			t.Errorf("Identity string (%s) does not match expected (%s)", d.Stmt.Identify(d.ID), d.Identstr)
+			return
+		}
+
+		// test DecodeStatment
+		cdr := d.Stmt.Encode()
+		resStmt := redo.DecodeStatement(cdr)
+		if !reflect.DeepEqual(d.Stmt, resStmt) {
+			t.Error("Decoded Statement does not match initial values")
+			return
+		}
+
+		// Test recreate
+		profile := sqprofile.CreateSQProfile()
+		err := d.Stmt.Recreate(profile)
+		// When an error is expected the catalog is still checked to make sure it has not changed
+		if sqtest.CheckErr(t, err, d.ExpErr) && d.ExpErr == "" {
+			return
+		}
+
+		seq, err := sqtables.GetSequence(profile, d.SeqName)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if (seq != nil) != d.ExpExists {
+			t.Errorf("Sequence %s exists = %t, expected %t", d.SeqName, seq != nil, d.ExpExists)
+			return
+		}
+		if seq != nil {
+			value, isCalled := seq.GetState()
+			if value != d.ExpValue || isCalled != d.ExpCalled {
+				t.Errorf("Sequence %s state (%d, %t) does not match expected (%d, %t)", d.SeqName, value, isCalled, d.ExpValue, d.ExpCalled)
+			}
+		}
+	}
+}
+
+type AlterTableData struct {
+	TestName  string
+	Stmt      redo.LogStatement
+	TableName string
+	ID        uint64
+	Identstr  string
+	ExpStr    string
+	ExpErr    string
+}
+
+func TestAlterTable(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	cols := []column.Def{
+		column.NewDef("col1", tokens.Int, true),
+		column.NewDef("col2", tokens.String, false),
+	}
+	s := redo.NewCreateDDL("testredoalter", cols)
+	if s.Recreate(profile) != nil {
+		t.Error("Error in data setup for TestAlterTable")
+		return
+	}
+	ins := redo.NewInsertRows("testredoalter", []string{"col1", "col2"}, sqtypes.CreateValuesFromRaw(sqtypes.RawVals{{1, "a"}, {2, "b"}}), sqptr.SQPtrs{1, 2})
+	if ins.Recreate(profile) != nil {
+		t.Error("Error in data setup for TestAlterTable")
+		return
+	}
+	newCol := column.NewDef("col3", tokens.Int, true)
+	newCol.Default = "7"
+
+	data := []AlterTableData{
+		{
+			TestName:  "Recreate ADD COLUMN from redo",
+			Stmt:      redo.NewAlterTable("testredoalter", redo.AlterAddColumn, "col3", "", "", newCol),
+			TableName: "testredoalter",
+			ID:        140,
+			Identstr:  "#140 - ALTER TABLE testredoalter",
+			ExpStr:    "testredoalter\n--------------------------------------\n\t{col1, INT NOT NULL}\n\t{col2, STRING}\n\t{col3, INT NOT NULL DEFAULT 7}\n",
+		},
+		{
+			TestName:  "Recreate ADD CHECK from redo",
+			Stmt:      redo.NewAlterTable("testredoalter", redo.AlterAddCheck, "col3_max", "", "col3 < 10", column.Def{}),
+			TableName: "testredoalter",
+			ID:        141,
+			Identstr:  "#141 - ALTER TABLE testredoalter",
+			ExpStr: "testredoalter\n--------------------------------------\n\t{col1, INT NOT NULL}\n\t{col2, STRING}\n\t{col3, INT NOT NULL DEFAULT 7}\n" +
+				"--------------------------------------\n\tCONSTRAINT col3_max CHECK (col3 < 10)\n",
+		},
+		{
+			TestName:  "Recreate RENAME COLUMN from redo",
+			Stmt:      redo.NewAlterTable("testredoalter", redo.AlterRenameColumn, "col3", "col4", "", column.Def{}),
+			TableName: "testredoalter",
+			ID:        142,
+			Identstr:  "#142 - ALTER TABLE testredoalter",
+			ExpStr: "testredoalter\n--------------------------------------\n\t{col1, INT NOT NULL}\n\t{col2, STRING}\n\t{col4, INT NOT NULL DEFAULT 7}\n" +
+				"--------------------------------------\n\tCONSTRAINT col3_max CHECK (col4 < 10)\n",
+		},
+		{
+			TestName:  "Recreate DROP CONSTRAINT from redo",
+			Stmt:      redo.NewAlterTable("testredoalter", redo.AlterDropConstraint, "col3_max", "", "", column.Def{}),
+			TableName: "testredoalter",
+			ID:        143,
+			Identstr:  "#143 - ALTER TABLE testredoalter",
+			ExpStr:    "testredoalter\n--------------------------------------\n\t{col1, INT NOT NULL}\n\t{col2, STRING}\n\t{col4, INT NOT NULL DEFAULT 7}\n",
+		},
+		{
+			TestName:  "Recreate ALTER COLUMN TYPE from redo",
+			Stmt:      redo.NewAlterTable("testredoalter", redo.AlterColumnType, "col1", "", "", column.NewDef("col1", tokens.String, false)),
+			TableName: "testredoalter",
+			ID:        144,
+			Identstr:  "#144 - ALTER TABLE testredoalter",
+			ExpStr:    "testredoalter\n--------------------------------------\n\t{col1, STRING NOT NULL}\n\t{col2, STRING}\n\t{col4, INT NOT NULL DEFAULT 7}\n",
+		},
+		{
+			TestName:  "Recreate DROP COLUMN from redo",
+			Stmt:      redo.NewAlterTable("testredoalter", redo.AlterDropColumn, "col2", "", "", column.Def{}),
+			TableName: "testredoalter",
+			ID:        145,
+			Identstr:  "#145 - ALTER TABLE testredoalter",
+			ExpStr:    "testredoalter\n--------------------------------------\n\t{col1, STRING NOT NULL}\n\t{col4, INT NOT NULL DEFAULT 7}\n",
+		},
+		{
+			TestName:  "Recreate RENAME TO from redo",
+			Stmt:      redo.NewAlterTable("testredoalter", redo.AlterRenameTable, "", "testredoalter2", "", column.Def{}),
+			TableName: "testredoalter2",
+			ID:        146,
+			Identstr:  "#146 - ALTER TABLE testredoalter",
+			ExpStr:    "testredoalter2\n--------------------------------------\n\t{col1, STRING NOT NULL}\n\t{col4, INT NOT NULL DEFAULT 7}\n",
+		},
+		{
+			TestName:  "Recreate ALTER TABLE invalid table",
+			Stmt:      redo.NewAlterTable("testredoalter", redo.AlterDropColumn, "col1", "", "", column.Def{}),
+			TableName: "testredoalter",
+			ID:        147,
+			Identstr:  "#147 - ALTER TABLE testredoalter",
+			ExpErr:    "Error: Table testredoalter does not exist",
+		},
+		{
+			TestName:  "Recreate ALTER TABLE invalid action",
+			Stmt:      redo.NewAlterTable("testredoalter2", 99, "col1", "", "", column.Def{}),
+			TableName: "testredoalter2",
+			ID:        148,
+			Identstr:  "#148 - ALTER TABLE testredoalter2",
+			ExpErr:    "Internal Error: Unknown ALTER TABLE action 99",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testAlterTableFunc(row))
+
+	}
+}
+
+func testAlterTableFunc(d AlterTableData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		// Test Identify
+		if d.Identstr != d.Stmt.Identify(d.ID) {
+			t.Errorf("Identity string (%s) does not match expected (%s)", d.Stmt.Identify(d.ID), d.Identstr)
+			return
+		}
+
+		// Test Encode/Decode
+		cdr := d.Stmt.Encode()
+		res := &redo.AlterTable{}
+		res.Decode(cdr)
+		if !reflect.DeepEqual(d.Stmt, res) {
+			t.Error("Encoding and then Decoding does not match values")
+			return
+		}
+
+		// test DecodeStatment
+		cdr = d.Stmt.Encode()
+		resStmt := redo.DecodeStatement(cdr)
+		if !reflect.DeepEqual(d.Stmt, resStmt) {
+			t.Error("Decoded Statement does not match initial values")
+			return
+		}
+
+		// Test recreate
+		profile := sqprofile.CreateSQProfile()
+		err := d.Stmt.Recreate(profile)
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+
+		tab, err := sqtables.GetTable(profile, d.TableName)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if tab == nil {
+			t.Errorf("Table %s does not exist", d.TableName)
+			return
+		}
+		if tab.String(profile) != d.ExpStr {
+			t.Errorf("Altered table did not match expected: \nActual: %s\nExpected: %s", tab.String(profile), d.ExpStr)
+		}
+	}
+}
+
+type TruncateTableData struct {
+	TestName  string
+	TableName string
+	ID        uint64
+	Identstr  string
+	ExpCount  int
+	ExpErr    string
+}
+
+func TestTruncateTable(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+	cols := []column.Def{
+		column.NewDef("col1", tokens.Int, true),
+		column.NewDef("col2", tokens.String, false),
+	}
+	s := redo.NewCreateDDL("testredotruncate", cols)
+	if s.Recreate(profile) != nil {
+		t.Error("Error in data setup for TestTruncateTable")
+		return
+	}
+	ins := redo.NewInsertRows("testredotruncate", []string{"col1", "col2"}, sqtypes.CreateValuesFromRaw(sqtypes.RawVals{{1, "a"}, {2, "b"}, {3, "c"}}), sqptr.SQPtrs{1, 2, 3})
+	if ins.Recreate(profile) != nil {
+		t.Error("Error in data setup for TestTruncateTable")
+		return
+	}
+
+	data := []TruncateTableData{
+		{
+			TestName:  "Recreate TRUNCATE TABLE from redo",
+			TableName: "testredotruncate",
+			ID:        150,
+			Identstr:  "#150 - TRUNCATE TABLE testredotruncate",
+			ExpCount:  0,
+		},
+		{
+			TestName:  "Recreate TRUNCATE TABLE empty table",
+			TableName: "testredotruncate",
+			ID:        151,
+			Identstr:  "#151 - TRUNCATE TABLE testredotruncate",
+			ExpCount:  0,
+		},
+		{
+			TestName:  "Recreate TRUNCATE TABLE invalid table",
+			TableName: "notatable",
+			ID:        152,
+			Identstr:  "#152 - TRUNCATE TABLE notatable",
+			ExpErr:    "Error: Invalid Name: Table notatable does not exist",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testTruncateTableFunc(row))
+
+	}
+}
+
+func testTruncateTableFunc(d TruncateTableData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		s := redo.NewTruncateTable(d.TableName)
+		// Test Identify
+		if d.Identstr != s.Identify(d.ID) {
+			t.Errorf("Identity string (%s) does not match expected (%s)", s.Identify(d.ID), d.Identstr)
+			return
+		}
+
+		// Test Encode/Decode
+		cdr := s.Encode()
+		res := &redo.TruncateTable{}
+		res.Decode(cdr)
+		if !reflect.DeepEqual(s, res) {
+			t.Error("Encoding and then Decoding does not match values")
+			return
+		}
+
+		// test DecodeStatment
+		cdr = s.Encode()
+		resStmt := redo.DecodeStatement(cdr)
+		if !reflect.DeepEqual(s, resStmt) {
+			t.Error("Decoded Statement does not match initial values")
+			return
+		}
+
+		// Test recreate
+		profile := sqprofile.CreateSQProfile()
+		err := s.Recreate(profile)
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+
+		tab, err := sqtables.GetTable(profile, d.TableName)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		cnt, err := tab.RawCount(profile)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if cnt != d.ExpCount {
+			t.Errorf("Truncated table has %d rows, expected %d", cnt, d.ExpCount)
+		}
+	}
+}
+
 func TestDecodeErr(t *testing.T) {
 	s := redo.NewDropDDL("ErrTest")
 	s2 := redo.NewDeleteRows("test", sqptr.SQPtrs{1, 2, 3})
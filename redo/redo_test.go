@@ -598,3 +598,44 @@ func TestTransProc(t *testing.T) {
 
 	})
 }
+
+func TestReadTlogCreateDropCreate(t *testing.T) {
+	defer sqtest.PanicTestRecovery(t, "")
+
+	profile := sqprofile.CreateSQProfile()
+	cols := []column.Def{{ColName: "col1", ColType: tokens.Int, Idx: 0}}
+	newCols := []column.Def{{ColName: "col1", ColType: tokens.String, Idx: 0}, {ColName: "col2", ColType: tokens.Int, Idx: 1}}
+	stmts := []LogStatement{
+		NewCreateDDL("tlogrecreate", cols),
+		NewDropDDL("tlogrecreate"),
+		NewCreateDDL("tlogrecreate", newCols),
+	}
+
+	transid.SetTransID(0)
+	enc := sqbin.NewCodec(nil)
+	for i, stmt := range stmts {
+		tmpenc := stmt.Encode()
+		enc.WriteUint64(uint64(i + 1))
+		enc.WriteInt64(int64(tmpenc.Len()))
+		enc.Write(tmpenc.Bytes())
+	}
+	err := ReadTlog(profile, bytes.NewBuffer(enc.Bytes()))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tab, err := sqtables.GetTable(profile, "tlogrecreate")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if tab == nil {
+		t.Error("Table tlogrecreate was not recreated")
+		return
+	}
+	colNames := tab.GetCols(profile).GetColNames()
+	if strings.Join(colNames, ",") != "col1,col2" {
+		t.Errorf("Columns %v of the recreated table do not match expected [col1 col2]", colNames)
+	}
+}
@@ -24,7 +24,15 @@ var dispatcher = []struct {
 	{Exec: cmd.Delete, First: tokens.Delete, Second: tokens.NilToken},
 	{Exec: cmd.CreateTable, First: tokens.Create, Second: tokens.Table},
 	{Exec: cmd.DropTable, First: tokens.Drop, Second: tokens.Table},
+	{Exec: cmd.AlterTable, First: tokens.Alter, Second: tokens.Table},
+	{Exec: cmd.TruncateTable, First: tokens.Truncate, Second: tokens.NilToken},
+	{Exec: cmd.CreateView, First: tokens.Create, Second: tokens.View},
+	{Exec: cmd.DropView, First: tokens.Drop, Second: tokens.View},
+	{Exec: cmd.CreateSequence, First: tokens.Create, Second: tokens.Sequence},
+	{Exec: cmd.DropSequence, First: tokens.Drop, Second: tokens.Sequence},
 	{Exec: cmd.Update, First: tokens.Update, Second: tokens.NilToken},
+	{Exec: cmd.Merge, First: tokens.Merge, Second: tokens.Into},
+	{Exec: cmd.With, First: tokens.With, Second: tokens.NilToken},
 }
 
 // ShutdownType -
@@ -230,6 +238,16 @@ func cmdLock(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (sqprotocol.R
 		tkn := tkns.Peek()
 		tableName := tkn.(*tokens.ValueToken).Value()
 		td, err := sqtables.GetTable(profile, tableName)
+		if td == nil && err == nil {
+			// Views do not have any rows to lock
+			var view *sqtables.ViewDef
+			view, err = sqtables.GetView(profile, tableName)
+			if view != nil {
+				resp.IsErr = true
+				resp.Msg = fmt.Sprintf("%s is a view, views can not be locked", view.GetName())
+				return resp, NoAction, nil
+			}
+		}
 		if td == nil || err != nil {
 			resp.IsErr = true
 			resp.Msg = "Table not found"
@@ -259,6 +277,16 @@ func cmdUnLock(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (sqprotocol
 		tkn := tkns.Peek()
 		tableName := tkn.(*tokens.ValueToken).Value()
 		td, err := sqtables.GetTable(profile, tableName)
+		if td == nil && err == nil {
+			// Views do not have any rows to unlock
+			var view *sqtables.ViewDef
+			view, err = sqtables.GetView(profile, tableName)
+			if view != nil {
+				resp.IsErr = true
+				resp.Msg = fmt.Sprintf("%s is a view, views can not be unlocked", view.GetName())
+				return resp, NoAction, nil
+			}
+		}
 		if td == nil || err != nil {
 			resp.IsErr = true
 			resp.Msg = "Table not found"
@@ -284,9 +312,17 @@ func cmdShowTables(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (sqprot
 		resp := sqprotocol.ResponseToClient{Msg: err.Error(), IsErr: true, HasData: false, NRows: 0, NCols: 0, CMDResponse: true}
 		return resp, NoAction, nil
 	}
-	str := "Table List\n--------------------------------------------\n"
+	views, err := sqtables.CatalogViews(profile)
+	if err != nil {
+		resp := sqprotocol.ResponseToClient{Msg: err.Error(), IsErr: true, HasData: false, NRows: 0, NCols: 0, CMDResponse: true}
+		return resp, NoAction, nil
+	}
+	str := "Table List\n---------------------------------------------------\n"
 	for i, tab := range tables {
-		str += fmt.Sprintf("  %-20s %20s\n", tab, format(rows[i]))
+		str += fmt.Sprintf("  %-20s %-6s %20s\n", tab, "TABLE", format(rows[i]))
+	}
+	for _, view := range views {
+		str += fmt.Sprintf("  %-20s %-6s %20s\n", view, "VIEW", "")
 	}
 	resp := sqprotocol.ResponseToClient{Msg: str, IsErr: false, HasData: false, NRows: 0, NCols: 0, CMDResponse: true}
 	return resp, NoAction, nil
@@ -300,6 +336,15 @@ func cmdShowTable(profile *sqprofile.SQProfile, tkns *tokens.TokenList) (sqproto
 		tkn := tkns.Peek()
 		tableName := tkn.(*tokens.ValueToken).Value()
 		td, err := sqtables.GetTable(profile, tableName)
+		if td == nil && err == nil {
+			// Views are shown as the statement that created them
+			var view *sqtables.ViewDef
+			view, err = sqtables.GetView(profile, tableName)
+			if view != nil {
+				resp.Msg = view.String()
+				return resp, NoAction, nil
+			}
+		}
 		if td == nil || err != nil {
 			resp.IsErr = true
 			resp.Msg = "Table \"" + tableName + "\" not found"
@@ -119,6 +119,12 @@ func TestGetCmdFunction(t *testing.T) {
 	if err != nil {
 		t.Errorf("%s: Unable to add data to table for test", t.Name())
 	}
+	trans = sqtables.BeginTrans(profile, true)
+	tkns = tokens.Tokenize("CREATE VIEW getcmdview AS SELECT col1 FROM " + tableName)
+	_, _, err = cmd.CreateView(trans, tkns)
+	if err != nil {
+		t.Errorf("%s: Unable to create view for test", t.Name())
+	}
 
 	data := []GetCmdData{
 		{
@@ -217,6 +223,22 @@ func TestGetCmdFunction(t *testing.T) {
 			ExpShutDown: NoAction,
 			ExpErr:      "",
 		},
+		{
+			TestName:    "Lock View",
+			Command:     "lock getcmdview",
+			NilFunc:     false,
+			ExpMsg:      "getcmdview is a view, views can not be locked",
+			ExpShutDown: NoAction,
+			ExpErr:      "",
+		},
+		{
+			TestName:    "UnLock View",
+			Command:     "unlock getcmdview",
+			NilFunc:     false,
+			ExpMsg:      "getcmdview is a view, views can not be unlocked",
+			ExpShutDown: NoAction,
+			ExpErr:      "",
+		},
 		{
 			TestName:    "Unlock Table does not exist",
 			Command:     "unlock notatable",
@@ -245,7 +267,7 @@ func TestGetCmdFunction(t *testing.T) {
 			TestName:    "Show Tables",
 			Command:     "show tables",
 			NilFunc:     false,
-			ExpMsg:      "Table List\n--------------------------------------------\n",
+			ExpMsg:      "  getcmdview           VIEW",
 			ExpShutDown: NoAction,
 			ExpErr:      "",
 		},
@@ -257,6 +279,14 @@ func TestGetCmdFunction(t *testing.T) {
 			ExpShutDown: NoAction,
 			ExpErr:      "",
 		},
+		{
+			TestName:    "Show Table getcmdview",
+			Command:     "show table getcmdview",
+			NilFunc:     false,
+			ExpMsg:      "CREATE VIEW getcmdview AS SELECT col1 FROM getcmdtest",
+			ExpShutDown: NoAction,
+			ExpErr:      "",
+		},
 		{
 			TestName:    "Show Table NotATable",
 			Command:     "show table NotATable",
@@ -404,6 +434,21 @@ func TestGetDispatchFunction(t *testing.T) {
 			Command:  "UPDATE",
 			NilFunc:  false,
 		},
+		{
+			TestName: "TRUNCATE",
+			Command:  "TRUNCATE test",
+			NilFunc:  false,
+		},
+		{
+			TestName: "MERGE INTO",
+			Command:  "MERGE INTO test USING stage ON test.id = stage.id WHEN MATCHED THEN DELETE",
+			NilFunc:  false,
+		},
+		{
+			TestName: "MERGE without INTO",
+			Command:  "MERGE test",
+			NilFunc:  true,
+		},
 	}
 	for i, row := range data {
 
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/wilphi/sqsrv/cmd"
 	"github.com/wilphi/sqsrv/sqprotocol"
 	"github.com/wilphi/sqsrv/tokens"
 
@@ -31,6 +32,7 @@ var Options struct {
 	dbfiles    string
 	cpuprofile string
 	lazytlog   int
+	maxrecurse int
 }
 
 // Main is the main process function for the SQServer
@@ -47,6 +49,7 @@ func Main() {
 	flag.StringVar(&Options.dbfiles, "dbfile", "./dbfiles/", "Directory where database files are stored")
 	flag.StringVar(&Options.cpuprofile, "cpuprofile", "", "write cpu profile to file")
 	flag.IntVar(&Options.lazytlog, "lazytlog", 1000, "Number of milliseconds between file.Sync of the tlog. A value of 0 will sync after every write. Non zero values may lead to n milliseconds of dataloss")
+	flag.IntVar(&Options.maxrecurse, "maxrecursion", 100, "Maximum number of iterations allowed for a WITH RECURSIVE query")
 	flag.Parse()
 
 	if Options.cpuprofile != "" {
@@ -60,6 +63,7 @@ func Main() {
 	// Set where datafile are
 	sqtables.SetDBDir(Options.dbfiles)
 	redo.SetTLog(Options.tlog)
+	cmd.SetMaxRecursion(Options.maxrecurse)
 
 	log.Println("Starting SQSrv version", SQVersion)
 	log.Println("dbfiles =", Options.dbfiles)
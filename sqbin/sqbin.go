@@ -30,6 +30,8 @@ const (
 	TMFloat
 	TMSQPtr
 	TMSQPtrs
+	TMBytes
+	TMUUID
 )
 
 // typeMarkerStrings translates the marker to a string
@@ -45,6 +47,8 @@ var typeMarkerStrings = map[TypeMarker]string{
 	TMFloat:         "TMFloat",
 	TMSQPtr:         "TMSQPtr",
 	TMSQPtrs:        "TMSQPtrs",
+	TMBytes:         "TMBytes",
+	TMUUID:          "TMUUID",
 }
 
 // TypeMarker is used to identify a type in a binary representation
@@ -176,6 +180,42 @@ func (c *Codec) ReadString() string {
 	return str
 }
 
+//WriteBytes writes a byte slice to the codec buffer
+func (c *Codec) WriteBytes(b []byte) {
+	c.WriteTypeMarker(TMBytes)
+	c.WriteInt(len(b))
+	c.buff.Write(b)
+}
+
+//ReadBytes reads a byte slice from the codec buffer
+func (c *Codec) ReadBytes() []byte {
+	c.ReadTypeMarker(TMBytes)
+	bLen := c.ReadInt()
+
+	b := make([]byte, bLen)
+	if n, _ := c.buff.Read(b); n != bLen && bLen != 0 {
+		panic("Unable to sqbin.ReadBytes from codec buffer")
+	}
+	return b
+}
+
+// WriteUUID writes the 16 bytes of a UUID to the codec buffer
+func (c *Codec) WriteUUID(u [16]byte) {
+	c.WriteTypeMarker(TMUUID)
+	c.buff.Write(u[:])
+}
+
+//ReadUUID reads the 16 bytes of a UUID from the codec buffer
+func (c *Codec) ReadUUID() [16]byte {
+	var u [16]byte
+
+	c.ReadTypeMarker(TMUUID)
+	if n, _ := c.buff.Read(u[:]); n != len(u) {
+		panic("Unable to sqbin.ReadUUID from codec buffer")
+	}
+	return u
+}
+
 // Writebyte writes a byte to the codec buffer
 func (c *Codec) Writebyte(b byte) {
 	c.WriteTypeMarker(TMByte)
@@ -431,6 +431,48 @@ func TestSQPtrsArray(t *testing.T) {
 	}
 
 }
+func TestBytes(t *testing.T) {
+	encdec := sqbin.NewCodec(nil)
+
+	wbytes := []byte{0xDE, 0xAD, 0x00, 0xBE, 0xEF}
+	encdec.WriteBytes(wbytes)
+	encdec.WriteBytes([]byte{})
+	rbytes := encdec.ReadBytes()
+	if !bytes.Equal(wbytes, rbytes) {
+		t.Error("The Written bytes do not match the Read bytes")
+	}
+	if rbytes = encdec.ReadBytes(); len(rbytes) != 0 {
+		t.Error("The Written empty bytes do not match the Read bytes")
+	}
+
+	t.Run("Read Partial Bytes", func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "Unable to sqbin.ReadBytes from codec buffer")
+		encdec.Reset()
+		encdec.Write([]byte{sqbin.TMBytes, sqbin.TMInt, 4, 0, 0, 0, 0, 0, 0, 0, 0xDE, 0xAD})
+		encdec.ReadBytes()
+	})
+}
+
+func TestUUID(t *testing.T) {
+	encdec := sqbin.NewCodec(nil)
+
+	wuuid := [16]byte{0xa0, 0xee, 0xbc, 0x99, 0x9c, 0x0b, 0x4e, 0xf8, 0xbb, 0x6d, 0x6b, 0xb9, 0xbd, 0x38, 0x0a, 0x11}
+	encdec.WriteUUID(wuuid)
+	if encdec.Len() != 17 {
+		t.Errorf("Encoded UUID length %d does not match expected 17", encdec.Len())
+	}
+	if ruuid := encdec.ReadUUID(); ruuid != wuuid {
+		t.Error("The Written UUID does not match the Read UUID")
+	}
+
+	t.Run("Read Partial UUID", func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "Unable to sqbin.ReadUUID from codec buffer")
+		encdec.Reset()
+		encdec.Write([]byte{sqbin.TMUUID, 0xDE, 0xAD})
+		encdec.ReadUUID()
+	})
+}
+
 func TestInsert(t *testing.T) {
 
 	data := []dataInsert{
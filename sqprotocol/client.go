@@ -17,6 +17,14 @@ func init() {
 	gob.Register(sqtypes.SQBool{})
 	gob.Register(sqtypes.SQNull{})
 	gob.Register(sqtypes.SQFloat{})
+	gob.Register(sqtypes.SQDate{})
+	gob.Register(sqtypes.SQTime{})
+	gob.Register(sqtypes.SQTimestamp{})
+	gob.Register(sqtypes.SQInterval{})
+	gob.Register(sqtypes.SQDecimal{})
+	gob.Register(sqtypes.SQBlob{})
+	gob.Register(sqtypes.SQJSON{})
+	gob.Register(sqtypes.SQUUID{})
 }
 
 // SetClientConn - set the connection for the server to communicate on
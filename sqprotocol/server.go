@@ -30,6 +30,14 @@ func init() {
 	gob.Register(sqtypes.SQBool{})
 	gob.Register(sqtypes.SQNull{})
 	gob.Register(sqtypes.SQFloat{})
+	gob.Register(sqtypes.SQDate{})
+	gob.Register(sqtypes.SQTime{})
+	gob.Register(sqtypes.SQTimestamp{})
+	gob.Register(sqtypes.SQInterval{})
+	gob.Register(sqtypes.SQDecimal{})
+	gob.Register(sqtypes.SQBlob{})
+	gob.Register(sqtypes.SQJSON{})
+	gob.Register(sqtypes.SQUUID{})
 	inShutdown = new(int64)
 }
 
@@ -89,6 +97,10 @@ func (srv *SvrConfig) Close() error {
 func (srv *SvrConfig) SendColumns(cols []column.Ref) error {
 	for _, c := range cols {
 		cInfo := ColInfo{ColName: c.ColName, Width: getTypeWidth(c.ColType)}
+		// Use the width from the declared size of the column if there is one
+		if c.Width != 0 {
+			cInfo.Width = c.Width
+		}
 		err := srv.enc.Encode(cInfo)
 		if err != nil {
 			log.Errorln("Error Writing to client connection", err)
@@ -121,6 +133,22 @@ func getTypeWidth(typeName tokens.TokenID) int {
 		ret = sqtypes.SQBoolWidth
 	case tokens.Float:
 		ret = sqtypes.SQFloatWidth
+	case tokens.Date:
+		ret = sqtypes.SQDateWidth
+	case tokens.Time:
+		ret = sqtypes.SQTimeWidth
+	case tokens.Timestamp:
+		ret = sqtypes.SQTimestampWidth
+	case tokens.Interval:
+		ret = -sqtypes.SQIntervalWidth
+	case tokens.Decimal:
+		ret = sqtypes.SQDecimalWidth
+	case tokens.Blob:
+		ret = -sqtypes.SQBlobWidth
+	case tokens.JSON:
+		ret = -sqtypes.SQJSONWidth
+	case tokens.UUID:
+		ret = sqtypes.SQUUIDWidth
 	default:
 		// This should never happen
 		//log.Panicf("Invalid type: %s", typeName)
@@ -275,11 +275,13 @@ func TestConnection(t *testing.T) {
 		defer sqtest.PanicTestRecovery(t, "")
 
 		testConn.Err = nil
+		sized := column.NewSizedDef("col5", tokens.Varchar, 12, false)
 		err := svr.SendColumns([]column.Ref{
 			column.NewRef("col1", tokens.Int, false),
 			column.NewRef("col2", tokens.String, true),
 			column.NewRef("col3", tokens.Bool, false),
 			column.NewRef("col4", tokens.Float, true),
+			sized.Ref(),
 		})
 		if err != nil {
 			t.Errorf("Unexpected Error: %s", err.Error())
@@ -308,7 +310,7 @@ func TestConnection(t *testing.T) {
 
 		testConn.Err = nil
 
-		colsResp, err := client.ReceiveColumns(4)
+		colsResp, err := client.ReceiveColumns(5)
 		if err != nil {
 			t.Errorf("Unexpected Error: %s", err.Error())
 			return
@@ -318,6 +320,7 @@ func TestConnection(t *testing.T) {
 			{"col2", -sqtypes.SQStringWidth},
 			{"col3", sqtypes.SQBoolWidth},
 			{"col4", sqtypes.SQFloatWidth},
+			{"col5", -12},
 		}
 		if !reflect.DeepEqual(cols, colsResp) {
 			t.Errorf("What the client sent %v was not what the server received %v", cols, colsResp)
@@ -0,0 +1,515 @@
+package sqtables
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// Aggregator calculates the value of an aggregate function for a group of rows. Init is called at the
+//   start of each group and Accumulate for each row of the group. vals has the value of the function's
+//   expression followed by the values of its extra arguments and ORDER BY. Finalize returns the result for the rows that have
+//   been accumulated and may be called more than once
+type Aggregator interface {
+	Init()
+	Accumulate(vals []sqtypes.Value) error
+	Finalize() (sqtypes.Value, error)
+}
+
+// newAggregator creates the Aggregator for an aggregate function
+func newAggregator(fex *FuncExpr) (Aggregator, error) {
+	var agg Aggregator
+
+	switch fex.Cmd {
+	case tokens.Grouping:
+		return &groupingAgg{}, nil
+	case tokens.Count:
+		agg = &countAgg{countRows: fex.exL == nil && fex.filter == nil}
+	case tokens.Sum, tokens.Avg:
+		agg = &sumAgg{avg: fex.Cmd == tokens.Avg}
+	case tokens.Min, tokens.Max:
+		agg = &minMaxAgg{max: fex.Cmd == tokens.Max}
+	case tokens.StddevPop, tokens.StddevSamp, tokens.Stddev, tokens.VarPop, tokens.VarSamp, tokens.Variance:
+		agg = &varianceAgg{cmd: fex.Cmd}
+	case tokens.Median:
+		agg = &percentileAgg{cmd: fex.Cmd, cont: true, fraction: 0.5}
+	case tokens.PercentileCont, tokens.PercentileDisc:
+		fraction, err := fex.param.Convert(tokens.Float)
+		if err != nil {
+			return nil, err
+		}
+		agg = &percentileAgg{
+			cmd:      fex.Cmd,
+			cont:     fex.Cmd == tokens.PercentileCont,
+			fraction: fraction.(sqtypes.SQFloat).Val,
+			desc:     len(fex.orderBy) > 0 && fex.orderBy[0].SortType == tokens.Desc,
+		}
+	case tokens.StringAgg:
+		sagg := &stringAgg{}
+		if fex.param != nil && !fex.param.IsNull() {
+			sagg.sep = fex.param.String()
+		}
+		for _, item := range fex.orderBy {
+			sagg.sortTypes = append(sagg.sortTypes, item.SortType)
+		}
+		agg = sagg
+	case tokens.JSONArrayAgg:
+		jagg := &jsonArrayAgg{}
+		for _, item := range fex.orderBy {
+			jagg.sortTypes = append(jagg.sortTypes, item.SortType)
+		}
+		agg = jagg
+	case tokens.JSONObjectAgg:
+		agg = &jsonObjectAgg{}
+	default:
+		return nil, sqerr.NewInternalf("Function %s is not a valid aggregate function", tokens.IDName(fex.Cmd))
+	}
+
+	if fex.Distinct {
+		agg = &distinctAgg{agg: agg}
+	}
+	return agg, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////
+
+// countAgg counts the rows or the non null values
+type countAgg struct {
+	countRows bool // COUNT() counts rows instead of values
+	cnt       int
+}
+
+// Init starts a new group
+func (a *countAgg) Init() {
+	a.cnt = 0
+}
+
+// Accumulate adds a row to the group
+func (a *countAgg) Accumulate(vals []sqtypes.Value) error {
+	if a.countRows || !vals[0].IsNull() {
+		a.cnt++
+	}
+	return nil
+}
+
+// Finalize returns the count
+func (a *countAgg) Finalize() (sqtypes.Value, error) {
+	return sqtypes.NewSQInt(a.cnt), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////
+
+// sumAgg calculates SUM or AVG of the non null values
+type sumAgg struct {
+	avg bool
+	sum sqtypes.Value
+	cnt int
+}
+
+// Init starts a new group
+func (a *sumAgg) Init() {
+	a.sum = nil
+	a.cnt = 0
+}
+
+// Accumulate adds a row to the group
+func (a *sumAgg) Accumulate(vals []sqtypes.Value) error {
+	var err error
+
+	v := vals[0]
+	if v.IsNull() {
+		return nil
+	}
+	a.cnt++
+	if a.sum == nil {
+		a.sum = v
+		return nil
+	}
+	a.sum, err = a.sum.Operation(tokens.Plus, v)
+	return err
+}
+
+// Finalize returns the sum or average. If there are no values the result is null
+func (a *sumAgg) Finalize() (sqtypes.Value, error) {
+	if a.sum == nil {
+		return sqtypes.NewSQNull(), nil
+	}
+	if !a.avg {
+		return a.sum, nil
+	}
+	// The average of decimals stays exact
+	if _, ok := a.sum.(sqtypes.SQDecimal); ok {
+		return a.sum.Operation(tokens.Divide, sqtypes.NewSQInt(a.cnt))
+	}
+	numer, err := a.sum.Convert(tokens.Float)
+	if err != nil {
+		return nil, err
+	}
+	return numer.Operation(tokens.Divide, sqtypes.NewSQFloat(float64(a.cnt)))
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////
+
+// minMaxAgg finds the smallest or largest non null value
+type minMaxAgg struct {
+	max bool
+	val sqtypes.Value
+}
+
+// Init starts a new group
+func (a *minMaxAgg) Init() {
+	a.val = nil
+}
+
+// Accumulate adds a row to the group
+func (a *minMaxAgg) Accumulate(vals []sqtypes.Value) error {
+	v := vals[0]
+	switch {
+	case v.IsNull():
+	case a.val == nil:
+		a.val = v
+	case a.max && v.GreaterThan(a.val):
+		a.val = v
+	case !a.max && v.LessThan(a.val):
+		a.val = v
+	}
+	return nil
+}
+
+// Finalize returns the smallest or largest value. If there are no values the result is null
+func (a *minMaxAgg) Finalize() (sqtypes.Value, error) {
+	if a.val == nil {
+		return sqtypes.NewSQNull(), nil
+	}
+	return a.val, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////
+
+// varianceAgg calculates the variance or standard deviation of the non null values. The running mean
+//   and sum of squared differences are updated for each value (Welford's method) so the values do not
+//   need to be kept
+type varianceAgg struct {
+	cmd  tokens.TokenID
+	cnt  int
+	mean float64
+	m2   float64
+}
+
+// Init starts a new group
+func (a *varianceAgg) Init() {
+	a.cnt = 0
+	a.mean = 0
+	a.m2 = 0
+}
+
+// Accumulate adds a row to the group
+func (a *varianceAgg) Accumulate(vals []sqtypes.Value) error {
+	if vals[0].IsNull() {
+		return nil
+	}
+	x, err := numericVal(a.cmd, vals[0])
+	if err != nil {
+		return err
+	}
+	a.cnt++
+	delta := x - a.mean
+	a.mean += delta / float64(a.cnt)
+	a.m2 += delta * (x - a.mean)
+	return nil
+}
+
+// Finalize returns the variance or standard deviation. The sample versions are null if there are fewer
+//   than two values, the population versions if there are no values
+func (a *varianceAgg) Finalize() (sqtypes.Value, error) {
+	var variance float64
+
+	switch a.cmd {
+	case tokens.StddevPop, tokens.VarPop:
+		if a.cnt < 1 {
+			return sqtypes.NewSQNull(), nil
+		}
+		variance = a.m2 / float64(a.cnt)
+	default:
+		if a.cnt < 2 {
+			return sqtypes.NewSQNull(), nil
+		}
+		variance = a.m2 / float64(a.cnt-1)
+	}
+
+	switch a.cmd {
+	case tokens.StddevPop, tokens.StddevSamp, tokens.Stddev:
+		return sqtypes.NewSQFloat(math.Sqrt(variance)), nil
+	}
+	return sqtypes.NewSQFloat(variance), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////
+
+// percentileAgg calculates MEDIAN, PERCENTILE_CONT or PERCENTILE_DISC. All of the non null values are
+//   kept until the result is needed
+type percentileAgg struct {
+	cmd      tokens.TokenID
+	cont     bool
+	fraction float64
+	desc     bool
+	vals     []sqtypes.Value
+}
+
+// Init starts a new group
+func (a *percentileAgg) Init() {
+	a.vals = nil
+}
+
+// Accumulate adds a row to the group
+func (a *percentileAgg) Accumulate(vals []sqtypes.Value) error {
+	v := vals[0]
+	if v.IsNull() {
+		return nil
+	}
+	if a.cont {
+		if _, err := numericVal(a.cmd, v); err != nil {
+			return err
+		}
+	}
+	a.vals = append(a.vals, v)
+	return nil
+}
+
+// Finalize returns the value at the fraction of the sorted values. PERCENTILE_CONT interpolates between
+//   the two nearest values, PERCENTILE_DISC returns the first value whose position is at or after the fraction
+func (a *percentileAgg) Finalize() (sqtypes.Value, error) {
+	n := len(a.vals)
+	if n == 0 {
+		return sqtypes.NewSQNull(), nil
+	}
+	sort.Slice(a.vals, func(i, j int) bool {
+		if a.desc {
+			return a.vals[i].GreaterThan(a.vals[j])
+		}
+		return a.vals[i].LessThan(a.vals[j])
+	})
+
+	if !a.cont {
+		idx := int(math.Ceil(a.fraction*float64(n))) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		return a.vals[idx], nil
+	}
+
+	pos := a.fraction * float64(n-1)
+	lo, hi := int(math.Floor(pos)), int(math.Ceil(pos))
+	// Values have already been checked as numeric
+	vLo, _ := numericVal(a.cmd, a.vals[lo])
+	vHi, _ := numericVal(a.cmd, a.vals[hi])
+	return sqtypes.NewSQFloat(vLo + (vHi-vLo)*(pos-float64(lo))), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////
+
+// stringAgg concatenates the non null values with a separator. The values are kept with their
+//   ORDER BY values until the result is needed
+type stringAgg struct {
+	sep       string
+	sortTypes []tokens.TokenID
+	rows      sqtypes.ValueMatrix
+}
+
+// Init starts a new group
+func (a *stringAgg) Init() {
+	a.rows = nil
+}
+
+// Accumulate adds a row to the group
+func (a *stringAgg) Accumulate(vals []sqtypes.Value) error {
+	if vals[0].IsNull() {
+		return nil
+	}
+	// The row number is added to the end so that rows with the same ORDER BY values keep their order
+	row := append(append([]sqtypes.Value{}, vals...), sqtypes.NewSQInt(len(a.rows)))
+	a.rows = append(a.rows, row)
+	return nil
+}
+
+// Finalize returns the concatenated values. If there are no values the result is null
+func (a *stringAgg) Finalize() (sqtypes.Value, error) {
+	if len(a.rows) == 0 {
+		return sqtypes.NewSQNull(), nil
+	}
+	sortAggRows(a.rows, a.sortTypes)
+
+	var b strings.Builder
+	for i, row := range a.rows {
+		if i > 0 {
+			b.WriteString(a.sep)
+		}
+		b.WriteString(row[0].String())
+	}
+	return sqtypes.NewSQString(b.String()), nil
+}
+
+// sortAggRows sorts the rows of an aggregate by their ORDER BY values which follow the value of the function.
+//   The last value of each row is its row number so that rows with the same ORDER BY values keep their order
+func sortAggRows(rows sqtypes.ValueMatrix, sortTypes []tokens.TokenID) {
+	if len(sortTypes) == 0 {
+		return
+	}
+	d := &DataSet{Vals: rows, validOrder: true}
+	for x, sortType := range sortTypes {
+		d.order = append(d.order, OrderItem{SortType: sortType, idx: x + 1})
+	}
+	d.order = append(d.order, OrderItem{SortType: tokens.Asc, idx: len(sortTypes) + 1})
+	sort.Sort(d)
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////
+
+// jsonArrayAgg builds a JSON array from the non null values. The values are kept with their ORDER BY
+//   values until the result is needed
+type jsonArrayAgg struct {
+	sortTypes []tokens.TokenID
+	rows      sqtypes.ValueMatrix
+}
+
+// Init starts a new group
+func (a *jsonArrayAgg) Init() {
+	a.rows = nil
+}
+
+// Accumulate adds a row to the group
+func (a *jsonArrayAgg) Accumulate(vals []sqtypes.Value) error {
+	if vals[0].IsNull() {
+		return nil
+	}
+	row := append(append([]sqtypes.Value{}, vals...), sqtypes.NewSQInt(len(a.rows)))
+	a.rows = append(a.rows, row)
+	return nil
+}
+
+// Finalize returns the JSON array. If there are no values the result is null
+func (a *jsonArrayAgg) Finalize() (sqtypes.Value, error) {
+	if len(a.rows) == 0 {
+		return sqtypes.NewSQNull(), nil
+	}
+	sortAggRows(a.rows, a.sortTypes)
+
+	vals := make([]sqtypes.Value, len(a.rows))
+	for i, row := range a.rows {
+		vals[i] = row[0]
+	}
+	return sqtypes.JSONArray(vals), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////
+
+// jsonObjectAgg builds a JSON object from the key and value of each row. Rows with a null key are not used
+//   and if a key is repeated the last value is kept
+type jsonObjectAgg struct {
+	keys []string
+	vals []sqtypes.Value
+}
+
+// Init starts a new group
+func (a *jsonObjectAgg) Init() {
+	a.keys = nil
+	a.vals = nil
+}
+
+// Accumulate adds a row to the group
+func (a *jsonObjectAgg) Accumulate(vals []sqtypes.Value) error {
+	if vals[0].IsNull() {
+		return nil
+	}
+	a.keys = append(a.keys, vals[0].String())
+	a.vals = append(a.vals, vals[1])
+	return nil
+}
+
+// Finalize returns the JSON object. If there are no keys the result is null
+func (a *jsonObjectAgg) Finalize() (sqtypes.Value, error) {
+	if len(a.keys) == 0 {
+		return sqtypes.NewSQNull(), nil
+	}
+	return sqtypes.JSONObject(a.keys, a.vals), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////
+
+// distinctAgg keeps the non null values until the result is needed, then passes the distinct values
+//   to the aggregate it wraps
+type distinctAgg struct {
+	agg  Aggregator
+	rows sqtypes.ValueMatrix
+}
+
+// Init starts a new group
+func (a *distinctAgg) Init() {
+	a.rows = nil
+}
+
+// Accumulate adds a row to the group
+func (a *distinctAgg) Accumulate(vals []sqtypes.Value) error {
+	if vals[0].IsNull() {
+		return nil
+	}
+	a.rows = append(a.rows, vals)
+	return nil
+}
+
+// Finalize returns the result of the wrapped aggregate for the distinct values
+func (a *distinctAgg) Finalize() (sqtypes.Value, error) {
+	sort.SliceStable(a.rows, func(i, j int) bool { return a.rows[i][0].LessThan(a.rows[j][0]) })
+	a.agg.Init()
+	for i, row := range a.rows {
+		if i > 0 && row[0].Equal(a.rows[i-1][0]) {
+			continue
+		}
+		if err := a.agg.Accumulate(row); err != nil {
+			return nil, err
+		}
+	}
+	return a.agg.Finalize()
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////
+
+// groupingAgg is GROUPING(expr). The result is 1 if the expression is not part of the grouping set that is
+//   being processed, otherwise 0
+type groupingAgg struct {
+	notGrouped bool
+}
+
+// Init starts a new group
+func (a *groupingAgg) Init() {
+}
+
+// Accumulate does nothing as the result does not depend on the values
+func (a *groupingAgg) Accumulate(vals []sqtypes.Value) error {
+	return nil
+}
+
+// Finalize returns 1 if the expression is not part of the grouping set
+func (a *groupingAgg) Finalize() (sqtypes.Value, error) {
+	if a.notGrouped {
+		return sqtypes.NewSQInt(1), nil
+	}
+	return sqtypes.NewSQInt(0), nil
+}
+
+// numericVal returns the value as a float64 if it is an INT or FLOAT
+func numericVal(cmd tokens.TokenID, v sqtypes.Value) (float64, error) {
+	switch val := v.(type) {
+	case sqtypes.SQInt:
+		return float64(val.Val), nil
+	case sqtypes.SQFloat:
+		return val.Val, nil
+	case sqtypes.SQDecimal:
+		return val.Float(), nil
+	}
+	return 0, sqerr.Newf("%s can only be used with numeric values not %s", tokens.IDName(cmd), tokens.IDName(v.Type()))
+}
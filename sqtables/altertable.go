@@ -0,0 +1,413 @@
+package sqtables
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqptr"
+	"github.com/wilphi/sqsrv/sqtables/column"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// Changes to the structure of a table. Each change is checked against all of the rows in the table
+//   and if any row does not fit the new structure the table is left unchanged
+
+// rowConverter returns the data of a row in the new structure of a table
+type rowConverter func(row *RowDef) ([]sqtypes.Value, error)
+
+// AddColumn adds a column to the end of the table. Existing rows are given the DEFAULT of the
+//   column or NULL if there is no DEFAULT
+func (t *TableDef) AddColumn(profile *sqprofile.SQProfile, col column.Def) error {
+	var defExpr Expr
+	var err error
+
+	if col.Default != "" {
+		defExpr, err = parseExpr(col.Default)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = t.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer t.Unlock(profile)
+
+	if t.FindColDef(profile, col.ColName) != nil {
+		return sqerr.Newf("Column %s already exists in table %s", col.ColName, t.tableName)
+	}
+	cols := append(cloneCols(t.tableCols), col)
+
+	conv := func(row *RowDef) ([]sqtypes.Value, error) {
+		var val sqtypes.Value = sqtypes.NewSQNull()
+		if defExpr != nil {
+			val, err = defExpr.Evaluate(profile, EvalFull, row)
+			if err != nil {
+				return nil, err
+			}
+			val, err = ColValue(&col, t.tableName, val)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if col.IsNotNull && val.IsNull() {
+			return nil, sqerr.Newf("Column %q in Table %q can not be NULL", col.ColName, t.tableName)
+		}
+		return append(cloneVals(row.Data), val), nil
+	}
+	return t.restructure(profile, cols, t.constraints, conv)
+}
+
+// DropColumn removes a column and its data from the table. A column that is used by a constraint
+//   can not be dropped
+func (t *TableDef) DropColumn(profile *sqprofile.SQProfile, colName string) error {
+	err := t.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer t.Unlock(profile)
+
+	colDef := t.FindColDef(profile, colName)
+	if colDef == nil {
+		return sqerr.Newf("Column %s does not exist in table %s", colName, t.tableName)
+	}
+	if len(t.tableCols) == 1 {
+		return sqerr.Newf("Column %s is the only column in table %s and can not be dropped", colName, t.tableName)
+	}
+	for _, con := range t.constraints {
+		if constraintUsesCol(con, colName) {
+			return sqerr.Newf("Column %s is used by the constraint %s and can not be dropped", colName, con.String())
+		}
+	}
+
+	idx := colDef.Idx
+	cols := append(cloneCols(t.tableCols[:idx]), t.tableCols[idx+1:]...)
+	conv := func(row *RowDef) ([]sqtypes.Value, error) {
+		return append(cloneVals(row.Data[:idx]), row.Data[idx+1:]...), nil
+	}
+	return t.restructure(profile, cols, t.constraints, conv)
+}
+
+// RenameColumn changes the name of a column. The constraints that use the column are changed to
+//   use the new name
+func (t *TableDef) RenameColumn(profile *sqprofile.SQProfile, colName, newName string) error {
+	err := t.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer t.Unlock(profile)
+
+	colDef := t.FindColDef(profile, colName)
+	if colDef == nil {
+		return sqerr.Newf("Column %s does not exist in table %s", colName, t.tableName)
+	}
+	if t.FindColDef(profile, newName) != nil {
+		return sqerr.Newf("Column %s already exists in table %s", newName, t.tableName)
+	}
+	cols := cloneCols(t.tableCols)
+	cols[colDef.Idx].ColName = newName
+
+	var cons []Constraint
+	for _, con := range t.constraints {
+		switch c := con.(type) {
+		case *PrimaryKey:
+			cons = append(cons, &PrimaryKey{Cols: renameOrder(c.Cols, colName, newName)})
+		case *Unique:
+			cons = append(cons, &Unique{Name: c.Name, Cols: renameOrder(c.Cols, colName, newName)})
+		case *Check:
+			if !constraintUsesCol(c, colName) {
+				cons = append(cons, c)
+				continue
+			}
+			chk, err := ParseCheck(c.Name, renameIdent(c.Text, colName, newName))
+			if err != nil {
+				return err
+			}
+			cons = append(cons, chk)
+		default:
+			cons = append(cons, con)
+		}
+	}
+	return t.restructure(profile, cols, cons, nil)
+}
+
+// AlterColumnType changes the type of the column with the same name as col. The values in the
+//   column are converted to the new type and must fit in it. The DEFAULT of the column is replaced
+//   by the DEFAULT of col
+func (t *TableDef) AlterColumnType(profile *sqprofile.SQProfile, col column.Def) error {
+	err := t.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer t.Unlock(profile)
+
+	colDef := t.FindColDef(profile, col.ColName)
+	if colDef == nil {
+		return sqerr.Newf("Column %s does not exist in table %s", col.ColName, t.tableName)
+	}
+	idx := colDef.Idx
+	cols := cloneCols(t.tableCols)
+	cols[idx].ColType = col.ColType
+	cols[idx].DeclType = col.DeclType
+	cols[idx].Precision = col.Precision
+	cols[idx].Scale = col.Scale
+	cols[idx].Default = col.Default
+
+	// CHECK constraints must be recreated for the new type of the column
+	var cons []Constraint
+	for _, con := range t.constraints {
+		if c, ok := con.(*Check); ok && constraintUsesCol(c, col.ColName) {
+			chk, err := ParseCheck(c.Name, c.Text)
+			if err != nil {
+				return err
+			}
+			cons = append(cons, chk)
+			continue
+		}
+		cons = append(cons, con)
+	}
+
+	conv := func(row *RowDef) ([]sqtypes.Value, error) {
+		data := cloneVals(row.Data)
+		val := data[idx]
+		if !val.IsNull() && val.Type() != cols[idx].ColType {
+			val, err = val.Convert(cols[idx].ColType)
+			if err != nil {
+				return nil, err
+			}
+		}
+		data[idx], err = ColValue(&cols[idx], t.tableName, val)
+		return data, err
+	}
+	return t.restructure(profile, cols, cons, conv)
+}
+
+// AddConstraint adds a constraint to the table. All of the rows in the table must satisfy the
+//   new constraint
+func (t *TableDef) AddConstraint(profile *sqprofile.SQProfile, con Constraint) error {
+	err := t.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer t.Unlock(profile)
+
+	cons := append(append([]Constraint{}, t.constraints...), con)
+	return t.restructure(profile, t.tableCols, cons, nil)
+}
+
+// DropConstraint removes the named CHECK constraint from the table
+func (t *TableDef) DropConstraint(profile *sqprofile.SQProfile, name string) error {
+	err := t.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer t.Unlock(profile)
+
+	name = strings.ToLower(name)
+	var cons []Constraint
+	for _, con := range t.constraints {
+		switch c := con.(type) {
+		case *Check:
+			if c.Name == name {
+				continue
+			}
+		case *Unique:
+			if strings.ToLower(c.Name) == name {
+				return sqerr.Newf("Constraint %s on table %s is a UNIQUE constraint, only CHECK constraints can be dropped", name, t.tableName)
+			}
+		}
+		cons = append(cons, con)
+	}
+	if len(cons) == len(t.constraints) {
+		return sqerr.Newf("Constraint %s does not exist on table %s", name, t.tableName)
+	}
+	t.constraints = cons
+	return nil
+}
+
+// HasConstraint returns true if the table has a CHECK constraint with the given name
+func (t *TableDef) HasConstraint(profile *sqprofile.SQProfile, name string) bool {
+	name = strings.ToLower(name)
+	for _, con := range t.constraints {
+		if chk, ok := con.(*Check); ok && chk.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// restructure replaces the columns and constraints of the table and converts the data of each row
+//   with conv. If conv is nil the data of the rows is not changed. If a row can not be converted or
+//   does not satisfy the constraints the table is left unchanged. The table must be write locked
+func (t *TableDef) restructure(profile *sqprofile.SQProfile, cols []column.Def, cons []Constraint, conv rowConverter) (err error) {
+	// Convert all of the rows before anything is changed. The rows are converted in RowID order
+	//   so that values such as NEXTVAL are given out in the order that the rows were added
+	newData := make(map[sqptr.SQPtr][]sqtypes.Value, len(t.rowm))
+	if conv != nil {
+		ptrs := make(sqptr.SQPtrs, 0, len(t.rowm))
+		for ptr := range t.rowm {
+			ptrs = append(ptrs, ptr)
+		}
+		sort.Slice(ptrs, func(i, j int) bool { return ptrs[i] < ptrs[j] })
+		for _, ptr := range ptrs {
+			row := t.rowm[ptr].(*RowDef)
+			if row.isDeleted {
+				newData[ptr] = nullVals(len(cols))
+				continue
+			}
+			newData[ptr], err = conv(row)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	oldCols, oldCons := t.tableCols, t.constraints
+	oldData := make(map[sqptr.SQPtr][]sqtypes.Value, len(newData))
+
+	// Put the table back the way it was if anything fails, even a panic, so that it still matches
+	//   the transaction log
+	defer func() {
+		if r := recover(); r != nil {
+			err = sqerr.NewInternalf("Unable to change the structure of table %s: %v", t.tableName, r)
+		}
+		if err != nil {
+			t.setCols(oldCols)
+			for ptr, data := range oldData {
+				row := t.rowm[ptr].(*RowDef)
+				row.Data = data
+				row.ColNum = len(oldCols)
+			}
+			t.setConstraints(profile, oldCons)
+		}
+	}()
+
+	t.setCols(cols)
+	for ptr, data := range newData {
+		row := t.rowm[ptr].(*RowDef)
+		oldData[ptr] = row.Data
+		row.Data = data
+		row.ColNum = len(cols)
+	}
+
+	err = t.setConstraints(profile, cons)
+	if err != nil {
+		return err
+	}
+	for _, rw := range t.rowm {
+		if rw.IsDeleted(profile) {
+			continue
+		}
+		err = t.checkRow(profile, rw)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The rows are written to disk in the new structure at the next checkpoint
+	if conv != nil {
+		for _, rw := range t.rowm {
+			rw.(*RowDef).isModified = true
+		}
+	}
+	return nil
+}
+
+// setCols sets the columns of the table and their position in the table
+func (t *TableDef) setCols(cols []column.Def) {
+	for i := range cols {
+		cols[i].Idx = i
+		cols[i].TableName = t.tableName
+	}
+	t.tableCols = cols
+}
+
+// setConstraints replaces the constraints of the table. The constraints are sorted so that the order
+//   is PK, FK, Unique, Index, Check and validated against the table
+func (t *TableDef) setConstraints(profile *sqprofile.SQProfile, cons []Constraint) error {
+	t.constraints = cons
+
+	sort.SliceStable(t.constraints, func(i int, j int) bool { return t.constraints[i].Ordering() < t.constraints[j].Ordering() })
+	for i := range t.constraints {
+		err := t.constraints[i].Validate(profile, t)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// constraintUsesCol returns true if the column is part of the constraint
+func constraintUsesCol(con Constraint, colName string) bool {
+	var names []string
+	switch c := con.(type) {
+	case *PrimaryKey:
+		names = c.Cols.Names()
+	case *Unique:
+		names = c.Cols.Names()
+	case *Check:
+		for _, ref := range c.Expr.ColRefs() {
+			names = append(names, ref.ColName)
+		}
+	}
+	for _, name := range names {
+		if name == colName {
+			return true
+		}
+	}
+	return false
+}
+
+// renameOrder returns a copy of the sort order with the column renamed
+func renameOrder(order SortOrder, colName, newName string) SortOrder {
+	nOrder := append(SortOrder{}, order...)
+	for i := range nOrder {
+		if nOrder[i].ColName == colName {
+			nOrder[i].ColName = newName
+		}
+	}
+	return nOrder
+}
+
+// renameIdent returns the SQL text with all uses of the column renamed
+func renameIdent(sql, colName, newName string) string {
+	tkns := tokens.Tokenize(sql)
+	var list []tokens.Token
+	for !tkns.IsEmpty() {
+		tkn := tkns.Peek()
+		if tkn.ID() == tokens.Ident && tkn.(*tokens.ValueToken).Value() == colName {
+			tkn = tokens.NewValueToken(tokens.Ident, newName)
+		}
+		list = append(list, tkn)
+		tkns.Remove()
+	}
+	return tokens.CreateList(list).SQL()
+}
+
+// parseExpr converts the SQL text of an expression into an Expr using ParseExprHook
+func parseExpr(sql string) (Expr, error) {
+	if ParseExprHook == nil {
+		return nil, sqerr.NewInternalf("Unable to parse the expression %s without an expression parser", sql)
+	}
+	return ParseExprHook(sql)
+}
+
+func cloneCols(cols []column.Def) []column.Def {
+	return append([]column.Def{}, cols...)
+}
+
+func cloneVals(vals []sqtypes.Value) []sqtypes.Value {
+	return append([]sqtypes.Value{}, vals...)
+}
+
+func nullVals(n int) []sqtypes.Value {
+	vals := make([]sqtypes.Value, n)
+	for i := range vals {
+		vals[i] = sqtypes.NewSQNull()
+	}
+	return vals
+}
@@ -0,0 +1,183 @@
+package sqtables_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtables/column"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+type AlterData struct {
+	TestName  string
+	TableName string
+	Alter     func(profile *sqprofile.SQProfile, tab *sqtables.TableDef) error
+	ExpErr    string
+	ExpStr    string
+	ExpVals   sqtypes.RawVals
+}
+
+func testAlterFunc(profile *sqprofile.SQProfile, tab *sqtables.TableDef, d AlterData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		err := d.Alter(profile, tab)
+		if sqtest.CheckErr(t, err, d.ExpErr) && d.ExpErr == "" {
+			return
+		}
+		// When an error is expected the table is checked to make sure it has not changed
+
+		tab, err := sqtables.GetTable(profile, d.TableName)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if tab == nil {
+			t.Errorf("Table %s does not exist", d.TableName)
+			return
+		}
+		if tab.String(profile) != d.ExpStr {
+			t.Errorf("Altered table did not match expected: \nActual: %s\nExpected: %s", tab.String(profile), d.ExpStr)
+			return
+		}
+		ptrs, err := tab.GetRowPtrs(profile, nil, true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		data, err := tab.GetRowDataFromPtrs(profile, ptrs)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		msg := sqtypes.Compare2DValue(data.Vals, sqtypes.CreateValuesFromRaw(d.ExpVals), "Actual", "Expect", false)
+		if msg != "" {
+			t.Error(msg)
+		}
+	}
+}
+
+func TestAlterTable(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+
+	tab := sqtables.CreateTableDef("altertest",
+		[]column.Def{
+			column.NewDef("col1", tokens.Int, true),
+			column.NewDef("col2", tokens.String, false),
+		},
+	)
+	err := sqtables.CreateTable(profile, tab)
+	if err != nil {
+		t.Error("Error creating table: ", err)
+		return
+	}
+	ds, err := sqtables.NewDataSet(profile, sqtables.NewTableListFromTableDef(profile, tab), sqtables.ColsToExpr(tab.GetCols(profile)))
+	if err != nil {
+		t.Error("Error creating dataset: ", err)
+		return
+	}
+	ds.Vals = sqtypes.CreateValuesFromRaw(sqtypes.RawVals{{1, "10"}, {2, "20"}, {3, "a"}})
+	_, err = tab.AddRows(sqtables.BeginTrans(profile, true), ds)
+	if err != nil {
+		t.Error("Error adding rows: ", err)
+		return
+	}
+
+	data := []AlterData{
+		{
+			TestName:  "Add Column",
+			TableName: "altertest",
+			Alter: func(profile *sqprofile.SQProfile, tab *sqtables.TableDef) error {
+				return tab.AddColumn(profile, column.NewDef("col3", tokens.Bool, false))
+			},
+			ExpStr:  "altertest\n--------------------------------------\n\t{col1, INT NOT NULL}\n\t{col2, STRING}\n\t{col3, BOOL}\n",
+			ExpVals: sqtypes.RawVals{{1, "10", nil}, {2, "20", nil}, {3, "a", nil}},
+		},
+		{
+			TestName:  "Add Column NOT NULL",
+			TableName: "altertest",
+			Alter: func(profile *sqprofile.SQProfile, tab *sqtables.TableDef) error {
+				return tab.AddColumn(profile, column.NewDef("col4", tokens.Bool, true))
+			},
+			ExpErr:  "Error: Column \"col4\" in Table \"altertest\" can not be NULL",
+			ExpStr:  "altertest\n--------------------------------------\n\t{col1, INT NOT NULL}\n\t{col2, STRING}\n\t{col3, BOOL}\n",
+			ExpVals: sqtypes.RawVals{{1, "10", nil}, {2, "20", nil}, {3, "a", nil}},
+		},
+		{
+			TestName:  "Alter Column Type fails",
+			TableName: "altertest",
+			Alter: func(profile *sqprofile.SQProfile, tab *sqtables.TableDef) error {
+				return tab.AlterColumnType(profile, column.NewDef("col2", tokens.Int, false))
+			},
+			ExpErr:  "Error: Unable to Convert \"a\" to an INT",
+			ExpStr:  "altertest\n--------------------------------------\n\t{col1, INT NOT NULL}\n\t{col2, STRING}\n\t{col3, BOOL}\n",
+			ExpVals: sqtypes.RawVals{{1, "10", nil}, {2, "20", nil}, {3, "a", nil}},
+		},
+		{
+			TestName:  "Drop Column",
+			TableName: "altertest",
+			Alter: func(profile *sqprofile.SQProfile, tab *sqtables.TableDef) error {
+				return tab.DropColumn(profile, "col1")
+			},
+			ExpStr:  "altertest\n--------------------------------------\n\t{col2, STRING}\n\t{col3, BOOL}\n",
+			ExpVals: sqtypes.RawVals{{"10", nil}, {"20", nil}, {"a", nil}},
+		},
+		{
+			TestName:  "Rename Column",
+			TableName: "altertest",
+			Alter: func(profile *sqprofile.SQProfile, tab *sqtables.TableDef) error {
+				return tab.RenameColumn(profile, "col3", "colb")
+			},
+			ExpStr:  "altertest\n--------------------------------------\n\t{col2, STRING}\n\t{colb, BOOL}\n",
+			ExpVals: sqtypes.RawVals{{"10", nil}, {"20", nil}, {"a", nil}},
+		},
+		{
+			TestName:  "Drop invalid Constraint",
+			TableName: "altertest",
+			Alter: func(profile *sqprofile.SQProfile, tab *sqtables.TableDef) error {
+				return tab.DropConstraint(profile, "nocon")
+			},
+			ExpErr:  "Error: Constraint nocon does not exist on table altertest",
+			ExpStr:  "altertest\n--------------------------------------\n\t{col2, STRING}\n\t{colb, BOOL}\n",
+			ExpVals: sqtypes.RawVals{{"10", nil}, {"20", nil}, {"a", nil}},
+		},
+		{
+			TestName:  "Rename Table",
+			TableName: "altertest2",
+			Alter: func(profile *sqprofile.SQProfile, tab *sqtables.TableDef) error {
+				return sqtables.RenameTable(profile, "AlterTest", "AlterTest2")
+			},
+			ExpStr:  "altertest2\n--------------------------------------\n\t{col2, STRING}\n\t{colb, BOOL}\n",
+			ExpVals: sqtypes.RawVals{{"10", nil}, {"20", nil}, {"a", nil}},
+		},
+		{
+			TestName:  "Rename invalid Table",
+			TableName: "altertest2",
+			Alter: func(profile *sqprofile.SQProfile, tab *sqtables.TableDef) error {
+				return sqtables.RenameTable(profile, "altertest", "altertest3")
+			},
+			ExpErr:  "Error: Invalid Name: Table altertest does not exist",
+			ExpStr:  "altertest2\n--------------------------------------\n\t{col2, STRING}\n\t{colb, BOOL}\n",
+			ExpVals: sqtypes.RawVals{{"10", nil}, {"20", nil}, {"a", nil}},
+		},
+		{
+			TestName:  "Rename Table to system name",
+			TableName: "altertest2",
+			Alter: func(profile *sqprofile.SQProfile, tab *sqtables.TableDef) error {
+				return sqtables.RenameTable(profile, "altertest2", "_altertest")
+			},
+			ExpErr:  "Error: Invalid Name: _altertest - Only system tables may begin with _",
+			ExpStr:  "altertest2\n--------------------------------------\n\t{col2, STRING}\n\t{colb, BOOL}\n",
+			ExpVals: sqtypes.RawVals{{"10", nil}, {"20", nil}, {"a", nil}},
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testAlterFunc(profile, tab, row))
+	}
+}
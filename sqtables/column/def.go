@@ -1,18 +1,54 @@
 package column
 
 import (
+	"fmt"
+	"math"
+
 	"github.com/wilphi/sqsrv/sqbin"
 	"github.com/wilphi/sqsrv/sqtables/moniker"
 	"github.com/wilphi/sqsrv/tokens"
 )
 
-// Def - column definition
+// Def - column definition. Precision and Scale are used by DECIMAL columns and Precision is the length
+//   of VARCHAR and CHAR columns, a Precision of 0 means that any value can be stored. DeclType is the type
+//   given when the column was created if it is a sized version of ColType (VARCHAR, CHAR, SMALLINT,
+//   INTEGER or BIGINT). Default is the SQL text of the expression used for the value of the column when
+//   it is not given in an INSERT, an empty Default means NULL. IsGenerated is true for a GENERATED ALWAYS AS IDENTITY
+//   column whose values always come from its Default and can not be given in an INSERT or UPDATE
 type Def struct {
-	ColName   string
-	ColType   tokens.TokenID
-	Idx       int
-	IsNotNull bool
-	TableName string
+	ColName     string
+	ColType     tokens.TokenID
+	Idx         int
+	IsNotNull   bool
+	TableName   string
+	Precision   int
+	Scale       int
+	DeclType    tokens.TokenID
+	Default     string
+	IsGenerated bool
+}
+
+// sizedTypes are the types that can be declared for a column and the type of the values that they store
+var sizedTypes = map[tokens.TokenID]tokens.TokenID{
+	tokens.Varchar:  tokens.String,
+	tokens.Char:     tokens.String,
+	tokens.SmallInt: tokens.Int,
+	tokens.Integer:  tokens.Int,
+	tokens.BigInt:   tokens.Int,
+}
+
+// intRanges are the smallest and largest values of the sized INT types
+var intRanges = map[tokens.TokenID][2]int{
+	tokens.SmallInt: {math.MinInt16, math.MaxInt16},
+	tokens.Integer:  {math.MinInt32, math.MaxInt32},
+	tokens.BigInt:   {math.MinInt64, math.MaxInt64},
+}
+
+// intWidths are the display widths of the sized INT types
+var intWidths = map[tokens.TokenID]int{
+	tokens.SmallInt: 6,
+	tokens.Integer:  11,
+	tokens.BigInt:   20,
 }
 
 // NewDef -
@@ -20,6 +56,21 @@ func NewDef(colName string, colType tokens.TokenID, isNotNull bool) Def {
 	return Def{ColName: colName, ColType: colType, Idx: -1, IsNotNull: isNotNull}
 }
 
+// NewSizedDef creates a Def for a column declared as VARCHAR, CHAR, SMALLINT, INTEGER or BIGINT. length is
+//   only used by VARCHAR and CHAR
+func NewSizedDef(colName string, declType tokens.TokenID, length int, isNotNull bool) Def {
+	def := NewDef(colName, sizedTypes[declType], isNotNull)
+	def.DeclType = declType
+	def.Precision = length
+	return def
+}
+
+// IsSizedType returns true if the type can only be used to declare a column
+func IsSizedType(tkn tokens.TokenID) bool {
+	_, ok := sizedTypes[tkn]
+	return ok
+}
+
 // Clone makes a deep copy of Def
 func (c *Def) Clone() Def {
 	var nDef Def
@@ -29,6 +80,11 @@ func (c *Def) Clone() Def {
 	nDef.Idx = c.Idx
 	nDef.IsNotNull = c.IsNotNull
 	nDef.TableName = c.TableName
+	nDef.Precision = c.Precision
+	nDef.Scale = c.Scale
+	nDef.DeclType = c.DeclType
+	nDef.Default = c.Default
+	nDef.IsGenerated = c.IsGenerated
 	return nDef
 }
 
@@ -40,32 +96,108 @@ func (c *Def) String() string {
 		ntype = " NOT NULL"
 	}
 
-	ret := "{" + c.ColName + ", " + tokens.IDName(c.ColType) + ntype + "}"
+	if c.IsGenerated {
+		ntype += " GENERATED ALWAYS"
+	}
+	if c.Default != "" {
+		ntype += " DEFAULT " + c.Default
+	}
+
+	ret := "{" + c.ColName + ", " + c.TypeName() + ntype + "}"
 	return ret
 }
 
+// TypeName returns the name of the column type including any length, precision and scale
+func (c *Def) TypeName() string {
+	if c.DeclType != tokens.NilToken {
+		name := tokens.IDName(c.DeclType)
+		if c.Precision > 0 {
+			name += fmt.Sprintf("(%d)", c.Precision)
+		}
+		return name
+	}
+	name := tokens.IDName(c.ColType)
+	if c.Precision > 0 {
+		name += fmt.Sprintf("(%d,%d)", c.Precision, c.Scale)
+	}
+	return name
+}
+
+// IntRange returns the smallest and largest values of a SMALLINT, INTEGER or BIGINT column. If the
+//   column is not one of them ok is false
+func (c *Def) IntRange() (min, max int, ok bool) {
+	r, ok := intRanges[c.DeclType]
+	return r[0], r[1], ok
+}
+
+// Width returns the display width of the column based on its declared size. The width is negative for
+//   VARCHAR and CHAR so that they are left justified. If the size of the column is not declared then 0 is returned
+func (c *Def) Width() int {
+	if w, ok := intWidths[c.DeclType]; ok {
+		return w
+	}
+	if c.Precision == 0 {
+		return 0
+	}
+	switch c.ColType {
+	case tokens.String:
+		return -c.Precision
+	case tokens.Decimal:
+		// Room for the sign and decimal point
+		w := c.Precision + 1
+		if c.Scale > 0 {
+			w++
+		}
+		return w
+	}
+	return 0
+}
+
 // Ref makes a column.Ref to the column.Def
 func (c *Def) Ref() Ref {
-	return Ref{ColName: c.ColName, ColType: c.ColType, Idx: c.Idx, IsNotNull: c.IsNotNull, TableName: moniker.New(c.TableName, "")}
+	return Ref{ColName: c.ColName, ColType: c.ColType, Idx: c.Idx, IsNotNull: c.IsNotNull, TableName: moniker.New(c.TableName, ""), Width: c.Width()}
 }
 
+// encodeVersion is the version of the binary layout of a Def. Version 1 added Precision, Scale,
+//   DeclType, Default and IsGenerated. The original layout did not have a version
+const encodeVersion = 1
+
 //Encode outputs a binary encoded version of the Def to the codec
 func (c *Def) Encode(enc *sqbin.Codec) {
 
+	enc.Writebyte(encodeVersion)
 	enc.WriteString(c.ColName)
 	enc.WriteUint64(uint64(c.ColType))
 	enc.WriteInt(c.Idx)
 	enc.WriteBool(c.IsNotNull)
 	enc.WriteString(c.TableName)
+	enc.WriteInt(c.Precision)
+	enc.WriteInt(c.Scale)
+	enc.WriteUint64(uint64(c.DeclType))
+	enc.WriteString(c.Default)
+	enc.WriteBool(c.IsGenerated)
 
 }
 
-//Decode a binary encoded version of a Def from the codec
+//Decode a binary encoded version of a Def from the codec. A Def in the original layout
+//   starts with the column name instead of the version
 func (c *Def) Decode(dec *sqbin.Codec) {
 
+	version := 0
+	if dec.PeekTypeMarker() == sqbin.TMByte {
+		version = int(dec.Readbyte())
+	}
 	c.ColName = dec.ReadString()
 	c.ColType = tokens.TokenID(dec.ReadUint64())
 	c.Idx = dec.ReadInt()
 	c.IsNotNull = dec.ReadBool()
 	c.TableName = dec.ReadString()
+	if version < 1 {
+		return
+	}
+	c.Precision = dec.ReadInt()
+	c.Scale = dec.ReadInt()
+	c.DeclType = tokens.TokenID(dec.ReadUint64())
+	c.Default = dec.ReadString()
+	c.IsGenerated = dec.ReadBool()
 }
@@ -67,6 +67,88 @@ func TestDef(t *testing.T) {
 			ExpString: "{col1, INT NOT NULL}",
 			ExpErr:    "Internal Error: Can't merge Def col1, col2",
 		},
+		{
+			TestName:  "NewDef Decimal with precision",
+			ColName:   "price",
+			ColType:   tokens.Decimal,
+			IsNotNull: true,
+			Precision: 10,
+			Scale:     2,
+			ExpString: "{price, DECIMAL(10,2) NOT NULL}",
+			ExpWidth:  12,
+		},
+		{
+			TestName:  "NewDef Decimal without precision",
+			ColName:   "price",
+			ColType:   tokens.Decimal,
+			ExpString: "{price, DECIMAL}",
+		},
+		{
+			TestName:  "NewDef Varchar",
+			ColName:   "name",
+			ColType:   tokens.String,
+			DeclType:  tokens.Varchar,
+			Precision: 20,
+			ExpString: "{name, VARCHAR(20)}",
+			ExpWidth:  -20,
+		},
+		{
+			TestName:  "NewDef Varchar without length",
+			ColName:   "name",
+			ColType:   tokens.String,
+			DeclType:  tokens.Varchar,
+			ExpString: "{name, VARCHAR}",
+		},
+		{
+			TestName:  "NewDef Char",
+			ColName:   "code",
+			ColType:   tokens.String,
+			DeclType:  tokens.Char,
+			IsNotNull: true,
+			Precision: 3,
+			ExpString: "{code, CHAR(3) NOT NULL}",
+			ExpWidth:  -3,
+		},
+		{
+			TestName:  "NewDef Smallint",
+			ColName:   "qty",
+			ColType:   tokens.Int,
+			DeclType:  tokens.SmallInt,
+			ExpString: "{qty, SMALLINT}",
+			ExpWidth:  6,
+		},
+		{
+			TestName:  "NewDef Bigint",
+			ColName:   "qty",
+			ColType:   tokens.Int,
+			DeclType:  tokens.BigInt,
+			ExpString: "{qty, BIGINT}",
+			ExpWidth:  20,
+		},
+		{
+			TestName:  "NewDef Default",
+			ColName:   "status",
+			ColType:   tokens.String,
+			IsNotNull: true,
+			Default:   "\"new\"",
+			ExpString: "{status, STRING NOT NULL DEFAULT \"new\"}",
+		},
+		{
+			TestName:  "NewDef Default function",
+			ColName:   "id",
+			ColType:   tokens.UUID,
+			Default:   "GEN_RANDOM_UUID ( )",
+			ExpString: "{id, UUID DEFAULT GEN_RANDOM_UUID ( )}",
+		},
+		{
+			TestName:    "NewDef Generated",
+			ColName:     "id",
+			ColType:     tokens.Int,
+			IsNotNull:   true,
+			Default:     "NEXTVAL ( \"orders_id_seq\" )",
+			IsGenerated: true,
+			ExpString:   "{id, INT NOT NULL GENERATED ALWAYS DEFAULT NEXTVAL ( \"orders_id_seq\" )}",
+		},
 	}
 
 	for i, row := range data {
@@ -78,15 +160,21 @@ func TestDef(t *testing.T) {
 }
 
 type DefData struct {
-	TestName  string
-	ColName   string
-	ColType   tokens.TokenID
-	TableName string
-	Idx       int
-	IsNotNull bool
-	ExpCD     column.Def
-	ExpString string
-	ExpErr    string
+	TestName    string
+	ColName     string
+	ColType     tokens.TokenID
+	TableName   string
+	Idx         int
+	IsNotNull   bool
+	Precision   int
+	Scale       int
+	DeclType    tokens.TokenID
+	Default     string
+	IsGenerated bool
+	ExpCD       column.Def
+	ExpString   string
+	ExpWidth    int
+	ExpErr      string
 }
 
 func testDefFunc(d DefData) func(*testing.T) {
@@ -104,6 +192,11 @@ func testDefFunc(d DefData) func(*testing.T) {
 		if d.TableName != "" {
 			cd.TableName = d.TableName
 		}
+		cd.Precision = d.Precision
+		cd.Scale = d.Scale
+		cd.DeclType = d.DeclType
+		cd.Default = d.Default
+		cd.IsGenerated = d.IsGenerated
 
 		if d.ExpString != cd.String() {
 			t.Errorf("String %q does not match expected: %q", cd.String(), d.ExpString)
@@ -115,6 +208,9 @@ func testDefFunc(d DefData) func(*testing.T) {
 			!moniker.Equal(colref.TableName, moniker.New(cd.TableName, "")) || colref.DisplayTableName != false {
 			t.Errorf(".Ref() value did not match expected: %v", colref)
 		}
+		if cd.Width() != d.ExpWidth || colref.Width != d.ExpWidth {
+			t.Errorf("Width %d and Ref Width %d do not match expected: %d", cd.Width(), colref.Width, d.ExpWidth)
+		}
 		bin := sqbin.NewCodec(nil)
 		cd.Encode(bin)
 		newCd := column.Def{}
@@ -132,3 +228,26 @@ func testDefFunc(d DefData) func(*testing.T) {
 
 	}
 }
+
+func TestDecodeOriginalLayout(t *testing.T) {
+	defer sqtest.PanicTestRecovery(t, "")
+
+	// A Def in the layout from before the version was added
+	bin := sqbin.NewCodec(nil)
+	bin.WriteString("col1")
+	bin.WriteUint64(uint64(tokens.String))
+	bin.WriteInt(2)
+	bin.WriteBool(true)
+	bin.WriteString("tab1")
+	bin.WriteString("next")
+
+	cd := column.Def{}
+	cd.Decode(bin)
+	exp := column.Def{ColName: "col1", ColType: tokens.String, Idx: 2, IsNotNull: true, TableName: "tab1"}
+	if !reflect.DeepEqual(cd, exp) {
+		t.Errorf("Decoded column.Def %v does not match expected: %v", cd, exp)
+	}
+	if s := bin.ReadString(); s != "next" {
+		t.Errorf("Value after the column.Def %q does not match expected: %q", s, "next")
+	}
+}
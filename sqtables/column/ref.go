@@ -7,7 +7,9 @@ import (
 	"github.com/wilphi/sqsrv/tokens"
 )
 
-// Ref - reference to a column definition
+// Ref - reference to a column definition. Width is the display width from the declared size of the
+//   column or 0 if it is not known. Width is not encoded, it is set again from the Def when the Ref is
+//   validated
 type Ref struct {
 	ColName          string
 	ColType          tokens.TokenID
@@ -16,6 +18,7 @@ type Ref struct {
 	TableName        *moniker.Moniker
 	DisplayTableName bool
 	SortType         tokens.TokenID
+	Width            int
 }
 
 // NewRef creates a new column reference
@@ -73,6 +76,7 @@ func MergeRefDef(colA Ref, colB Def) (Ref, error) {
 	result.ColType = colB.ColType
 	result.Idx = colB.Idx
 	result.IsNotNull = colB.IsNotNull
+	result.Width = colB.Width()
 
 	if colA.TableName != nil {
 		if colA.TableName.Name() != colB.TableName {
@@ -173,3 +173,33 @@ func testRefFunc(d RefData) func(*testing.T) {
 
 	}
 }
+
+func TestRefEncodeWidth(t *testing.T) {
+	defer sqtest.PanicTestRecovery(t, "")
+
+	// The Width is not encoded so Refs in existing transaction logs can still be decoded
+	bin := sqbin.NewCodec(nil)
+	bin.WriteString("col1")
+	bin.WriteUint64(uint64(tokens.String))
+	bin.WriteInt(2)
+	bin.WriteBool(true)
+	bin.WriteString("tab1")
+	bin.WriteString("t")
+	bin.WriteBool(false)
+	bin.WriteUint64(uint64(tokens.Asc))
+
+	col := column.Ref{ColName: "col1", ColType: tokens.String, Idx: 2, IsNotNull: true, TableName: moniker.New("tab1", "t"), SortType: tokens.Asc, Width: 10}
+	enc := sqbin.NewCodec(nil)
+	col.Encode(enc)
+	if !reflect.DeepEqual(enc.Bytes(), bin.Bytes()) {
+		t.Error("Encoded column.Ref does not match the expected layout")
+		return
+	}
+
+	newCol := column.Ref{}
+	newCol.Decode(bin)
+	col.Width = 0
+	if !reflect.DeepEqual(newCol, col) {
+		t.Errorf("Decoded column.Ref %v does not match expected: %v", newCol, col)
+	}
+}
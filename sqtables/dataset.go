@@ -1,7 +1,10 @@
 package sqtables
 
 import (
+	"container/heap"
 	"sort"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/wilphi/sqsrv/sqerr"
 	"github.com/wilphi/sqsrv/sqprofile"
@@ -38,6 +41,8 @@ type DataSet struct {
 	order      SortOrder
 	validOrder bool
 	eList      *ExprList
+	limit      int
+	convTypes  map[int]tokens.TokenID
 }
 
 // GetColNames - returns a string array of column names
@@ -112,32 +117,37 @@ func (d *DataSet) Swap(i, j int) {
 
 // Less is part of sort Interface
 func (d *DataSet) Less(i, j int) bool {
+	return d.lessRow(d.Vals[i], d.Vals[j])
+}
+
+// lessRow compares two rows using the sort order of the dataset
+func (d *DataSet) lessRow(a, b []sqtypes.Value) bool {
 	if len(d.order) > 0 {
 		for x := range d.order {
 			col := d.order[x]
-			nullA := d.Vals[i][col.idx] == nil || d.Vals[i][col.idx].IsNull()
-			nullB := d.Vals[j][col.idx] == nil || d.Vals[j][col.idx].IsNull()
+			nullA := a[col.idx] == nil || a[col.idx].IsNull()
+			nullB := b[col.idx] == nil || b[col.idx].IsNull()
 			if nullA && nullB {
 				continue
 			}
-			if d.Vals[i][col.idx].LessThan(d.Vals[j][col.idx]) || nullB {
+			if a[col.idx].LessThan(b[col.idx]) || nullB {
 				return col.SortType == tokens.Asc
 			}
-			if d.Vals[i][col.idx].GreaterThan(d.Vals[j][col.idx]) || nullA {
+			if a[col.idx].GreaterThan(b[col.idx]) || nullA {
 				return col.SortType != tokens.Asc
 			}
 		}
 	} else {
 		for x := 0; x < d.eList.Len(); x++ {
-			nullA := d.Vals[i][x].IsNull()
-			nullB := d.Vals[j][x].IsNull()
+			nullA := a[x].IsNull()
+			nullB := b[x].IsNull()
 			if nullA && nullB {
 				continue
 			}
-			if d.Vals[i][x].LessThan(d.Vals[j][x]) || nullB {
+			if a[x].LessThan(b[x]) || nullB {
 				return true
 			}
-			if d.Vals[i][x].GreaterThan(d.Vals[j][x]) || nullA {
+			if a[x].GreaterThan(b[x]) || nullA {
 				return false
 			}
 		}
@@ -145,7 +155,7 @@ func (d *DataSet) Less(i, j int) bool {
 	return true
 }
 
-// Distinct sorts and removes duplicate rows in the data set
+// Distinct sorts and removes duplicate rows in the data set. Null values are treated as equal
 func (d *DataSet) Distinct() {
 	sort.Sort(d)
 	if (len(d.Vals) - 1) > 0 {
@@ -153,7 +163,7 @@ func (d *DataSet) Distinct() {
 		for i := 0; i < len(d.Vals)-1; i++ {
 			match := false
 			for j := 0; j < len(d.Vals[i]); j++ {
-				if d.Vals[i][j].Equal(d.Vals[i+1][j]) {
+				if d.Vals[i][j].Equal(d.Vals[i+1][j]) || (d.Vals[i][j].IsNull() && d.Vals[i+1][j].IsNull()) {
 					match = true
 				} else {
 					match = false
@@ -168,16 +178,259 @@ func (d *DataSet) Distinct() {
 	}
 }
 
+// SetOperation combines the rows of d2 with the dataset using UNION, INTERSECT or EXCEPT.
+//   Unless all is true duplicate rows are removed from the result.
+func (d *DataSet) SetOperation(op tokens.TokenID, all bool, d2 *DataSet) error {
+	err := d.CheckCompatible(op, d2)
+	if err != nil {
+		return err
+	}
+
+	switch op {
+	case tokens.Union:
+		d.Vals = append(d.Vals, d2.Vals...)
+		if !all {
+			d.Distinct()
+		}
+		return nil
+	case tokens.Intersect, tokens.Except:
+	default:
+		return sqerr.NewInternalf("%s is not a valid set operation", tokens.IDName(op))
+	}
+
+	if !all {
+		d.Distinct()
+	}
+	sortRows(d.Vals)
+	other := make(sqtypes.ValueMatrix, len(d2.Vals))
+	copy(other, d2.Vals)
+	sortRows(other)
+
+	// Both lists are sorted so merge them. Each row in other can only match one row in d
+	result := make(sqtypes.ValueMatrix, 0, len(d.Vals))
+	j := 0
+	for _, row := range d.Vals {
+		for j < len(other) && compareRows(other[j], row) < 0 {
+			j++
+		}
+		match := j < len(other) && compareRows(other[j], row) == 0
+		if match {
+			j++
+		}
+		if match == (op == tokens.Intersect) {
+			result = append(result, row)
+		}
+	}
+	d.Vals = result
+	return nil
+}
+
+// PadChars pads the values of CHAR columns with spaces to the length of the column. The values are
+//   stored without trailing spaces so this is done when the result of a query is returned
+func (d *DataSet) PadChars() {
+	for x, ex := range d.eList.exprlist {
+		col, ok := ex.(*ColExpr)
+		if !ok || col.padTo == 0 {
+			continue
+		}
+		for _, row := range d.Vals {
+			str, ok := row[x].(sqtypes.SQString)
+			if !ok {
+				continue
+			}
+			if n := utf8.RuneCountInString(str.Val); n < col.padTo {
+				row[x] = sqtypes.NewSQString(str.Val + strings.Repeat(" ", col.padTo-n))
+			}
+		}
+	}
+}
+
+// CheckCompatible makes sure that d2 has the same number of columns as the dataset and that the
+//   column types match so that they can be combined by the set operation op. The types come from
+//   the expressions of the columns. INT, FLOAT and DECIMAL columns can be combined, the values in
+//   both datasets are converted to the wider type
+func (d *DataSet) CheckCompatible(op tokens.TokenID, d2 *DataSet) error {
+	opName := tokens.IDName(op)
+	if d.NumCols() != d2.NumCols() {
+		return sqerr.Newf("Each SELECT in %s must have the same number of columns: %d != %d", opName, d.NumCols(), d2.NumCols())
+	}
+	for x := 0; x < d.NumCols(); x++ {
+		typeA := d.exprType(x)
+		typeB := d2.exprType(x)
+		switch {
+		case typeA == tokens.Null || typeB == tokens.Null || typeA == typeB:
+		case isNumericType(typeA) && isNumericType(typeB):
+			var wide tokens.TokenID = tokens.Decimal
+			if typeA == tokens.Float || typeB == tokens.Float {
+				wide = tokens.Float
+			}
+			if err := d.convertCol(x, wide); err != nil {
+				return err
+			}
+			if err := d2.convertCol(x, wide); err != nil {
+				return err
+			}
+		default:
+			return sqerr.Newf("Type Mismatch: Column %d in %s has types %s and %s", x+1, opName, tokens.IDName(typeA), tokens.IDName(typeB))
+		}
+	}
+	return nil
+}
+
+// exprType returns the type of the column. It is the type of the column expression unless the
+//   values have been converted by a set operation. If the expression does not have a known type,
+//   the type of the values in the column is used
+func (d *DataSet) exprType(idx int) tokens.TokenID {
+	if typ, ok := d.convTypes[idx]; ok {
+		return typ
+	}
+	if typ := d.eList.exprlist[idx].ColRef().ColType; isValueType(typ) {
+		return typ
+	}
+	return d.colType(idx)
+}
+
+// convertCol converts all of the values in the column to the given type
+func (d *DataSet) convertCol(idx int, typ tokens.TokenID) error {
+	for _, row := range d.Vals {
+		if row[idx] == nil || row[idx].IsNull() || row[idx].Type() == typ {
+			continue
+		}
+		v, err := row[idx].Convert(typ)
+		if err != nil {
+			return err
+		}
+		row[idx] = v
+	}
+	if d.convTypes == nil {
+		d.convTypes = make(map[int]tokens.TokenID)
+	}
+	d.convTypes[idx] = typ
+	return nil
+}
+
+// isValueType returns true if the token is the type of a value
+func isValueType(id tokens.TokenID) bool {
+	for _, typ := range tokens.AllTypes {
+		if typ == id {
+			return true
+		}
+	}
+	return false
+}
+
+// colType returns the type of the first non null value in the column
+func (d *DataSet) colType(idx int) tokens.TokenID {
+	for _, row := range d.Vals {
+		if row[idx] != nil && !row[idx].IsNull() {
+			return row[idx].Type()
+		}
+	}
+	return tokens.Null
+}
+
+// sortRows sorts the rows using all columns in ascending order with nulls last
+func sortRows(rows sqtypes.ValueMatrix) {
+	sort.Slice(rows, func(i, j int) bool { return compareRows(rows[i], rows[j]) < 0 })
+}
+
+// compareRows returns -1, 0, 1 if row a is less than, equal to or greater than row b. Nulls sort last
+func compareRows(a, b []sqtypes.Value) int {
+	for x := range a {
+		nullA := a[x] == nil || a[x].IsNull()
+		nullB := b[x] == nil || b[x].IsNull()
+		switch {
+		case nullA && nullB:
+			continue
+		case nullA:
+			return 1
+		case nullB:
+			return -1
+		case a[x].LessThan(b[x]):
+			return -1
+		case a[x].GreaterThan(b[x]):
+			return 1
+		}
+	}
+	return 0
+}
+
 // Sort is a convenience function
+//   If a limit has been set only the first limit rows are kept. They are found
+//   using a bounded heap so that the full dataset does not need to be sorted.
+//   The heap only bounds the memory used by the sort, all of the rows have already
+//   been read into the dataset. A LIMIT without an ORDER BY stops reading rows
+//   early in Query.GetRowData instead.
 func (d *DataSet) Sort() error {
 	if len(d.order) <= 0 || !d.validOrder {
 		return sqerr.New("Sort Order has not been set for DataSet")
 	}
 
-	sort.Sort(d)
+	if d.limit <= 0 || d.limit >= len(d.Vals) {
+		sort.Sort(d)
+		return nil
+	}
+
+	// The heap keeps the worst of the top rows at the root so it can be replaced
+	h := &topNHeap{d: d, rows: make(sqtypes.ValueMatrix, 0, d.limit)}
+	for _, row := range d.Vals {
+		if len(h.rows) < d.limit {
+			heap.Push(h, row)
+		} else if d.lessRow(row, h.rows[0]) && !d.lessRow(h.rows[0], row) {
+			h.rows[0] = row
+			heap.Fix(h, 0)
+		}
+	}
+
+	// Pop the rows off the heap from worst to best
+	vals := make(sqtypes.ValueMatrix, len(h.rows))
+	for i := len(vals) - 1; i >= 0; i-- {
+		vals[i] = heap.Pop(h).([]sqtypes.Value)
+	}
+	d.Vals = vals
 	return nil
 }
 
+// SetLimit bounds the number of rows that will be kept by Sort. A limit <= 0 removes the bound
+func (d *DataSet) SetLimit(limit int) {
+	d.limit = limit
+}
+
+// Slice reduces the dataset to at most limit rows starting at offset. A limit < 0 keeps all remaining rows
+func (d *DataSet) Slice(offset, limit int) {
+	if offset >= len(d.Vals) {
+		d.Vals = d.Vals[:0]
+		return
+	}
+	if offset > 0 {
+		d.Vals = d.Vals[offset:]
+	}
+	if limit >= 0 && limit < len(d.Vals) {
+		d.Vals = d.Vals[:limit]
+	}
+}
+
+// topNHeap is a max heap (based on the dataset sort order) used to find the first n rows of a dataset
+type topNHeap struct {
+	d    *DataSet
+	rows sqtypes.ValueMatrix
+}
+
+func (h *topNHeap) Len() int           { return len(h.rows) }
+func (h *topNHeap) Less(i, j int) bool { return h.d.lessRow(h.rows[j], h.rows[i]) }
+func (h *topNHeap) Swap(i, j int)      { h.rows[i], h.rows[j] = h.rows[j], h.rows[i] }
+
+func (h *topNHeap) Push(x interface{}) {
+	h.rows = append(h.rows, x.([]sqtypes.Value))
+}
+
+func (h *topNHeap) Pop() interface{} {
+	n := len(h.rows)
+	row := h.rows[n-1]
+	h.rows = h.rows[:n-1]
+	return row
+}
+
 // DSRow defines row definition for datasets
 type DSRow struct {
 	Ptr       sqptr.SQPtr
@@ -295,6 +295,74 @@ func TestDataSet(t *testing.T) {
 			ExpVals:  sqtypes.ValueMatrix{rw1, rw2, rw3, rw4, rw5, rw6, rw7, rwNil10, rwNil11, rwNil12},
 			Distinct: true,
 		},
+		{
+			TestName: "Sort Dataset with Limit",
+			Tables:   tables,
+			DataCols: exprCols,
+			InitVals: vals,
+			Order:    sqtables.SortOrder{{ColName: "col2", SortType: tokens.Asc}, {ColName: "col1", SortType: tokens.Asc}},
+			SONames:  []string{"col2", "col1"},
+			SOString: "(col2, col1)",
+			Limit:    3,
+			ExpVals:  sqtypes.ValueMatrix{rw1, rw1, rw2},
+		},
+		{
+			TestName: "Sort Dataset desc with Limit",
+			Tables:   tables,
+			DataCols: exprCols,
+			InitVals: vals,
+			Order:    sqtables.SortOrder{{ColName: "col2", SortType: tokens.Desc}, {ColName: "col1", SortType: tokens.Desc}},
+			SONames:  []string{"col2", "col1"},
+			SOString: "(col2 DESC, col1 DESC)",
+			Limit:    4,
+			ExpVals:  sqtypes.ValueMatrix{rw7, rw6, rw5, rw4},
+		},
+		{
+			TestName: "Sort Dataset with Limit & Offset",
+			Tables:   tables,
+			DataCols: exprCols,
+			InitVals: vals,
+			Order:    sqtables.SortOrder{{ColName: "col2", SortType: tokens.Asc}, {ColName: "col1", SortType: tokens.Asc}},
+			SONames:  []string{"col2", "col1"},
+			SOString: "(col2, col1)",
+			Limit:    3,
+			Offset:   2,
+			ExpVals:  sqtypes.ValueMatrix{rw2, rw3, rw4},
+		},
+		{
+			TestName: "Sort Dataset with Limit larger than Dataset",
+			Tables:   tables,
+			DataCols: exprCols,
+			InitVals: vals,
+			Order:    sqtables.SortOrder{{ColName: "col2", SortType: tokens.Asc}, {ColName: "col1", SortType: tokens.Asc}},
+			SONames:  []string{"col2", "col1"},
+			SOString: "(col2, col1)",
+			Limit:    20,
+			ExpVals:  sqtypes.ValueMatrix{rw1, rw1, rw2, rw3, rw4, rw5, rw6, rw7},
+		},
+		{
+			TestName: "Sort Dataset with Offset past end",
+			Tables:   tables,
+			DataCols: exprCols,
+			InitVals: vals,
+			Order:    sqtables.SortOrder{{ColName: "col2", SortType: tokens.Asc}, {ColName: "col1", SortType: tokens.Asc}},
+			SONames:  []string{"col2", "col1"},
+			SOString: "(col2, col1)",
+			Limit:    2,
+			Offset:   8,
+			ExpVals:  sqtypes.ValueMatrix{},
+		},
+		{
+			TestName: "Sort Dataset with nulls & Limit",
+			Tables:   tables,
+			DataCols: exprCols,
+			InitVals: valsWithNull,
+			Order:    sqtables.SortOrder{{ColName: "col2", SortType: tokens.Desc}, {ColName: "col1", SortType: tokens.Asc}},
+			SONames:  []string{"col2", "col1"},
+			SOString: "(col2 DESC, col1)",
+			Limit:    4,
+			ExpVals:  sqtypes.ValueMatrix{rwNil10, rwNil11, rwNil12, rw6},
+		},
 	}
 
 	for i, row := range data {
@@ -317,6 +385,8 @@ type SortData struct {
 	SortOrderErr string
 	SortErr      string
 	Distinct     bool
+	Limit        int
+	Offset       int
 }
 
 func testSortFunc(d SortData) func(*testing.T) {
@@ -342,10 +412,16 @@ func testSortFunc(d SortData) func(*testing.T) {
 				return
 			}
 
+			if d.Limit > 0 {
+				data.SetLimit(d.Offset + d.Limit)
+			}
 			err = data.Sort()
 			if sqtest.CheckErr(t, err, d.SortErr) {
 				return
 			}
+			if d.Limit > 0 {
+				data.Slice(d.Offset, d.Limit)
+			}
 
 		}
 		if d.ExpVals != nil {
@@ -374,6 +450,188 @@ func testSortFunc(d SortData) func(*testing.T) {
 ///////////////////////////////////////////////////////////////////////////////////////////
 //
 
+func TestSetOperation(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+
+	tableName := "setopdataset"
+	tab := sqtables.CreateTableDef(tableName,
+		[]column.Def{
+			column.NewDef("col1", tokens.Int, false),
+			column.NewDef("col2", tokens.String, false),
+		},
+	)
+	err := sqtables.CreateTable(profile, tab)
+	if err != nil {
+		t.Error("Error creating table: ", err)
+		return
+	}
+	tables := sqtables.NewTableListFromTableDef(profile, tab)
+	colds := []column.Ref{{ColName: "col2", ColType: tokens.String}, {ColName: "col1", ColType: tokens.Int}}
+	exprCols := sqtables.ColsToExpr(column.NewListRefs(colds))
+	oneCol := sqtables.ColsToExpr(column.NewListRefs(colds[:1]))
+	swapCols := sqtables.ColsToExpr(column.NewListRefs([]column.Ref{colds[1], colds[0]}))
+	floatCols := sqtables.NewExprList(sqtables.NewColExpr(colds[0]), sqtables.NewValueExpr(sqtypes.NewSQFloat(2.5)))
+
+	valsA := sqtypes.RawVals{{"aaa", 1}, {"bbb", 2}, {"bbb", 2}, {"ccc", 3}, {nil, 4}}
+	valsB := sqtypes.RawVals{{"bbb", 2}, {"ddd", 4}, {nil, 4}, {"ccc", 3}, {"ccc", 3}}
+
+	data := []SetOpData{
+		{
+			TestName: "UNION",
+			Op:       tokens.Union,
+			ValsA:    valsA,
+			ValsB:    valsB,
+			ExpVals:  sqtypes.RawVals{{"aaa", 1}, {"bbb", 2}, {"ccc", 3}, {"ddd", 4}, {nil, 4}},
+		},
+		{
+			TestName: "UNION ALL",
+			Op:       tokens.Union,
+			All:      true,
+			ValsA:    valsA,
+			ValsB:    valsB,
+			ExpVals: sqtypes.RawVals{{"aaa", 1}, {"bbb", 2}, {"bbb", 2}, {"ccc", 3}, {nil, 4},
+				{"bbb", 2}, {"ddd", 4}, {nil, 4}, {"ccc", 3}, {"ccc", 3}},
+		},
+		{
+			TestName: "INTERSECT",
+			Op:       tokens.Intersect,
+			ValsA:    valsA,
+			ValsB:    valsB,
+			ExpVals:  sqtypes.RawVals{{"bbb", 2}, {"ccc", 3}, {nil, 4}},
+		},
+		{
+			TestName: "INTERSECT ALL",
+			Op:       tokens.Intersect,
+			All:      true,
+			ValsA:    valsB,
+			ValsB:    valsA,
+			ExpVals:  sqtypes.RawVals{{"bbb", 2}, {"ccc", 3}, {nil, 4}},
+		},
+		{
+			TestName: "EXCEPT",
+			Op:       tokens.Except,
+			ValsA:    valsA,
+			ValsB:    valsB,
+			ExpVals:  sqtypes.RawVals{{"aaa", 1}},
+		},
+		{
+			TestName: "EXCEPT ALL",
+			Op:       tokens.Except,
+			All:      true,
+			ValsA:    valsA,
+			ValsB:    valsB,
+			ExpVals:  sqtypes.RawVals{{"aaa", 1}, {"bbb", 2}},
+		},
+		{
+			TestName: "EXCEPT empty",
+			Op:       tokens.Except,
+			ValsA:    valsA,
+			ValsB:    sqtypes.RawVals{},
+			ExpVals:  sqtypes.RawVals{{"aaa", 1}, {"bbb", 2}, {"ccc", 3}, {nil, 4}},
+		},
+		{
+			TestName: "Type Mismatch",
+			Op:       tokens.Union,
+			ValsA:    valsA,
+			ValsB:    sqtypes.RawVals{{1, "x"}},
+			ColsB:    swapCols,
+			ExpErr:   "Error: Type Mismatch: Column 1 in UNION has types STRING and INT",
+		},
+		{
+			TestName: "Type Mismatch with no rows",
+			Op:       tokens.Union,
+			ValsA:    sqtypes.RawVals{},
+			ValsB:    sqtypes.RawVals{{1, "x"}},
+			ColsB:    swapCols,
+			ExpErr:   "Error: Type Mismatch: Column 1 in UNION has types STRING and INT",
+		},
+		{
+			TestName: "UNION INT and FLOAT",
+			Op:       tokens.Union,
+			All:      true,
+			ValsA:    sqtypes.RawVals{{"aaa", 1}},
+			ValsB:    sqtypes.RawVals{{"bbb", 2.5}},
+			ColsB:    floatCols,
+			ExpVals:  sqtypes.RawVals{{"aaa", 1.0}, {"bbb", 2.5}},
+		},
+		{
+			TestName: "Type Mismatch with null col",
+			Op:       tokens.Union,
+			ValsA:    sqtypes.RawVals{{nil, 1}},
+			ValsB:    sqtypes.RawVals{{1, 2}},
+			ExpVals:  sqtypes.RawVals{{1, 2}, {nil, 1}},
+		},
+		{
+			TestName: "Column count mismatch",
+			Op:       tokens.Intersect,
+			ValsA:    valsA,
+			ValsB:    sqtypes.RawVals{{"aaa"}},
+			ColsB:    oneCol,
+			ExpErr:   "Error: Each SELECT in INTERSECT must have the same number of columns: 2 != 1",
+		},
+		{
+			TestName: "Invalid Operation",
+			Op:       tokens.Select,
+			ValsA:    valsA,
+			ValsB:    valsB,
+			ExpErr:   "Internal Error: SELECT is not a valid set operation",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testSetOperationFunc(profile, tables, exprCols, row))
+
+	}
+}
+
+type SetOpData struct {
+	TestName string
+	Op       tokens.TokenID
+	All      bool
+	ValsA    sqtypes.RawVals
+	ValsB    sqtypes.RawVals
+	ColsB    *sqtables.ExprList
+	ExpVals  sqtypes.RawVals
+	ExpErr   string
+}
+
+func testSetOperationFunc(profile *sqprofile.SQProfile, tables sqtables.TableList, eList *sqtables.ExprList, d SetOpData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		colsB := eList
+		if d.ColsB != nil {
+			colsB = d.ColsB
+		}
+		dataA, err := sqtables.NewDataSet(profile, tables, eList)
+		if err != nil {
+			t.Errorf("Unexpected Error in test: %s", err.Error())
+			return
+		}
+		dataA.Vals = d.ValsA.ValueMatrix()
+		dataB, err := sqtables.NewDataSet(profile, tables, colsB)
+		if err != nil {
+			t.Errorf("Unexpected Error in test: %s", err.Error())
+			return
+		}
+		dataB.Vals = d.ValsB.ValueMatrix()
+
+		err = dataA.SetOperation(d.Op, d.All, dataB)
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+		msg := sqtypes.Compare2DValue(dataA.Vals, d.ExpVals.ValueMatrix(), "Actual", "Expect", false)
+		if msg != "" {
+			t.Error(msg)
+			return
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////
+//
+
 func TestDSColVal(t *testing.T) {
 
 	DSRow1 := sqtables.DSRow{
@@ -29,6 +29,25 @@ var infoFile = "/info.sqd"
 type DBInfo struct {
 	LastTransID uint64
 	Tables      []string
+	Views       []DBView
+	Sequences   []DBSequence
+}
+
+// DBView stores view information
+type DBView struct {
+	ViewName string
+	Cols     []string
+	Query    string
+}
+
+// DBSequence stores sequence information including its current value
+type DBSequence struct {
+	SeqName   string
+	Start     int
+	Increment int
+	Owner     string
+	Value     int
+	IsCalled  bool
 }
 
 // DBTable stores table information
@@ -37,6 +56,13 @@ type DBTable struct {
 	Cols       []column.Def
 	NRows      int
 	NextRowPtr uint64
+	Checks     []DBCheck
+}
+
+// DBCheck stores a CHECK constraint as the SQL text of its expression
+type DBCheck struct {
+	Name string
+	Text string
 }
 
 // DBRow -
@@ -55,6 +81,14 @@ func init() {
 	gob.Register(sqtypes.SQInt{})
 	gob.Register(sqtypes.SQBool{})
 	gob.Register(sqtypes.SQNull{})
+	gob.Register(sqtypes.SQDate{})
+	gob.Register(sqtypes.SQTime{})
+	gob.Register(sqtypes.SQTimestamp{})
+	gob.Register(sqtypes.SQInterval{})
+	gob.Register(sqtypes.SQDecimal{})
+	gob.Register(sqtypes.SQBlob{})
+	gob.Register(sqtypes.SQJSON{})
+	gob.Register(sqtypes.SQUUID{})
 }
 
 // SetDBDir sets the path to the directory that contains the database files
@@ -91,7 +125,15 @@ func WriteDB(profile *sqprofile.SQProfile) error {
 	if err != nil {
 		return err
 	}
-	info := DBInfo{LastTransID: id, Tables: tables}
+	views, err := catalogDBViews(profile)
+	if err != nil {
+		return err
+	}
+	sequences, err := catalogDBSequences(profile)
+	if err != nil {
+		return err
+	}
+	info := DBInfo{LastTransID: id, Tables: tables, Views: views, Sequences: sequences}
 
 	err = writeDBInfo(profile, info)
 	if err != nil {
@@ -119,6 +161,41 @@ func WriteDB(profile *sqprofile.SQProfile) error {
 	log.Infof("Checkpoint Completed. TransactionId = %d", transid.GetTransID())
 	return nil
 }
+// catalogDBViews returns the list of views in the catalog in the form they are stored
+func catalogDBViews(profile *sqprofile.SQProfile) ([]DBView, error) {
+	names, err := CatalogViews(profile)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]DBView, len(names))
+	for i, name := range names {
+		v, err := GetView(profile, name)
+		if err != nil {
+			return nil, err
+		}
+		views[i] = DBView{ViewName: v.viewName, Cols: v.cols, Query: v.query}
+	}
+	return views, nil
+}
+
+// catalogDBSequences returns the list of sequences in the catalog in the form they are stored
+func catalogDBSequences(profile *sqprofile.SQProfile) ([]DBSequence, error) {
+	names, err := CatalogSequences(profile)
+	if err != nil {
+		return nil, err
+	}
+	sequences := make([]DBSequence, len(names))
+	for i, name := range names {
+		seq, err := GetSequence(profile, name)
+		if err != nil {
+			return nil, err
+		}
+		value, isCalled := seq.checkpointState()
+		sequences[i] = DBSequence{SeqName: seq.seqName, Start: seq.start, Increment: seq.increment, Owner: seq.owner, Value: value, IsCalled: isCalled}
+	}
+	return sequences, nil
+}
+
 func writeDBInfo(profile *sqprofile.SQProfile, d DBInfo) error {
 
 	file, err := os.OpenFile(dbDirectory+infoFile, os.O_CREATE|os.O_WRONLY, 0644)
@@ -132,6 +209,16 @@ func writeDBInfo(profile *sqprofile.SQProfile, d DBInfo) error {
 	return err
 }
 
+// numberDroppedFile renumbers the file of a dropped table. There is no file if the table was dropped
+//   before a checkpoint wrote it or if the file was renumbered by an earlier checkpoint
+func numberDroppedFile(fileName string) error {
+	isFile, err := files.Exists(fileName)
+	if err != nil || !isFile {
+		return err
+	}
+	return files.NumberFile(fileName, maxFiles)
+}
+
 func writeDBTableInfo(profile *sqprofile.SQProfile, tName string) error {
 	fileName := dbDirectory + "/" + tName + ".sqt"
 
@@ -140,14 +227,15 @@ func writeDBTableInfo(profile *sqprofile.SQProfile, tName string) error {
 		return err
 	}
 	if td == nil {
-		err = files.NumberFile(fileName, maxFiles)
-		if err != nil {
-			return err
-		}
-		return nil
+		return numberDroppedFile(fileName)
 	}
 
 	tab := DBTable{TableName: tName, Cols: td.tableCols, NRows: len(td.rowm), NextRowPtr: *td.nextRowID}
+	for _, con := range td.constraints {
+		if chk, ok := con.(*Check); ok {
+			tab.Checks = append(tab.Checks, DBCheck{Name: chk.Name, Text: chk.Text})
+		}
+	}
 	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		//	log.Fatal(err)
@@ -177,14 +265,16 @@ func writeDBTableData(profile *sqprofile.SQProfile, tName string) error {
 		return err
 	}
 	if td == nil {
-		err := files.NumberFile(fileName, maxFiles)
-		if err != nil {
-			return err
-		}
-		return nil
+		return numberDroppedFile(fileName)
 	}
 
-	datafile, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY, 0644)
+	flags := os.O_CREATE | os.O_WRONLY
+	if td.isTruncated {
+		// All rows were removed from the table so the old contents of the file are discarded
+		flags |= os.O_TRUNC
+		td.isTruncated = false
+	}
+	datafile, err := os.OpenFile(fileName, flags, 0644)
 	if err != nil {
 		//	log.Fatal(err)
 		log.Panic(err)
@@ -394,6 +484,22 @@ func ReadDB(profile *sqprofile.SQProfile) error {
 			log.Panicf("Unable to read table data for %s: %s", tableName, err)
 		}
 	}
+	for _, dbView := range info.Views {
+		log.Info("Loading view " + dbView.ViewName)
+		err = CreateView(profile, CreateViewDef(dbView.ViewName, dbView.Cols, dbView.Query))
+		if err != nil {
+			log.Panicf("Unable to create view %s: %s", dbView.ViewName, err)
+		}
+	}
+	for _, dbSeq := range info.Sequences {
+		log.Info("Loading sequence " + dbSeq.SeqName)
+		seq := CreateSequenceDef(dbSeq.SeqName, dbSeq.Start, dbSeq.Increment, dbSeq.Owner)
+		seq.SetState(dbSeq.Value, dbSeq.IsCalled)
+		err = CreateSequence(profile, seq)
+		if err != nil {
+			log.Panicf("Unable to create sequence %s: %s", dbSeq.SeqName, err)
+		}
+	}
 	length := time.Since(start)
 	log.Infof("Time spend opening Database: %v", length)
 
@@ -437,6 +543,19 @@ func readDBTableInfo(profile *sqprofile.SQProfile, tName string) (*TableDef, err
 	tabDef := CreateTableDef(tName, tab.Cols)
 	nn := tab.NextRowPtr
 	tabDef.nextRowID = &nn
+
+	// CHECK constraints are recreated from the SQL text of their expressions
+	var cons []Constraint
+	for _, dbCheck := range tab.Checks {
+		chk, err := ParseCheck(dbCheck.Name, dbCheck.Text)
+		if err != nil {
+			return nil, err
+		}
+		cons = append(cons, chk)
+	}
+	if len(cons) > 0 {
+		err = tabDef.AddConstraints(profile, cons)
+	}
 	return tabDef, err
 
 }
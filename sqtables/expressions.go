@@ -2,6 +2,7 @@ package sqtables
 
 import (
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/wilphi/assertions"
@@ -59,10 +60,12 @@ type Expr interface {
 
 //////////////////////////////////////////////////////////////////////////////////////////////////////////
 
-// ValueExpr stores a single value. It is a leaf node for the Expr tree
+// ValueExpr stores a single value. It is a leaf node for the Expr tree. text is the text of a number
+//   literal, it is used to convert the literal exactly to a DECIMAL
 type ValueExpr struct {
 	v     sqtypes.Value
 	alias string
+	text  string
 }
 
 // Left - ValueExpr is a leaf node, it will always return nil
@@ -142,6 +145,37 @@ func NewValueExpr(v sqtypes.Value) Expr {
 	return &ValueExpr{v: v}
 }
 
+// NewNumberExpr creates a new ValueExpr object for a number literal. The text of the literal is kept
+//   so that it can be converted to a DECIMAL without going through a FLOAT
+func NewNumberExpr(v sqtypes.Value, text string) Expr {
+	return &ValueExpr{v: v, text: text}
+}
+
+// LiteralForType returns the expression to use as a value of type typ. A number literal with a
+//   fraction is a FLOAT, it is converted from the text of the literal to an exact DECIMAL when typ
+//   is DECIMAL. Any other expression is returned unchanged
+func LiteralForType(ex Expr, typ tokens.TokenID) Expr {
+	if typ != tokens.Decimal {
+		return ex
+	}
+	if neg, ok := ex.(*NegateExpr); ok {
+		return NewNegateExpr(LiteralForType(neg.exL, typ))
+	}
+	val, ok := ex.(*ValueExpr)
+	if !ok || val.text == "" {
+		return ex
+	}
+	if _, ok := val.v.(sqtypes.SQFloat); !ok {
+		return ex
+	}
+	d, err := sqtypes.NewSQString(val.text).Convert(tokens.Decimal)
+	if err != nil {
+		// Literals such as 1e10 stay a FLOAT
+		return ex
+	}
+	return &ValueExpr{v: d, alias: val.alias}
+}
+
 // Encode returns a binary encoded version of the expression
 func (e *ValueExpr) Encode() *sqbin.Codec {
 	enc := sqbin.NewCodec(nil)
@@ -175,10 +209,12 @@ func (e *ValueExpr) IsAggregate() bool {
 
 ///////////////////////////////////////////////////////////////////////////////////////////////////
 
-// ColExpr stores information about a column to allow Evaluate() to determine the correct Value
+// ColExpr stores information about a column to allow Evaluate() to determine the correct Value.
+//   padTo is the length of a CHAR column, the values are stored without trailing spaces
 type ColExpr struct {
 	col   column.Ref
 	alias string
+	padTo int
 }
 
 // Left - ColExpr is a leaf node, it will always return nil
@@ -256,11 +292,19 @@ func (e *ColExpr) ColRefs(names ...*moniker.Moniker) []column.Ref {
 func (e *ColExpr) Evaluate(profile *sqprofile.SQProfile, partial bool, rows ...RowInterface) (sqtypes.Value, error) {
 	var row RowInterface
 
-	// Find the row with the proper table name
-	for _, rw := range rows {
-		if e.col.TableName != nil && e.col.TableName.Name() == rw.GetTableName(profile) {
-			row = rw
-			break
+	// Find the row with the proper table name. A row of an aliased table is named by its alias
+	if e.col.TableName != nil {
+		for _, rw := range rows {
+			if e.col.TableName.Show() == rw.GetTableName(profile) {
+				row = rw
+				break
+			}
+		}
+		for _, rw := range rows {
+			if row == nil && e.col.TableName.Name() == rw.GetTableName(profile) {
+				row = rw
+				break
+			}
 		}
 	}
 	if row == nil {
@@ -299,10 +343,36 @@ func (e *ColExpr) ValidateCols(profile *sqprofile.SQProfile, tables TableList) e
 	if err != nil {
 		return err
 	}
+	if cd.DeclType == tokens.Char {
+		e.padTo = cd.Precision
+	}
 	e.col, err = column.MergeRefDef(e.col, *cd)
 	return err
 }
 
+// matchLiteral returns the expression to compare with the column. Trailing spaces are removed from a
+//   STRING literal compared to a CHAR column in the same way as the values stored in the column. A
+//   STRING literal compared to a column that stores strings as another type is converted to that type
+func (e *ColExpr) matchLiteral(ex Expr) (Expr, error) {
+	val, ok := ex.(*ValueExpr)
+	if !ok {
+		return ex, nil
+	}
+	str, ok := val.v.(sqtypes.SQString)
+	switch {
+	case !ok:
+	case e.padTo > 0:
+		return &ValueExpr{v: sqtypes.NewSQString(strings.TrimRight(str.Val, " ")), alias: val.alias}, nil
+	case fromString(e.col.ColType):
+		v, err := str.Convert(e.col.ColType)
+		if err != nil {
+			return nil, err
+		}
+		return &ValueExpr{v: v, alias: val.alias}, nil
+	}
+	return ex, nil
+}
+
 // NewColExpr creates a new ColExpr object
 func NewColExpr(c column.Ref) Expr {
 	return &ColExpr{col: c}
@@ -402,8 +472,46 @@ func (e *OpExpr) Name() string {
 
 // ColRef returns a column definition for the expression
 func (e *OpExpr) ColRef() column.Ref {
-	col := e.exL.ColRef()
-	return column.Ref{ColName: e.Name(), ColType: col.ColType}
+	colType := opType(e.Operator, e.exL.ColRef().ColType, e.exR.ColRef().ColType)
+	return column.Ref{ColName: e.Name(), ColType: colType}
+}
+
+// opType returns the type of the result of the operator on values of typeL and typeR
+func opType(op, typeL, typeR tokens.TokenID) tokens.TokenID {
+	switch op {
+	case tokens.Equal, tokens.NotEqual, tokens.LessThan, tokens.GreaterThan, tokens.LessThanEqual,
+		tokens.GreaterThanEqual, tokens.And, tokens.Or:
+		return tokens.Bool
+	case tokens.Arrow:
+		return tokens.JSON
+	case tokens.DoubleArrow:
+		return tokens.String
+	}
+
+	switch {
+	case typeL == tokens.Null:
+		return typeR
+	case op == tokens.Minus && typeL == typeR && typeL == tokens.Date:
+		return tokens.Int
+	case op == tokens.Minus && typeL == typeR && (typeL == tokens.Time || typeL == tokens.Timestamp):
+		return tokens.Interval
+	case typeL == tokens.Date && typeR == tokens.Interval:
+		return tokens.Timestamp
+	case typeL == tokens.Float || typeR == tokens.Float:
+		if isNumericType(typeL) && isNumericType(typeR) {
+			return tokens.Float
+		}
+	case typeL == tokens.Decimal || typeR == tokens.Decimal:
+		if isNumericType(typeL) && isNumericType(typeR) {
+			return tokens.Decimal
+		}
+	}
+	return typeL
+}
+
+// isNumericType returns true for the INT, FLOAT and DECIMAL types
+func isNumericType(typ tokens.TokenID) bool {
+	return typ == tokens.Int || typ == tokens.Float || typ == tokens.Decimal
 }
 
 // ColRefs returns a list of all actual columns in the expression
@@ -489,7 +597,31 @@ func (e *OpExpr) ValidateCols(profile *sqprofile.SQProfile, tables TableList) er
 		return err
 	}
 	err = e.exR.ValidateCols(profile, tables)
-	return err
+	if err != nil {
+		return err
+	}
+
+	// A number literal used with a DECIMAL is an exact DECIMAL
+	e.exR = LiteralForType(e.exR, e.exL.ColRef().ColType)
+	e.exL = LiteralForType(e.exL, e.exR.ColRef().ColType)
+
+	// A literal compared to a column is matched to the values stored in the column
+	switch e.Operator {
+	case tokens.Equal, tokens.NotEqual, tokens.LessThan, tokens.GreaterThan, tokens.LessThanEqual, tokens.GreaterThanEqual:
+		if col, ok := e.exL.(*ColExpr); ok {
+			e.exR, err = col.matchLiteral(e.exR)
+			if err != nil {
+				return err
+			}
+		}
+		if col, ok := e.exR.(*ColExpr); ok {
+			e.exL, err = col.matchLiteral(e.exL)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // NewOpExpr creates a new OpExpr and returns it as an Expr
@@ -645,7 +777,14 @@ func (e *NegateExpr) Reduce() (Expr, error) {
 			return vL, sqerr.NewSyntaxf("%s values can not be negated", tokens.IDName(val.Type()))
 		}
 
-		return NewValueExpr(n.Negate()), nil
+		neg := &ValueExpr{v: n.Negate()}
+		if vL.text != "" {
+			neg.text = "-" + vL.text
+			if strings.HasPrefix(vL.text, "-") {
+				neg.text = vL.text[1:]
+			}
+		}
+		return neg, nil
 	}
 	return e, nil
 }
@@ -697,10 +836,19 @@ func (e *NegateExpr) IsAggregate() bool {
 ///////////////////////////////////////////////////////////////////////////////////////////////////
 
 // FuncExpr stores information about a function to allow Evaluate() to determine the correct Value
+//   Aggregate functions may only use the DISTINCT values of the expression or filter the rows they use.
+//   param is the separator of STRING_AGG, the fraction of PERCENTILE_CONT and PERCENTILE_DISC,
+//   the field of EXTRACT, the unit of DATE_TRUNC or the path of JSON_EXTRACT. args are the start position
+//   and length of SUBSTR or the value of JSON_OBJECTAGG
 type FuncExpr struct {
-	Cmd   tokens.TokenID
-	exL   Expr
-	alias string
+	Cmd      tokens.TokenID
+	Distinct bool
+	exL      Expr
+	args     []Expr
+	filter   Expr
+	param    sqtypes.Value
+	orderBy  []OrderExpr
+	alias    string
 }
 
 // Left - FuncExpr may have an expression
@@ -708,7 +856,7 @@ func (e *FuncExpr) Left() Expr {
 	return e.exL
 }
 
-// Right - FuncExpr currently implements functions with 0 or 1 arguments
+// Right - FuncExpr does not have a right expression, any extra arguments are kept in args
 func (e *FuncExpr) Right() Expr {
 	return nil
 }
@@ -735,12 +883,72 @@ func (e *FuncExpr) String() string {
 // Build - uses a Builder to create a string representation of the Expression
 func (e *FuncExpr) Build(b *strings.Builder) {
 	b.WriteString(tokens.IDName(e.Cmd))
+	if e.Cmd == tokens.CurrentDate {
+		// CURRENT_DATE is used without brackets
+		e.buildAlias(b)
+		return
+	}
 	b.WriteString("(")
-	if e.exL != nil {
+	if e.Distinct {
+		b.WriteString("DISTINCT ")
+	}
+	switch e.Cmd {
+	case tokens.PercentileCont, tokens.PercentileDisc:
+		b.WriteString(e.param.String())
+		b.WriteString(") WITHIN GROUP (")
+		buildOrderBy(b, e.orderBy)
+	case tokens.StringAgg:
+		// The separator is quoted so that it can be told apart from the comma
+		e.exL.Build(b)
+		b.WriteString(", '")
+		b.WriteString(strings.ReplaceAll(e.param.String(), "'", "''"))
+		b.WriteString("'")
+		if len(e.orderBy) > 0 {
+			b.WriteString(" ")
+			buildOrderBy(b, e.orderBy)
+		}
+	case tokens.Extract:
+		b.WriteString(e.param.String())
+		b.WriteString(" FROM ")
+		e.exL.Build(b)
+	case tokens.DateTrunc:
+		b.WriteString(e.param.String())
+		b.WriteString(", ")
 		e.exL.Build(b)
+	case tokens.JSONExtract:
+		e.exL.Build(b)
+		b.WriteString(", ")
+		b.WriteString(e.param.String())
+	case tokens.NextVal, tokens.CurrVal, tokens.SetVal:
+		b.WriteString(e.param.String())
+		for _, arg := range e.args {
+			b.WriteString(", ")
+			arg.Build(b)
+		}
+	default:
+		if e.exL != nil {
+			e.exL.Build(b)
+		}
+		for _, arg := range e.args {
+			b.WriteString(", ")
+			arg.Build(b)
+		}
+		if len(e.orderBy) > 0 {
+			b.WriteString(" ")
+			buildOrderBy(b, e.orderBy)
+		}
 	}
 	b.WriteString(")")
+	if e.filter != nil {
+		b.WriteString(" FILTER (WHERE ")
+		e.filter.Build(b)
+		b.WriteString(")")
+	}
+	e.buildAlias(b)
+}
 
+// buildAlias adds the alias of the function to the string representation
+func (e *FuncExpr) buildAlias(b *strings.Builder) {
 	if e.alias != "" {
 		b.WriteString(" ")
 		b.WriteString(e.alias)
@@ -760,26 +968,76 @@ func (e *FuncExpr) ColRef() column.Ref {
 	name := e.Name()
 	//???? INT for Count, FUNC otherwise????????
 	colType := e.Cmd
+	switch e.Cmd {
+	case tokens.Now, tokens.DateTrunc:
+		colType = tokens.Timestamp
+	case tokens.CurrentDate:
+		colType = tokens.Date
+	case tokens.Length, tokens.NextVal, tokens.CurrVal, tokens.SetVal:
+		colType = tokens.Int
+	case tokens.Substr:
+		colType = e.exL.ColRef().ColType
+	case tokens.JSONArrayAgg, tokens.JSONObjectAgg:
+		colType = tokens.JSON
+	case tokens.GenRandomUUID:
+		colType = tokens.UUID
+	case tokens.Extract:
+		colType = tokens.Int
+		if field := strings.ToUpper(e.param.String()); field == "SECOND" || field == "EPOCH" {
+			colType = tokens.Float
+		}
+	}
 
 	return column.Ref{ColName: name, ColType: colType}
 }
 
 // ColRefs returns a list of all actual columns in the expression
 func (e *FuncExpr) ColRefs(names ...*moniker.Moniker) []column.Ref {
+	var cols []column.Ref
 	if e.exL != nil {
-		colsL := e.exL.ColRefs(names...)
-		return colsL
+		cols = e.exL.ColRefs(names...)
 	}
-	return nil
+	for _, arg := range e.args {
+		cols = append(cols, arg.ColRefs(names...)...)
+	}
+	if e.filter != nil {
+		cols = append(cols, e.filter.ColRefs(names...)...)
+	}
+	for _, item := range e.orderBy {
+		cols = append(cols, item.Exp.ColRefs(names...)...)
+	}
+	return cols
 }
 
 // Evaluate takes the current Expression and calculates the results based on the given row
 func (e *FuncExpr) Evaluate(profile *sqprofile.SQProfile, partial bool, rows ...RowInterface) (retVal sqtypes.Value, err error) {
 	var vL sqtypes.Value
 
+	// Rows that are filtered out of an aggregate function have a null value so they are not used
+	if e.filter != nil {
+		vF, err := e.filter.Evaluate(profile, partial, rows...)
+		if err != nil {
+			return nil, err
+		}
+		if b, ok := vF.(sqtypes.SQBool); !ok || !b.Bool() {
+			return sqtypes.NewSQNull(), nil
+		}
+		if e.exL == nil {
+			return sqtypes.NewSQInt(1), nil
+		}
+	}
 	if e.exL == nil {
-		if e.Cmd == tokens.Count {
+		switch e.Cmd {
+		case tokens.Count:
 			return sqtypes.NewSQNull(), nil
+		case tokens.Now:
+			return sqtypes.NewSQTimestamp(time.Now()), nil
+		case tokens.CurrentDate:
+			return sqtypes.NewSQDate(time.Now().UTC()), nil
+		case tokens.GenRandomUUID:
+			return sqtypes.RandomUUID()
+		case tokens.NextVal, tokens.CurrVal, tokens.SetVal:
+			return e.evalSequence(profile, partial, rows...)
 		}
 		return nil, sqerr.Newf("%s does not have an argument to evaluate", tokens.IDName(e.Cmd))
 	}
@@ -788,20 +1046,84 @@ func (e *FuncExpr) Evaluate(profile *sqprofile.SQProfile, partial bool, rows ...
 		return
 	}
 	assertions.Assert(vL != nil, "Evaluate must have a value")
+	argVals := make([]sqtypes.Value, len(e.args))
+	for i, arg := range e.args {
+		argVals[i], err = arg.Evaluate(profile, partial, rows...)
+		if err != nil {
+			return
+		}
+	}
 
-	retVal, err = evalFunc(e.Cmd, vL)
+	retVal, err = evalFunc(e.Cmd, e.param, vL, argVals...)
 	if err != nil {
 		return
 	}
 	return
 }
 
-func evalFunc(cmd tokens.TokenID, v sqtypes.Value) (retVal sqtypes.Value, err error) {
+// evalSequence calculates the result of the sequence functions NEXTVAL, CURRVAL and SETVAL
+func (e *FuncExpr) evalSequence(profile *sqprofile.SQProfile, partial bool, rows ...RowInterface) (sqtypes.Value, error) {
+	seq, err := getSequence(profile, e.param.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var val int
+	switch e.Cmd {
+	case tokens.NextVal:
+		val, err = seq.NextVal()
+	case tokens.CurrVal:
+		val, err = seq.CurrVal()
+	case tokens.SetVal:
+		argVals := make([]sqtypes.Value, len(e.args))
+		for i, arg := range e.args {
+			argVals[i], err = arg.Evaluate(profile, partial, rows...)
+			if err != nil {
+				return nil, err
+			}
+			if argVals[i].IsNull() {
+				return sqtypes.NewSQNull(), nil
+			}
+		}
+		v, ok := argVals[0].(sqtypes.SQInt)
+		if !ok {
+			return nil, sqerr.Newf("Type Mismatch: The value for SETVAL must be an INT not %s", argVals[0].String())
+		}
+		isCalled := true
+		if len(argVals) > 1 {
+			b, ok := argVals[1].(sqtypes.SQBool)
+			if !ok {
+				return nil, sqerr.Newf("Type Mismatch: The is_called flag for SETVAL must be a BOOL not %s", argVals[1].String())
+			}
+			isCalled = b.Bool()
+		}
+		val, err = seq.SetVal(v.Val, isCalled)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sqtypes.NewSQInt(val), nil
+}
+
+func evalFunc(cmd tokens.TokenID, param, v sqtypes.Value, args ...sqtypes.Value) (retVal sqtypes.Value, err error) {
 
 	switch cmd {
-	case tokens.Float, tokens.Int, tokens.Bool, tokens.String:
+	case tokens.Float, tokens.Int, tokens.Bool, tokens.String, tokens.Date, tokens.Time, tokens.Timestamp, tokens.Interval,
+		tokens.Decimal, tokens.Blob, tokens.JSON, tokens.UUID:
 		retVal, err = v.Convert(cmd)
-	case tokens.Count, tokens.Sum, tokens.Avg, tokens.Min, tokens.Max:
+	case tokens.Length:
+		retVal, err = sqtypes.Length(v)
+	case tokens.Substr:
+		retVal, err = sqtypes.Substr(v, args[0], args[1:]...)
+	case tokens.Extract:
+		retVal, err = sqtypes.Extract(param.String(), v)
+	case tokens.DateTrunc:
+		retVal, err = sqtypes.DateTrunc(param.String(), v)
+	case tokens.JSONExtract:
+		retVal, err = sqtypes.JSONExtract(v, param.String())
+	case tokens.Count, tokens.Sum, tokens.Avg, tokens.Min, tokens.Max, tokens.StddevPop, tokens.StddevSamp, tokens.Stddev,
+		tokens.VarPop, tokens.VarSamp, tokens.Variance, tokens.Median, tokens.PercentileCont, tokens.PercentileDisc,
+		tokens.StringAgg, tokens.Grouping, tokens.JSONArrayAgg, tokens.JSONObjectAgg:
 		// aggregate functions are evaluated elsewhere, just pass the data along
 		retVal = v
 	default:
@@ -822,6 +1144,20 @@ func (e *FuncExpr) IsAggregate() bool {
 
 // Reduce will colapse the expression to it's simplest form
 func (e *FuncExpr) Reduce() (Expr, error) {
+	if e.filter != nil {
+		f, err := e.filter.Reduce()
+		if err != nil {
+			return nil, err
+		}
+		e.filter = f
+	}
+	for i := range e.orderBy {
+		ex, err := e.orderBy[i].Exp.Reduce()
+		if err != nil {
+			return nil, err
+		}
+		e.orderBy[i].Exp = ex
+	}
 	if e.exL == nil {
 		return e, nil
 	}
@@ -831,8 +1167,20 @@ func (e *FuncExpr) Reduce() (Expr, error) {
 	}
 	e.exL = ex
 	v, ok := ex.(*ValueExpr)
-	if ok {
-		val, err := evalFunc(e.Cmd, v.v)
+	argVals := make([]sqtypes.Value, len(e.args))
+	for i := range e.args {
+		e.args[i], err = e.args[i].Reduce()
+		if err != nil {
+			return nil, err
+		}
+		argVal, isVal := e.args[i].(*ValueExpr)
+		if isVal {
+			argVals[i] = argVal.v
+		}
+		ok = ok && isVal
+	}
+	if ok && !e.IsAggregate() {
+		val, err := evalFunc(e.Cmd, e.param, v.v, argVals...)
 		if err != nil {
 			return nil, err
 		}
@@ -843,18 +1191,83 @@ func (e *FuncExpr) Reduce() (Expr, error) {
 
 // ValidateCols make sure that the cols in the expression match the tabledef
 func (e *FuncExpr) ValidateCols(profile *sqprofile.SQProfile, tables TableList) error {
+	if e.filter != nil {
+		if e.filter.IsAggregate() {
+			return sqerr.NewSyntaxf("Aggregate functions are not allowed in the FILTER of %s", tokens.IDName(e.Cmd))
+		}
+		if err := e.filter.ValidateCols(profile, tables); err != nil {
+			return err
+		}
+	}
+	for _, item := range e.orderBy {
+		if item.Exp.IsAggregate() {
+			return sqerr.NewSyntaxf("Aggregate functions are not allowed in the ORDER BY of %s", tokens.IDName(e.Cmd))
+		}
+		if err := item.Exp.ValidateCols(profile, tables); err != nil {
+			return err
+		}
+	}
+	for _, arg := range e.args {
+		if arg.IsAggregate() {
+			return sqerr.NewSyntaxf("Aggregate functions are not allowed in the arguments of %s", tokens.IDName(e.Cmd))
+		}
+		if err := arg.ValidateCols(profile, tables); err != nil {
+			return err
+		}
+	}
 	if e.exL != nil {
 		return e.exL.ValidateCols(profile, tables)
 	}
 	return nil
 }
 
+// aggregateKeys returns the expressions of an aggregate function that are used along with its value. These
+//   are the extra arguments followed by the ORDER BY expressions
+func (e *FuncExpr) aggregateKeys() []Expr {
+	keys := append([]Expr{}, e.args...)
+	for _, item := range e.orderBy {
+		keys = append(keys, item.Exp)
+	}
+	return keys
+}
+
 // NewFuncExpr creates a new CountExpr object
 func NewFuncExpr(cmd tokens.TokenID, lExp Expr) Expr {
 	return &FuncExpr{Cmd: cmd, exL: lExp}
 
 }
 
+// NewParamFuncExpr creates a FuncExpr for a function that has a constant parameter as well as an expression
+func NewParamFuncExpr(cmd tokens.TokenID, lExp Expr, param sqtypes.Value) Expr {
+	return &FuncExpr{Cmd: cmd, exL: lExp, param: param}
+}
+
+// NewArgsFuncExpr creates a FuncExpr for a function that has more than one expression as arguments
+func NewArgsFuncExpr(cmd tokens.TokenID, lExp Expr, args ...Expr) Expr {
+	return &FuncExpr{Cmd: cmd, exL: lExp, args: args}
+}
+
+// NewSequenceFuncExpr creates a FuncExpr for NEXTVAL, CURRVAL or SETVAL on the named sequence.
+//   args are the value and optional is_called flag for SETVAL
+func NewSequenceFuncExpr(cmd tokens.TokenID, seqName string, args ...Expr) Expr {
+	return &FuncExpr{Cmd: cmd, param: sqtypes.NewSQString(strings.ToLower(seqName)), args: args}
+}
+
+// AggregateOptions are the optional parts of an aggregate function
+type AggregateOptions struct {
+	Distinct bool
+	Filter   Expr
+	Param    sqtypes.Value // separator for STRING_AGG, fraction for PERCENTILE_CONT and PERCENTILE_DISC
+	Args     []Expr        // value for JSON_OBJECTAGG
+	OrderBy  []OrderExpr
+}
+
+// NewAggregateFuncExpr creates a FuncExpr for an aggregate function that may use only the distinct values
+//   of the expression, the rows where the filter is true and have its values ordered
+func NewAggregateFuncExpr(cmd tokens.TokenID, lExp Expr, opts AggregateOptions) Expr {
+	return &FuncExpr{Cmd: cmd, Distinct: opts.Distinct, exL: lExp, args: opts.Args, filter: opts.Filter, param: opts.Param, orderBy: opts.OrderBy}
+}
+
 // Encode returns a binary encoded version of the expression
 func (e *FuncExpr) Encode() *sqbin.Codec {
 	panic("FuncExpr Encode not implemented")
@@ -31,6 +31,7 @@ func TestInterfaces(t *testing.T) {
 		{"OpExpr is an Expr", &sqtables.OpExpr{}},
 		{"NegateExpr is an Expr", &sqtables.NegateExpr{}},
 		{"FuncExpr is an Expr", &sqtables.FuncExpr{}},
+		{"WindowExpr is an Expr", &sqtables.WindowExpr{}},
 	}
 
 	for i, row := range data {
@@ -179,6 +180,7 @@ func TestSetLeftExpr(t *testing.T) {
 		{TestName: "OpExpr", TestExpr: sqtables.NewOpExpr(cExpr, tokens.Plus, vExpr), ExpExpr: cExpr, ExpPanic: ""},
 		{TestName: "NegateExpr", TestExpr: sqtables.NewNegateExpr(vExpr), ExpExpr: vExpr, ExpPanic: ""},
 		{TestName: "FuncExpr", TestExpr: sqtables.NewFuncExpr(tokens.Float, vExpr), ExpExpr: vExpr, ExpPanic: ""},
+		{TestName: "WindowExpr", TestExpr: sqtables.NewWindowExpr(tokens.Sum, []sqtables.Expr{cExpr}, nil), ExpExpr: nil, ExpPanic: "Invalid to SetLeft on a WindowExpr"},
 	}
 
 	for i, row := range data {
@@ -288,6 +290,18 @@ func TestStringExpr(t *testing.T) {
 		{TestName: "NegateExpr with Alias", TestExpr: sqtables.NewNegateExpr(sqtables.NewValueExpr(sqtypes.NewSQInt(1234))), ExpVal: "(-1234) nAlias", Alias: "nAlias"},
 		{TestName: "FuncExpr", TestExpr: sqtables.NewFuncExpr(tokens.Float, sqtables.NewValueExpr(sqtypes.NewSQInt(1234))), ExpVal: "FLOAT(1234)"},
 		{TestName: "FuncExpr with Alias", TestExpr: sqtables.NewFuncExpr(tokens.Float, sqtables.NewValueExpr(sqtypes.NewSQInt(1234))), ExpVal: "FLOAT(1234) fAlias", Alias: "fAlias"},
+		{TestName: "FuncExpr Distinct", TestExpr: sqtables.NewAggregateFuncExpr(tokens.Count, sqtables.NewColExpr(column.Ref{ColName: "col1"}), sqtables.AggregateOptions{Distinct: true}), ExpVal: "COUNT(DISTINCT col1)"},
+		{TestName: "FuncExpr Filter", TestExpr: sqtables.NewAggregateFuncExpr(tokens.Count, nil, sqtables.AggregateOptions{Filter: sqtables.NewValueExpr(sqtypes.NewSQBool(true))}), ExpVal: "COUNT() FILTER (WHERE true)"},
+		{TestName: "FuncExpr Percentile", TestExpr: sqtables.NewAggregateFuncExpr(tokens.PercentileCont, sqtables.NewColExpr(column.Ref{ColName: "col1"}), sqtables.AggregateOptions{Param: sqtypes.NewSQFloat(0.5), OrderBy: []sqtables.OrderExpr{{Exp: sqtables.NewColExpr(column.Ref{ColName: "col1"}), SortType: tokens.Desc}}}), ExpVal: "PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY col1 DESC)"},
+		{TestName: "FuncExpr String Agg", TestExpr: sqtables.NewAggregateFuncExpr(tokens.StringAgg, sqtables.NewColExpr(column.Ref{ColName: "col1"}), sqtables.AggregateOptions{Param: sqtypes.NewSQString("-"), OrderBy: []sqtables.OrderExpr{{Exp: sqtables.NewColExpr(column.Ref{ColName: "col2"}), SortType: tokens.Asc}}}), ExpVal: "STRING_AGG(col1, '-' ORDER BY col2)"},
+		{TestName: "FuncExpr String Agg no Order", TestExpr: sqtables.NewAggregateFuncExpr(tokens.StringAgg, sqtables.NewColExpr(column.Ref{ColName: "col1"}), sqtables.AggregateOptions{Param: sqtypes.NewSQString("-")}), ExpVal: "STRING_AGG(col1, '-')"},
+		{TestName: "FuncExpr String Agg quote separator", TestExpr: sqtables.NewAggregateFuncExpr(tokens.StringAgg, sqtables.NewColExpr(column.Ref{ColName: "col1"}), sqtables.AggregateOptions{Param: sqtypes.NewSQString("'")}), ExpVal: "STRING_AGG(col1, '''')"},
+		{TestName: "FuncExpr Extract", TestExpr: sqtables.NewParamFuncExpr(tokens.Extract, sqtables.NewColExpr(column.Ref{ColName: "col1"}), sqtypes.NewSQString("YEAR")), ExpVal: "EXTRACT(YEAR FROM col1)"},
+		{TestName: "FuncExpr Date Trunc", TestExpr: sqtables.NewParamFuncExpr(tokens.DateTrunc, sqtables.NewColExpr(column.Ref{ColName: "col1"}), sqtypes.NewSQString("month")), ExpVal: "DATE_TRUNC(month, col1)"},
+		{TestName: "FuncExpr Substr", TestExpr: sqtables.NewArgsFuncExpr(tokens.Substr, sqtables.NewColExpr(column.Ref{ColName: "col1"}), sqtables.NewValueExpr(sqtypes.NewSQInt(2)), sqtables.NewValueExpr(sqtypes.NewSQInt(3))), ExpVal: "SUBSTR(col1, 2, 3)"},
+		{TestName: "FuncExpr Current Date", TestExpr: sqtables.NewFuncExpr(tokens.CurrentDate, nil), ExpVal: "CURRENT_DATE"},
+		{TestName: "FuncExpr JSON Extract", TestExpr: sqtables.NewParamFuncExpr(tokens.JSONExtract, sqtables.NewColExpr(column.Ref{ColName: "col1"}), sqtypes.NewSQString("$.a[0]")), ExpVal: "JSON_EXTRACT(col1, $.a[0])"},
+		{TestName: "FuncExpr JSON Object Agg", TestExpr: sqtables.NewAggregateFuncExpr(tokens.JSONObjectAgg, sqtables.NewColExpr(column.Ref{ColName: "col1"}), sqtables.AggregateOptions{Args: []sqtables.Expr{sqtables.NewColExpr(column.Ref{ColName: "col2"})}}), ExpVal: "JSON_OBJECTAGG(col1, col2)"},
 	}
 
 	for i, row := range data {
@@ -331,6 +345,9 @@ func TestGetColDefExpr(t *testing.T) {
 		{TestName: "CountExpr", TestExpr: sqtables.NewFuncExpr(tokens.Count, nil), ExpCol: column.Ref{ColName: "COUNT()", ColType: tokens.Count}},
 		{TestName: "NegateExpr", TestExpr: sqtables.NewNegateExpr(vExpr), ExpCol: column.Ref{ColName: "(-1)", ColType: tokens.Int}},
 		{TestName: "FuncExpr", TestExpr: sqtables.NewFuncExpr(tokens.Float, vExpr), ExpCol: column.Ref{ColName: "FLOAT(1)", ColType: tokens.Float}},
+		{TestName: "WindowExpr Rank", TestExpr: sqtables.NewWindowExpr(tokens.Rank, nil, &sqtables.Window{OrderBy: []sqtables.OrderExpr{{Exp: cExpr, SortType: tokens.Desc}}}), ExpCol: column.Ref{ColName: "RANK() OVER (ORDER BY col1 DESC)", ColType: tokens.Int}},
+		{TestName: "WindowExpr Avg", TestExpr: sqtables.NewWindowExpr(tokens.Avg, []sqtables.Expr{cExpr}, nil), ExpCol: column.Ref{ColName: "AVG(col1) OVER ()", ColType: tokens.Float}},
+		{TestName: "WindowExpr Lag", TestExpr: sqtables.NewWindowExpr(tokens.Lag, []sqtables.Expr{cExpr, vExpr}, &sqtables.Window{PartitionBy: []sqtables.Expr{cExpr}}), ExpCol: column.Ref{ColName: "LAG(col1, 1) OVER (PARTITION BY col1)", ColType: tokens.Int}},
 	}
 
 	for i, row := range data {
@@ -858,6 +875,24 @@ func TestEvaluateExpr(t *testing.T) {
 			ExpVal:   sqtypes.NewSQNull(),
 			ExpErr:   "",
 		},
+		{
+			TestName: "Count Filter true",
+			e:        sqtables.NewAggregateFuncExpr(tokens.Count, nil, sqtables.AggregateOptions{Filter: sqtables.NewValueExpr(sqtypes.NewSQBool(true))}),
+			profile:  profile,
+			Tables:   tables,
+			rows:     rows,
+			ExpVal:   sqtypes.NewSQInt(1),
+			ExpErr:   "",
+		},
+		{
+			TestName: "Sum Filter false",
+			e:        sqtables.NewAggregateFuncExpr(tokens.Sum, sqtables.NewValueExpr(sqtypes.NewSQInt(1)), sqtables.AggregateOptions{Filter: sqtables.NewValueExpr(sqtypes.NewSQBool(false))}),
+			profile:  profile,
+			Tables:   tables,
+			rows:     rows,
+			ExpVal:   sqtypes.NewSQNull(),
+			ExpErr:   "",
+		},
 		{
 			TestName: "Sum no arg Expr",
 			e:        sqtables.NewFuncExpr(tokens.Sum, nil),
@@ -157,6 +157,15 @@ func (el *ExprList) HasAggregateFunc() bool {
 	return false
 }
 
+// FindWindowFuncs returns a list of the window functions in the Expression list
+func (el *ExprList) FindWindowFuncs() []*WindowExpr {
+	var wlist []*WindowExpr
+	for _, expr := range el.exprlist {
+		wlist = findWindowFuncs(expr, wlist)
+	}
+	return wlist
+}
+
 // FindAggregateFuncs returns a list of aggregate functions in the Expression list
 func (el *ExprList) FindAggregateFuncs() (flist []*FuncExpr, idx []int) {
 
@@ -171,22 +180,34 @@ func (el *ExprList) FindAggregateFuncs() (flist []*FuncExpr, idx []int) {
 	return
 }
 
-//GetValues returns a list of values if all expressions reduce to a value
-func (el *ExprList) GetValues() ([]sqtypes.Value, error) {
+//GetValues returns a list of values if all expressions reduce to a value. Functions that do not use
+//   any columns such as NOW() are evaluated without a row. A nil expression returns a nil value
+func (el *ExprList) GetValues(profile *sqprofile.SQProfile) ([]sqtypes.Value, error) {
 	var err error
 	var expr Expr
 
 	vals := make([]sqtypes.Value, len(el.exprlist))
 	for i, e := range el.exprlist {
+		if e == nil {
+			// DEFAULT in a VALUES list
+			continue
+		}
 		expr, err = e.Reduce()
 		if err != nil {
 			return nil, err
 		}
 		v, ok := expr.(*ValueExpr)
-		if !ok {
+		if ok {
+			vals[i] = v.v
+			continue
+		}
+		if len(expr.ColRefs()) > 0 || expr.IsAggregate() {
 			return nil, sqerr.NewSyntax("Expression did not reduce to a Value")
 		}
-		vals[i] = v.v
+		vals[i], err = expr.Evaluate(profile, false)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return vals, nil
 }
@@ -239,6 +239,7 @@ func testListEncDecFunc(eList *sqtables.ExprList) func(*testing.T) {
 	}
 }
 func TestEvalListMisc(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
 
 	var eList *sqtables.ExprList
 	eList = sqtables.NewExprList()
@@ -267,7 +268,7 @@ func TestEvalListMisc(t *testing.T) {
 
 		vals := sqtypes.CreateValueArrayFromRaw([]sqtypes.Raw{1, "test", true})
 		eList := sqtables.NewExprListFromValues(vals)
-		actValues, err := eList.GetValues()
+		actValues, err := eList.GetValues(profile)
 		if err != nil {
 			t.Errorf("Unexpected error: %s", err)
 			return
@@ -290,7 +291,7 @@ func TestEvalListMisc(t *testing.T) {
 		defer sqtest.PanicTestRecovery(t, "")
 
 		ExpErr := "Syntax Error: Invalid Int Operator ASC"
-		_, err := errList.GetValues()
+		_, err := errList.GetValues(profile)
 		if sqtest.CheckErr(t, err, ExpErr) {
 			return
 		}
@@ -304,11 +305,24 @@ func TestEvalListMisc(t *testing.T) {
 		),
 		sqtables.NewColExpr(column.NewRef("col1", tokens.Int, false)),
 	)
+	t.Run("ExprList GetValues with Function", func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		fList := sqtables.NewExprList(sqtables.NewFuncExpr(tokens.Now, nil), nil)
+		vals, err := fList.GetValues(profile)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			return
+		}
+		if vals[0].Type() != tokens.Timestamp || vals[1] != nil {
+			t.Errorf("GetValues returned %v, expecting a TIMESTAMP and nil", vals)
+		}
+	})
 	t.Run("ExprList GetValues with Column", func(t *testing.T) {
 		defer sqtest.PanicTestRecovery(t, "")
 
 		ExpErr := "Syntax Error: Expression did not reduce to a Value"
-		_, err := errList.GetValues()
+		_, err := errList.GetValues(profile)
 		if sqtest.CheckErr(t, err, ExpErr) {
 			return
 		}
@@ -107,7 +107,8 @@ func (idx SQIndex) IsSorted() bool {
 	return sort.IsSorted(idx.elemArray)
 }
 
-// NewSQIndex - Create a new Index for the given table
+// NewSQIndex - Create a new Index for the given table. The caller must have the table write locked
+//   if it is in use
 func NewSQIndex(profile *sqprofile.SQProfile, name string, tab *TableDef, cols *column.List, allowNulls, isUnique bool) (*SQIndex, error) {
 
 	tabs := NewTableListFromTableDef(profile, tab)
@@ -129,12 +130,10 @@ func NewSQIndex(profile *sqprofile.SQProfile, name string, tab *TableDef, cols *
 		}
 		colMap[c.ColName] = true
 	}
-	tab.Lock(profile)
-	defer tab.Unlock(profile)
 	// Get the initial data
 	for _, row := range tab.rowm {
 		if !row.IsDeleted(profile) {
-			vals := []sqtypes.Value{}
+			vals := make([]sqtypes.Value, len(idx.cols))
 			for j, col := range idx.cols {
 				vals[j], err = row.ColVal(profile, &col)
 				if err != nil {
@@ -18,14 +18,26 @@ import (
 
 // Query has all of the information required for a query
 type Query struct {
-	Tables     TableList
-	EList      *ExprList
-	IsDistinct bool
-	WhereExpr  Expr
-	GroupBy    *ExprList
-	HavingExpr *Expr
-	OrderBy    []OrderItem
-	Joins      []JoinInfo
+	Tables       TableList
+	EList        *ExprList
+	IsDistinct   bool
+	WhereExpr    Expr
+	GroupBy      *ExprList
+	GroupingSets [][]int // indexes into GroupBy for ROLLUP, CUBE and GROUPING SETS. nil is a single set of all GroupBy
+	HavingExpr   *Expr
+	OrderBy      []OrderItem
+	Joins        []JoinInfo
+	HasLimit     bool
+	Limit        int
+	Offset       int
+	SetOps       []SetOp
+}
+
+// SetOp contains a query that is combined with the results of another query by UNION, INTERSECT or EXCEPT
+type SetOp struct {
+	Op    tokens.TokenID
+	All   bool
+	Query *Query
 }
 
 // JoinInfo contains the information required for a table join
@@ -39,15 +51,11 @@ type JoinInfo struct {
 func (q *Query) GetRowData(profile *sqprofile.SQProfile) (*DataSet, error) {
 	var err error
 	var finalResult *DataSet
-	var whereList *ExprList
 	timeOut := new(int32)
 
 	if q.EList == nil || q.EList.Len() < 1 {
 		return nil, sqerr.NewInternal("Expression List must have at least one item")
 	}
-	if q.Tables.Len() == 0 {
-		return nil, sqerr.NewInternal("TableList must not be empty for query")
-	}
 
 	err = q.Tables.RLock(profile)
 	if err != nil {
@@ -91,12 +99,25 @@ func (q *Query) GetRowData(profile *sqprofile.SQProfile) (*DataSet, error) {
 		}
 	}
 
+	// Window functions are calculated after the rows are selected so they can't be used in where or group by
+	windows := q.EList.FindWindowFuncs()
+	err = q.validateWindowSemantics(windows)
+	if err != nil {
+		return nil, err
+	}
+
 	// Make sure groupby, having clause and eList follow rules for group by (if there is one)
 	err = q.ValidateGroupBySemantics(profile)
 	if err != nil {
 		return nil, err
 	}
 
+	// The extra argument and ORDER BY values of aggregate functions are added to the end of each row for ProcessGroupBy
+	aggKeys, err := q.aggregateKeys(profile)
+	if err != nil {
+		return nil, err
+	}
+
 	// Setup the result dataset
 	finalResult, err = NewDataSet(profile, q.Tables, q.EList)
 	if err != nil {
@@ -108,6 +129,119 @@ func (q *Query) GetRowData(profile *sqprofile.SQProfile) (*DataSet, error) {
 	if log.GetLevel() >= log.DebugLevel {
 		log.Debugf("Where expression = %s", q.WhereExpr)
 	}
+
+	// A query without any tables evaluates the expressions once
+	var joined []JoinTable
+	var jresult [][]RowInterface
+	if q.Tables.Len() == 0 {
+		jresult, err = q.noTableRows(profile)
+	} else {
+		joined, jresult, err = q.joinTables(profile, timeOut)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Fill in the final Datastore result
+	finalResult.Vals = make([][]sqtypes.Value, len(jresult))
+	var windowRows [][]RowInterface
+	if len(windows) > 0 {
+		windowRows = make([][]RowInterface, len(jresult))
+	}
+
+	for i, tuple := range jresult {
+		rows, err := tupleRows(profile, joined, tuple)
+		if err != nil {
+			return nil, err
+		}
+		// The window functions need all of the rows before the expressions can be evaluated
+		if windowRows != nil {
+			windowRows[i] = rows
+			continue
+		}
+		finalResult.Vals[i], err = q.EList.Evaluate(profile, EvalPartial, rows...)
+		if err != nil {
+			return nil, err
+		}
+		if aggKeys != nil {
+			keys, err := aggKeys.Evaluate(profile, EvalPartial, rows...)
+			if err != nil {
+				return nil, err
+			}
+			finalResult.Vals[i] = append(finalResult.Vals[i], keys...)
+		}
+	}
+	if windowRows != nil {
+		finalResult.Vals, err = calcWindows(profile, q.EList, windows, windowRows)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if q.GroupBy != nil || q.EList.HasAggregateFunc() {
+		err = q.ProcessGroupBy(profile, finalResult)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return finalResult, nil
+
+}
+
+// JoinedRows returns the rows of the tables that are combined by the joins and where clause of the
+//   query. Each tuple has a row for every table in the same order as names. If a table does not have a
+//   matching row in an outer join its row has a pointer of 0 and all NULL values
+func (q *Query) JoinedRows(profile *sqprofile.SQProfile) ([]*moniker.Moniker, [][]RowInterface, error) {
+	var err error
+
+	if q.Tables.Len() == 0 {
+		return nil, nil, sqerr.NewInternal("TableList must not be empty for query")
+	}
+
+	err = q.Tables.RLock(profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer q.Tables.RUnlock(profile)
+
+	if q.WhereExpr != nil {
+		err = q.WhereExpr.ValidateCols(profile, q.Tables)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, j := range q.Joins {
+		if j.ONClause != nil {
+			err = j.ONClause.ValidateCols(profile, q.Tables)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	joined, jresult, err := q.joinTables(profile, new(int32))
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make([]*moniker.Moniker, len(joined))
+	for i, tab := range joined {
+		names[i] = tab.TR.Name
+	}
+	tuples := make([][]RowInterface, len(jresult))
+	for i, tuple := range jresult {
+		tuples[i], err = tupleRows(profile, joined, tuple)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return names, tuples, nil
+}
+
+// joinTables gets the rows of each table that match the where clause and joins them together. Each
+//   tuple of the result has a row for each table in the order of joined
+func (q *Query) joinTables(profile *sqprofile.SQProfile, timeOut *int32) ([]JoinTable, [][]RowInterface, error) {
+	var whereList *ExprList
+	var err error
+
 	var unJoined []JoinTable
 	var joined []JoinTable
 
@@ -147,10 +281,15 @@ func (q *Query) GetRowData(profile *sqprofile.SQProfile) (*DataSet, error) {
 		}
 		whereList = ColsToExpr(column.NewListRefs(cols))
 
-		// Get the pointers to the rows based on the conditions
-		tmpData, err := tabInfo.GetRowData(profile, whereList, q.WhereExpr)
+		// Get the pointers to the rows based on the conditions. A single table only needs the rows up to
+		//   the end of the limit
+		limit := 0
+		if q.Tables.Len() == 1 {
+			limit = q.rowLimit()
+		}
+		tmpData, err := tabInfo.getRowData(profile, whereList, tableWhere(q.WhereExpr, tabInfo.Name), limit)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		resultRows := make([]JoinRow, len(tmpData.Ptrs))
 		for i, ptr := range tmpData.Ptrs {
@@ -167,7 +306,7 @@ func (q *Query) GetRowData(profile *sqprofile.SQProfile) (*DataSet, error) {
 		return len(unJoined[i].Rows) < len(unJoined[j].Rows)
 	})
 	if atomic.LoadInt32(timeOut) != 0 {
-		return nil, sqerr.New("Query terminated due to timeout")
+		return nil, nil, sqerr.New("Query terminated due to timeout")
 	}
 	// Join the datasets together
 	jtab := unJoined[0]
@@ -185,7 +324,7 @@ func (q *Query) GetRowData(profile *sqprofile.SQProfile) (*DataSet, error) {
 		// find the join clause
 		joinIdx, joinedIdx, unJoinedIdx = findJoin(unusedJoins, joined, unJoined)
 		if joinIdx == -1 || joinedIdx == -1 || unJoinedIdx == -1 {
-			return nil, sqerr.Newf("Could not find a valid join for %s", unJoined[0].TR.Name)
+			return nil, nil, sqerr.Newf("Could not find a valid join for %s", unJoined[0].TR.Name)
 		}
 		log.Debugf("Joining tables %s, %s using Expr %s ", unusedJoins[joinIdx].TableA.Name,
 			unusedJoins[joinIdx].TableB.Name, unusedJoins[joinIdx].ONClause)
@@ -197,62 +336,112 @@ func (q *Query) GetRowData(profile *sqprofile.SQProfile) (*DataSet, error) {
 		case tokens.Inner:
 			jresult, err = innerJoin(profile, currentJoin, joined[joinedIdx], unJoined[unJoinedIdx], joinedIdx, jresult)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			log.Debugf("Join resulted in %d rows", len(jresult))
 		case tokens.Cross:
 			jresult, err = crossJoin(profile, currentJoin, joined[joinedIdx], unJoined[unJoinedIdx], joinedIdx, jresult, timeOut)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			log.Debugf("Cross Join resulted in %d rows", len(jresult))
 		case tokens.Left, tokens.Right, tokens.Full:
 			jresult, err = outerJoin(profile, joined, currentJoin, joined[joinedIdx], unJoined[unJoinedIdx], joinedIdx, jresult)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			log.Debugf("%s Outer Join resulted in %d rows", tokens.IDName(currentJoin.JoinType), len(jresult))
 		default:
-			return nil, sqerr.NewInternalf("Join Type %s is not currently implemented", tokens.IDName(currentJoin.JoinType))
+			return nil, nil, sqerr.NewInternalf("Join Type %s is not currently implemented", tokens.IDName(currentJoin.JoinType))
 		}
 		jtab := unJoined[unJoinedIdx]
 		unJoined = append(unJoined[:unJoinedIdx], unJoined[unJoinedIdx+1:]...)
 		joined = append(joined, jtab)
 
 	}
+	return joined, jresult, nil
+}
 
-	// Fill in the final Datastore result
-	finalResult.Vals = make([][]sqtypes.Value, len(jresult))
+// rowLimit returns the number of rows that are needed for the result of a query with a LIMIT. The
+//   rows are only cut short when the query does not sort, remove duplicates, group or combine them.
+//   The rows found first are the result so the rest do not need to be read. 0 means all rows are needed
+func (q *Query) rowLimit() int {
+	if !q.HasLimit || len(q.OrderBy) > 0 || q.IsDistinct || len(q.SetOps) > 0 || q.GroupBy != nil ||
+		q.EList.HasAggregateFunc() || len(q.EList.FindWindowFuncs()) > 0 {
+		return 0
+	}
+	return q.Offset + q.Limit
+}
 
-	for i, tuple := range jresult {
-		rows := make([]RowInterface, len(joined))
-		for j, tab := range joined {
-			ptr := tuple[j].GetPtr(profile)
-			// The ptr will be 0 in the case of an outer join. That table's results will be nulls
-			if ptr != 0 {
-				row, ok := tab.TR.Table.rowm[ptr]
-				if !ok {
-					return nil, sqerr.Newf("Invalid pointer for table %s:%d", tab.TR.Name, tuple[j])
-				}
-				rows[j] = RowInterface(row)
-			} else {
-				row := JoinRow{Vals: make([]sqtypes.Value, len(tab.TR.Table.tableCols)), TableName: tab.TR.Name}
-				for x := range row.Vals {
-					row.Vals[x] = sqtypes.NewSQNull()
-				}
-				rows[j] = RowInterface(&row)
-			}
+// tableWhere returns the conditions of the where clause that only use the given table. The rows of
+//   a table do not know its alias so the conditions of other aliases of the same table are removed
+func tableWhere(where Expr, name *moniker.Moniker) Expr {
+	if where == nil {
+		return nil
+	}
+	if opx, ok := where.(*OpExpr); ok && opx.Operator == tokens.And {
+		left := tableWhere(opx.Left(), name)
+		right := tableWhere(opx.Right(), name)
+		switch {
+		case left == nil:
+			return right
+		case right == nil:
+			return left
+		case left == opx.Left() && right == opx.Right():
+			return where
 		}
-		finalResult.Vals[i], err = q.EList.Evaluate(profile, EvalPartial, rows...)
+		return NewOpExpr(left, tokens.And, right)
+	}
+	for _, col := range where.ColRefs() {
+		if col.TableName != nil && !moniker.Equal(col.TableName, name) {
+			return nil
+		}
+	}
+	return where
+}
+
+// tupleRows returns the table rows for a tuple of joined rows
+// noTableRows returns the single empty tuple of a query that does not have any tables. If the where
+//   clause is not true there are no tuples
+func (q *Query) noTableRows(profile *sqprofile.SQProfile) ([][]RowInterface, error) {
+	if q.WhereExpr != nil {
+		val, err := q.WhereExpr.Evaluate(profile, EvalFull)
 		if err != nil {
 			return nil, err
 		}
+		b, ok := val.(sqtypes.SQBool)
+		if !ok || !b.Bool() {
+			return nil, nil
+		}
 	}
-	if q.GroupBy != nil || q.EList.HasAggregateFunc() {
-		err = q.ProcessGroupBy(profile, finalResult)
-	}
-	return finalResult, nil
+	return [][]RowInterface{{}}, nil
+}
 
+func tupleRows(profile *sqprofile.SQProfile, joined []JoinTable, tuple []RowInterface) ([]RowInterface, error) {
+	rows := make([]RowInterface, len(joined))
+	for j, tab := range joined {
+		ptr := tuple[j].GetPtr(profile)
+		// The ptr will be 0 in the case of an outer join. That table's results will be nulls
+		if ptr != 0 {
+			row, ok := tab.TR.Table.rowm[ptr]
+			if !ok {
+				return nil, sqerr.Newf("Invalid pointer for table %s:%d", tab.TR.Name, tuple[j])
+			}
+			if tab.TR.Name.Alias() != "" {
+				// The alias tells the rows apart when a table is in the join more than once
+				rows[j] = &JoinRow{Ptr: ptr, Vals: row.GetVals(profile), TableName: tab.TR.Name}
+				continue
+			}
+			rows[j] = RowInterface(row)
+		} else {
+			row := JoinRow{Vals: make([]sqtypes.Value, len(tab.TR.Table.tableCols)), TableName: tab.TR.Name}
+			for x := range row.Vals {
+				row.Vals[x] = sqtypes.NewSQNull()
+			}
+			rows[j] = RowInterface(&row)
+		}
+	}
+	return rows, nil
 }
 
 func findCol(a []column.Ref, b column.Ref) int {
@@ -438,6 +627,23 @@ func swapOuterJoin(currentJoin JoinInfo) JoinInfo {
 	return newJoin
 }
 
+// validateWindowSemantics makes sure that window functions are only used in the expression list
+//   of a query without GROUP BY or aggregate functions
+func (q *Query) validateWindowSemantics(windows []*WindowExpr) error {
+	if q.WhereExpr != nil && len(findWindowFuncs(q.WhereExpr, nil)) > 0 {
+		return sqerr.NewSyntax("Window functions are not allowed in the WHERE clause")
+	}
+	for _, j := range q.Joins {
+		if j.ONClause != nil && len(findWindowFuncs(j.ONClause, nil)) > 0 {
+			return sqerr.NewSyntax("Window functions are not allowed in the ON clause")
+		}
+	}
+	if len(windows) > 0 && (q.GroupBy != nil || q.HavingExpr != nil || q.EList.HasAggregateFunc()) {
+		return sqerr.NewSyntax("Window functions can not be used with GROUP BY or aggregate functions")
+	}
+	return nil
+}
+
 //ValidateGroupBySemantics validates a query that it follows the group by rules
 func (q *Query) ValidateGroupBySemantics(profile *sqprofile.SQProfile) error {
 	var err error
@@ -479,6 +685,22 @@ func (q *Query) ValidateGroupBySemantics(profile *sqprofile.SQProfile) error {
 			}
 		}
 	}
+	// The expression of GROUPING() must be in the group by clause
+	funcEx, _ := q.EList.FindAggregateFuncs()
+	if q.HavingExpr != nil {
+		for _, f := range FindAggregateFuncs(*q.HavingExpr) {
+			fex := f
+			funcEx = append(funcEx, &fex)
+		}
+	}
+	for _, fex := range funcEx {
+		if fex.Cmd != tokens.Grouping {
+			continue
+		}
+		if q.GroupBy == nil || q.GroupBy.FindName(fex.exL.Name()) == -1 {
+			return sqerr.NewSyntaxf("%s is not in the group by clause for %s", fex.exL.Name(), fex.Name())
+		}
+	}
 	if q.HavingExpr != nil {
 		h := *q.HavingExpr
 		err = h.ValidateCols(profile, q.Tables)
@@ -497,14 +719,57 @@ func (q *Query) ValidateGroupBySemantics(profile *sqprofile.SQProfile) error {
 	return nil
 }
 
-// ProcessGroupBy sorts and removes duplicate rows in the data set
+// aggregateKeys returns a list of the extra arguments and ORDER BY expressions of the aggregate functions in the
+//   expression list. If there are none then nil is returned
+func (q *Query) aggregateKeys(profile *sqprofile.SQProfile) (*ExprList, error) {
+	var keys []Expr
+
+	funcEx, _ := q.EList.FindAggregateFuncs()
+	for _, fex := range funcEx {
+		keys = append(keys, fex.aggregateKeys()...)
+	}
+	if keys == nil {
+		return nil, nil
+	}
+	aggKeys := NewExprList(keys...)
+	err := aggKeys.ValidateCols(profile, q.Tables)
+	if err != nil {
+		return nil, err
+	}
+	return aggKeys, nil
+}
+
+// ProcessGroupBy sorts and removes duplicate rows in the data set. If there are grouping sets then the
+//   rows are grouped for each set and the group by expressions that are not in the set are null
 func (q *Query) ProcessGroupBy(profile *sqprofile.SQProfile, d *DataSet) error {
 	var err error
-	var gbOrder []OrderItem
 
 	funcEx, funcIdx := q.EList.FindAggregateFuncs()
-	colCnt := make([]int, q.EList.Len())
-	//sort by the group by Cols
+	g := &groupAggs{aggs: make([]Aggregator, len(funcEx)), funcIdx: funcIdx, keyIdx: make([]int, len(funcEx)), nKeys: make([]int, len(funcEx))}
+	// The extra argument and ORDER BY values of the aggregates are after the expression list values in each row
+	g.nCols = q.EList.Len()
+	g.rowLen = g.nCols
+	for j, fex := range funcEx {
+		g.aggs[j], err = newAggregator(fex)
+		if err != nil {
+			return err
+		}
+		g.keyIdx[j] = g.rowLen
+		g.nKeys[j] = len(fex.aggregateKeys())
+		g.rowLen += g.nKeys[j]
+	}
+
+	sets := q.GroupingSets
+	if sets == nil {
+		// A single set with all of the group by expressions
+		var set []int
+		if q.GroupBy != nil {
+			for i := range q.GroupBy.exprlist {
+				set = append(set, i)
+			}
+		}
+		sets = [][]int{set}
+	}
 
 	if q.GroupBy != nil {
 		//save the original order
@@ -512,45 +777,83 @@ func (q *Query) ProcessGroupBy(profile *sqprofile.SQProfile, d *DataSet) error {
 		defer func() {
 			d.order = oldOrder
 		}()
-		d.order = nil
+	}
 
-		// Set the sort order to be the same as the group by & Sort
-		gbOrder = make([]OrderItem, q.GroupBy.Len())
-		for i, expr := range q.GroupBy.GetExprs() {
-			gbOrder[i] = OrderItem{ColName: expr.Name(), SortType: tokens.Asc}
+	result := make([][]sqtypes.Value, 0)
+	for _, set := range sets {
+		//sort by the group by Cols in the set
+		gbOrder := make([]OrderItem, len(set))
+		for i, idx := range set {
+			gbOrder[i] = OrderItem{ColName: q.GroupBy.exprlist[idx].Name(), SortType: tokens.Asc}
+		}
+		if len(gbOrder) > 0 {
+			d.order = nil
+			err = d.SetOrder(gbOrder)
+			if err != nil {
+				return err
+			}
+			err = d.Sort()
+			if err != nil {
+				return err
+			}
+			gbOrder = d.order
 		}
-		err = d.SetOrder(gbOrder)
-		if err != nil {
-			return err
+
+		// GROUPING() is 1 when its expression is not part of the set
+		for j, fex := range funcEx {
+			if gAgg, ok := g.aggs[j].(*groupingAgg); ok {
+				gAgg.notGrouped = !containsInt(set, q.GroupBy.FindName(fex.exL.Name()))
+			}
 		}
-		err = d.Sort()
+
+		rows, err := groupRows(d.Vals, gbOrder, g)
 		if err != nil {
 			return err
 		}
+		if q.GroupBy != nil && len(set) < q.GroupBy.Len() {
+			for i, exp := range q.GroupBy.exprlist {
+				if containsInt(set, i) {
+					continue
+				}
+				idx := q.EList.FindName(exp.Name())
+				for _, row := range rows {
+					row[idx] = sqtypes.NewSQNull()
+				}
+			}
+		}
+		result = append(result, rows...)
 	}
+	d.Vals = result
+
+	err = q.filterHaving(profile, d)
+	return err
+}
+
+// groupRows combines the sorted rows that have the same values for the group by columns into a single row
+//   with the results of the aggregate functions
+func groupRows(vals sqtypes.ValueMatrix, gbOrder []OrderItem, g *groupAggs) (sqtypes.ValueMatrix, error) {
+	var err error
+
 	result := make([][]sqtypes.Value, 0)
-	var res []sqtypes.Value
-	grpCnt := 0
 	resultIdx := 0
 	var match bool
-	for i := range d.Vals {
+	for i := range vals {
 		if len(result) == resultIdx {
-			if q.EList.Len() != len(d.Vals[i]) {
-				return sqerr.NewInternalf("Expression list len (%d) does not match value list len (%d)", q.EList.Len(), len(d.Vals[i]))
+			if g.rowLen != len(vals[i]) {
+				return nil, sqerr.NewInternalf("Expression list len (%d) does not match value list len (%d)", g.rowLen, len(vals[i]))
 			}
-			res, colCnt = initResultRow(q.EList.Len(), d.Vals[i])
-			result = append(result, res)
-			grpCnt = 1
-
-		} else {
-			grpCnt++
-			result[resultIdx], colCnt, err = calcAggregates(d.Vals[i], result[resultIdx], funcEx, funcIdx, colCnt)
+			result = append(result, append([]sqtypes.Value{}, vals[i][:g.nCols]...))
+			g.init()
 		}
-		match = q.GroupBy == nil
+		err = g.accumulate(vals[i])
+		if err != nil {
+			return nil, err
+		}
+		match = len(gbOrder) == 0
 
-		if q.GroupBy != nil && i < len(d.Vals)-1 {
+		if len(gbOrder) > 0 && i < len(vals)-1 {
 			for _, exp := range gbOrder {
-				if d.Vals[i][exp.idx].Equal(d.Vals[i+1][exp.idx]) || (d.Vals[i][exp.idx].IsNull() && d.Vals[i+1][exp.idx].IsNull()) {
+				if vals[i][exp.idx].Equal(vals[i+1][exp.idx]) || (vals[i][exp.idx].IsNull() && vals[i+1][exp.idx].IsNull()) {
 					match = true
 				} else {
 					match = false
@@ -559,28 +862,63 @@ func (q *Query) ProcessGroupBy(profile *sqprofile.SQProfile, d *DataSet) error {
 			}
 		}
 		if !match {
-			v, err := finalizeGroup(result[resultIdx], funcEx, funcIdx, grpCnt, colCnt)
+			err = g.finalize(result[resultIdx])
 			if err != nil {
-				return nil
+				return nil, err
 			}
-			result[resultIdx] = v
 			resultIdx++
-			grpCnt = 0
 		}
 	}
 
 	// fixup last row of results
 	if match {
-		res, err = finalizeGroup(result[resultIdx], funcEx, funcIdx, grpCnt, colCnt)
+		err = g.finalize(result[resultIdx])
 		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// groupAggs are the aggregate functions of a query with the location of their values in the rows of a data set
+type groupAggs struct {
+	aggs    []Aggregator
+	funcIdx []int // value of the function's expression
+	keyIdx  []int // first extra argument or ORDER BY value of the function
+	nKeys   []int
+	nCols   int // number of values from the expression list
+	rowLen  int
+}
+
+// init starts a new group for all of the aggregates
+func (g *groupAggs) init() {
+	for _, agg := range g.aggs {
+		agg.Init()
+	}
+}
+
+// accumulate adds the row to all of the aggregates
+func (g *groupAggs) accumulate(row []sqtypes.Value) error {
+	for j, agg := range g.aggs {
+		vals := append([]sqtypes.Value{row[g.funcIdx[j]]}, row[g.keyIdx[j]:g.keyIdx[j]+g.nKeys[j]]...)
+		if err := agg.Accumulate(vals); err != nil {
 			return err
 		}
-		result[resultIdx] = res
 	}
-	d.Vals = result
+	return nil
+}
 
-	err = q.filterHaving(profile, d)
-	return err
+// finalize puts the results of the aggregates into the row for the group
+func (g *groupAggs) finalize(valRow []sqtypes.Value) error {
+	var err error
+
+	for j, agg := range g.aggs {
+		valRow[g.funcIdx[j]], err = agg.Finalize()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (q *Query) filterHaving(profile *sqprofile.SQProfile, d *DataSet) error {
@@ -617,89 +955,13 @@ func (q *Query) filterHaving(profile *sqprofile.SQProfile, d *DataSet) error {
 	return nil
 }
 
-func finalizeGroup(valRow []sqtypes.Value, funcEx []*FuncExpr, funcIdx []int, mcnt int, colCnt []int) ([]sqtypes.Value, error) {
 
-	for j, fex := range funcEx {
-		switch fex.Cmd {
-		case tokens.Count:
-			valRow[funcIdx[j]] = sqtypes.NewSQInt(mcnt)
-		case tokens.Avg:
-			numer, err := valRow[funcIdx[j]].Convert(tokens.Float)
-			if err != nil {
-				return nil, err
-			}
-			denom := sqtypes.NewSQFloat(float64(colCnt[funcIdx[j]]))
-			v, err := numer.Operation(tokens.Divide, denom)
-			if err != nil {
-				return nil, err
-			}
-			valRow[funcIdx[j]] = v
+// containsInt returns true if the list contains the value
+func containsInt(list []int, val int) bool {
+	for _, v := range list {
+		if v == val {
+			return true
 		}
-
 	}
-	return valRow, nil
-}
-
-func initResultRow(resultLen int, vals []sqtypes.Value) ([]sqtypes.Value, []int) {
-	result := make([]sqtypes.Value, resultLen)
-	colCnt := make([]int, resultLen)
-	for i := range vals {
-		result[i] = vals[i]
-		if !result[i].IsNull() {
-			colCnt[i] = 1
-		}
-
-	}
-	return result, colCnt
-}
-func calcAggregates(vals, result []sqtypes.Value,
-	funcEx []*FuncExpr,
-	funcIdx []int,
-	colCnt []int) ([]sqtypes.Value, []int, error) {
-	var err error
-	for j, fex := range funcEx {
-		if fex.Cmd != tokens.Count {
-			k := funcIdx[j]
-			v := vals[k]
-			if !v.IsNull() {
-				if result[k] == nil || result[k].IsNull() {
-					result[k] = v
-					colCnt[k] = 1
-				} else {
-					switch fex.Cmd {
-					case tokens.Sum:
-						result[k], err = result[k].Operation(tokens.Plus, v)
-					case tokens.Min:
-						lt, err := v.Operation(tokens.LessThan, result[k])
-						if err == nil {
-							b, ok := lt.(sqtypes.SQBool)
-							if b.Bool() && ok {
-								result[k] = v
-							}
-						}
-					case tokens.Max:
-						lt, err := v.Operation(tokens.GreaterThan, result[k])
-						if err == nil {
-							b, ok := lt.(sqtypes.SQBool)
-							if b.Bool() && ok {
-								result[k] = v
-							}
-						}
-					case tokens.Avg:
-						result[k], err = result[k].Operation(tokens.Plus, v)
-						colCnt[k]++
-					default:
-						return nil, nil, sqerr.NewInternalf("Function %s is not a valid aggregate function", tokens.IDName(fex.Cmd))
-					}
-					if err != nil {
-						return nil, nil, err
-					}
-				}
-			}
-
-		}
-
-	}
-
-	return result, colCnt, nil
+	return false
 }
@@ -174,7 +174,29 @@ func TestQueryGetRowData(t *testing.T) {
 				WhereExpr: whereExpr,
 				Joins:     joins,
 			},
-			ExpErr: "Internal Error: TableList must not be empty for query",
+			ExpErr: "Error: Table country not found in table list",
+		},
+		{
+			TestName: "No Tables",
+			Query: sqtables.Query{
+				Tables: sqtables.NewTableList(profile, nil),
+				EList: sqtables.NewExprList(
+					sqtables.NewOpExpr(sqtables.NewValueExpr(sqtypes.NewSQInt(1)), tokens.Plus, sqtables.NewValueExpr(sqtypes.NewSQInt(2))),
+					sqtables.NewValueExpr(sqtypes.NewSQString("test")),
+				),
+			},
+			ExpErr:  "",
+			ExpVals: sqtypes.RawVals{{3, "test"}},
+		},
+		{
+			TestName: "No Tables False Where",
+			Query: sqtables.Query{
+				Tables:    sqtables.NewTableList(profile, nil),
+				EList:     sqtables.NewExprList(sqtables.NewValueExpr(sqtypes.NewSQInt(1))),
+				WhereExpr: sqtables.NewOpExpr(sqtables.NewValueExpr(sqtypes.NewSQInt(1)), tokens.Equal, sqtables.NewValueExpr(sqtypes.NewSQInt(2))),
+			},
+			ExpErr:  "",
+			ExpVals: sqtypes.RawVals{},
 		},
 		{
 			TestName: "Multitable Query No Where clause",
@@ -602,6 +624,75 @@ func TestQueryGetRowData(t *testing.T) {
 
 }
 
+func TestQueryGetRowDataLimit(t *testing.T) {
+	defer sqtest.PanicTestRecovery(t, "")
+
+	profile := sqprofile.CreateSQProfile()
+	// The country table may have been created by TestQueryGetRowData
+	if tab, _ := sqtables.GetTable(profile, "country"); tab == nil {
+		_, err := sqtables.CreateTableFromRawFile(profile, "./testdata/query/country.txt", "country")
+		if err != nil {
+			panic(err)
+		}
+	}
+	// Make sure the first rows are in RowID order
+	sqtables.RowOrder = true
+	defer func() { sqtables.RowOrder = false }()
+
+	short := sqtables.NewColExpr(column.NewRef("short", tokens.String, false))
+	data := []QueryGetRowData{
+		{
+			TestName: "Limit without order by stops early",
+			Query: sqtables.Query{
+				Tables:   sqtables.NewTableList(profile, []sqtables.TableRef{{Name: moniker.New("country", "")}}),
+				EList:    sqtables.NewExprList(short),
+				HasLimit: true,
+				Limit:    2,
+				Offset:   1,
+			},
+			ExpVals: sqtypes.RawVals{{"GBR"}, {"USA"}, {"CAN"}},
+		},
+		{
+			TestName: "Limit with where clause",
+			Query: sqtables.Query{
+				Tables:    sqtables.NewTableList(profile, []sqtables.TableRef{{Name: moniker.New("country", "")}}),
+				EList:     sqtables.NewExprList(short),
+				WhereExpr: sqtables.NewOpExpr(short, tokens.NotEqual, sqtables.NewValueExpr(sqtypes.NewSQString("USA"))),
+				HasLimit:  true,
+				Limit:     2,
+			},
+			ExpVals: sqtypes.RawVals{{"GBR"}, {"CAN"}},
+		},
+		{
+			TestName: "Limit with order by reads all rows",
+			Query: sqtables.Query{
+				Tables:   sqtables.NewTableList(profile, []sqtables.TableRef{{Name: moniker.New("country", "")}}),
+				EList:    sqtables.NewExprList(short),
+				OrderBy:  []sqtables.OrderItem{{ColName: "short", SortType: tokens.Asc}},
+				HasLimit: true,
+				Limit:    1,
+			},
+			ExpVals: sqtypes.RawVals{{"GBR"}, {"USA"}, {"CAN"}, {"FRA"}},
+		},
+		{
+			TestName: "Limit with distinct reads all rows",
+			Query: sqtables.Query{
+				Tables:     sqtables.NewTableList(profile, []sqtables.TableRef{{Name: moniker.New("country", "")}}),
+				EList:      sqtables.NewExprList(short),
+				IsDistinct: true,
+				HasLimit:   true,
+				Limit:      1,
+			},
+			ExpVals: sqtypes.RawVals{{"GBR"}, {"USA"}, {"CAN"}, {"FRA"}},
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testQueryGetRowDataFunc(row))
+	}
+}
+
 type QueryGetRowData struct {
 	TestName    string
 	Query       sqtables.Query
@@ -630,6 +721,12 @@ func testQueryGetRowDataFunc(d QueryGetRowData) func(*testing.T) {
 		} else {
 			expVals = d.ExpVals.ValueMatrix()
 		}
+		if len(expVals) == 0 {
+			if data.Len() != 0 {
+				t.Errorf("Expecting no rows but got %d", data.Len())
+			}
+			return
+		}
 
 		msg := sqtypes.Compare2DValue(data.Vals, expVals, "Actual", "Expect", true)
 		if msg != "" {
@@ -1125,6 +1222,95 @@ func TestGroupBy(t *testing.T) {
 			},
 			ExpErr: "",
 		},
+		{
+			TestName: "Dataset GroupBy firstname with distinct and non null counts",
+			Query: &sqtables.Query{
+				Tables: tables,
+				EList: sqtables.NewExprList(
+					firstNameExp,
+					sqtables.NewAggregateFuncExpr(tokens.Count, ageExp, sqtables.AggregateOptions{Distinct: true}),
+					sqtables.NewAggregateFuncExpr(tokens.Sum, ageExp, sqtables.AggregateOptions{Distinct: true}),
+					sqtables.NewFuncExpr(tokens.Count, ageExp),
+				),
+				GroupBy: sqtables.NewExprList(firstNameExp),
+			},
+			InitVals: sqtypes.RawVals{
+				{"fred", 10, 10, 10},
+				{"betty", 20, 20, 20},
+				{"fred", nil, nil, nil},
+				{"fred", 10, 10, 10},
+				{"betty", nil, nil, nil},
+				{"fred", 30, 30, 30},
+			},
+			ExpVals: sqtypes.RawVals{
+				{"betty", 1, 20, 1},
+				{"fred", 2, 40, 3},
+			},
+			ExpErr: "",
+		},
+		{
+			TestName: "Dataset statistical and string aggregates",
+			Query: &sqtables.Query{
+				Tables: tables,
+				EList: sqtables.NewExprList(
+					sqtables.NewAggregateFuncExpr(tokens.StringAgg, firstNameExp, sqtables.AggregateOptions{
+						Param:   sqtypes.NewSQString(","),
+						OrderBy: []sqtables.OrderExpr{{Exp: ageExp, SortType: tokens.Desc}},
+					}),
+					sqtables.NewFuncExpr(tokens.Median, ageExp),
+					sqtables.NewAggregateFuncExpr(tokens.PercentileDisc, ageExp, sqtables.AggregateOptions{
+						Param:   sqtypes.NewSQFloat(0.5),
+						OrderBy: []sqtables.OrderExpr{{Exp: ageExp, SortType: tokens.Asc}},
+					}),
+				),
+			},
+			InitVals: sqtypes.RawVals{
+				{"fred", 10, 10, 10, 10},
+				{"betty", 20, 20, 20, 20},
+				{"barney", 40, 40, 40, 40},
+				{"wilma", 30, 30, 30, 30},
+			},
+			ExpVals: sqtypes.RawVals{
+				{"barney,wilma,betty,fred", 25.0, 20},
+			},
+			ExpErr: "",
+		},
+		{
+			TestName: "Dataset GroupBy grouping sets",
+			Query: &sqtables.Query{
+				Tables:       tables,
+				EList:        sqtables.NewExprList(firstNameExp, sqtables.NewFuncExpr(tokens.Count, nil), sqtables.NewFuncExpr(tokens.Grouping, firstNameExp)),
+				GroupBy:      sqtables.NewExprList(firstNameExp),
+				GroupingSets: [][]int{{0}, {}},
+			},
+			InitVals: sqtypes.RawVals{
+				{"fred", nil, "fred"},
+				{"betty", nil, "betty"},
+				{"fred", nil, "fred"},
+			},
+			ExpVals: sqtypes.RawVals{
+				{"betty", 1, 0},
+				{"fred", 2, 0},
+				{nil, 3, 1},
+			},
+			ExpErr: "",
+		},
+		{
+			TestName: "Dataset aggregate missing ORDER BY values",
+			Query: &sqtables.Query{
+				Tables: tables,
+				EList: sqtables.NewExprList(
+					sqtables.NewAggregateFuncExpr(tokens.StringAgg, firstNameExp, sqtables.AggregateOptions{
+						Param:   sqtypes.NewSQString(","),
+						OrderBy: []sqtables.OrderExpr{{Exp: ageExp, SortType: tokens.Desc}},
+					}),
+				),
+			},
+			InitVals: sqtypes.RawVals{
+				{"fred"},
+			},
+			ExpErr: "Internal Error: Expression list len (2) does not match value list len (1)",
+		},
 	}
 
 	for i, row := range data {
@@ -0,0 +1,102 @@
+package sqtables
+
+import (
+	"sort"
+
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqptr"
+	"github.com/wilphi/sqsrv/sqtables/moniker"
+	"github.com/wilphi/sqsrv/sqtypes"
+)
+
+// Returning holds the RETURNING clause of an INSERT, UPDATE or DELETE. The expressions are evaluated
+//   for each row of the table that is added, updated or deleted. Added and updated rows use the values
+//   after the change, deleted rows use the values before they were deleted
+type Returning struct {
+	tab   *TableDef
+	eList *ExprList
+	rows  map[sqptr.SQPtr][]sqtypes.Value
+}
+
+// NewReturning creates the RETURNING clause for the table. The expressions can only use the columns of
+//   the table
+func NewReturning(profile *sqprofile.SQProfile, tab *TableDef, eList *ExprList) (*Returning, error) {
+	if eList.HasAggregateFunc() {
+		return nil, sqerr.NewSyntax("Aggregate functions are not allowed in RETURNING clause")
+	}
+	if len(eList.FindWindowFuncs()) > 0 {
+		return nil, sqerr.NewSyntax("Window functions are not allowed in RETURNING clause")
+	}
+	err := eList.ValidateCols(profile, NewTableListFromTableDef(profile, tab))
+	if err != nil {
+		return nil, err
+	}
+	return &Returning{tab: tab, eList: eList, rows: make(map[sqptr.SQPtr][]sqtypes.Value)}, nil
+}
+
+// Watch returns a transaction that records the rows of the table as they are changed by trans
+func (r *Returning) Watch(trans Transaction) Transaction {
+	return &returningTrans{Transaction: trans, ret: r}
+}
+
+// record keeps the values of the row if it is in the table of the RETURNING clause
+func (r *Returning) record(profile *sqprofile.SQProfile, tab *TableDef, row RowInterface) {
+	if tab != r.tab {
+		return
+	}
+	r.rows[row.GetPtr(profile)] = row.GetVals(profile)
+}
+
+// GetData returns the result of the expressions for each of the changed rows in the order of the rows
+//   in the table
+func (r *Returning) GetData(profile *sqprofile.SQProfile) (*DataSet, error) {
+	data, err := NewDataSet(profile, NewTableListFromTableDef(profile, r.tab), r.eList)
+	if err != nil {
+		return nil, err
+	}
+
+	ptrs := make(sqptr.SQPtrs, 0, len(r.rows))
+	for ptr := range r.rows {
+		ptrs = append(ptrs, ptr)
+	}
+	sort.Slice(ptrs, func(i, j int) bool { return ptrs[i] < ptrs[j] })
+
+	tabName := moniker.New(r.tab.GetName(profile), "")
+	data.Vals = make([][]sqtypes.Value, len(ptrs))
+	data.Ptrs = ptrs
+	for i, ptr := range ptrs {
+		row := &JoinRow{Ptr: ptr, Vals: r.rows[ptr], TableName: tabName}
+		data.Vals[i], err = r.eList.Evaluate(profile, EvalFull, row)
+		if err != nil {
+			return nil, err
+		}
+	}
+	data.PadChars()
+	return data, nil
+}
+
+// returningTrans passes the changes of a transaction on to the underlying transaction after they have
+//   been recorded for the RETURNING clause
+type returningTrans struct {
+	Transaction
+	ret *Returning
+}
+
+// AddRow to transaction
+func (t *returningTrans) AddRow(tab *TableDef, row RowInterface) error {
+	t.ret.record(t.Profile(), tab, row)
+	return t.Transaction.AddRow(tab, row)
+}
+
+// UpdateRow to transaction
+func (t *returningTrans) UpdateRow(tab *TableDef, row RowInterface) error {
+	t.ret.record(t.Profile(), tab, row)
+	return t.Transaction.UpdateRow(tab, row)
+}
+
+// Delete soft deletes a row from the given table in a transaction
+func (t *returningTrans) Delete(tab *TableDef, row RowInterface) error {
+	t.ret.record(t.Profile(), tab, row)
+	return t.Transaction.Delete(tab, row)
+}
@@ -0,0 +1,264 @@
+package sqtables
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqprofile"
+)
+
+// SeqLogAhead is the number of sequence values that are reserved in the transaction log at a time.
+//   After a crash a sequence continues from the end of the last reserved block so values are never
+//   given out twice, but some values may be skipped
+const SeqLogAhead = 32
+
+// SequenceLog records the state of a sequence in the transaction log. It is set by the redo package
+var SequenceLog = func(name string, value int, isCalled bool) error { return nil }
+
+// SequenceDef is a named counter that gives out a new value each time NEXTVAL is called
+type SequenceDef struct {
+	seqName   string
+	start     int
+	increment int
+	owner     string
+	value     int
+	isCalled  bool
+	logCnt    int
+	mtx       sync.Mutex
+}
+
+// CreateSequenceDef creates a sequence definition. owner is the name of the table that
+//   uses the sequence for an identity column, blank if the sequence was created with CREATE SEQUENCE
+func CreateSequenceDef(name string, start, increment int, owner string) *SequenceDef {
+	return &SequenceDef{seqName: strings.ToLower(name), start: start, increment: increment, owner: strings.ToLower(owner), value: start}
+}
+
+// GetName returns the name of the sequence
+func (s *SequenceDef) GetName() string {
+	return s.seqName
+}
+
+// GetStart returns the first value of the sequence
+func (s *SequenceDef) GetStart() int {
+	return s.start
+}
+
+// GetIncrement returns the amount the sequence changes for each call to NEXTVAL
+func (s *SequenceDef) GetIncrement() int {
+	return s.increment
+}
+
+// GetOwner returns the name of the table that owns the sequence
+func (s *SequenceDef) GetOwner() string {
+	return s.owner
+}
+
+// GetState returns the current value of the sequence and if it has been given out yet
+func (s *SequenceDef) GetState() (int, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.value, s.isCalled
+}
+
+// checkpointState returns the current value of the sequence to be saved by a checkpoint. The log
+//   entry that reserved the current block of values is older than the checkpoint so it will not be
+//   replayed, the next call to NEXTVAL reserves a new block
+func (s *SequenceDef) checkpointState() (int, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.logCnt = 0
+	return s.value, s.isCalled
+}
+
+// SetState sets the current value of the sequence without writing to the transaction log.
+//   It is used to restore a sequence from the transaction log or a checkpoint
+func (s *SequenceDef) SetState(value int, isCalled bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.value = value
+	s.isCalled = isCalled
+	s.logCnt = 0
+}
+
+// String returns the sequence as a CREATE SEQUENCE statement
+func (s *SequenceDef) String() string {
+	return fmt.Sprintf("CREATE SEQUENCE %s START %d INCREMENT %d", s.seqName, s.start, s.increment)
+}
+
+// NextVal advances the sequence and returns the new value
+func (s *SequenceDef) NextVal() (int, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	val := s.value
+	if s.isCalled {
+		if (s.increment > 0 && val > math.MaxInt64-s.increment) || (s.increment < 0 && val < math.MinInt64-s.increment) {
+			return 0, sqerr.Newf("Sequence %s has reached its limit", s.seqName)
+		}
+		val += s.increment
+	}
+	if s.logCnt <= 0 {
+		// reserve the next block of values in the transaction log
+		logVal := val
+		for i := 1; i < SeqLogAhead; i++ {
+			if (s.increment > 0 && logVal > math.MaxInt64-s.increment) || (s.increment < 0 && logVal < math.MinInt64-s.increment) {
+				break
+			}
+			logVal += s.increment
+		}
+		if err := SequenceLog(s.seqName, logVal, true); err != nil {
+			return 0, err
+		}
+		s.logCnt = SeqLogAhead
+	}
+	s.logCnt--
+	s.value = val
+	s.isCalled = true
+
+	return val, nil
+}
+
+// CurrVal returns the last value given out by the sequence
+func (s *SequenceDef) CurrVal() (int, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if !s.isCalled {
+		return 0, sqerr.Newf("CURRVAL of sequence %s is not yet defined", s.seqName)
+	}
+	return s.value, nil
+}
+
+// SetVal sets the current value of the sequence. If isCalled is true the next call to NEXTVAL
+//   returns value plus the increment, otherwise it returns value
+func (s *SequenceDef) SetVal(value int, isCalled bool) (int, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if err := SequenceLog(s.seqName, value, isCalled); err != nil {
+		return 0, err
+	}
+	s.value = value
+	s.isCalled = isCalled
+	s.logCnt = 0
+
+	return value, nil
+}
+
+// CreateSequence adds a sequence to the catalog
+//		protected by a mutex to be concurrency safe
+func CreateSequence(profile *sqprofile.SQProfile, seq *SequenceDef) error {
+	seqName := seq.seqName
+	// Err if name begins with _ (UnderScore is reserved for system tables)
+	if isUnderScore(seqName) {
+		return sqerr.Newf("Invalid Name: %s - Only system tables may begin with _", seqName)
+	}
+
+	if seqName == "" {
+		return sqerr.New("Invalid Name: Sequence names can not be blank")
+	}
+	if seq.increment == 0 {
+		return sqerr.Newf("The INCREMENT of sequence %s can not be zero", seqName)
+	}
+
+	err := _Catalog.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer _Catalog.Unlock(profile)
+
+	if _Catalog.sequences[seqName] != nil {
+		return sqerr.Newf("Invalid Name: Sequence %s already exists", seqName)
+	}
+	_Catalog.sequences[seqName] = seq
+
+	return nil
+}
+
+// DropSequence removes a sequence from the catalog
+//		protected by a mutex to be concurrency safe
+func DropSequence(profile *sqprofile.SQProfile, name string) error {
+	name = strings.ToLower(name)
+
+	err := _Catalog.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer _Catalog.Unlock(profile)
+
+	if _Catalog.sequences[name] == nil {
+		return sqerr.Newf("Invalid Name: Sequence %s does not exist", name)
+	}
+	delete(_Catalog.sequences, name)
+
+	return nil
+}
+
+// GetSequence returns the sequence definition for the given name. If there is no sequence
+//   with the name then nil is returned
+func GetSequence(profile *sqprofile.SQProfile, name string) (*SequenceDef, error) {
+	err := _Catalog.RLock(profile)
+	if err != nil {
+		return nil, err
+	}
+	defer _Catalog.RUnlock(profile)
+
+	return _Catalog.sequences[strings.ToLower(name)], nil
+}
+
+// CatalogSequences returns a sorted list of sequence names
+func CatalogSequences(profile *sqprofile.SQProfile) ([]string, error) {
+	err := _Catalog.RLock(profile)
+	if err != nil {
+		return nil, err
+	}
+	defer _Catalog.RUnlock(profile)
+
+	var sNames []string
+	for name := range _Catalog.sequences {
+		sNames = append(sNames, name)
+	}
+	sort.Strings(sNames)
+
+	return sNames, nil
+}
+
+// OwnedSequences returns a sorted list of the sequences owned by a table
+func OwnedSequences(profile *sqprofile.SQProfile, tableName string) ([]string, error) {
+	tableName = strings.ToLower(tableName)
+
+	err := _Catalog.RLock(profile)
+	if err != nil {
+		return nil, err
+	}
+	defer _Catalog.RUnlock(profile)
+
+	var sNames []string
+	for name, seq := range _Catalog.sequences {
+		if seq.owner != "" && seq.owner == tableName {
+			sNames = append(sNames, name)
+		}
+	}
+	sort.Strings(sNames)
+
+	return sNames, nil
+}
+
+// getSequence returns the sequence with the given name or an error if it does not exist
+func getSequence(profile *sqprofile.SQProfile, name string) (*SequenceDef, error) {
+	seq, err := GetSequence(profile, name)
+	if err != nil {
+		return nil, err
+	}
+	if seq == nil {
+		return nil, sqerr.Newf("Sequence %s does not exist", name)
+	}
+	return seq, nil
+}
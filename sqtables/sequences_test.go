@@ -0,0 +1,281 @@
+package sqtables_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtest"
+)
+
+type SequenceData struct {
+	TestName  string
+	SeqName   string
+	Start     int
+	Increment int
+	Owner     string
+	Drop      bool
+	ExpErr    string
+	ExpSeqs   []string
+	ExpOwned  []string
+}
+
+func testSequenceFunc(profile *sqprofile.SQProfile, d SequenceData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		var err error
+		if d.Drop {
+			err = sqtables.DropSequence(profile, d.SeqName)
+		} else {
+			err = sqtables.CreateSequence(profile, sqtables.CreateSequenceDef(d.SeqName, d.Start, d.Increment, d.Owner))
+		}
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+
+		seq, err := sqtables.GetSequence(profile, d.SeqName)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if d.Drop {
+			if seq != nil {
+				t.Errorf("Sequence %s has not been dropped", d.SeqName)
+			}
+		} else {
+			if seq == nil {
+				t.Errorf("Sequence %s was not created", d.SeqName)
+				return
+			}
+			if seq.GetStart() != d.Start || seq.GetIncrement() != d.Increment {
+				t.Errorf("Sequence %s does not match the definition: %s", d.SeqName, seq.String())
+				return
+			}
+		}
+
+		seqs, err := sqtables.CatalogSequences(profile)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(seqs, d.ExpSeqs) {
+			t.Errorf("Actual sequences %v do not match expected %v", seqs, d.ExpSeqs)
+			return
+		}
+		owned, err := sqtables.OwnedSequences(profile, "seqtesttable")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(owned, d.ExpOwned) {
+			t.Errorf("Actual owned sequences %v do not match expected %v", owned, d.ExpOwned)
+		}
+	}
+}
+
+func TestSequences(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+
+	data := []SequenceData{
+		{
+			TestName:  "Create Sequence",
+			SeqName:   "seqtest1",
+			Start:     1,
+			Increment: 1,
+			ExpSeqs:   []string{"seqtest1"},
+		},
+		{
+			TestName:  "Create Sequence with owner",
+			SeqName:   "SeqTestTable_id_seq",
+			Start:     10,
+			Increment: -2,
+			Owner:     "SeqTestTable",
+			ExpSeqs:   []string{"seqtest1", "seqtesttable_id_seq"},
+			ExpOwned:  []string{"seqtesttable_id_seq"},
+		},
+		{
+			TestName:  "Duplicate Sequence",
+			SeqName:   "SEQTEST1",
+			Start:     1,
+			Increment: 1,
+			ExpErr:    "Error: Invalid Name: Sequence seqtest1 already exists",
+		},
+		{
+			TestName:  "Underscore Sequence",
+			SeqName:   "_seqtest",
+			Start:     1,
+			Increment: 1,
+			ExpErr:    "Error: Invalid Name: _seqtest - Only system tables may begin with _",
+		},
+		{
+			TestName:  "Blank Sequence",
+			SeqName:   "",
+			Start:     1,
+			Increment: 1,
+			ExpErr:    "Error: Invalid Name: Sequence names can not be blank",
+		},
+		{
+			TestName:  "Zero Increment",
+			SeqName:   "seqtest2",
+			Start:     1,
+			Increment: 0,
+			ExpErr:    "Error: The INCREMENT of sequence seqtest2 can not be zero",
+		},
+		{
+			TestName: "Drop Sequence",
+			SeqName:  "seqtesttable_id_seq",
+			Drop:     true,
+			ExpSeqs:  []string{"seqtest1"},
+		},
+		{
+			TestName: "Drop invalid Sequence",
+			SeqName:  "seqtesttable_id_seq",
+			Drop:     true,
+			ExpErr:   "Error: Invalid Name: Sequence seqtesttable_id_seq does not exist",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testSequenceFunc(profile, row))
+	}
+}
+
+type seqLogEntry struct {
+	value    int
+	isCalled bool
+}
+
+func TestSequenceValues(t *testing.T) {
+	var logged []seqLogEntry
+	saveLog := sqtables.SequenceLog
+	defer func() { sqtables.SequenceLog = saveLog }()
+	sqtables.SequenceLog = func(name string, value int, isCalled bool) error {
+		logged = append(logged, seqLogEntry{value, isCalled})
+		return nil
+	}
+
+	t.Run("CURRVAL before NEXTVAL", func(t *testing.T) {
+		seq := sqtables.CreateSequenceDef("seqval", 1, 1, "")
+		_, err := seq.CurrVal()
+		sqtest.CheckErr(t, err, "Error: CURRVAL of sequence seqval is not yet defined")
+	})
+
+	t.Run("NEXTVAL reserves blocks of values", func(t *testing.T) {
+		logged = nil
+		seq := sqtables.CreateSequenceDef("seqval", 5, 2, "")
+		for i := 0; i < sqtables.SeqLogAhead+1; i++ {
+			val, err := seq.NextVal()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if val != 5+i*2 {
+				t.Errorf("NEXTVAL returned %d, expected %d", val, 5+i*2)
+				return
+			}
+		}
+		expLog := []seqLogEntry{{5 + (sqtables.SeqLogAhead-1)*2, true}, {5 + (2*sqtables.SeqLogAhead-1)*2, true}}
+		if !reflect.DeepEqual(logged, expLog) {
+			t.Errorf("Logged values %v do not match expected %v", logged, expLog)
+			return
+		}
+		cur, err := seq.CurrVal()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if cur != 5+sqtables.SeqLogAhead*2 {
+			t.Errorf("CURRVAL returned %d, expected %d", cur, 5+sqtables.SeqLogAhead*2)
+		}
+	})
+
+	t.Run("SETVAL is always logged", func(t *testing.T) {
+		logged = nil
+		seq := sqtables.CreateSequenceDef("seqval", 1, 1, "")
+		_, err := seq.SetVal(50, false)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		val, err := seq.NextVal()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expLog := []seqLogEntry{{50, false}, {50 + sqtables.SeqLogAhead - 1, true}}
+		if val != 50 || !reflect.DeepEqual(logged, expLog) {
+			t.Errorf("NEXTVAL returned %d and logged %v, expected 50 and %v", val, logged, expLog)
+		}
+	})
+
+	t.Run("SetState is not logged", func(t *testing.T) {
+		logged = nil
+		seq := sqtables.CreateSequenceDef("seqval", 1, 1, "")
+		seq.SetState(70, true)
+		val, isCalled := seq.GetState()
+		if val != 70 || !isCalled || logged != nil {
+			t.Errorf("State (%d, %t) and log %v do not match expected (70, true) and no log", val, isCalled, logged)
+		}
+	})
+
+	t.Run("NEXTVAL after a checkpoint and restart", func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		profile := sqprofile.CreateSQProfile()
+		dir, err := ioutil.TempDir("", "sqtestseqcheckpoint")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer os.RemoveAll(dir)
+		sqtables.SetDBDir(dir)
+
+		seq := sqtables.CreateSequenceDef("seqcheckpoint", 1, 1, "")
+		err = sqtables.CreateSequence(profile, seq)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer sqtables.DropSequence(profile, "seqcheckpoint")
+		seq.NextVal()
+		seq.NextVal()
+		err = sqtables.WriteDB(profile)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		// The state saved by the checkpoint and the log entries written after it are used by a restart
+		value, isCalled := seq.GetState()
+		logged = nil
+		seq.NextVal()
+		seq.NextVal()
+
+		restart := sqtables.CreateSequenceDef("seqcheckpoint", 1, 1, "")
+		restart.SetState(value, isCalled)
+		for _, entry := range logged {
+			restart.SetState(entry.value, entry.isCalled)
+		}
+		val, err := restart.NextVal()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if val <= 4 {
+			t.Errorf("NEXTVAL after restart returned %d, expected a value greater than 4", val)
+		}
+	})
+
+	t.Run("NEXTVAL at limit", func(t *testing.T) {
+		seq := sqtables.CreateSequenceDef("seqval", 1, 1, "")
+		seq.SetState(math.MaxInt64, true)
+		_, err := seq.NextVal()
+		sqtest.CheckErr(t, err, "Error: Sequence seqval has reached its limit")
+	})
+}
@@ -1,6 +1,8 @@
 package sqtables
 
 import (
+	"strings"
+
 	"github.com/wilphi/sqsrv/sqerr"
 	"github.com/wilphi/sqsrv/sqprofile"
 	"github.com/wilphi/sqsrv/sqptr"
@@ -69,10 +71,11 @@ func (r *RowDef) UpdateRow(profile *sqprofile.SQProfile, cols []string, vals []s
 		if colDef.IsNotNull && vals[i].IsNull() {
 			return sqerr.Newf("Column %q in Table %q can not be NULL", col, r.Table.tableName)
 		}
-		if colDef.ColType != vals[i].Type() && !vals[i].IsNull() {
-			return sqerr.Newf("Type Mismatch: Column %s in Table %s has a type of %s, Unable to set value of type %s", colDef.ColName, r.Table.tableName, tokens.IDName(colDef.ColType), tokens.IDName(vals[i].Type()))
+		val, err := ColValue(colDef, r.Table.tableName, vals[i])
+		if err != nil {
+			return err
 		}
-		r.Data[colDef.Idx] = vals[i]
+		r.Data[colDef.Idx] = val
 
 	}
 	r.isModified = true
@@ -110,11 +113,12 @@ func CreateRow(profile *sqprofile.SQProfile, rowPtr sqptr.SQPtr, table *TableDef
 		if colDef.IsNotNull && vals[i].IsNull() {
 			return nil, sqerr.Newf("Column %q in Table %q can not be NULL", col, row.Table.tableName)
 		}
-		if colDef.ColType != vals[i].Type() && !vals[i].IsNull() {
-			return nil, sqerr.Newf("Type Mismatch: Column %s in Table %s has a type of %s, Unable to set value of type %s", colDef.ColName, row.Table.tableName, tokens.IDName(colDef.ColType), tokens.IDName(vals[i].Type()))
+		val, err := ColValue(colDef, row.Table.tableName, vals[i])
+		if err != nil {
+			return nil, err
 		}
 
-		row.Data[colDef.Idx] = vals[i]
+		row.Data[colDef.Idx] = val
 
 	}
 	// Validate NotNull cols
@@ -131,6 +135,99 @@ func CreateRow(profile *sqprofile.SQProfile, rowPtr sqptr.SQPtr, table *TableDef
 	return &row, nil
 }
 
+// ColValue returns the value as it is stored in the column. An error is returned if the value does
+//   not fit in the column or is not the type of the column
+func ColValue(colDef *column.Def, tableName string, val sqtypes.Value) (sqtypes.Value, error) {
+	val, err := fitValue(colDef, tableName, val)
+	if err != nil {
+		return nil, err
+	}
+	if colDef.ColType != val.Type() && !val.IsNull() {
+		return nil, sqerr.Newf("Type Mismatch: Column %s in Table %s has a type of %s, Unable to set value of type %s", colDef.ColName, tableName, tokens.IDName(colDef.ColType), tokens.IDName(val.Type()))
+	}
+	return val, nil
+}
+
+// fitValue adjusts a value to the declared size of the column. INT and FLOAT values are converted
+//   for DECIMAL columns and rounded to the scale of the column. STRING values are validated and converted
+//   for JSON columns. VARCHAR and CHAR values must fit in the length of the column. CHAR values are
+//   stored without trailing spaces, they are padded when they are returned by a query. SMALLINT and
+//   INTEGER values must be in the range of the type. STRING values are converted for DATE, TIME,
+//   TIMESTAMP, INTERVAL and UUID columns
+func fitValue(colDef *column.Def, tableName string, val sqtypes.Value) (sqtypes.Value, error) {
+	if val.IsNull() {
+		return val, nil
+	}
+	switch colDef.ColType {
+	case tokens.JSON:
+		// JSON documents may be given as strings but they must be valid
+		if _, ok := val.(sqtypes.SQString); ok {
+			return val.Convert(tokens.JSON)
+		}
+	case tokens.String:
+		str, ok := val.(sqtypes.SQString)
+		if !ok || colDef.Precision == 0 {
+			return val, nil
+		}
+		runes := []rune(str.Val)
+		if len(runes) > colDef.Precision {
+			// Trailing spaces that do not fit are removed
+			runes = []rune(strings.TrimRight(str.Val, " "))
+			if len(runes) > colDef.Precision {
+				return nil, sqerr.Newf("Value %q of Column %s in Table %s does not fit in %s", str.Val, colDef.ColName, tableName, colDef.TypeName())
+			}
+		}
+		if colDef.DeclType == tokens.Char {
+			return sqtypes.NewSQString(strings.TrimRight(string(runes), " ")), nil
+		}
+		return sqtypes.NewSQString(string(runes)), nil
+	case tokens.Int:
+		i, ok := val.(sqtypes.SQInt)
+		min, max, sized := colDef.IntRange()
+		if ok && sized && (i.Val < min || i.Val > max) {
+			return nil, sqerr.Newf("Value %d of Column %s in Table %s is out of range for %s", i.Val, colDef.ColName, tableName, colDef.TypeName())
+		}
+	case tokens.Decimal:
+		return fitDecimal(colDef, tableName, val)
+	default:
+		if _, ok := val.(sqtypes.SQString); ok && fromString(colDef.ColType) {
+			return val.Convert(colDef.ColType)
+		}
+	}
+	return val, nil
+}
+
+// fromString returns true if STRING values are converted to the type when they are stored in or
+//   compared to a column of the type
+func fromString(typ tokens.TokenID) bool {
+	switch typ {
+	case tokens.Date, tokens.Time, tokens.Timestamp, tokens.Interval, tokens.UUID, tokens.Decimal:
+		return true
+	}
+	return false
+}
+
+// fitDecimal converts INT, FLOAT and STRING values to DECIMAL and rounds them to the scale of the column
+func fitDecimal(colDef *column.Def, tableName string, val sqtypes.Value) (sqtypes.Value, error) {
+	switch val.(type) {
+	case sqtypes.SQInt, sqtypes.SQFloat, sqtypes.SQString:
+		var err error
+		val, err = val.Convert(tokens.Decimal)
+		if err != nil {
+			return nil, err
+		}
+	}
+	d, ok := val.(sqtypes.SQDecimal)
+	if !ok || colDef.Precision == 0 {
+		return val, nil
+	}
+	d = d.Round(colDef.Scale)
+	if d.Digits() > colDef.Precision {
+		return nil, sqerr.Newf("Value %s of Column %s in Table %s does not fit in %s", val.String(), colDef.ColName, tableName, colDef.TypeName())
+	}
+	return d, nil
+}
+
 // ColVal -
 func (r *RowDef) ColVal(profile *sqprofile.SQProfile, c *column.Ref) (sqtypes.Value, error) {
 
@@ -34,6 +34,8 @@ type TableDef struct {
 	nextOffset  int64
 	nextRowID   *uint64
 	isDropped   bool
+	isTruncated bool
+	isTemp      bool
 	*sqmutex.SQMtx
 }
 
@@ -103,20 +105,22 @@ func (t *TableDef) AddConstraints(profile *sqprofile.SQProfile, constraints []Co
 	defer t.Unlock(profile)
 
 	if t.constraints == nil {
-		t.constraints = constraints
+		err = t.setConstraints(profile, constraints)
 	} else {
-		t.constraints = append(t.constraints, constraints...)
+		err = t.setConstraints(profile, append(t.constraints, constraints...))
 	}
+	return err
+}
 
-	// Sort the constraints so that the order is PK, FK, Unique, Index
-	sort.SliceStable(t.constraints, func(i int, j int) bool { return t.constraints[i].Ordering() < t.constraints[j].Ordering() })
-
-	for i := range t.constraints {
-		err = t.constraints[i].Validate(profile, t)
-		if err != nil {
-			return err
+// checkRow makes sure that a new or updated row satisfies the CHECK constraints of the table
+func (t *TableDef) checkRow(profile *sqprofile.SQProfile, row RowInterface) error {
+	for _, con := range t.constraints {
+		if chk, ok := con.(*Check); ok {
+			err := chk.CheckRow(profile, t, row)
+			if err != nil {
+				return err
+			}
 		}
-
 	}
 	return nil
 }
@@ -186,6 +190,11 @@ func (t *TableDef) AddRows(trans Transaction, data *DataSet) (int, error) {
 			trans.RollbackIfAuto()
 			return -1, err
 		}
+		err = t.checkRow(trans.Profile(), row)
+		if err != nil {
+			trans.RollbackIfAuto()
+			return -1, err
+		}
 		newRows[cnt] = row
 	}
 
@@ -341,9 +350,12 @@ func (t *TableDef) NumCol(profile *sqprofile.SQProfile) int {
 //    If the expression is nil, then all rows are returned. The list can be sorted or not.
 //    By default the table is Read Locked, to have a write lock the calling function must do it.
 func (t *TableDef) GetRowPtrs(profile *sqprofile.SQProfile, exp Expr, sorted bool) (ptrs sqptr.SQPtrs, err error) {
-	var val sqtypes.Value
+	return t.getRowPtrs(profile, exp, sorted, 0)
+}
 
-	includeRow := (exp == nil)
+// getRowPtrs returns the rowIDs of the first limit rows that match the expression. The rows after them
+//   are not checked. A limit <= 0 returns all of the matching rows
+func (t *TableDef) getRowPtrs(profile *sqprofile.SQProfile, exp Expr, sorted bool, limit int) (ptrs sqptr.SQPtrs, err error) {
 	err = t.RLock(profile)
 	if err != nil {
 		return nil, err
@@ -351,31 +363,41 @@ func (t *TableDef) GetRowPtrs(profile *sqprofile.SQProfile, exp Expr, sorted boo
 
 	defer t.RUnlock(profile)
 
-	for rowID, row := range t.rowm {
-
-		if row == nil || row.IsDeleted(profile) {
-			continue
+	// The first rows in RowID order are needed so the rows are checked in that order
+	if sorted && limit > 0 {
+		rowIDs := make(sqptr.SQPtrs, 0, len(t.rowm))
+		for rowID := range t.rowm {
+			rowIDs = append(rowIDs, rowID)
 		}
-		if exp != nil {
-			val, err = exp.Evaluate(profile, EvalPartial, row)
+		sort.Slice(rowIDs, func(i, j int) bool { return rowIDs[i] < rowIDs[j] })
+		for _, rowID := range rowIDs {
+			include, err := t.includeRow(profile, exp, t.rowm[rowID])
 			if err != nil {
 				return nil, err
 			}
-			if val != nil {
-				boolVal, ok := val.(sqtypes.SQBool)
-				if ok {
-					includeRow = boolVal.Bool()
+			if include {
+				ptrs = append(ptrs, rowID)
+				if len(ptrs) >= limit {
+					break
 				}
-			} else {
-				includeRow = true
 			}
+		}
+		return ptrs, nil
+	}
 
+	for rowID, row := range t.rowm {
+		include, err := t.includeRow(profile, exp, row)
+		if err != nil {
+			return nil, err
 		}
-		if !includeRow {
+		if !include {
 			continue
 		}
 
 		ptrs = append(ptrs, rowID)
+		if limit > 0 && len(ptrs) >= limit {
+			break
+		}
 	}
 	if sorted {
 		sort.Slice(ptrs, func(i, j int) bool { return ptrs[i] < ptrs[j] })
@@ -383,6 +405,27 @@ func (t *TableDef) GetRowPtrs(profile *sqprofile.SQProfile, exp Expr, sorted boo
 	return ptrs, nil
 }
 
+// includeRow returns true if the row has not been deleted and matches the expression. A nil expression
+//   matches all rows
+func (t *TableDef) includeRow(profile *sqprofile.SQProfile, exp Expr, row RowInterface) (bool, error) {
+	if row == nil || row.IsDeleted(profile) {
+		return false, nil
+	}
+	if exp == nil {
+		return true, nil
+	}
+	val, err := exp.Evaluate(profile, EvalPartial, row)
+	if err != nil {
+		return false, err
+	}
+	if val == nil {
+		return true, nil
+	}
+	// A null result does not include the row
+	boolVal, ok := val.(sqtypes.SQBool)
+	return ok && boolVal.Bool(), nil
+}
+
 //UpdateRows updates rows in the table based on the given expression, columns to be changed and values to be set
 func (t *TableDef) UpdateRows(trans Transaction, exp Expr, cols []string, eList *ExprList) (int, error) {
 	// get the data
@@ -436,6 +479,11 @@ func (t *TableDef) UpdateRowsFromPtrs(trans Transaction, ptrs sqptr.SQPtrs, cols
 			trans.RollbackIfAuto()
 			return err
 		}
+		err = t.checkRow(trans.Profile(), row)
+		if err != nil {
+			trans.RollbackIfAuto()
+			return err
+		}
 		err = trans.UpdateRow(t, row)
 
 	}
@@ -453,6 +501,12 @@ func (t *TableDef) GetRow(profile *sqprofile.SQProfile, RowPtr sqptr.SQPtr) RowI
 
 // GetRowData - Returns a dataset with the data from table
 func (tr *TableRef) GetRowData(profile *sqprofile.SQProfile, eList *ExprList, whereExpr Expr) (*DataSet, error) {
+	return tr.getRowData(profile, eList, whereExpr, 0)
+}
+
+// getRowData returns a dataset with the data from the first limit rows of the table that match
+//   whereExpr. A limit <= 0 returns all of the matching rows
+func (tr *TableRef) getRowData(profile *sqprofile.SQProfile, eList *ExprList, whereExpr Expr, limit int) (*DataSet, error) {
 	var err error
 
 	err = tr.Table.RLock(profile)
@@ -471,7 +525,7 @@ func (tr *TableRef) GetRowData(profile *sqprofile.SQProfile, eList *ExprList, wh
 	ret.usePtrs = !eList.HasAggregateFunc()
 
 	// Get the pointers to the rows based on the conditions
-	ptrs, err := tr.Table.GetRowPtrs(profile, whereExpr, RowOrder)
+	ptrs, err := tr.Table.getRowPtrs(profile, whereExpr, RowOrder, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -497,6 +551,11 @@ func (tr *TableRef) GetRowData(profile *sqprofile.SQProfile, eList *ExprList, wh
 func (tr *TableRef) Validate(profile *sqprofile.SQProfile) error {
 	var err error
 	if tr.Table == nil {
+		// Temporary tables hide tables in the catalog
+		tr.Table = GetTempTable(profile, tr.Name.Name())
+		if tr.Table != nil {
+			return nil
+		}
 		// Get the TableDef
 		tr.Table, err = GetTable(profile, tr.Name.Name())
 		if err != nil {
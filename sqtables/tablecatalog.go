@@ -7,10 +7,13 @@ import (
 	"github.com/wilphi/sqsrv/sqerr"
 	"github.com/wilphi/sqsrv/sqmutex"
 	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqptr"
 )
 
 type tableCatalog struct {
-	tables map[string]*TableDef
+	tables    map[string]*TableDef
+	views     map[string]*ViewDef
+	sequences map[string]*SequenceDef
 	*sqmutex.SQMtx
 }
 
@@ -65,6 +68,9 @@ func CreateTable(profile *sqprofile.SQProfile, tab *TableDef) error {
 	if tDef != nil {
 		return sqerr.Newf("Invalid Name: Table %s already exists", tableName)
 	}
+	if _Catalog.views[tableName] != nil {
+		return sqerr.Newf("Invalid Name: View %s already exists", tableName)
+	}
 	_Catalog.tables[tableName] = tab
 
 	return nil
@@ -92,6 +98,9 @@ func DropTable(profile *sqprofile.SQProfile, name string) error {
 		return err
 	}
 	if tab == nil {
+		if _Catalog.views[name] != nil {
+			return sqerr.Newf("Invalid Name: %s is a view, use DROP VIEW to remove it", name)
+		}
 		return sqerr.Newf("Invalid Name: Table %s does not exist", name)
 	}
 	// Make sure that no one else is changing the table
@@ -112,9 +121,117 @@ func DropTable(profile *sqprofile.SQProfile, name string) error {
 	return nil
 }
 
+// RenameTable - changes the name of a table. The sequences owned by the table are moved to the new name
+//		protected by a mutex to be concurrency safe
+func RenameTable(profile *sqprofile.SQProfile, name, newName string) error {
+	name = strings.ToLower(name)
+	newName = strings.ToLower(newName)
+	// Err if name begins with _ (UnderScore is reserved for system tables)
+	if isUnderScore(name) {
+		return sqerr.Newf("Invalid Name: %s - Unable to rename system tables", name)
+	}
+	if isUnderScore(newName) {
+		return sqerr.Newf("Invalid Name: %s - Only system tables may begin with _", newName)
+	}
+
+	err := _Catalog.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer _Catalog.Unlock(profile)
+
+	tab, err := _Catalog.FindTableDef(profile, name)
+	if err != nil {
+		return err
+	}
+	if tab == nil {
+		return sqerr.Newf("Invalid Name: Table %s does not exist", name)
+	}
+	newTab, err := _Catalog.FindTableDef(profile, newName)
+	if err != nil {
+		return err
+	}
+	if newTab != nil {
+		return sqerr.Newf("Invalid Name: Table %s already exists", newName)
+	}
+	if _Catalog.views[newName] != nil {
+		return sqerr.Newf("Invalid Name: View %s already exists", newName)
+	}
+
+	// Make sure that no one else is using the table
+	err = tab.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer tab.Unlock(profile)
+
+	// The files of the old name are removed at the next checkpoint
+	_Catalog.tables[name] = nil
+	_Catalog.tables[newName] = tab
+	tab.tableName = newName
+	tab.setCols(tab.tableCols)
+
+	// All of the rows are written to the data file of the new name at the next checkpoint
+	for _, rw := range tab.rowm {
+		row := rw.(*RowDef)
+		row.SetStorage(profile, -1, -1, 0)
+		row.isModified = true
+	}
+	tab.nextOffset = 0
+
+	for _, seq := range _Catalog.sequences {
+		if seq.owner == name {
+			seq.owner = newName
+		}
+	}
+	return nil
+}
+
+// TruncateTable - removes all rows from a table. The data file of the table is emptied at the next checkpoint
+//		protected by a mutex to be concurrency safe
+func TruncateTable(profile *sqprofile.SQProfile, name string) error {
+	name = strings.ToLower(name)
+	// Err if name begins with _
+	if isUnderScore(name) {
+		return sqerr.Newf("Invalid Name: %s - Unable to truncate system tables", name)
+	}
+
+	err := _Catalog.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer _Catalog.Unlock(profile)
+
+	// Err if table does not exist
+	tab, err := _Catalog.FindTableDef(profile, name)
+	if err != nil {
+		return err
+	}
+	if tab == nil {
+		if _Catalog.views[name] != nil {
+			return sqerr.Newf("Invalid Name: %s is a view and can not be truncated", name)
+		}
+		return sqerr.Newf("Invalid Name: Table %s does not exist", name)
+	}
+
+	// Wait until no one else is using the table
+	err = tab.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer tab.Unlock(profile)
+
+	tab.rowm = make(map[sqptr.SQPtr]RowInterface)
+	tab.rowCnt = 0
+	tab.nextOffset = 0
+	tab.isTruncated = true
+
+	return nil
+}
+
 // newTableCatalog - Initialize a new TableCatalog
 func newTableCatalog() *tableCatalog {
-	return &tableCatalog{tables: make(map[string]*TableDef), SQMtx: sqmutex.NewSQMtx("TableCatalog: ")}
+	return &tableCatalog{tables: make(map[string]*TableDef), views: make(map[string]*ViewDef), sequences: make(map[string]*SequenceDef), SQMtx: sqmutex.NewSQMtx("TableCatalog: ")}
 }
 
 // CatalogTables returns a sorted list of tablenames
@@ -1,10 +1,13 @@
 package sqtables
 
 import (
+	"strings"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/wilphi/sqsrv/sqerr"
 	"github.com/wilphi/sqsrv/sqprofile"
 	"github.com/wilphi/sqsrv/sqtables/column"
+	"github.com/wilphi/sqsrv/sqtypes"
 	"github.com/wilphi/sqsrv/tokens"
 )
 
@@ -18,7 +21,7 @@ type Constraint struct {
 }
 */
 
-// Constraint is an interface for table constraints (Primary Key, Unique, Foreign Key, Index)
+// Constraint is an interface for table constraints (Primary Key, Unique, Foreign Key, Index, Check)
 type Constraint interface {
 	Type() tokens.TokenID
 	Validate(profile *sqprofile.SQProfile, tab *TableDef) error
@@ -238,6 +241,90 @@ func NewIndex(name string, cols []string) Constraint {
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////
 
+// ParseExprType is a function that converts the SQL text of an expression into an Expr
+type ParseExprType func(sql string) (Expr, error)
+
+// ParseExprHook is the variable used to store a function of type ParseExprType. It is set by the cmd
+//   package so that CHECK constraints can be recreated from their SQL text
+var ParseExprHook ParseExprType
+
+// Check structure holds the information for a CHECK constraint. Every row in the table must have
+//   a true or null result for Expr. Text is the SQL text of the expression
+type Check struct {
+	Name string
+	Expr Expr
+	Text string
+}
+
+// Type returns the type of Constraint
+func (c Check) Type() tokens.TokenID {
+	return tokens.Check
+}
+
+// String returns string representation of the constraint
+func (c Check) String() string {
+	return "CONSTRAINT " + c.Name + " CHECK (" + c.Text + ")"
+}
+
+// Ordering returns an int used to sort a list of constraints
+func (c Check) Ordering() int {
+	return 4
+}
+
+//Validate makes sure that the constraint is valid for the table
+func (c Check) Validate(profile *sqprofile.SQProfile, tab *TableDef) error {
+	// verify tab does not have another constraint with the same name
+	cnt := 0
+	for _, con := range tab.constraints {
+		if chk, ok := con.(*Check); ok && chk.Name == c.Name {
+			cnt++
+			if cnt > 1 {
+				return sqerr.NewSyntaxf("The table %s cannot have more than one constraint named %s", tab.tableName, c.Name)
+			}
+		}
+	}
+	if c.Expr.IsAggregate() {
+		return sqerr.NewSyntaxf("CHECK constraint %s can not use aggregate functions", c.Name)
+	}
+
+	return c.Expr.ValidateCols(profile, NewTableListFromTableDef(profile, tab))
+}
+
+// CheckRow makes sure that the row satisfies the constraint
+func (c Check) CheckRow(profile *sqprofile.SQProfile, tab *TableDef, row RowInterface) error {
+	val, err := c.Expr.Evaluate(profile, EvalFull, row)
+	if err != nil {
+		return err
+	}
+	if val.IsNull() {
+		return nil
+	}
+	b, ok := val.(sqtypes.SQBool)
+	if !ok {
+		return sqerr.Newf("CHECK constraint %s must be a boolean expression", c.Name)
+	}
+	if !b.Bool() {
+		return sqerr.Newf("Row violates CHECK constraint %s on table %s", c.Name, tab.tableName)
+	}
+	return nil
+}
+
+//NewCheck create a new table constraint
+func NewCheck(name string, exp Expr, text string) Constraint {
+	return &Check{Name: strings.ToLower(name), Expr: exp, Text: text}
+}
+
+// ParseCheck creates a CHECK constraint from the SQL text of its expression
+func ParseCheck(name, text string) (Constraint, error) {
+	exp, err := parseExpr(text)
+	if err != nil {
+		return nil, err
+	}
+	return NewCheck(name, exp, text), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////
+
 // Constraints is a list of constraints
 type Constraints []Constraint
 
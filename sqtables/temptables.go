@@ -0,0 +1,162 @@
+package sqtables
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqptr"
+	"github.com/wilphi/sqsrv/sqtables/column"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// tempTables holds the temporary tables (e.g. Common Table Expressions) that are visible to each profile.
+//   Each name has a stack of tables so that an inner definition can hide an outer one
+var tempTables = struct {
+	sync.Mutex
+	profiles map[int64]map[string][]*TableDef
+}{profiles: make(map[int64]map[string][]*TableDef)}
+
+// CreateTempTableDef creates a table that is not part of the catalog using the rows of a dataset.
+//   If colNames is nil then the column names of the dataset are used.
+func CreateTempTableDef(profile *sqprofile.SQProfile, name string, colNames []string, data *DataSet) (*TableDef, error) {
+	cols, err := ColsFromDataSet(name, colNames, data)
+	if err != nil {
+		return nil, err
+	}
+
+	tab := CreateTableDef(name, cols)
+	tab.isTemp = true
+	err = tab.SetTempRows(profile, data.Vals)
+	if err != nil {
+		return nil, err
+	}
+	return tab, nil
+}
+
+// ColsFromDataSet returns the column definitions of a table that can hold the rows of a dataset.
+//   The type of each column is the type of the values in the dataset, or the type of the expression
+//   if all of the values are null. If colNames is nil then the column names of the dataset are used.
+func ColsFromDataSet(name string, colNames []string, data *DataSet) ([]column.Def, error) {
+	if colNames == nil {
+		colNames = data.GetColNames()
+		// Table names are not part of the column name
+		for i, colName := range colNames {
+			if idx := strings.LastIndex(colName, "."); idx >= 0 {
+				colNames[i] = colName[idx+1:]
+			}
+		}
+	}
+	if len(colNames) != data.NumCols() {
+		return nil, sqerr.Newf("%s has %d columns available but %d columns specified", name, data.NumCols(), len(colNames))
+	}
+
+	cols := make([]column.Def, len(colNames))
+	dupCheck := make(map[string]bool)
+	for i, colName := range colNames {
+		colName = strings.ToLower(colName)
+		if dupCheck[colName] {
+			return nil, sqerr.Newf("Column %q is defined more than once in %s", colName, name)
+		}
+		dupCheck[colName] = true
+
+		colType := data.colType(i)
+		if colType == tokens.Null {
+			colType = data.eList.exprlist[i].ColRef().ColType
+		}
+		cols[i] = column.NewDef(colName, colType, false)
+	}
+	return cols, nil
+}
+
+// SetTempRows replaces all of the rows in a temporary table
+func (t *TableDef) SetTempRows(profile *sqprofile.SQProfile, vals sqtypes.ValueMatrix) error {
+	if !t.isTemp {
+		return sqerr.NewInternalf("Table %s is not a temporary table", t.tableName)
+	}
+	err := t.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer t.Unlock(profile)
+
+	t.rowm = make(map[sqptr.SQPtr]RowInterface, len(vals))
+	for i, val := range vals {
+		if len(val) != len(t.tableCols) {
+			return sqerr.NewInternalf("Row has %d values but table %s has %d columns", len(val), t.tableName, len(t.tableCols))
+		}
+		ptr := sqptr.SQPtr(i + 1)
+		t.rowm[ptr] = &RowDef{
+			RowPtr: ptr,
+			Data:   val,
+			Table:  t,
+			ColNum: len(t.tableCols),
+			offset: -1,
+			alloc:  -1,
+		}
+	}
+	t.rowCnt = len(vals)
+	*t.nextRowID = uint64(len(vals))
+	return nil
+}
+
+// IsTemp returns true if the table is a temporary table that is not part of the catalog
+func (t *TableDef) IsTemp() bool {
+	return t.isTemp
+}
+
+// AddTempTable makes a temporary table visible to the profile. It will hide any table in the catalog
+//   or previously added temporary table with the same name until it is removed.
+func AddTempTable(profile *sqprofile.SQProfile, tab *TableDef) error {
+	if !tab.isTemp {
+		return sqerr.NewInternalf("Table %s is not a temporary table", tab.tableName)
+	}
+	tempTables.Lock()
+	defer tempTables.Unlock()
+
+	id := profile.GetID()
+	tabs, ok := tempTables.profiles[id]
+	if !ok {
+		tabs = make(map[string][]*TableDef)
+		tempTables.profiles[id] = tabs
+	}
+	tabs[tab.tableName] = append(tabs[tab.tableName], tab)
+	return nil
+}
+
+// RemoveTempTable removes the most recently added temporary table with the given name from the profile
+func RemoveTempTable(profile *sqprofile.SQProfile, name string) {
+	tempTables.Lock()
+	defer tempTables.Unlock()
+
+	name = strings.ToLower(name)
+	id := profile.GetID()
+	tabs := tempTables.profiles[id]
+	stack := tabs[name]
+	if len(stack) == 0 {
+		return
+	}
+	if len(stack) == 1 {
+		delete(tabs, name)
+		if len(tabs) == 0 {
+			delete(tempTables.profiles, id)
+		}
+		return
+	}
+	tabs[name] = stack[:len(stack)-1]
+}
+
+// GetTempTable returns the temporary table visible to the profile with the given name. If there is
+//   no temporary table then nil is returned
+func GetTempTable(profile *sqprofile.SQProfile, name string) *TableDef {
+	tempTables.Lock()
+	defer tempTables.Unlock()
+
+	stack := tempTables.profiles[profile.GetID()][strings.ToLower(name)]
+	if len(stack) == 0 {
+		return nil
+	}
+	return stack[len(stack)-1]
+}
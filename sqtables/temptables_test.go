@@ -0,0 +1,185 @@
+package sqtables_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtables/column"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+type CreateTempData struct {
+	TestName string
+	Name     string
+	ColNames []string
+	Vals     sqtypes.RawVals
+	ExpCols  []string
+	ExpErr   string
+}
+
+func testCreateTempFunc(data *sqtables.DataSet, d CreateTempData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		profile := sqprofile.CreateSQProfile()
+		data.Vals = d.Vals.ValueMatrix()
+		tab, err := sqtables.CreateTempTableDef(profile, d.Name, d.ColNames, data)
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+		if !tab.IsTemp() {
+			t.Error("Table is not a temporary table")
+			return
+		}
+		cols := tab.GetCols(profile).GetColNames()
+		if !reflect.DeepEqual(cols, d.ExpCols) {
+			t.Errorf("Actual cols %v do not match expected %v", cols, d.ExpCols)
+			return
+		}
+		cnt, err := tab.RowCount(profile)
+		if err != nil {
+			t.Errorf("Unexpected Error in test: %s", err.Error())
+			return
+		}
+		if cnt != len(d.Vals) {
+			t.Errorf("Actual RowCount (%d) does not match expected (%d)", cnt, len(d.Vals))
+		}
+	}
+}
+
+func TestCreateTempTableDef(t *testing.T) {
+	defer sqtest.PanicTestRecovery(t, "")
+
+	profile := sqprofile.CreateSQProfile()
+	tab := sqtables.CreateTableDef("temptest",
+		[]column.Def{
+			column.NewDef("col1", tokens.Int, false),
+			column.NewDef("col2", tokens.String, false),
+		},
+	)
+	err := sqtables.CreateTable(profile, tab)
+	if err != nil {
+		t.Error("Error creating table: ", err)
+		return
+	}
+	tables := sqtables.NewTableListFromTableDef(profile, tab)
+	eList := sqtables.ColsToExpr(column.NewListNames([]string{"col1", "col2"}))
+	data, err := sqtables.NewDataSet(profile, tables, eList)
+	if err != nil {
+		t.Error("Error creating dataset: ", err)
+		return
+	}
+
+	testData := []CreateTempData{
+		{
+			TestName: "Dataset column names",
+			Name:     "tmp",
+			Vals:     sqtypes.RawVals{{1, "a"}, {2, "b"}},
+			ExpCols:  []string{"col1", "col2"},
+		},
+		{
+			TestName: "Specified column names",
+			Name:     "tmp",
+			ColNames: []string{"A", "b"},
+			Vals:     sqtypes.RawVals{{1, "a"}},
+			ExpCols:  []string{"a", "b"},
+		},
+		{
+			TestName: "No rows",
+			Name:     "tmp",
+			Vals:     sqtypes.RawVals{},
+			ExpCols:  []string{"col1", "col2"},
+		},
+		{
+			TestName: "Too few column names",
+			Name:     "tmp",
+			ColNames: []string{"a"},
+			ExpErr:   "Error: tmp has 2 columns available but 1 columns specified",
+		},
+		{
+			TestName: "Duplicate column names",
+			Name:     "tmp",
+			ColNames: []string{"a", "A"},
+			ExpErr:   "Error: Column \"a\" is defined more than once in tmp",
+		},
+	}
+
+	for i, row := range testData {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testCreateTempFunc(data, row))
+	}
+}
+
+func TestTempTableScope(t *testing.T) {
+	defer sqtest.PanicTestRecovery(t, "")
+
+	profile := sqprofile.CreateSQProfile()
+	other := sqprofile.CreateSQProfile()
+	tab := sqtables.CreateTableDef("scopetest", []column.Def{column.NewDef("col1", tokens.Int, false)})
+	err := sqtables.CreateTable(profile, tab)
+	if err != nil {
+		t.Error("Error creating table: ", err)
+		return
+	}
+
+	err = sqtables.AddTempTable(profile, tab)
+	if err == nil || err.Error() != "Internal Error: Table scopetest is not a temporary table" {
+		t.Errorf("Unexpected result adding a catalog table as a temp table: %v", err)
+		return
+	}
+
+	tables := sqtables.NewTableListFromTableDef(profile, tab)
+	data, err := sqtables.NewDataSet(profile, tables, sqtables.ColsToExpr(column.NewListNames([]string{"col1"})))
+	if err != nil {
+		t.Error("Error creating dataset: ", err)
+		return
+	}
+	outer, err := sqtables.CreateTempTableDef(profile, "scopetest", nil, data)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	inner, err := sqtables.CreateTempTableDef(profile, "scopetest", nil, data)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if sqtables.GetTempTable(profile, "scopetest") != nil {
+		t.Error("Temp table found before it was added")
+		return
+	}
+	if err = sqtables.AddTempTable(profile, outer); err != nil {
+		t.Error(err)
+		return
+	}
+	if err = sqtables.AddTempTable(profile, inner); err != nil {
+		t.Error(err)
+		return
+	}
+	if sqtables.GetTempTable(profile, "ScopeTest") != inner {
+		t.Error("Inner temp table should hide the outer one")
+		return
+	}
+	if sqtables.GetTempTable(other, "scopetest") != nil {
+		t.Error("Temp table should not be visible to other profiles")
+		return
+	}
+	sqtables.RemoveTempTable(profile, "scopetest")
+	if sqtables.GetTempTable(profile, "scopetest") != outer {
+		t.Error("Outer temp table should be visible after the inner is removed")
+		return
+	}
+	sqtables.RemoveTempTable(profile, "scopetest")
+	if sqtables.GetTempTable(profile, "scopetest") != nil {
+		t.Error("Temp table found after it was removed")
+		return
+	}
+	// Removing a table that does not exist is ignored
+	sqtables.RemoveTempTable(profile, "scopetest")
+}
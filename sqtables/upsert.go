@@ -0,0 +1,282 @@
+package sqtables
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqptr"
+	"github.com/wilphi/sqsrv/sqtables/column"
+	"github.com/wilphi/sqsrv/sqtables/moniker"
+	"github.com/wilphi/sqsrv/sqtypes"
+)
+
+// ExcludedName is the name used in the SET and WHERE expressions of ON CONFLICT DO UPDATE to refer
+//   to the values of the row that could not be inserted
+const ExcludedName = "excluded"
+
+// OnConflict holds the ON CONFLICT clause of an insert. Cols is the list of columns of the PRIMARY KEY
+//   or UNIQUE constraint used to detect a conflict. If Cols is empty all of the PRIMARY KEY and UNIQUE
+//   constraints of the table are used. If SetCols is empty a conflicting row is skipped (DO NOTHING),
+//   otherwise the existing row has the SetCols set to the values of SetExprs (DO UPDATE). WhereExpr
+//   limits the existing rows that are updated. An updated row must not have the same values as another
+//   row for any of the PRIMARY KEY or UNIQUE constraints in uniqueKeys
+type OnConflict struct {
+	Cols       []string
+	SetCols    []string
+	SetExprs   ExprList
+	WhereExpr  Expr
+	keys       [][]int
+	uniqueKeys [][]int
+}
+
+// IsUpdate returns true if the conflicting rows are updated
+func (c *OnConflict) IsUpdate() bool {
+	return len(c.SetCols) > 0
+}
+
+// Validate finds the constraints used to detect conflicts and makes sure that the SET and WHERE
+//   expressions are valid for the table. The expressions may use the table and the excluded row
+func (c *OnConflict) Validate(profile *sqprofile.SQProfile, tab *TableDef) error {
+	var err error
+
+	c.keys, err = tab.conflictKeys(profile, c.Cols)
+	if err != nil {
+		return err
+	}
+	if !c.IsUpdate() {
+		return nil
+	}
+	c.uniqueKeys, err = tab.conflictKeys(profile, nil)
+	if err != nil {
+		return err
+	}
+
+	// The excluded row has the same columns as the table
+	var cols []column.Def
+	for _, col := range tab.tableCols {
+		cols = append(cols, col.Clone())
+	}
+	tables := NewTableListFromTableDef(profile, tab, CreateTableDef(ExcludedName, cols))
+
+	err = c.SetExprs.ValidateCols(profile, tables)
+	if err != nil {
+		return err
+	}
+	if c.WhereExpr != nil {
+		err = c.WhereExpr.ValidateCols(profile, tables)
+	}
+	return err
+}
+
+// conflictKeys returns the column indexes of the PRIMARY KEY and UNIQUE constraints that are used to
+//   detect a conflict. If cols is given it must match the columns of one of the constraints
+func (t *TableDef) conflictKeys(profile *sqprofile.SQProfile, cols []string) ([][]int, error) {
+	var keys [][]int
+
+	target := sortedNames(cols)
+	for _, con := range t.constraints {
+		var names []string
+		switch c := con.(type) {
+		case *PrimaryKey:
+			names = c.Cols.Names()
+		case *Unique:
+			names = c.Cols.Names()
+		default:
+			continue
+		}
+		if len(cols) > 0 && strings.Join(sortedNames(names), ",") != strings.Join(target, ",") {
+			continue
+		}
+		key := make([]int, len(names))
+		for i, name := range names {
+			cd := t.FindColDef(profile, name)
+			if cd == nil {
+				return nil, sqerr.NewInternalf("Column %s of constraint not found in table %s", name, t.tableName)
+			}
+			key[i] = cd.Idx
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		if len(cols) > 0 {
+			return nil, sqerr.Newf("There is no PRIMARY KEY or UNIQUE constraint on table %s that matches the ON CONFLICT columns", t.tableName)
+		}
+		return nil, sqerr.Newf("ON CONFLICT requires a PRIMARY KEY or UNIQUE constraint on table %s", t.tableName)
+	}
+	return keys, nil
+}
+
+// sortedNames returns a sorted lower case copy of the list of names
+func sortedNames(names []string) []string {
+	sorted := make([]string, len(names))
+	for i, name := range names {
+		sorted[i] = strings.ToLower(name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// UpsertRows adds the rows of data to the table. A row that has the same key values as an existing
+//   row or a row added earlier by the same call is a conflict that is handled by the OnConflict clause.
+//   The rows are added and updated as a single change in the transaction. When done data only has the
+//   rows that were added. The number of rows added and the number of rows updated are returned
+func (t *TableDef) UpsertRows(trans Transaction, data *DataSet, con *OnConflict) (int, int, error) {
+	profile := trans.Profile()
+
+	// Create all of the rows before locking and adding them to the table
+	newRows := make([]*RowDef, data.Len())
+	for cnt, val := range data.Vals {
+		rowID := atomic.AddUint64(t.nextRowID, 1)
+		row, err := CreateRow(profile, sqptr.SQPtr(rowID), t, data.GetColNames(), val)
+		if err != nil {
+			trans.RollbackIfAuto()
+			return -1, 0, err
+		}
+		newRows[cnt] = row
+	}
+
+	err := trans.AddLock(t)
+	if err != nil {
+		trans.RollbackIfAuto()
+		return -1, 0, err
+	}
+	ptrs, err := t.GetRowPtrs(profile, nil, true)
+	if err != nil {
+		trans.RollbackIfAuto()
+		return -1, 0, err
+	}
+	rows := make([]*RowDef, len(ptrs))
+	for i, ptr := range ptrs {
+		rows[i] = t.rowm[ptr].(*RowDef)
+	}
+
+	var addedVals [][]sqtypes.Value
+	var addedPtrs sqptr.SQPtrs
+	added := make(map[sqptr.SQPtr]bool)
+	updated := make(map[sqptr.SQPtr]bool)
+	nUpdated := 0
+	for i, newRow := range newRows {
+		idx := con.findConflict(profile, rows, newRow)
+		if idx < 0 {
+			err = t.checkRow(profile, newRow)
+			if err != nil {
+				trans.RollbackIfAuto()
+				return -1, 0, err
+			}
+			trans.AddRow(t, newRow)
+			rows = append(rows, newRow)
+			added[newRow.RowPtr] = true
+			addedVals = append(addedVals, data.Vals[i])
+			addedPtrs = append(addedPtrs, newRow.RowPtr)
+			continue
+		}
+		if !con.IsUpdate() {
+			continue
+		}
+		existing := rows[idx]
+		if added[existing.RowPtr] || updated[existing.RowPtr] {
+			trans.RollbackIfAuto()
+			return -1, 0, sqerr.New("ON CONFLICT DO UPDATE can not change the same row more than once")
+		}
+		row, err := con.updateRow(trans, t, rows, idx, newRow)
+		if err != nil {
+			trans.RollbackIfAuto()
+			return -1, 0, err
+		}
+		if row != nil {
+			// Later rows must be checked against the updated values
+			rows[idx] = row
+			updated[existing.RowPtr] = true
+			nUpdated++
+		}
+	}
+
+	data.Vals = addedVals
+	data.Ptrs = addedPtrs
+	t.rowCnt += len(addedPtrs)
+	return len(addedPtrs), nUpdated, trans.CommitIfAuto()
+}
+
+// findConflict returns the index of the row that has the same key values as the new row. If there is
+//   no conflict -1 is returned
+func (c *OnConflict) findConflict(profile *sqprofile.SQProfile, rows []*RowDef, newRow *RowDef) int {
+	for _, key := range c.keys {
+		for i, row := range rows {
+			if keyMatch(profile, key, row, newRow) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// keyMatch returns true if both rows have the same values for the key columns. Rows with a NULL in
+//   a key never match
+func keyMatch(profile *sqprofile.SQProfile, key []int, rowA, rowB *RowDef) bool {
+	for _, idx := range key {
+		a, _ := rowA.IdxVal(profile, idx)
+		b, _ := rowB.IdxVal(profile, idx)
+		if a == nil || b == nil || a.IsNull() || b.IsNull() || !a.Equal(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkKeys makes sure that the updated row does not have the same PRIMARY KEY or UNIQUE values as
+//   any of the other rows. rows[idx] is the row before it was updated
+func (c *OnConflict) checkKeys(profile *sqprofile.SQProfile, t *TableDef, rows []*RowDef, idx int, row *RowDef) error {
+	for _, key := range c.uniqueKeys {
+		for i, other := range rows {
+			if i != idx && keyMatch(profile, key, other, row) {
+				names := make([]string, len(key))
+				for j, colIdx := range key {
+					names[j] = t.tableCols[colIdx].ColName
+				}
+				return sqerr.Newf("ON CONFLICT DO UPDATE would create a duplicate value for (%s) in table %s", strings.Join(names, ", "), t.tableName)
+			}
+		}
+	}
+	return nil
+}
+
+// updateRow applies the DO UPDATE clause to rows[idx]. The values of the new row are available as the
+//   excluded row. The updated row is returned, or nil if the row does not satisfy the WHERE expression
+func (c *OnConflict) updateRow(trans Transaction, t *TableDef, rows []*RowDef, idx int, newRow *RowDef) (*RowDef, error) {
+	profile := trans.Profile()
+	existing := rows[idx]
+	excluded := &JoinRow{Ptr: newRow.RowPtr, Vals: newRow.GetVals(profile), TableName: moniker.New(ExcludedName, "")}
+
+	if c.WhereExpr != nil {
+		val, err := c.WhereExpr.Evaluate(profile, EvalFull, existing, excluded)
+		if err != nil {
+			return nil, err
+		}
+		b, ok := val.(sqtypes.SQBool)
+		if !ok || !b.Bool() {
+			return nil, nil
+		}
+	}
+
+	row := existing.Clone()
+	vals, err := c.SetExprs.Evaluate(profile, EvalFull, row, excluded)
+	if err != nil {
+		return nil, err
+	}
+	err = row.UpdateRow(profile, c.SetCols, vals)
+	if err != nil {
+		return nil, err
+	}
+	err = t.checkRow(profile, row)
+	if err != nil {
+		return nil, err
+	}
+	err = c.checkKeys(profile, t, rows, idx, row)
+	if err != nil {
+		return nil, err
+	}
+	return row, trans.UpdateRow(t, row)
+}
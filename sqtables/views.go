@@ -0,0 +1,138 @@
+package sqtables
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqprofile"
+)
+
+// ViewDef is a stored SELECT statement that can be used in a FROM clause like a table
+type ViewDef struct {
+	viewName string
+	cols     []string
+	query    string
+}
+
+// CreateViewDef creates a view definition. cols is the optional list of column names
+//   for the view and query is the text of the SELECT statement
+func CreateViewDef(name string, cols []string, query string) *ViewDef {
+	return &ViewDef{viewName: strings.ToLower(name), cols: cols, query: query}
+}
+
+// GetName returns the name of the view
+func (v *ViewDef) GetName() string {
+	return v.viewName
+}
+
+// GetCols returns the list of column names for the view. If nil, the column names
+//   of the SELECT statement are used
+func (v *ViewDef) GetCols() []string {
+	return v.cols
+}
+
+// GetQuery returns the text of the SELECT statement for the view
+func (v *ViewDef) GetQuery() string {
+	return v.query
+}
+
+// String returns the view as a CREATE VIEW statement
+func (v *ViewDef) String() string {
+	cols := ""
+	if v.cols != nil {
+		cols = " (" + strings.Join(v.cols, ", ") + ")"
+	}
+	return fmt.Sprintf("CREATE VIEW %s%s AS %s", v.viewName, cols, v.query)
+}
+
+// CreateView adds a view to the catalog
+//		protected by a mutex to be concurrency safe
+func CreateView(profile *sqprofile.SQProfile, view *ViewDef) error {
+	viewName := view.viewName
+	// Err if name begins with _ (UnderScore is reserved for system tables)
+	if isUnderScore(viewName) {
+		return sqerr.Newf("Invalid Name: %s - Only system tables may begin with _", viewName)
+	}
+
+	if viewName == "" {
+		return sqerr.New("Invalid Name: View names can not be blank")
+	}
+
+	err := _Catalog.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer _Catalog.Unlock(profile)
+
+	// Err if there is already a table or view with the same name
+	tDef, err := _Catalog.FindTableDef(profile, viewName)
+	if err != nil {
+		return err
+	}
+	if tDef != nil {
+		return sqerr.Newf("Invalid Name: Table %s already exists", viewName)
+	}
+	if _Catalog.views[viewName] != nil {
+		return sqerr.Newf("Invalid Name: View %s already exists", viewName)
+	}
+	_Catalog.views[viewName] = view
+
+	return nil
+}
+
+// DropView removes a view from the catalog
+//		protected by a mutex to be concurrency safe
+func DropView(profile *sqprofile.SQProfile, name string) error {
+	name = strings.ToLower(name)
+
+	err := _Catalog.Lock(profile)
+	if err != nil {
+		return err
+	}
+	defer _Catalog.Unlock(profile)
+
+	if _Catalog.views[name] == nil {
+		tab, err := _Catalog.FindTableDef(profile, name)
+		if err != nil {
+			return err
+		}
+		if tab != nil {
+			return sqerr.Newf("Invalid Name: %s is a table, use DROP TABLE to remove it", name)
+		}
+		return sqerr.Newf("Invalid Name: View %s does not exist", name)
+	}
+	delete(_Catalog.views, name)
+
+	return nil
+}
+
+// GetView returns the view definition for the given name. If there is no view
+//   with the name then nil is returned
+func GetView(profile *sqprofile.SQProfile, name string) (*ViewDef, error) {
+	err := _Catalog.RLock(profile)
+	if err != nil {
+		return nil, err
+	}
+	defer _Catalog.RUnlock(profile)
+
+	return _Catalog.views[strings.ToLower(name)], nil
+}
+
+// CatalogViews returns a sorted list of view names
+func CatalogViews(profile *sqprofile.SQProfile) ([]string, error) {
+	err := _Catalog.RLock(profile)
+	if err != nil {
+		return nil, err
+	}
+	defer _Catalog.RUnlock(profile)
+
+	var vNames []string
+	for name := range _Catalog.views {
+		vNames = append(vNames, name)
+	}
+	sort.Strings(vNames)
+
+	return vNames, nil
+}
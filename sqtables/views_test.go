@@ -0,0 +1,147 @@
+package sqtables_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables"
+	"github.com/wilphi/sqsrv/sqtables/column"
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+type ViewData struct {
+	TestName string
+	ViewName string
+	Cols     []string
+	Query    string
+	Drop     bool
+	ExpErr   string
+	ExpViews []string
+}
+
+func testViewFunc(profile *sqprofile.SQProfile, d ViewData) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		var err error
+		if d.Drop {
+			err = sqtables.DropView(profile, d.ViewName)
+		} else {
+			err = sqtables.CreateView(profile, sqtables.CreateViewDef(d.ViewName, d.Cols, d.Query))
+		}
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+
+		v, err := sqtables.GetView(profile, d.ViewName)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if d.Drop {
+			if v != nil {
+				t.Errorf("View %s has not been dropped", d.ViewName)
+			}
+		} else {
+			if v == nil {
+				t.Errorf("View %s was not created", d.ViewName)
+				return
+			}
+			if !reflect.DeepEqual(v.GetCols(), d.Cols) || v.GetQuery() != d.Query {
+				t.Errorf("View %s does not match the definition: %s", d.ViewName, v.String())
+				return
+			}
+		}
+
+		views, err := sqtables.CatalogViews(profile)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(views, d.ExpViews) {
+			t.Errorf("Actual views %v do not match expected %v", views, d.ExpViews)
+		}
+	}
+}
+
+func TestViews(t *testing.T) {
+	profile := sqprofile.CreateSQProfile()
+
+	tab := sqtables.CreateTableDef("viewtesttable", []column.Def{column.NewDef("col1", tokens.Int, false)})
+	err := sqtables.CreateTable(profile, tab)
+	if err != nil {
+		t.Error("Error creating table: ", err)
+		return
+	}
+
+	data := []ViewData{
+		{
+			TestName: "Create View",
+			ViewName: "viewtest1",
+			Query:    "SELECT col1 FROM viewtesttable",
+			ExpViews: []string{"viewtest1"},
+		},
+		{
+			TestName: "Create View with cols",
+			ViewName: "viewtest2",
+			Cols:     []string{"a"},
+			Query:    "SELECT col1 FROM viewtesttable",
+			ExpViews: []string{"viewtest1", "viewtest2"},
+		},
+		{
+			TestName: "Underscore",
+			ViewName: "_viewtest",
+			Query:    "SELECT col1 FROM viewtesttable",
+			ExpErr:   "Error: Invalid Name: _viewtest - Only system tables may begin with _",
+		},
+		{
+			TestName: "Blank name",
+			ViewName: "",
+			Query:    "SELECT col1 FROM viewtesttable",
+			ExpErr:   "Error: Invalid Name: View names can not be blank",
+		},
+		{
+			TestName: "Duplicate View",
+			ViewName: "ViewTest1",
+			Query:    "SELECT col1 FROM viewtesttable",
+			ExpErr:   "Error: Invalid Name: View viewtest1 already exists",
+		},
+		{
+			TestName: "Name of a Table",
+			ViewName: "viewtesttable",
+			Query:    "SELECT col1 FROM viewtesttable",
+			ExpErr:   "Error: Invalid Name: Table viewtesttable already exists",
+		},
+		{
+			TestName: "Drop View",
+			ViewName: "viewtest1",
+			Drop:     true,
+			ExpViews: []string{"viewtest2"},
+		},
+		{
+			TestName: "Drop View does not exist",
+			ViewName: "viewtest1",
+			Drop:     true,
+			ExpErr:   "Error: Invalid Name: View viewtest1 does not exist",
+		},
+		{
+			TestName: "Drop View on a Table",
+			ViewName: "viewtesttable",
+			Drop:     true,
+			ExpErr:   "Error: Invalid Name: viewtesttable is a table, use DROP TABLE to remove it",
+		},
+		{
+			TestName: "Drop View different case",
+			ViewName: "VIEWTEST2",
+			Drop:     true,
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testViewFunc(profile, row))
+	}
+}
@@ -0,0 +1,544 @@
+package sqtables
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/wilphi/sqsrv/sqbin"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/sqprofile"
+	"github.com/wilphi/sqsrv/sqtables/column"
+	"github.com/wilphi/sqsrv/sqtables/moniker"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// BoundType is the type of a window frame bound. The types are in order from the start of the partition
+//   to the end of the partition
+type BoundType int
+
+// Window frame bound types
+const (
+	UnboundedPreceding BoundType = iota
+	OffsetPreceding
+	CurrentRow
+	OffsetFollowing
+	UnboundedFollowing
+)
+
+// FrameBound is the start or end of a window frame
+type FrameBound struct {
+	Type   BoundType
+	Offset int
+}
+
+// WindowFrame defines the rows of a partition that are used to calculate a window function for a row.
+//   If IsRows is false then the frame is a RANGE frame and CURRENT ROW includes all of the peers of the row
+type WindowFrame struct {
+	IsRows     bool
+	Start, End FrameBound
+}
+
+// OrderExpr is an expression in the ORDER BY of a window or an aggregate function
+type OrderExpr struct {
+	Exp      Expr
+	SortType tokens.TokenID
+}
+
+// buildOrderBy writes ORDER BY and the list of order expressions
+func buildOrderBy(b *strings.Builder, orderBy []OrderExpr) {
+	b.WriteString("ORDER BY ")
+	for i, item := range orderBy {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		item.Exp.Build(b)
+		if item.SortType == tokens.Desc {
+			b.WriteString(" DESC")
+		}
+	}
+}
+
+// Window is the definition of the window in an OVER clause
+type Window struct {
+	PartitionBy []Expr
+	OrderBy     []OrderExpr
+	Frame       *WindowFrame
+}
+
+// WindowExpr is a function that is calculated over a window of rows related to the current row.
+//   The values are calculated for all rows of the query before the expression is evaluated
+type WindowExpr struct {
+	Cmd   tokens.TokenID
+	args  []Expr
+	over  *Window
+	alias string
+	val   sqtypes.Value
+}
+
+// Left - WindowExpr does not have child expressions
+func (e *WindowExpr) Left() Expr {
+	return nil
+}
+
+// Right - WindowExpr does not have child expressions
+func (e *WindowExpr) Right() Expr {
+	return nil
+}
+
+// SetLeft -
+func (e *WindowExpr) SetLeft(ex Expr) {
+	log.Panic("Invalid to SetLeft on a WindowExpr")
+}
+
+// SetRight -
+func (e *WindowExpr) SetRight(ex Expr) {
+	log.Panic("Invalid to SetRight on a WindowExpr")
+}
+
+// String - string representation of Expression. Will traverse to child conditions to form full string
+func (e *WindowExpr) String() string {
+	var b strings.Builder
+
+	e.Build(&b)
+	return b.String()
+}
+
+// Build - uses a Builder to create a string representation of the Expression
+func (e *WindowExpr) Build(b *strings.Builder) {
+	b.WriteString(tokens.IDName(e.Cmd))
+	b.WriteString("(")
+	for i, arg := range e.args {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		arg.Build(b)
+	}
+	b.WriteString(") OVER (")
+	sep := ""
+	if len(e.over.PartitionBy) > 0 {
+		b.WriteString("PARTITION BY ")
+		for i, exp := range e.over.PartitionBy {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			exp.Build(b)
+		}
+		sep = " "
+	}
+	if len(e.over.OrderBy) > 0 {
+		b.WriteString(sep)
+		buildOrderBy(b, e.over.OrderBy)
+		sep = " "
+	}
+	if e.over.Frame != nil {
+		b.WriteString(sep)
+		e.over.Frame.build(b)
+	}
+	b.WriteString(")")
+
+	if e.alias != "" {
+		b.WriteString(" ")
+		b.WriteString(e.alias)
+	}
+}
+
+// build creates a string representation of the frame
+func (f *WindowFrame) build(b *strings.Builder) {
+	if f.IsRows {
+		b.WriteString("ROWS BETWEEN ")
+	} else {
+		b.WriteString("RANGE BETWEEN ")
+	}
+	b.WriteString(f.Start.String())
+	b.WriteString(" AND ")
+	b.WriteString(f.End.String())
+}
+
+// String returns a string representation of the frame bound
+func (fb FrameBound) String() string {
+	switch fb.Type {
+	case UnboundedPreceding:
+		return "UNBOUNDED PRECEDING"
+	case OffsetPreceding:
+		return strconv.Itoa(fb.Offset) + " PRECEDING"
+	case CurrentRow:
+		return "CURRENT ROW"
+	case OffsetFollowing:
+		return strconv.Itoa(fb.Offset) + " FOLLOWING"
+	}
+	return "UNBOUNDED FOLLOWING"
+}
+
+// Name returns the name of the expression
+func (e *WindowExpr) Name() string {
+	if e.alias != "" {
+		return e.alias
+	}
+	return e.String()
+}
+
+// ColRef returns a column definition for the expression
+func (e *WindowExpr) ColRef() column.Ref {
+	var colType tokens.TokenID
+	switch e.Cmd {
+	case tokens.RowNumber, tokens.Rank, tokens.DenseRank, tokens.Count:
+		colType = tokens.Int
+	case tokens.Avg, tokens.StddevPop, tokens.StddevSamp, tokens.Stddev, tokens.VarPop, tokens.VarSamp,
+		tokens.Variance, tokens.Median:
+		colType = tokens.Float
+	default:
+		colType = e.args[0].ColRef().ColType
+	}
+	return column.Ref{ColName: e.Name(), ColType: colType}
+}
+
+// ColRefs returns a list of all actual columns in the expression
+func (e *WindowExpr) ColRefs(names ...*moniker.Moniker) []column.Ref {
+	var cols []column.Ref
+	for _, exp := range e.subExprs() {
+		cols = append(cols, exp.ColRefs(names...)...)
+	}
+	return cols
+}
+
+// subExprs returns all of the expressions used by the window function in the order
+//   partition by, order by then args
+func (e *WindowExpr) subExprs() []Expr {
+	exprs := append([]Expr{}, e.over.PartitionBy...)
+	for _, item := range e.over.OrderBy {
+		exprs = append(exprs, item.Exp)
+	}
+	return append(exprs, e.args...)
+}
+
+// Evaluate returns the value that was calculated for the current row of the query
+func (e *WindowExpr) Evaluate(profile *sqprofile.SQProfile, partial bool, rows ...RowInterface) (sqtypes.Value, error) {
+	if e.val == nil {
+		return nil, sqerr.NewInternalf("Window function %s has not been calculated", tokens.IDName(e.Cmd))
+	}
+	return e.val, nil
+}
+
+// Reduce will colapse the expressions of the window function to their simplest form
+func (e *WindowExpr) Reduce() (Expr, error) {
+	var err error
+	for i := range e.args {
+		if e.args[i], err = e.args[i].Reduce(); err != nil {
+			return nil, err
+		}
+	}
+	for i := range e.over.PartitionBy {
+		if e.over.PartitionBy[i], err = e.over.PartitionBy[i].Reduce(); err != nil {
+			return nil, err
+		}
+	}
+	for i := range e.over.OrderBy {
+		if e.over.OrderBy[i].Exp, err = e.over.OrderBy[i].Exp.Reduce(); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// ValidateCols make sure that the cols in the expression match the tabledef
+func (e *WindowExpr) ValidateCols(profile *sqprofile.SQProfile, tables TableList) error {
+	for _, exp := range e.subExprs() {
+		if err := exp.ValidateCols(profile, tables); err != nil {
+			return err
+		}
+		if exp.IsAggregate() {
+			return sqerr.NewSyntax("Window functions can not be used with GROUP BY or aggregate functions")
+		}
+		if len(findWindowFuncs(exp, nil)) > 0 {
+			return sqerr.NewSyntaxf("Window functions can not be nested in %s", tokens.IDName(e.Cmd))
+		}
+	}
+	return nil
+}
+
+// NewWindowExpr creates a new WindowExpr object
+func NewWindowExpr(cmd tokens.TokenID, args []Expr, over *Window) Expr {
+	if over == nil {
+		over = &Window{}
+	}
+	return &WindowExpr{Cmd: cmd, args: args, over: over}
+}
+
+// Encode returns a binary encoded version of the expression
+func (e *WindowExpr) Encode() *sqbin.Codec {
+	panic("WindowExpr Encode not implemented")
+}
+
+// Decode gets a binary encoded version of the expression
+func (e *WindowExpr) Decode(*sqbin.Codec) {
+	panic("WindowExpr Decode not implemented")
+}
+
+//SetAlias sets an alternative name for the expression
+func (e *WindowExpr) SetAlias(alias string) {
+	e.alias = alias
+}
+
+// IsAggregate is false. Window aggregates return a value for each row instead of each group
+func (e *WindowExpr) IsAggregate() bool {
+	return false
+}
+
+// findWindowFuncs adds the window functions in the expression to wlist
+func findWindowFuncs(e Expr, wlist []*WindowExpr) []*WindowExpr {
+	if e == nil {
+		return wlist
+	}
+	if wexpr, ok := e.(*WindowExpr); ok {
+		return append(wlist, wexpr)
+	}
+	wlist = findWindowFuncs(e.Left(), wlist)
+	return findWindowFuncs(e.Right(), wlist)
+}
+
+// calcWindows calculates the value of each window function for every row, then evaluates the
+//   expression list for each row
+func calcWindows(profile *sqprofile.SQProfile, eList *ExprList, wlist []*WindowExpr, tuples [][]RowInterface) (sqtypes.ValueMatrix, error) {
+	var err error
+
+	results := make([][]sqtypes.Value, len(wlist))
+	for i, w := range wlist {
+		results[i], err = w.calc(profile, tuples)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	vals := make(sqtypes.ValueMatrix, len(tuples))
+	for i, rows := range tuples {
+		for j, w := range wlist {
+			w.val = results[j][i]
+		}
+		vals[i], err = eList.Evaluate(profile, EvalPartial, rows...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, w := range wlist {
+		w.val = nil
+	}
+	return vals, nil
+}
+
+// calc returns the value of the window function for each of the rows
+func (e *WindowExpr) calc(profile *sqprofile.SQProfile, tuples [][]RowInterface) ([]sqtypes.Value, error) {
+	var err error
+
+	// Each row of the dataset has the partition values, order values, args and the original row number.
+	//  The rows are sorted using the sort order of the dataset
+	nPart, nOrder := len(e.over.PartitionBy), len(e.over.OrderBy)
+	argIdx := nPart + nOrder
+	exprs := e.subExprs()
+	d := &DataSet{Vals: make(sqtypes.ValueMatrix, len(tuples))}
+	for i, rows := range tuples {
+		d.Vals[i] = make([]sqtypes.Value, len(exprs)+1)
+		for x, exp := range exprs {
+			d.Vals[i][x], err = exp.Evaluate(profile, EvalPartial, rows...)
+			if err != nil {
+				return nil, err
+			}
+		}
+		d.Vals[i][len(exprs)] = sqtypes.NewSQInt(i)
+	}
+	for x := 0; x < nPart; x++ {
+		d.order = append(d.order, OrderItem{SortType: tokens.Asc, idx: x})
+	}
+	for x, item := range e.over.OrderBy {
+		d.order = append(d.order, OrderItem{SortType: item.SortType, idx: nPart + x})
+	}
+	d.order = append(d.order, OrderItem{SortType: tokens.Asc, idx: len(exprs)})
+	d.validOrder = true
+	sort.Sort(d)
+
+	results := make([]sqtypes.Value, len(d.Vals))
+	for start := 0; start < len(d.Vals); {
+		end := start + 1
+		for end < len(d.Vals) && equalVals(d.Vals[start][:nPart], d.Vals[end][:nPart]) {
+			end++
+		}
+		err = e.calcPartition(d.Vals[start:end], nPart, argIdx, results)
+		if err != nil {
+			return nil, err
+		}
+		start = end
+	}
+	return results, nil
+}
+
+// calcPartition calculates the window function for the sorted rows of a partition
+func (e *WindowExpr) calcPartition(part sqtypes.ValueMatrix, orderIdx, argIdx int, results []sqtypes.Value) error {
+	var err error
+	var agg Aggregator
+	var val sqtypes.Value
+
+	// Rows are peers if they have the same values in the ORDER BY
+	peerStart := make([]int, len(part))
+	peerEnd := make([]int, len(part))
+	for k := range part {
+		if k > 0 && equalVals(part[k][orderIdx:argIdx], part[k-1][orderIdx:argIdx]) {
+			peerStart[k] = peerStart[k-1]
+		} else {
+			peerStart[k] = k
+		}
+	}
+	for k := len(part) - 1; k >= 0; k-- {
+		if k < len(part)-1 && peerStart[k] == peerStart[k+1] {
+			peerEnd[k] = peerEnd[k+1]
+		} else {
+			peerEnd[k] = k + 1
+		}
+	}
+
+	if tokens.GetWordToken(e.Cmd).TestFlags(tokens.IsAggregate) {
+		fex := &FuncExpr{Cmd: e.Cmd}
+		if len(e.args) > 0 {
+			fex.exL = e.args[0]
+		}
+		agg, err = newAggregator(fex)
+		if err != nil {
+			return err
+		}
+	}
+	rank := 0
+	prevStart, prevEnd := -1, -1
+	for k, row := range part {
+		switch e.Cmd {
+		case tokens.RowNumber:
+			val = sqtypes.NewSQInt(k + 1)
+		case tokens.Rank:
+			val = sqtypes.NewSQInt(peerStart[k] + 1)
+		case tokens.DenseRank:
+			if peerStart[k] == k {
+				rank++
+			}
+			val = sqtypes.NewSQInt(rank)
+		case tokens.Lag, tokens.Lead:
+			val, err = e.offsetValue(part, k, argIdx)
+		default:
+			fStart, fEnd := e.frameRows(k, len(part), peerStart[k], peerEnd[k])
+			if agg == nil {
+				// FIRST_VALUE
+				val = sqtypes.NewSQNull()
+				if fStart < fEnd {
+					val = part[fStart][argIdx]
+				}
+				break
+			}
+			// Reuse the previous frame if the new one only adds rows to the end of it
+			if fStart != prevStart || fEnd < prevEnd {
+				agg.Init()
+				prevEnd = fStart
+			}
+			for x := prevEnd; x < fEnd; x++ {
+				err = agg.Accumulate(part[x][argIdx:])
+				if err != nil {
+					return err
+				}
+			}
+			prevStart, prevEnd = fStart, fEnd
+			val, err = agg.Finalize()
+		}
+		if err != nil {
+			return err
+		}
+		results[row[len(row)-1].(sqtypes.SQInt).Val] = val
+	}
+	return nil
+}
+
+// offsetValue returns the value for LAG or LEAD for row k of the partition
+func (e *WindowExpr) offsetValue(part sqtypes.ValueMatrix, k, argIdx int) (sqtypes.Value, error) {
+	offset := 1
+	if len(e.args) > 1 {
+		v, ok := part[k][argIdx+1].(sqtypes.SQInt)
+		if !ok || v.Val < 0 {
+			return nil, sqerr.Newf("The offset for %s must be a non negative integer", tokens.IDName(e.Cmd))
+		}
+		offset = v.Val
+	}
+	if e.Cmd == tokens.Lag {
+		offset = -offset
+	}
+	if k+offset < 0 || k+offset >= len(part) {
+		if len(e.args) > 2 {
+			return part[k][argIdx+2], nil
+		}
+		return sqtypes.NewSQNull(), nil
+	}
+	return part[k+offset][argIdx], nil
+}
+
+// frameRows returns the first row and one past the last row of the frame for row k of a partition.
+//   Without a frame the window is from the start of the partition to the last peer of the row
+func (e *WindowExpr) frameRows(k, partLen, peerStart, peerEnd int) (int, int) {
+	frame := e.over.Frame
+	if frame == nil {
+		return 0, peerEnd
+	}
+
+	var start, end int
+	switch frame.Start.Type {
+	case UnboundedPreceding:
+		start = 0
+	case OffsetPreceding:
+		start = k - frame.Start.Offset
+	case CurrentRow:
+		start = k
+		if !frame.IsRows {
+			start = peerStart
+		}
+	case OffsetFollowing:
+		start = k + frame.Start.Offset
+	default:
+		start = partLen
+	}
+	switch frame.End.Type {
+	case UnboundedPreceding:
+		end = 0
+	case OffsetPreceding:
+		end = k - frame.End.Offset + 1
+	case CurrentRow:
+		end = k + 1
+		if !frame.IsRows {
+			end = peerEnd
+		}
+	case OffsetFollowing:
+		end = k + frame.End.Offset + 1
+	default:
+		end = partLen
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > partLen {
+		end = partLen
+	}
+	if start > end {
+		start = end
+	}
+	return start, end
+}
+
+// equalVals returns true if each value in a equals the value in b. Nulls are treated as equal
+func equalVals(a, b []sqtypes.Value) bool {
+	for x := range a {
+		if a[x].IsNull() || b[x].IsNull() {
+			if a[x].IsNull() != b[x].IsNull() {
+				return false
+			}
+			continue
+		}
+		if !a[x].Equal(b[x]) {
+			return false
+		}
+	}
+	return true
+}
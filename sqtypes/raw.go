@@ -3,6 +3,7 @@ package sqtypes
 import (
 	"fmt"
 	"reflect"
+	"time"
 )
 
 //Raw is a type that can be converted into sq Values
@@ -13,7 +14,8 @@ type Raw interface{}
 type RawVals [][]Raw
 
 //RawValue given any type convert it into a SQ Value
-// Currently only works for int, string, bool, float32/64
+// Currently only works for int, string, bool, float32/64, time.Time (as a TIMESTAMP),
+//  []byte (as a BLOB) and Values
 //  nil values get converted to SQNull
 func RawValue(raw Raw) Value {
 	var retVal Value
@@ -32,6 +34,12 @@ func RawValue(raw Raw) Value {
 		retVal = NewSQFloat(float64(v))
 	case float64:
 		retVal = NewSQFloat(v)
+	case time.Time:
+		retVal = NewSQTimestamp(v)
+	case []byte:
+		retVal = NewSQBlob(v)
+	case Value:
+		retVal = v
 	default:
 		panic(fmt.Sprintf("%T is not a valid Raw SQ type", v))
 	}
@@ -0,0 +1,133 @@
+package sqtypes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+
+	"github.com/wilphi/sqsrv/sqbin"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// SQBlob - Binary type for SQ. Val must never be modified once the SQBlob is created
+type SQBlob struct {
+	Val []byte
+}
+
+// SQBlob Methods & Functions  =========================================
+
+// String - return string representation of type as a hex literal e.g. x'DEADBEEF'
+func (b SQBlob) String() string {
+	return "x'" + strings.ToUpper(hex.EncodeToString(b.Val)) + "'"
+}
+
+// Type - returns the type
+func (b SQBlob) Type() tokens.TokenID {
+	return tokens.Blob
+}
+
+// Len -
+func (b SQBlob) Len() int {
+	return -SQBlobWidth
+}
+
+// Equal - true if values are the same. type mismatch will return false
+func (b SQBlob) Equal(v Value) bool {
+	vb, ok := v.(SQBlob)
+	return ok && bytes.Equal(b.Val, vb.Val)
+}
+
+// LessThan -
+func (b SQBlob) LessThan(v Value) bool {
+	if v.IsNull() {
+		return true
+	}
+	vb, ok := v.(SQBlob)
+	return ok && bytes.Compare(b.Val, vb.Val) < 0
+}
+
+// GreaterThan -
+func (b SQBlob) GreaterThan(v Value) bool {
+	if v.IsNull() {
+		return false
+	}
+	vb, ok := v.(SQBlob)
+	return ok && bytes.Compare(b.Val, vb.Val) > 0
+}
+
+// IsNull - Is the value Null or not
+func (b SQBlob) IsNull() bool {
+	return false
+}
+
+// Write returns a binary representation of the value
+func (b SQBlob) Write(c *sqbin.Codec) {
+	c.Writebyte(SQBlobType)
+	c.WriteBytes(b.Val)
+}
+
+// Operation transforms two SQBlob values based on given operator. + joins the two values together
+func (b SQBlob) Operation(op tokens.TokenID, v Value) (retVal Value, err error) {
+	vb, ok := v.(SQBlob)
+	if !ok {
+		if v.IsNull() {
+			retVal = v
+			return
+		}
+		err = sqerr.Newf("Type Mismatch: %s is not a Blob", v.String())
+		return
+	}
+	if op == tokens.Plus {
+		val := make([]byte, 0, len(b.Val)+len(vb.Val))
+		retVal = NewSQBlob(append(append(val, b.Val...), vb.Val...))
+		return
+	}
+	retVal, ok = compareOp(op, bytes.Compare(b.Val, vb.Val))
+	if !ok {
+		err = sqerr.NewSyntax("Invalid Blob Operator " + tokens.IDName(op))
+	}
+	return
+}
+
+// Convert returns the value converted to the given type. A STRING is the bytes of the value as text
+func (b SQBlob) Convert(newtype tokens.TokenID) (retVal Value, err error) {
+	switch newtype {
+	case tokens.Blob:
+		retVal = b
+	case tokens.String:
+		retVal = NewSQString(string(b.Val))
+	case tokens.UUID:
+		if len(b.Val) != 16 {
+			err = sqerr.Newf("Unable to Convert %s to a UUID", b.String())
+			return
+		}
+		var u [16]byte
+		copy(u[:], b.Val)
+		retVal = NewSQUUID(u)
+	default:
+		err = sqerr.Newf("A value of type %s can not be converted to type %s", tokens.IDName(b.Type()), tokens.IDName(newtype))
+	}
+	return
+}
+
+// NewSQBlob - creates a new SQBlob value
+func NewSQBlob(b []byte) Value {
+	return SQBlob{b}
+}
+
+// Clone creates a deep copy of the Value
+func (b SQBlob) Clone() Value {
+	val := make([]byte, len(b.Val))
+	copy(val, b.Val)
+	return NewSQBlob(val)
+}
+
+// ParseHex converts the digits of a hex literal such as x'DEADBEEF' into a SQBlob
+func ParseHex(s string) (Value, error) {
+	val, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, sqerr.NewSyntaxf("x'%s' is not a valid hex value", s)
+	}
+	return NewSQBlob(val), nil
+}
@@ -0,0 +1,137 @@
+package sqtypes_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+func TestSQBlob(t *testing.T) {
+	a := sqtypes.NewSQBlob([]byte{0xDE, 0xAD, 0xBE, 0xEF})
+	b := sqtypes.NewSQBlob([]byte{0xDE, 0xAE})
+	equalA := sqtypes.NewSQBlob([]byte{0xDE, 0xAD, 0xBE, 0xEF})
+	empty := sqtypes.NewSQBlob([]byte{})
+	t.Run("Type Test", testValueType(a, tokens.Blob))
+	t.Run("To String Test", testValueString(a, "x'DEADBEEF'"))
+	t.Run("To String Test empty", testValueString(empty, "x''"))
+	t.Run("GetLen Test", testGetLen(a, -sqtypes.SQBlobWidth))
+	t.Run("Equal Test:equal", testEqual(a, equalA, true))
+	t.Run("Equal Test:not equal", testEqual(a, b, false))
+	t.Run("Equal Test:string", testEqual(sqtypes.NewSQBlob([]byte("abc")), sqtypes.NewSQString("abc"), false))
+	t.Run("LessThan Test:true", testLessThan(a, b, true))
+	t.Run("LessThan Test:false", testLessThan(b, a, false))
+	t.Run("LessThan Test:Null", testLessThan(a, sqtypes.NewSQNull(), true))
+	t.Run("GreaterThan Test:true", testGreaterThan(b, a, true))
+	t.Run("GreaterThan Test:false", testGreaterThan(empty, a, false))
+	t.Run("GreaterThan Test:Null", testGreaterThan(a, sqtypes.NewSQNull(), false))
+	t.Run("IsNull", testisNull(a, false))
+	t.Run("Write/Read", testWriteRead(a))
+	t.Run("Write/Read empty", testWriteRead(empty))
+	t.Run("Negate", testNegate(a, a, "sqtypes.SQBlob is not Negatable"))
+	t.Run("Clone Test", testClone(a))
+	data := []OperationData{
+		{name: "blob+blob", a: a, b: b, op: tokens.Plus, ExpVal: sqtypes.NewSQBlob([]byte{0xDE, 0xAD, 0xBE, 0xEF, 0xDE, 0xAE})},
+		{name: "blob+empty", a: a, b: empty, op: tokens.Plus, ExpVal: a},
+		{name: "blob=blob : true", a: a, b: equalA, op: tokens.Equal, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "blob!=blob : true", a: a, b: b, op: tokens.NotEqual, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "blob<blob : true", a: a, b: b, op: tokens.LessThan, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "blob>=blob : false", a: a, b: b, op: tokens.GreaterThanEqual, ExpVal: sqtypes.NewSQBool(false)},
+		{name: "blob-blob", a: a, b: b, op: tokens.Minus, ExpErr: "Syntax Error: Invalid Blob Operator -"},
+		{name: "Null Value", a: a, b: sqtypes.NewSQNull(), op: tokens.Plus, ExpVal: sqtypes.NewSQNull()},
+		{name: "Type Mismatch string", a: a, b: sqtypes.NewSQString("test"), op: tokens.Plus, ExpErr: "Error: Type Mismatch: test is not a Blob"},
+	}
+	for _, row := range data {
+		t.Run(row.name, testOperation(row))
+	}
+}
+
+func TestBlobConvert(t *testing.T) {
+	data := []ConvertData{
+		{TestName: "String to Blob", V: sqtypes.NewSQString("hi"), NewType: tokens.Blob, ExpVal: []byte{'h', 'i'}},
+		{TestName: "Blob to String", V: sqtypes.NewSQBlob([]byte("hello")), NewType: tokens.String, ExpVal: "hello"},
+		{TestName: "Blob to Blob", V: sqtypes.NewSQBlob([]byte{1, 2}), NewType: tokens.Blob, ExpVal: []byte{1, 2}},
+		{TestName: "Blob to Int", V: sqtypes.NewSQBlob([]byte{1, 2}), NewType: tokens.Int, ExpErr: "Error: A value of type BLOB can not be converted to type INT"},
+		{TestName: "Int to Blob", V: sqtypes.NewSQInt(12), NewType: tokens.Blob, ExpErr: "Error: A value of type INT can not be converted to type BLOB"},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testConvertFunc(row))
+
+	}
+}
+
+type StrFuncData struct {
+	TestName string
+	V        sqtypes.Value
+	Args     []sqtypes.Value
+	ExpVal   sqtypes.Raw
+	ExpErr   string
+}
+
+func TestLength(t *testing.T) {
+	data := []StrFuncData{
+		{TestName: "String", V: sqtypes.NewSQString("héllo"), ExpVal: 5},
+		{TestName: "Empty String", V: sqtypes.NewSQString(""), ExpVal: 0},
+		{TestName: "Blob", V: sqtypes.NewSQBlob([]byte("héllo")), ExpVal: 6},
+		{TestName: "Null", V: sqtypes.NewSQNull(), ExpVal: nil},
+		{TestName: "Int", V: sqtypes.NewSQInt(12), ExpErr: "Error: LENGTH can only be used with STRING or BLOB values not INT"},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testStrFunc(row, func(v sqtypes.Value, args ...sqtypes.Value) (sqtypes.Value, error) {
+				return sqtypes.Length(v)
+			}))
+	}
+}
+
+func TestSubstr(t *testing.T) {
+	str := sqtypes.NewSQString("héllo world")
+	blob := sqtypes.NewSQBlob([]byte{0, 1, 2, 3, 4, 5})
+	data := []StrFuncData{
+		{TestName: "String from start", V: str, Args: []sqtypes.Value{sqtypes.NewSQInt(7)}, ExpVal: "world"},
+		{TestName: "String with length", V: str, Args: []sqtypes.Value{sqtypes.NewSQInt(2), sqtypes.NewSQInt(4)}, ExpVal: "éllo"},
+		{TestName: "String start before beginning", V: str, Args: []sqtypes.Value{sqtypes.NewSQInt(-1), sqtypes.NewSQInt(4)}, ExpVal: "hé"},
+		{TestName: "String start past end", V: str, Args: []sqtypes.Value{sqtypes.NewSQInt(20)}, ExpVal: ""},
+		{TestName: "String length past end", V: str, Args: []sqtypes.Value{sqtypes.NewSQInt(10), sqtypes.NewSQInt(10)}, ExpVal: "ld"},
+		{TestName: "String zero length", V: str, Args: []sqtypes.Value{sqtypes.NewSQInt(3), sqtypes.NewSQInt(0)}, ExpVal: ""},
+		{TestName: "Blob", V: blob, Args: []sqtypes.Value{sqtypes.NewSQInt(2), sqtypes.NewSQInt(3)}, ExpVal: []byte{1, 2, 3}},
+		{TestName: "Blob from start", V: blob, Args: []sqtypes.Value{sqtypes.NewSQInt(5)}, ExpVal: []byte{4, 5}},
+		{TestName: "Null value", V: sqtypes.NewSQNull(), Args: []sqtypes.Value{sqtypes.NewSQInt(1)}, ExpVal: nil},
+		{TestName: "Null start", V: str, Args: []sqtypes.Value{sqtypes.NewSQNull()}, ExpVal: nil},
+		{TestName: "Null length", V: str, Args: []sqtypes.Value{sqtypes.NewSQInt(1), sqtypes.NewSQNull()}, ExpVal: nil},
+		{TestName: "Negative length", V: str, Args: []sqtypes.Value{sqtypes.NewSQInt(1), sqtypes.NewSQInt(-1)}, ExpErr: "Error: The length of SUBSTR can not be negative"},
+		{TestName: "String start", V: str, Args: []sqtypes.Value{sqtypes.NewSQString("1")}, ExpErr: "Error: The start position of SUBSTR must be an INT not STRING"},
+		{TestName: "Float length", V: str, Args: []sqtypes.Value{sqtypes.NewSQInt(1), sqtypes.NewSQFloat(1.5)}, ExpErr: "Error: The length of SUBSTR must be an INT not FLOAT"},
+		{TestName: "Int value", V: sqtypes.NewSQInt(12345), Args: []sqtypes.Value{sqtypes.NewSQInt(1)}, ExpErr: "Error: SUBSTR can only be used with STRING or BLOB values not INT"},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testStrFunc(row, func(v sqtypes.Value, args ...sqtypes.Value) (sqtypes.Value, error) {
+				return sqtypes.Substr(v, args[0], args[1:]...)
+			}))
+	}
+}
+
+func testStrFunc(d StrFuncData, fn func(sqtypes.Value, ...sqtypes.Value) (sqtypes.Value, error)) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		actVal, err := fn(d.V, d.Args...)
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+		expVal := sqtypes.RawValue(d.ExpVal)
+		if actVal.IsNull() && expVal.IsNull() {
+			return
+		}
+		if !actVal.Equal(expVal) {
+			t.Errorf("Actual value %q does not match expected value %q", actVal.String(), expVal.String())
+		}
+	}
+}
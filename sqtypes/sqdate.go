@@ -0,0 +1,130 @@
+package sqtypes
+
+import (
+	"time"
+
+	"github.com/wilphi/sqsrv/sqbin"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// SQDate - Date type for SQ. The value is midnight UTC of the date
+type SQDate struct {
+	Val time.Time
+}
+
+// SQDate Methods & Functions  =========================================
+
+// String - return string representation of type
+func (d SQDate) String() string {
+	return d.Val.Format(dateFormat)
+}
+
+// Type - returns the type
+func (d SQDate) Type() tokens.TokenID {
+	return tokens.Date
+}
+
+// Len -
+func (d SQDate) Len() int {
+	return SQDateWidth
+}
+
+// Equal - true if values are the same. type mismatch will return false
+func (d SQDate) Equal(v Value) bool {
+	vd, ok := v.(SQDate)
+	return ok && d.Val.Equal(vd.Val)
+}
+
+// LessThan -
+func (d SQDate) LessThan(v Value) bool {
+	if v.IsNull() {
+		return true
+	}
+	vd, ok := v.(SQDate)
+	return ok && d.Val.Before(vd.Val)
+}
+
+// GreaterThan -
+func (d SQDate) GreaterThan(v Value) bool {
+	if v.IsNull() {
+		return false
+	}
+	vd, ok := v.(SQDate)
+	return ok && d.Val.After(vd.Val)
+}
+
+// IsNull - Is the value Null or not
+func (d SQDate) IsNull() bool {
+	return false
+}
+
+// Write returns a binary representation of the value. The date is stored as the number of days since 1970-01-01
+func (d SQDate) Write(c *sqbin.Codec) {
+	c.Writebyte(SQDateType)
+	c.WriteInt64(d.Val.Unix() / int64(day/time.Second))
+}
+
+// Operation transforms a SQDate value based on given operator. An INT is a number of days.
+//   Subtracting two dates returns the number of days between them
+func (d SQDate) Operation(op tokens.TokenID, v Value) (retVal Value, err error) {
+
+	// if v is null then the result is null
+	if v.IsNull() {
+		retVal = v
+		return
+	}
+
+	switch val := v.(type) {
+	case SQInt:
+		switch op {
+		case tokens.Plus:
+			retVal = NewSQDate(d.Val.AddDate(0, 0, val.Val))
+		case tokens.Minus:
+			retVal = NewSQDate(d.Val.AddDate(0, 0, -val.Val))
+		default:
+			err = sqerr.NewSyntax("Invalid Date Operator " + tokens.IDName(op))
+		}
+	case SQInterval:
+		retVal, err = NewSQTimestamp(d.Val).Operation(op, v)
+	case SQDate:
+		if op == tokens.Minus {
+			retVal = NewSQInt(int(d.Val.Sub(val.Val) / day))
+			return
+		}
+		var ok bool
+		retVal, ok = compareOp(op, compareTimes(d.Val, val.Val))
+		if !ok {
+			err = sqerr.NewSyntax("Invalid Date Operator " + tokens.IDName(op))
+		}
+	default:
+		err = sqerr.New("Type Mismatch: " + v.String() + " is not a Date")
+	}
+	return
+}
+
+// Convert returns the value converted to the given type
+func (d SQDate) Convert(newtype tokens.TokenID) (retVal Value, err error) {
+	switch newtype {
+	case tokens.Date:
+		retVal = d
+	case tokens.Timestamp:
+		retVal = NewSQTimestamp(d.Val)
+	case tokens.String:
+		retVal = NewSQString(d.String())
+	default:
+		err = sqerr.Newf("A value of type %s can not be converted to type %s", tokens.IDName(d.Type()), tokens.IDName(newtype))
+	}
+	return
+}
+
+// NewSQDate - creates a new SQDate value from the date of t
+func NewSQDate(t time.Time) Value {
+	y, m, dd := t.Date()
+	return SQDate{time.Date(y, m, dd, 0, 0, 0, 0, time.UTC)}
+}
+
+// Clone creates a deep copy of the Value
+func (d SQDate) Clone() Value {
+	return NewSQDate(d.Val)
+}
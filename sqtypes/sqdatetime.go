@@ -0,0 +1,345 @@
+package sqtypes
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// Formats used to display the temporal types
+const (
+	dateFormat      = "2006-01-02"
+	timestampFormat = "2006-01-02 15:04:05.999999999"
+)
+
+const day = 24 * time.Hour
+
+// Layouts that can be converted into a TIMESTAMP. When parsing, fractional seconds
+//   are accepted after the seconds of any layout
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	dateFormat,
+}
+
+// Layouts that can be converted into a TIME
+var timeLayouts = []string{
+	"15:04:05",
+	"15:04",
+}
+
+// Fields that can be used with EXTRACT
+var extractFields = map[string]bool{
+	"YEAR": true, "QUARTER": true, "MONTH": true, "WEEK": true, "DAY": true, "DOW": true, "DOY": true,
+	"HOUR": true, "MINUTE": true, "SECOND": true, "EPOCH": true,
+}
+
+// Units that can be used with DATE_TRUNC
+var truncUnits = map[string]bool{
+	"YEAR": true, "QUARTER": true, "MONTH": true, "WEEK": true, "DAY": true, "HOUR": true, "MINUTE": true, "SECOND": true,
+}
+
+// parseTimestamp converts a string into a time in UTC
+func parseTimestamp(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timestampLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseTime converts a string into the time since midnight
+func parseTime(s string) (time.Duration, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return sinceMidnight(t), true
+		}
+	}
+	return 0, false
+}
+
+// parseInterval converts a string such as "1 year 2 months 3 days 04:05:06" into an interval. Each part
+//   is a number followed by a unit or a time of hh:mm[:ss]. Any part may be negative
+func parseInterval(s string) (SQInterval, bool) {
+	var iv SQInterval
+
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 0 {
+		return iv, false
+	}
+	for i := 0; i < len(fields); i++ {
+		if strings.Contains(fields[i], ":") {
+			d, ok := parseTime(strings.TrimPrefix(fields[i], "-"))
+			if !ok {
+				return iv, false
+			}
+			if strings.HasPrefix(fields[i], "-") {
+				d = -d
+			}
+			iv.Dur += d
+			continue
+		}
+		n, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil || i+1 >= len(fields) {
+			return iv, false
+		}
+		i++
+		unit := strings.TrimSuffix(fields[i], "s")
+		isWhole := n == math.Trunc(n)
+		switch {
+		case unit == "year" && isWhole:
+			iv.Months += int(n) * 12
+		case (unit == "month" || unit == "mon") && isWhole:
+			iv.Months += int(n)
+		case unit == "week" && isWhole:
+			iv.Days += int(n) * 7
+		case unit == "day" && isWhole:
+			iv.Days += int(n)
+		case unit == "hour":
+			iv.Dur += time.Duration(n * float64(time.Hour))
+		case unit == "minute" || unit == "min":
+			iv.Dur += time.Duration(n * float64(time.Minute))
+		case unit == "second" || unit == "sec":
+			iv.Dur += time.Duration(n * float64(time.Second))
+		default:
+			return iv, false
+		}
+	}
+	return iv, true
+}
+
+// sinceMidnight returns the time of day of t
+func sinceMidnight(t time.Time) time.Duration {
+	y, m, d := t.Date()
+	return t.Sub(time.Date(y, m, d, 0, 0, 0, 0, t.Location()))
+}
+
+// formatDuration returns the duration as [-]hh:mm:ss[.fraction]
+func formatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	str := fmt.Sprintf("%s%02d:%02d:%02d", sign, d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second)
+	if ns := d % time.Second; ns != 0 {
+		str += strings.TrimRight(fmt.Sprintf(".%09d", ns), "0")
+	}
+	return str
+}
+
+// addMonths adds months to t. If the day does not exist in the new month, the last day of the month is used
+func addMonths(t time.Time, months int) time.Time {
+	if months == 0 {
+		return t
+	}
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return time.Date(first.Year(), first.Month(), d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// addInterval adds the interval to t
+func addInterval(t time.Time, iv SQInterval) time.Time {
+	return addMonths(t, iv.Months).AddDate(0, 0, iv.Days).Add(iv.Dur)
+}
+
+// compareOp returns the result of a comparison operator given the result of comparing two values.
+//   cmp is negative, zero or positive if the first value is less than, equal to or greater than the second
+func compareOp(op tokens.TokenID, cmp int) (Value, bool) {
+	var ret bool
+	switch op {
+	case tokens.Equal:
+		ret = cmp == 0
+	case tokens.NotEqual:
+		ret = cmp != 0
+	case tokens.LessThan:
+		ret = cmp < 0
+	case tokens.GreaterThan:
+		ret = cmp > 0
+	case tokens.LessThanEqual:
+		ret = cmp <= 0
+	case tokens.GreaterThanEqual:
+		ret = cmp >= 0
+	default:
+		return nil, false
+	}
+	return NewSQBool(ret), true
+}
+
+// compareTimes returns -1, 0 or 1 if a is before, equal to or after b
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// compareInt64 returns -1, 0 or 1 if a is less than, equal to or greater than b
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// IsExtractField returns true if the field can be used with EXTRACT
+func IsExtractField(field string) bool {
+	return extractFields[strings.ToUpper(field)]
+}
+
+// IsTruncUnit returns true if the unit can be used with DATE_TRUNC
+func IsTruncUnit(unit string) bool {
+	return truncUnits[strings.ToUpper(unit)]
+}
+
+// Extract returns a field of a DATE, TIME, TIMESTAMP or INTERVAL value. SECOND and EPOCH are
+//   FLOAT values, all other fields are INT values
+func Extract(field string, v Value) (Value, error) {
+	var ret Value
+	var ok bool
+
+	if v.IsNull() {
+		return v, nil
+	}
+	field = strings.ToUpper(field)
+	switch val := v.(type) {
+	case SQDate:
+		ret, ok = extractDate(field, val.Val)
+	case SQTimestamp:
+		ret, ok = extractDate(field, val.Val)
+		if !ok {
+			ret, ok = extractDuration(field, sinceMidnight(val.Val))
+		}
+	case SQTime:
+		ret, ok = extractDuration(field, val.Val)
+	case SQInterval:
+		ret, ok = extractInterval(field, val)
+	default:
+		return nil, sqerr.Newf("EXTRACT can only be used with DATE, TIME, TIMESTAMP or INTERVAL values not %s", tokens.IDName(v.Type()))
+	}
+	if !ok {
+		return nil, sqerr.Newf("%s can not be extracted from a %s value", field, tokens.IDName(v.Type()))
+	}
+	return ret, nil
+}
+
+// extractDate returns the calendar fields of a time
+func extractDate(field string, t time.Time) (Value, bool) {
+	var ret int
+	switch field {
+	case "YEAR":
+		ret = t.Year()
+	case "QUARTER":
+		ret = (int(t.Month())-1)/3 + 1
+	case "MONTH":
+		ret = int(t.Month())
+	case "WEEK":
+		_, ret = t.ISOWeek()
+	case "DAY":
+		ret = t.Day()
+	case "DOW":
+		ret = int(t.Weekday())
+	case "DOY":
+		ret = t.YearDay()
+	case "EPOCH":
+		return NewSQFloat(float64(t.Unix()) + float64(t.Nanosecond())/1e9), true
+	default:
+		return nil, false
+	}
+	return NewSQInt(ret), true
+}
+
+// extractDuration returns the time of day fields of a duration
+func extractDuration(field string, d time.Duration) (Value, bool) {
+	switch field {
+	case "HOUR":
+		return NewSQInt(int(d / time.Hour)), true
+	case "MINUTE":
+		return NewSQInt(int(d % time.Hour / time.Minute)), true
+	case "SECOND":
+		return NewSQFloat((d % time.Minute).Seconds()), true
+	case "EPOCH":
+		return NewSQFloat(d.Seconds()), true
+	}
+	return nil, false
+}
+
+// extractInterval returns the fields of an interval
+func extractInterval(field string, iv SQInterval) (Value, bool) {
+	switch field {
+	case "YEAR":
+		return NewSQInt(iv.Months / 12), true
+	case "QUARTER":
+		return NewSQInt(iv.Months%12/3 + 1), true
+	case "MONTH":
+		return NewSQInt(iv.Months % 12), true
+	case "DAY":
+		return NewSQInt(iv.Days), true
+	case "EPOCH":
+		return NewSQFloat(float64(iv.nanos()) / 1e9), true
+	}
+	return extractDuration(field, iv.Dur)
+}
+
+// DateTrunc returns the TIMESTAMP of a DATE or TIMESTAMP value truncated to the given unit
+func DateTrunc(unit string, v Value) (Value, error) {
+	var t time.Time
+
+	if v.IsNull() {
+		return v, nil
+	}
+	switch val := v.(type) {
+	case SQDate:
+		t = val.Val
+	case SQTimestamp:
+		t = val.Val
+	default:
+		return nil, sqerr.Newf("DATE_TRUNC can only be used with DATE or TIMESTAMP values not %s", tokens.IDName(v.Type()))
+	}
+	y, m, d := t.Date()
+	switch strings.ToUpper(unit) {
+	case "YEAR":
+		t = time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+	case "QUARTER":
+		t = time.Date(y, (m-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+	case "MONTH":
+		t = time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	case "WEEK":
+		// Weeks start on Monday
+		t = time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+	case "DAY":
+		t = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	case "HOUR":
+		t = t.Truncate(time.Hour)
+	case "MINUTE":
+		t = t.Truncate(time.Minute)
+	case "SECOND":
+		t = t.Truncate(time.Second)
+	default:
+		return nil, sqerr.Newf("%q is not a valid unit for DATE_TRUNC", unit)
+	}
+	return NewSQTimestamp(t), nil
+}
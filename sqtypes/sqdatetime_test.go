@@ -0,0 +1,290 @@
+package sqtypes_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/wilphi/sqsrv/sqtest"
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+func mustConvert(s string, newType tokens.TokenID) sqtypes.Value {
+	v, err := sqtypes.NewSQString(s).Convert(newType)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestSQDate(t *testing.T) {
+	a := mustConvert("2020-01-31", tokens.Date)
+	b := mustConvert("2020-03-15", tokens.Date)
+	equalA := sqtypes.NewSQDate(time.Date(2020, 1, 31, 13, 14, 15, 0, time.UTC))
+	old := mustConvert("1969-07-20", tokens.Date)
+	t.Run("Type Test", testValueType(a, tokens.Date))
+	t.Run("To String Test", testValueString(a, "2020-01-31"))
+	t.Run("GetLen Test", testGetLen(a, sqtypes.SQDateWidth))
+	t.Run("Equal Test:equal", testEqual(a, equalA, true))
+	t.Run("Equal Test:not equal", testEqual(a, b, false))
+	t.Run("LessThan Test:true", testLessThan(a, b, true))
+	t.Run("LessThan Test:false", testLessThan(b, a, false))
+	t.Run("LessThan Test:Null", testLessThan(a, sqtypes.NewSQNull(), true))
+	t.Run("GreaterThan Test:true", testGreaterThan(b, a, true))
+	t.Run("GreaterThan Test:false", testGreaterThan(a, b, false))
+	t.Run("GreaterThan Test:Null", testGreaterThan(a, sqtypes.NewSQNull(), false))
+	t.Run("IsNull", testisNull(a, false))
+	t.Run("Write/Read", testWriteRead(a))
+	t.Run("Write/Read before 1970", testWriteRead(old))
+	t.Run("Clone Test", testClone(a))
+	data := []OperationData{
+		{name: "date+int", a: a, b: sqtypes.NewSQInt(1), op: tokens.Plus, ExpVal: mustConvert("2020-02-01", tokens.Date)},
+		{name: "date-int", a: a, b: sqtypes.NewSQInt(31), op: tokens.Minus, ExpVal: mustConvert("2019-12-31", tokens.Date)},
+		{name: "date*int", a: a, b: sqtypes.NewSQInt(31), op: tokens.Asterix, ExpErr: "Syntax Error: Invalid Date Operator *"},
+		{name: "date-date", a: b, b: a, op: tokens.Minus, ExpVal: sqtypes.NewSQInt(44)},
+		{name: "date+interval month end", a: a, b: mustConvert("1 month", tokens.Interval), op: tokens.Plus, ExpVal: mustConvert("2020-02-29", tokens.Timestamp)},
+		{name: "date-interval", a: a, b: mustConvert("1 day 12:00", tokens.Interval), op: tokens.Minus, ExpVal: mustConvert("2020-01-29 12:00", tokens.Timestamp)},
+		{name: "date=date : true", a: a, b: equalA, op: tokens.Equal, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "date!=date : true", a: a, b: b, op: tokens.NotEqual, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "date<date : true", a: a, b: b, op: tokens.LessThan, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "date>date : false", a: a, b: b, op: tokens.GreaterThan, ExpVal: sqtypes.NewSQBool(false)},
+		{name: "date<=date : true", a: a, b: equalA, op: tokens.LessThanEqual, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "date>=date : false", a: a, b: b, op: tokens.GreaterThanEqual, ExpVal: sqtypes.NewSQBool(false)},
+		{name: "date+date", a: a, b: b, op: tokens.Plus, ExpErr: "Syntax Error: Invalid Date Operator +"},
+		{name: "Null Value", a: a, b: sqtypes.NewSQNull(), op: tokens.Plus, ExpVal: sqtypes.NewSQNull()},
+		{name: "Type Mismatch string", a: a, b: sqtypes.NewSQString("test"), op: tokens.Plus, ExpErr: "Error: Type Mismatch: test is not a Date"},
+	}
+	for _, row := range data {
+		t.Run(row.name, testOperation(row))
+	}
+}
+
+func TestSQTimestamp(t *testing.T) {
+	a := mustConvert("2020-01-31 10:30:00.25", tokens.Timestamp)
+	b := mustConvert("2020-02-01T12:45:30Z", tokens.Timestamp)
+	equalA := mustConvert("2020-01-31T05:30:00.25-05:00", tokens.Timestamp)
+	t.Run("Type Test", testValueType(a, tokens.Timestamp))
+	t.Run("To String Test", testValueString(a, "2020-01-31 10:30:00.25"))
+	t.Run("To String Test no fraction", testValueString(b, "2020-02-01 12:45:30"))
+	t.Run("GetLen Test", testGetLen(a, sqtypes.SQTimestampWidth))
+	t.Run("Equal Test:equal", testEqual(a, equalA, true))
+	t.Run("Equal Test:not equal", testEqual(a, b, false))
+	t.Run("LessThan Test:true", testLessThan(a, b, true))
+	t.Run("LessThan Test:false", testLessThan(b, a, false))
+	t.Run("LessThan Test:Null", testLessThan(a, sqtypes.NewSQNull(), true))
+	t.Run("GreaterThan Test:true", testGreaterThan(b, a, true))
+	t.Run("GreaterThan Test:false", testGreaterThan(a, b, false))
+	t.Run("GreaterThan Test:Null", testGreaterThan(a, sqtypes.NewSQNull(), false))
+	t.Run("IsNull", testisNull(a, false))
+	t.Run("Write/Read", testWriteRead(a))
+	t.Run("Clone Test", testClone(a))
+	data := []OperationData{
+		{name: "timestamp+interval", a: a, b: mustConvert("1 year 1 month 2 hours", tokens.Interval), op: tokens.Plus, ExpVal: mustConvert("2021-02-28 12:30:00.25", tokens.Timestamp)},
+		{name: "timestamp-interval", a: a, b: mustConvert("1 mon 00:30", tokens.Interval), op: tokens.Minus, ExpVal: mustConvert("2019-12-31 10:00:00.25", tokens.Timestamp)},
+		{name: "timestamp*interval", a: a, b: mustConvert("1 mon", tokens.Interval), op: tokens.Asterix, ExpErr: "Syntax Error: Invalid Timestamp Operator *"},
+		{name: "timestamp-timestamp", a: b, b: a, op: tokens.Minus, ExpVal: mustConvert("1 day 02:15:29.75", tokens.Interval)},
+		{name: "timestamp=timestamp : true", a: a, b: equalA, op: tokens.Equal, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "timestamp<timestamp : true", a: a, b: b, op: tokens.LessThan, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "timestamp>=timestamp : false", a: a, b: b, op: tokens.GreaterThanEqual, ExpVal: sqtypes.NewSQBool(false)},
+		{name: "timestamp+timestamp", a: a, b: b, op: tokens.Plus, ExpErr: "Syntax Error: Invalid Timestamp Operator +"},
+		{name: "Null Value", a: a, b: sqtypes.NewSQNull(), op: tokens.Minus, ExpVal: sqtypes.NewSQNull()},
+		{name: "Type Mismatch date", a: a, b: mustConvert("2020-01-31", tokens.Date), op: tokens.Equal, ExpErr: "Error: Type Mismatch: 2020-01-31 is not a Timestamp"},
+	}
+	for _, row := range data {
+		t.Run(row.name, testOperation(row))
+	}
+}
+
+func TestSQTime(t *testing.T) {
+	a := mustConvert("10:30", tokens.Time)
+	b := mustConvert("23:15:01.5", tokens.Time)
+	equalA := sqtypes.NewSQTime(34*time.Hour + 30*time.Minute)
+	t.Run("Type Test", testValueType(a, tokens.Time))
+	t.Run("To String Test", testValueString(a, "10:30:00"))
+	t.Run("To String Test fraction", testValueString(b, "23:15:01.5"))
+	t.Run("GetLen Test", testGetLen(a, sqtypes.SQTimeWidth))
+	t.Run("Equal Test:equal", testEqual(a, equalA, true))
+	t.Run("Equal Test:not equal", testEqual(a, b, false))
+	t.Run("LessThan Test:true", testLessThan(a, b, true))
+	t.Run("LessThan Test:Null", testLessThan(a, sqtypes.NewSQNull(), true))
+	t.Run("GreaterThan Test:true", testGreaterThan(b, a, true))
+	t.Run("GreaterThan Test:Null", testGreaterThan(a, sqtypes.NewSQNull(), false))
+	t.Run("IsNull", testisNull(a, false))
+	t.Run("Write/Read", testWriteRead(b))
+	t.Run("Clone Test", testClone(a))
+	data := []OperationData{
+		{name: "time+interval", a: a, b: mustConvert("2 hours 15 minutes", tokens.Interval), op: tokens.Plus, ExpVal: mustConvert("12:45", tokens.Time)},
+		{name: "time+interval past midnight", a: b, b: mustConvert("1 hour", tokens.Interval), op: tokens.Plus, ExpVal: mustConvert("00:15:01.5", tokens.Time)},
+		{name: "time-interval before midnight", a: a, b: mustConvert("11:00", tokens.Interval), op: tokens.Minus, ExpVal: mustConvert("23:30", tokens.Time)},
+		{name: "time-time", a: a, b: b, op: tokens.Minus, ExpVal: mustConvert("-12:45:01.5", tokens.Interval)},
+		{name: "time<time : true", a: a, b: b, op: tokens.LessThan, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "time=time : true", a: a, b: equalA, op: tokens.Equal, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "time/time", a: a, b: b, op: tokens.Divide, ExpErr: "Syntax Error: Invalid Time Operator /"},
+		{name: "Type Mismatch int", a: a, b: sqtypes.NewSQInt(1), op: tokens.Plus, ExpErr: "Error: Type Mismatch: 1 is not a Time"},
+	}
+	for _, row := range data {
+		t.Run(row.name, testOperation(row))
+	}
+}
+
+func TestSQInterval(t *testing.T) {
+	a := mustConvert("1 year 2 months 3 days 04:05:06", tokens.Interval)
+	negA := sqtypes.NewSQInterval(-14, -3, -(4*time.Hour + 5*time.Minute + 6*time.Second))
+	b := mustConvert("2 weeks -1 hour", tokens.Interval)
+	month := mustConvert("1 mon", tokens.Interval)
+	t.Run("Type Test", testValueType(a, tokens.Interval))
+	t.Run("To String Test", testValueString(a, "1 year 2 mons 3 days 04:05:06"))
+	t.Run("To String Test negative", testValueString(b, "14 days -01:00:00"))
+	t.Run("To String Test zero", testValueString(sqtypes.NewSQInterval(0, 0, 0), "00:00:00"))
+	t.Run("GetLen Test", testGetLen(a, sqtypes.SQIntervalWidth))
+	t.Run("Equal Test:30 days", testEqual(month, mustConvert("30 days", tokens.Interval), true))
+	t.Run("Equal Test:not equal", testEqual(a, b, false))
+	t.Run("LessThan Test:true", testLessThan(b, a, true))
+	t.Run("LessThan Test:Null", testLessThan(a, sqtypes.NewSQNull(), true))
+	t.Run("GreaterThan Test:true", testGreaterThan(a, b, true))
+	t.Run("GreaterThan Test:Null", testGreaterThan(a, sqtypes.NewSQNull(), false))
+	t.Run("IsNull", testisNull(a, false))
+	t.Run("Write/Read", testWriteRead(a))
+	t.Run("Negate", testNegate(a, negA, ""))
+	t.Run("-Negate", testNegate(negA, a, ""))
+	t.Run("Clone Test", testClone(a))
+	data := []OperationData{
+		{name: "interval+interval", a: a, b: b, op: tokens.Plus, ExpVal: mustConvert("1 year 2 mons 17 days 03:05:06", tokens.Interval)},
+		{name: "interval-interval", a: a, b: a, op: tokens.Minus, ExpVal: sqtypes.NewSQInterval(0, 0, 0)},
+		{name: "interval*int", a: month, b: sqtypes.NewSQInt(3), op: tokens.Asterix, ExpVal: mustConvert("3 months", tokens.Interval)},
+		{name: "interval*float", a: mustConvert("1 day", tokens.Interval), b: sqtypes.NewSQFloat(1.5), op: tokens.Asterix, ExpVal: mustConvert("1 day 12:00", tokens.Interval)},
+		{name: "interval/int", a: month, b: sqtypes.NewSQInt(4), op: tokens.Divide, ExpVal: mustConvert("7 days 12:00", tokens.Interval)},
+		{name: "interval/0", a: month, b: sqtypes.NewSQInt(0), op: tokens.Divide, ExpErr: "Error: Division by zero"},
+		{name: "interval+int", a: month, b: sqtypes.NewSQInt(0), op: tokens.Plus, ExpErr: "Syntax Error: Invalid Interval Operator +"},
+		{name: "interval+date", a: month, b: mustConvert("2020-01-31", tokens.Date), op: tokens.Plus, ExpVal: mustConvert("2020-02-29", tokens.Timestamp)},
+		{name: "interval-date", a: month, b: mustConvert("2020-01-31", tokens.Date), op: tokens.Minus, ExpErr: "Syntax Error: Invalid Interval Operator -"},
+		{name: "interval>interval : true", a: a, b: b, op: tokens.GreaterThan, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "interval=interval : true", a: month, b: mustConvert("720 hours", tokens.Interval), op: tokens.Equal, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "Null Value", a: a, b: sqtypes.NewSQNull(), op: tokens.Plus, ExpVal: sqtypes.NewSQNull()},
+		{name: "Type Mismatch string", a: a, b: sqtypes.NewSQString("test"), op: tokens.Plus, ExpErr: "Error: Type Mismatch: test is not an Interval"},
+	}
+	for _, row := range data {
+		t.Run(row.name, testOperation(row))
+	}
+}
+
+func TestDateTimeConvert(t *testing.T) {
+	data := []ConvertData{
+		{TestName: "String to Date", V: sqtypes.NewSQString("2020-01-31"), NewType: tokens.Date, ExpVal: sqtypes.NewSQDate(time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC))},
+		{TestName: "String with time to Date", V: sqtypes.NewSQString("2020-01-31 23:59"), NewType: tokens.Date, ExpVal: sqtypes.NewSQDate(time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC))},
+		{TestName: "Invalid String to Date", V: sqtypes.NewSQString("2020-02-30"), NewType: tokens.Date, ExpErr: "Error: Unable to Convert \"2020-02-30\" to a DATE"},
+		{TestName: "String to Timestamp", V: sqtypes.NewSQString("2020-01-31 10:11:12"), NewType: tokens.Timestamp, ExpVal: time.Date(2020, 1, 31, 10, 11, 12, 0, time.UTC)},
+		{TestName: "String to Timestamp with zone", V: sqtypes.NewSQString("2020-01-31T10:11:12+02:00"), NewType: tokens.Timestamp, ExpVal: time.Date(2020, 1, 31, 8, 11, 12, 0, time.UTC)},
+		{TestName: "Invalid String to Timestamp", V: sqtypes.NewSQString("Jan 31"), NewType: tokens.Timestamp, ExpErr: "Error: Unable to Convert \"Jan 31\" to a TIMESTAMP"},
+		{TestName: "String to Time", V: sqtypes.NewSQString("10:11:12.5"), NewType: tokens.Time, ExpVal: sqtypes.NewSQTime(10*time.Hour + 11*time.Minute + 12500*time.Millisecond)},
+		{TestName: "Invalid String to Time", V: sqtypes.NewSQString("25:00"), NewType: tokens.Time, ExpErr: "Error: Unable to Convert \"25:00\" to a TIME"},
+		{TestName: "String to Interval", V: sqtypes.NewSQString("2 Years 1 week 1.5 hours 30 secs"), NewType: tokens.Interval, ExpVal: sqtypes.NewSQInterval(24, 7, 90*time.Minute+30*time.Second)},
+		{TestName: "Invalid String to Interval", V: sqtypes.NewSQString("1.5 days"), NewType: tokens.Interval, ExpErr: "Error: Unable to Convert \"1.5 days\" to an INTERVAL"},
+		{TestName: "Missing unit String to Interval", V: sqtypes.NewSQString("1"), NewType: tokens.Interval, ExpErr: "Error: Unable to Convert \"1\" to an INTERVAL"},
+		{TestName: "Date to Timestamp", V: mustConvert("2020-01-31", tokens.Date), NewType: tokens.Timestamp, ExpVal: time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{TestName: "Date to String", V: mustConvert("2020-01-31", tokens.Date), NewType: tokens.String, ExpVal: "2020-01-31"},
+		{TestName: "Date to Int", V: mustConvert("2020-01-31", tokens.Date), NewType: tokens.Int, ExpErr: "Error: A value of type DATE can not be converted to type INT"},
+		{TestName: "Timestamp to Date", V: mustConvert("2020-01-31 10:11", tokens.Timestamp), NewType: tokens.Date, ExpVal: mustConvert("2020-01-31", tokens.Date)},
+		{TestName: "Timestamp to Time", V: mustConvert("2020-01-31 10:11", tokens.Timestamp), NewType: tokens.Time, ExpVal: mustConvert("10:11", tokens.Time)},
+		{TestName: "Timestamp to String", V: mustConvert("2020-01-31 10:11", tokens.Timestamp), NewType: tokens.String, ExpVal: "2020-01-31 10:11:00"},
+		{TestName: "Timestamp to Float", V: mustConvert("2020-01-31 10:11", tokens.Timestamp), NewType: tokens.Float, ExpErr: "Error: A value of type TIMESTAMP can not be converted to type FLOAT"},
+		{TestName: "Time to Interval", V: mustConvert("10:11", tokens.Time), NewType: tokens.Interval, ExpVal: mustConvert("10 hours 11 minutes", tokens.Interval)},
+		{TestName: "Time to String", V: mustConvert("10:11", tokens.Time), NewType: tokens.String, ExpVal: "10:11:00"},
+		{TestName: "Time to Date", V: mustConvert("10:11", tokens.Time), NewType: tokens.Date, ExpErr: "Error: A value of type TIME can not be converted to type DATE"},
+		{TestName: "Interval to String", V: mustConvert("25 hours", tokens.Interval), NewType: tokens.String, ExpVal: "25:00:00"},
+		{TestName: "Interval to Time", V: mustConvert("25 hours", tokens.Interval), NewType: tokens.Time, ExpErr: "Error: A value of type INTERVAL can not be converted to type TIME"},
+		{TestName: "Int to Date", V: sqtypes.NewSQInt(20200131), NewType: tokens.Date, ExpErr: "Error: A value of type INT can not be converted to type DATE"},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testConvertFunc(row))
+
+	}
+}
+
+type DateFuncData struct {
+	TestName string
+	Field    string
+	V        sqtypes.Value
+	ExpVal   sqtypes.Raw
+	ExpErr   string
+}
+
+func TestExtract(t *testing.T) {
+	ts := mustConvert("2020-02-29 13:14:15.5", tokens.Timestamp)
+	data := []DateFuncData{
+		{TestName: "Year", Field: "YEAR", V: ts, ExpVal: 2020},
+		{TestName: "Quarter", Field: "quarter", V: ts, ExpVal: 1},
+		{TestName: "Month", Field: "Month", V: ts, ExpVal: 2},
+		{TestName: "Week", Field: "WEEK", V: ts, ExpVal: 9},
+		{TestName: "Day", Field: "DAY", V: ts, ExpVal: 29},
+		{TestName: "Day of Week", Field: "DOW", V: ts, ExpVal: 6},
+		{TestName: "Day of Year", Field: "DOY", V: ts, ExpVal: 60},
+		{TestName: "Hour", Field: "HOUR", V: ts, ExpVal: 13},
+		{TestName: "Minute", Field: "MINUTE", V: ts, ExpVal: 14},
+		{TestName: "Second", Field: "SECOND", V: ts, ExpVal: 15.5},
+		{TestName: "Epoch", Field: "EPOCH", V: ts, ExpVal: 1582982055.5},
+		{TestName: "Date Day", Field: "DAY", V: mustConvert("2020-02-29", tokens.Date), ExpVal: 29},
+		{TestName: "Date Epoch", Field: "EPOCH", V: mustConvert("1970-01-02", tokens.Date), ExpVal: 86400.0},
+		{TestName: "Date Hour", Field: "HOUR", V: mustConvert("2020-02-29", tokens.Date), ExpErr: "Error: HOUR can not be extracted from a DATE value"},
+		{TestName: "Time Minute", Field: "MINUTE", V: mustConvert("10:11:12", tokens.Time), ExpVal: 11},
+		{TestName: "Time Epoch", Field: "EPOCH", V: mustConvert("01:00:01", tokens.Time), ExpVal: 3601.0},
+		{TestName: "Time Year", Field: "YEAR", V: mustConvert("01:00:01", tokens.Time), ExpErr: "Error: YEAR can not be extracted from a TIME value"},
+		{TestName: "Interval Year", Field: "YEAR", V: mustConvert("14 months", tokens.Interval), ExpVal: 1},
+		{TestName: "Interval Month", Field: "MONTH", V: mustConvert("14 months", tokens.Interval), ExpVal: 2},
+		{TestName: "Interval Day", Field: "DAY", V: mustConvert("1 month 3 days", tokens.Interval), ExpVal: 3},
+		{TestName: "Interval Hour", Field: "HOUR", V: mustConvert("3 days 26 hours", tokens.Interval), ExpVal: 26},
+		{TestName: "Interval Epoch", Field: "EPOCH", V: mustConvert("1 day 1 second", tokens.Interval), ExpVal: 86401.0},
+		{TestName: "Interval DOW", Field: "DOW", V: mustConvert("1 day", tokens.Interval), ExpErr: "Error: DOW can not be extracted from a INTERVAL value"},
+		{TestName: "Null", Field: "YEAR", V: sqtypes.NewSQNull(), ExpVal: nil},
+		{TestName: "Int", Field: "YEAR", V: sqtypes.NewSQInt(2020), ExpErr: "Error: EXTRACT can only be used with DATE, TIME, TIMESTAMP or INTERVAL values not INT"},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testDateFunc(row, sqtypes.Extract))
+	}
+}
+
+func TestDateTrunc(t *testing.T) {
+	ts := mustConvert("2020-02-29 13:14:15.5", tokens.Timestamp)
+	data := []DateFuncData{
+		{TestName: "Year", Field: "year", V: ts, ExpVal: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{TestName: "Quarter", Field: "QUARTER", V: mustConvert("2020-08-15", tokens.Date), ExpVal: time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{TestName: "Month", Field: "month", V: ts, ExpVal: time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{TestName: "Week", Field: "week", V: ts, ExpVal: time.Date(2020, 2, 24, 0, 0, 0, 0, time.UTC)},
+		{TestName: "Week on Monday", Field: "week", V: mustConvert("2020-02-24 10:00", tokens.Timestamp), ExpVal: time.Date(2020, 2, 24, 0, 0, 0, 0, time.UTC)},
+		{TestName: "Day", Field: "day", V: ts, ExpVal: time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{TestName: "Hour", Field: "hour", V: ts, ExpVal: time.Date(2020, 2, 29, 13, 0, 0, 0, time.UTC)},
+		{TestName: "Minute", Field: "minute", V: ts, ExpVal: time.Date(2020, 2, 29, 13, 14, 0, 0, time.UTC)},
+		{TestName: "Second", Field: "second", V: ts, ExpVal: time.Date(2020, 2, 29, 13, 14, 15, 0, time.UTC)},
+		{TestName: "Invalid unit", Field: "century", V: ts, ExpErr: "Error: \"century\" is not a valid unit for DATE_TRUNC"},
+		{TestName: "Null", Field: "day", V: sqtypes.NewSQNull(), ExpVal: nil},
+		{TestName: "Time", Field: "hour", V: mustConvert("10:11", tokens.Time), ExpErr: "Error: DATE_TRUNC can only be used with DATE or TIMESTAMP values not TIME"},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testDateFunc(row, sqtypes.DateTrunc))
+	}
+}
+
+func testDateFunc(d DateFuncData, fn func(string, sqtypes.Value) (sqtypes.Value, error)) func(*testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		actVal, err := fn(d.Field, d.V)
+		if sqtest.CheckErr(t, err, d.ExpErr) {
+			return
+		}
+		if actVal.IsNull() && d.ExpVal == nil {
+			return
+		}
+		expVal := sqtypes.RawValue(d.ExpVal)
+		if actVal.Type() != expVal.Type() || !actVal.Equal(expVal) {
+			t.Errorf("Actual value %s %q does not match expected value %s %q", tokens.IDName(actVal.Type()), actVal.String(), tokens.IDName(expVal.Type()), expVal.String())
+		}
+	}
+}
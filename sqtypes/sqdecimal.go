@@ -0,0 +1,284 @@
+package sqtypes
+
+import (
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/wilphi/sqsrv/sqbin"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// SQDecimal - Exact fixed point number type for SQ. The value is Val / 10^Scale.
+//   Val must never be modified once the SQDecimal is created
+type SQDecimal struct {
+	Val   *big.Int
+	Scale int
+}
+
+// decimalDivScale is the minimum number of digits after the decimal point in the result of a division
+const decimalDivScale = 16
+
+// MaxDecimalPrecision is the largest precision that can be declared for a DECIMAL column
+const MaxDecimalPrecision = 1000
+
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)$`)
+
+// SQDecimal Methods & Functions  =========================================
+
+// String - return string representation of type
+func (d SQDecimal) String() string {
+	str := new(big.Int).Abs(d.Val).String()
+	if d.Scale > 0 {
+		if len(str) <= d.Scale {
+			str = strings.Repeat("0", d.Scale-len(str)+1) + str
+		}
+		str = str[:len(str)-d.Scale] + "." + str[len(str)-d.Scale:]
+	}
+	if d.Val.Sign() < 0 {
+		str = "-" + str
+	}
+	return str
+}
+
+// Type - returns the type
+func (d SQDecimal) Type() tokens.TokenID {
+	return tokens.Decimal
+}
+
+// Len -
+func (d SQDecimal) Len() int {
+	return SQDecimalWidth
+}
+
+// Equal - true if values are the same. type mismatch will return false
+func (d SQDecimal) Equal(v Value) bool {
+	vd, ok := v.(SQDecimal)
+	return ok && d.cmp(vd) == 0
+}
+
+// LessThan -
+func (d SQDecimal) LessThan(v Value) bool {
+	if v.IsNull() {
+		return true
+	}
+	vd, ok := v.(SQDecimal)
+	return ok && d.cmp(vd) < 0
+}
+
+// GreaterThan -
+func (d SQDecimal) GreaterThan(v Value) bool {
+	if v.IsNull() {
+		return false
+	}
+	vd, ok := v.(SQDecimal)
+	return ok && d.cmp(vd) > 0
+}
+
+// IsNull - Is the value Null or not
+func (d SQDecimal) IsNull() bool {
+	return false
+}
+
+// Write returns a binary representation of the value
+func (d SQDecimal) Write(c *sqbin.Codec) {
+	c.Writebyte(SQDecimalType)
+	c.WriteInt(d.Scale)
+	c.WriteString(d.Val.String())
+}
+
+// Operation transforms two SQDecimal values based on given operator. INT values are treated as a DECIMAL
+//   with a scale of 0 and a DECIMAL used with a FLOAT becomes a FLOAT
+func (d SQDecimal) Operation(op tokens.TokenID, v Value) (retVal Value, err error) {
+	var vd SQDecimal
+
+	// if v is null then the result is null
+	if v.IsNull() {
+		retVal = v
+		return
+	}
+
+	switch val := v.(type) {
+	case SQDecimal:
+		vd = val
+	case SQInt:
+		vd = SQDecimal{big.NewInt(int64(val.Val)), 0}
+	case SQFloat:
+		retVal, err = NewSQFloat(d.Float()).Operation(op, v)
+		return
+	default:
+		err = sqerr.New("Type Mismatch: " + v.String() + " is not a Decimal")
+		return
+	}
+
+	scale := maxInt(d.Scale, vd.Scale)
+	switch op {
+	case tokens.Plus:
+		retVal = NewSQDecimal(new(big.Int).Add(d.rescale(scale), vd.rescale(scale)), scale)
+	case tokens.Minus:
+		retVal = NewSQDecimal(new(big.Int).Sub(d.rescale(scale), vd.rescale(scale)), scale)
+	case tokens.Asterix:
+		retVal = NewSQDecimal(new(big.Int).Mul(d.Val, vd.Val), d.Scale+vd.Scale)
+	case tokens.Divide:
+		if vd.Val.Sign() == 0 {
+			err = sqerr.New("Division by zero")
+			return
+		}
+		divScale := maxInt(scale, decimalDivScale)
+		numer := new(big.Int).Mul(d.Val, pow10(divScale-d.Scale+vd.Scale))
+		retVal = SQDecimal{roundQuo(numer, vd.Val), divScale}.trim(scale)
+	case tokens.Modulus:
+		if vd.Val.Sign() == 0 {
+			err = sqerr.New("Division by zero")
+			return
+		}
+		retVal = NewSQDecimal(new(big.Int).Rem(d.rescale(scale), vd.rescale(scale)), scale)
+	default:
+		var ok bool
+		retVal, ok = compareOp(op, d.cmp(vd))
+		if !ok {
+			err = sqerr.NewSyntax("Invalid Decimal Operator " + tokens.IDName(op))
+		}
+	}
+	return
+}
+
+// Convert returns the value converted to the given type
+func (d SQDecimal) Convert(newtype tokens.TokenID) (retVal Value, err error) {
+	switch newtype {
+	case tokens.Int:
+		i := new(big.Int).Quo(d.Val, pow10(d.Scale))
+		if !i.IsInt64() {
+			err = sqerr.Newf("Unable to Convert %s to an INT", d.String())
+			return
+		}
+		retVal = NewSQInt(int(i.Int64()))
+	case tokens.Bool:
+		retVal = NewSQBool(d.Val.Sign() > 0)
+	case tokens.Float:
+		retVal = NewSQFloat(d.Float())
+	case tokens.Decimal:
+		retVal = d
+	case tokens.String:
+		retVal = NewSQString(d.String())
+	default:
+		err = sqerr.Newf("A value of type %s can not be converted to type %s", tokens.IDName(d.Type()), tokens.IDName(newtype))
+	}
+	return
+}
+
+// Float returns the closest float64 to the value
+func (d SQDecimal) Float() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Round returns the value rounded half away from zero to the given number of digits after the decimal point
+func (d SQDecimal) Round(scale int) SQDecimal {
+	return SQDecimal{d.rescale(scale), scale}
+}
+
+// Digits returns the total number of digits in the value
+func (d SQDecimal) Digits() int {
+	if d.Val.Sign() == 0 {
+		return 1
+	}
+	return len(new(big.Int).Abs(d.Val).String())
+}
+
+// cmp returns -1, 0 or 1 if d is less than, equal to or greater than vd
+func (d SQDecimal) cmp(vd SQDecimal) int {
+	scale := maxInt(d.Scale, vd.Scale)
+	return d.rescale(scale).Cmp(vd.rescale(scale))
+}
+
+// rescale returns the unscaled value of d with the given scale
+func (d SQDecimal) rescale(scale int) *big.Int {
+	if scale >= d.Scale {
+		return new(big.Int).Mul(d.Val, pow10(scale-d.Scale))
+	}
+	return roundQuo(d.Val, pow10(d.Scale-scale))
+}
+
+// trim removes trailing zeros after the decimal point while the scale is more than minScale
+func (d SQDecimal) trim(minScale int) SQDecimal {
+	val := d.Val
+	scale := d.Scale
+	ten := big.NewInt(10)
+	for scale > minScale {
+		q, r := new(big.Int).QuoRem(val, ten, new(big.Int))
+		if r.Sign() != 0 {
+			break
+		}
+		val = q
+		scale--
+	}
+	return SQDecimal{val, scale}
+}
+
+// roundQuo returns a / b rounded half away from zero
+func roundQuo(a, b *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+	r.Abs(r).Lsh(r, 1)
+	if r.Cmp(new(big.Int).Abs(b)) >= 0 {
+		if a.Sign() != b.Sign() {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// pow10 returns 10^n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// parseDecimal converts a string such as "-123.45" into a decimal
+func parseDecimal(s string) (SQDecimal, bool) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return SQDecimal{}, false
+	}
+	scale := 0
+	if idx := strings.Index(s, "."); idx >= 0 {
+		scale = len(s) - idx - 1
+		s = s[:idx] + s[idx+1:]
+	}
+	val, ok := new(big.Int).SetString(strings.TrimPrefix(s, "+"), 10)
+	return SQDecimal{val, scale}, ok
+}
+
+// floatToDecimal converts a float64 to the decimal with the fewest digits that converts back to the same float
+func floatToDecimal(f float64) (SQDecimal, bool) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return SQDecimal{}, false
+	}
+	return parseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// NewSQDecimal - creates a new SQDecimal value of val / 10^scale
+func NewSQDecimal(val *big.Int, scale int) Value {
+	return SQDecimal{val, scale}
+}
+
+// Negate returns minus the current value
+func (d SQDecimal) Negate() Value {
+	return NewSQDecimal(new(big.Int).Neg(d.Val), d.Scale)
+}
+
+// Clone creates a deep copy of the Value
+func (d SQDecimal) Clone() Value {
+	return NewSQDecimal(new(big.Int).Set(d.Val), d.Scale)
+}
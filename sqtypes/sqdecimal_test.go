@@ -0,0 +1,114 @@
+package sqtypes_test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+func TestSQDecimal(t *testing.T) {
+	a := mustConvert("12.34", tokens.Decimal)
+	negA := mustConvert("-12.34", tokens.Decimal)
+	b := mustConvert("0.5", tokens.Decimal)
+	equalA := sqtypes.NewSQDecimal(big.NewInt(123400), 4)
+	small := mustConvert("-0.05", tokens.Decimal)
+	t.Run("Type Test", testValueType(a, tokens.Decimal))
+	t.Run("To String Test", testValueString(a, "12.34"))
+	t.Run("To String Test keeps scale", testValueString(equalA, "12.3400"))
+	t.Run("To String Test leading zero", testValueString(small, "-0.05"))
+	t.Run("GetLen Test", testGetLen(a, sqtypes.SQDecimalWidth))
+	t.Run("Equal Test:different scale", testEqual(a, equalA, true))
+	t.Run("Equal Test:not equal", testEqual(a, b, false))
+	t.Run("Equal Test:float", testEqual(a, sqtypes.NewSQFloat(12.34), false))
+	t.Run("LessThan Test:true", testLessThan(b, a, true))
+	t.Run("LessThan Test:false", testLessThan(a, b, false))
+	t.Run("LessThan Test:Null", testLessThan(a, sqtypes.NewSQNull(), true))
+	t.Run("GreaterThan Test:true", testGreaterThan(a, b, true))
+	t.Run("GreaterThan Test:false", testGreaterThan(small, b, false))
+	t.Run("GreaterThan Test:Null", testGreaterThan(a, sqtypes.NewSQNull(), false))
+	t.Run("IsNull", testisNull(a, false))
+	t.Run("Write/Read", testWriteRead(a))
+	t.Run("Write/Read negative", testWriteRead(small))
+	t.Run("Negate", testNegate(a, negA, ""))
+	t.Run("-Negate", testNegate(negA, a, ""))
+	t.Run("Clone Test", testClone(a))
+	data := []OperationData{
+		{name: "decimal+decimal exact", a: mustConvert("0.1", tokens.Decimal), b: mustConvert("0.2", tokens.Decimal), op: tokens.Plus, ExpVal: mustConvert("0.3", tokens.Decimal)},
+		{name: "decimal-decimal", a: b, b: a, op: tokens.Minus, ExpVal: mustConvert("-11.84", tokens.Decimal)},
+		{name: "decimal*decimal", a: a, b: b, op: tokens.Asterix, ExpVal: mustConvert("6.17", tokens.Decimal)},
+		{name: "decimal/decimal", a: a, b: b, op: tokens.Divide, ExpVal: mustConvert("24.68", tokens.Decimal)},
+		{name: "decimal/decimal repeating", a: mustConvert("1", tokens.Decimal), b: mustConvert("3", tokens.Decimal), op: tokens.Divide, ExpVal: mustConvert("0.3333333333333333", tokens.Decimal)},
+		{name: "decimal/decimal round", a: mustConvert("2", tokens.Decimal), b: mustConvert("3", tokens.Decimal), op: tokens.Divide, ExpVal: mustConvert("0.6666666666666667", tokens.Decimal)},
+		{name: "decimal/0", a: a, b: mustConvert("0.00", tokens.Decimal), op: tokens.Divide, ExpErr: "Error: Division by zero"},
+		{name: "decimal%decimal", a: a, b: mustConvert("5", tokens.Decimal), op: tokens.Modulus, ExpVal: mustConvert("2.34", tokens.Decimal)},
+		{name: "decimal%0", a: a, b: sqtypes.NewSQInt(0), op: tokens.Modulus, ExpErr: "Error: Division by zero"},
+		{name: "decimal+int", a: a, b: sqtypes.NewSQInt(3), op: tokens.Plus, ExpVal: mustConvert("15.34", tokens.Decimal)},
+		{name: "int+decimal", a: sqtypes.NewSQInt(3), b: a, op: tokens.Plus, ExpVal: mustConvert("15.34", tokens.Decimal)},
+		{name: "int*decimal", a: sqtypes.NewSQInt(3), b: a, op: tokens.Asterix, ExpVal: mustConvert("37.02", tokens.Decimal)},
+		{name: "decimal*float", a: b, b: sqtypes.NewSQFloat(1.5), op: tokens.Asterix, ExpVal: sqtypes.NewSQFloat(0.75)},
+		{name: "float*decimal", a: sqtypes.NewSQFloat(1.5), b: b, op: tokens.Asterix, ExpVal: sqtypes.NewSQFloat(0.75)},
+		{name: "decimal=decimal : true", a: a, b: equalA, op: tokens.Equal, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "decimal!=decimal : true", a: a, b: b, op: tokens.NotEqual, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "decimal<decimal : false", a: a, b: b, op: tokens.LessThan, ExpVal: sqtypes.NewSQBool(false)},
+		{name: "decimal>=int : true", a: a, b: sqtypes.NewSQInt(12), op: tokens.GreaterThanEqual, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "decimal AND decimal", a: a, b: b, op: tokens.And, ExpErr: "Syntax Error: Invalid Decimal Operator AND"},
+		{name: "Null Value", a: a, b: sqtypes.NewSQNull(), op: tokens.Plus, ExpVal: sqtypes.NewSQNull()},
+		{name: "Type Mismatch string", a: a, b: sqtypes.NewSQString("test"), op: tokens.Plus, ExpErr: "Error: Type Mismatch: test is not a Decimal"},
+	}
+	for _, row := range data {
+		t.Run(row.name, testOperation(row))
+	}
+}
+
+func TestDecimalConvert(t *testing.T) {
+	data := []ConvertData{
+		{TestName: "String to Decimal", V: sqtypes.NewSQString("-123.450"), NewType: tokens.Decimal, ExpVal: sqtypes.NewSQDecimal(big.NewInt(-12345), 2)},
+		{TestName: "String no leading digit to Decimal", V: sqtypes.NewSQString(".5"), NewType: tokens.Decimal, ExpVal: sqtypes.NewSQDecimal(big.NewInt(5), 1)},
+		{TestName: "Invalid String to Decimal", V: sqtypes.NewSQString("1.2.3"), NewType: tokens.Decimal, ExpErr: "Error: Unable to Convert \"1.2.3\" to a DECIMAL"},
+		{TestName: "Int to Decimal", V: sqtypes.NewSQInt(42), NewType: tokens.Decimal, ExpVal: sqtypes.NewSQDecimal(big.NewInt(42), 0)},
+		{TestName: "Float to Decimal", V: sqtypes.NewSQFloat(0.1), NewType: tokens.Decimal, ExpVal: sqtypes.NewSQDecimal(big.NewInt(1), 1)},
+		{TestName: "Decimal to Int", V: mustConvert("-12.99", tokens.Decimal), NewType: tokens.Int, ExpVal: -12},
+		{TestName: "Decimal to Int overflow", V: mustConvert("99999999999999999999", tokens.Decimal), NewType: tokens.Int, ExpErr: "Error: Unable to Convert 99999999999999999999 to an INT"},
+		{TestName: "Decimal to Float", V: mustConvert("12.5", tokens.Decimal), NewType: tokens.Float, ExpVal: 12.5},
+		{TestName: "Decimal to Bool", V: mustConvert("0.01", tokens.Decimal), NewType: tokens.Bool, ExpVal: true},
+		{TestName: "Decimal to String", V: mustConvert("12.50", tokens.Decimal), NewType: tokens.String, ExpVal: "12.50"},
+		{TestName: "Decimal to Date", V: mustConvert("12.50", tokens.Decimal), NewType: tokens.Date, ExpErr: "Error: A value of type DECIMAL can not be converted to type DATE"},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testConvertFunc(row))
+
+	}
+}
+
+func TestDecimalRound(t *testing.T) {
+	data := []struct {
+		TestName  string
+		V         string
+		Scale     int
+		ExpString string
+		ExpDigits int
+	}{
+		{TestName: "Round half up", V: "2.345", Scale: 2, ExpString: "2.35", ExpDigits: 3},
+		{TestName: "Round half away from zero", V: "-2.345", Scale: 2, ExpString: "-2.35", ExpDigits: 3},
+		{TestName: "Round down", V: "2.344", Scale: 2, ExpString: "2.34", ExpDigits: 3},
+		{TestName: "Increase scale", V: "7", Scale: 2, ExpString: "7.00", ExpDigits: 3},
+		{TestName: "Round to zero", V: "0.004", Scale: 2, ExpString: "0.00", ExpDigits: 1},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName), func(t *testing.T) {
+			d := mustConvert(row.V, tokens.Decimal).(sqtypes.SQDecimal).Round(row.Scale)
+			if d.String() != row.ExpString {
+				t.Errorf("Round actual (%s) does not match expected (%s)", d.String(), row.ExpString)
+			}
+			if d.Digits() != row.ExpDigits {
+				t.Errorf("Digits actual (%d) does not match expected (%d)", d.Digits(), row.ExpDigits)
+			}
+		})
+	}
+}
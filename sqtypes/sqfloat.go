@@ -78,6 +78,10 @@ func (fp SQFloat) Operation(op tokens.TokenID, v Value) (retVal Value, err error
 		return
 	}
 
+	// A Decimal used with a Float is treated as a Float
+	if vd, ok := v.(SQDecimal); ok {
+		v = NewSQFloat(vd.Float())
+	}
 	vfp, ok := v.(SQFloat)
 	if !ok {
 		err = sqerr.New("Type Mismatch: " + v.String() + " is not a Float")
@@ -121,6 +125,13 @@ func (fp SQFloat) Convert(newtype tokens.TokenID) (retVal Value, err error) {
 		retVal = NewSQBool(fp.Val > 0)
 	case tokens.Float:
 		retVal = fp
+	case tokens.Decimal:
+		d, ok := floatToDecimal(fp.Val)
+		if ok {
+			retVal = d
+		} else {
+			err = sqerr.Newf("Unable to Convert %s to a DECIMAL", fp.String())
+		}
 	case tokens.String:
 		retVal = NewSQString(strconv.FormatFloat(fp.Val, 'G', -1, 64))
 	default:
@@ -1,6 +1,7 @@
 package sqtypes
 
 import (
+	"math/big"
 	"strconv"
 
 	"github.com/wilphi/sqsrv/sqbin"
@@ -79,6 +80,11 @@ func (i SQInt) Operation(op tokens.TokenID, v Value) (retVal Value, err error) {
 			retVal = v
 			return
 		}
+		// An Int used with a Decimal is treated as a Decimal
+		if _, ok := v.(SQDecimal); ok {
+			retVal, err = NewSQDecimal(big.NewInt(int64(i.Val)), 0).Operation(op, v)
+			return
+		}
 		err = sqerr.New("Type Mismatch: " + v.String() + " is not an Int")
 		return
 	}
@@ -122,6 +128,8 @@ func (i SQInt) Convert(newtype tokens.TokenID) (retVal Value, err error) {
 		retVal = NewSQBool(i.Val > 0)
 	case tokens.Float:
 		retVal = NewSQFloat(float64(i.Val))
+	case tokens.Decimal:
+		retVal = NewSQDecimal(big.NewInt(int64(i.Val)), 0)
 	case tokens.String:
 		retVal = NewSQString(strconv.Itoa(i.Val))
 	default:
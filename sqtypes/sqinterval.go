@@ -0,0 +1,191 @@
+package sqtypes
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wilphi/sqsrv/sqbin"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// SQInterval - Interval type for SQ. Months and days are kept separate from the time because
+//   their length depends on the date they are added to
+type SQInterval struct {
+	Months int
+	Days   int
+	Dur    time.Duration
+}
+
+// SQInterval Methods & Functions  =========================================
+
+// String - return string representation of type
+func (iv SQInterval) String() string {
+	var parts []string
+
+	parts = appendIntervalPart(parts, iv.Months/12, "year")
+	parts = appendIntervalPart(parts, iv.Months%12, "mon")
+	parts = appendIntervalPart(parts, iv.Days, "day")
+	if iv.Dur != 0 || len(parts) == 0 {
+		parts = append(parts, formatDuration(iv.Dur))
+	}
+	return strings.Join(parts, " ")
+}
+
+// appendIntervalPart adds a non zero part of an interval to parts
+func appendIntervalPart(parts []string, n int, unit string) []string {
+	if n == 0 {
+		return parts
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return append(parts, strconv.Itoa(n)+" "+unit)
+}
+
+// Type - returns the type
+func (iv SQInterval) Type() tokens.TokenID {
+	return tokens.Interval
+}
+
+// Len -
+func (iv SQInterval) Len() int {
+	return SQIntervalWidth
+}
+
+// nanos returns the approximate length of the interval used for comparisons. A month is 30 days
+func (iv SQInterval) nanos() int64 {
+	return (int64(iv.Months)*30+int64(iv.Days))*int64(day) + int64(iv.Dur)
+}
+
+// Equal - true if values are the same. type mismatch will return false
+func (iv SQInterval) Equal(v Value) bool {
+	viv, ok := v.(SQInterval)
+	return ok && iv.nanos() == viv.nanos()
+}
+
+// LessThan -
+func (iv SQInterval) LessThan(v Value) bool {
+	if v.IsNull() {
+		return true
+	}
+	viv, ok := v.(SQInterval)
+	return ok && iv.nanos() < viv.nanos()
+}
+
+// GreaterThan -
+func (iv SQInterval) GreaterThan(v Value) bool {
+	if v.IsNull() {
+		return false
+	}
+	viv, ok := v.(SQInterval)
+	return ok && iv.nanos() > viv.nanos()
+}
+
+// IsNull - Is the value Null or not
+func (iv SQInterval) IsNull() bool {
+	return false
+}
+
+// Write returns a binary representation of the value
+func (iv SQInterval) Write(c *sqbin.Codec) {
+	c.Writebyte(SQIntervalType)
+	c.WriteInt(iv.Months)
+	c.WriteInt(iv.Days)
+	c.WriteInt64(int64(iv.Dur))
+}
+
+// Operation transforms a SQInterval value based on given operator. Intervals can be added to each other,
+//   multiplied or divided by a number and added to a DATE, TIME or TIMESTAMP
+func (iv SQInterval) Operation(op tokens.TokenID, v Value) (retVal Value, err error) {
+
+	// if v is null then the result is null
+	if v.IsNull() {
+		retVal = v
+		return
+	}
+
+	switch val := v.(type) {
+	case SQInterval:
+		switch op {
+		case tokens.Plus:
+			retVal = NewSQInterval(iv.Months+val.Months, iv.Days+val.Days, iv.Dur+val.Dur)
+		case tokens.Minus:
+			retVal = NewSQInterval(iv.Months-val.Months, iv.Days-val.Days, iv.Dur-val.Dur)
+		default:
+			var ok bool
+			retVal, ok = compareOp(op, compareInt64(iv.nanos(), val.nanos()))
+			if !ok {
+				err = sqerr.NewSyntax("Invalid Interval Operator " + tokens.IDName(op))
+			}
+		}
+	case SQInt, SQFloat:
+		var f Value
+		f, err = v.Convert(tokens.Float)
+		if err != nil {
+			return
+		}
+		n := f.(SQFloat).Val
+		switch op {
+		case tokens.Asterix:
+			retVal = iv.scale(n)
+		case tokens.Divide:
+			if n == 0 {
+				err = sqerr.New("Division by zero")
+				return
+			}
+			retVal = iv.scale(1 / n)
+		default:
+			err = sqerr.NewSyntax("Invalid Interval Operator " + tokens.IDName(op))
+		}
+	case SQDate, SQTimestamp, SQTime:
+		if op != tokens.Plus {
+			err = sqerr.NewSyntax("Invalid Interval Operator " + tokens.IDName(op))
+			return
+		}
+		retVal, err = v.Operation(op, iv)
+	default:
+		err = sqerr.New("Type Mismatch: " + v.String() + " is not an Interval")
+	}
+	return
+}
+
+// scale multiplies the interval by n. Fractions of a month are carried into days and fractions
+//   of a day are carried into the time
+func (iv SQInterval) scale(n float64) Value {
+	m := float64(iv.Months) * n
+	months := int(m)
+	d := float64(iv.Days)*n + (m-float64(months))*30
+	days := int(d)
+	dur := time.Duration(float64(iv.Dur)*n + (d-float64(days))*float64(day))
+	return NewSQInterval(months, days, dur)
+}
+
+// Convert returns the value converted to the given type
+func (iv SQInterval) Convert(newtype tokens.TokenID) (retVal Value, err error) {
+	switch newtype {
+	case tokens.Interval:
+		retVal = iv
+	case tokens.String:
+		retVal = NewSQString(iv.String())
+	default:
+		err = sqerr.Newf("A value of type %s can not be converted to type %s", tokens.IDName(iv.Type()), tokens.IDName(newtype))
+	}
+	return
+}
+
+// NewSQInterval - creates a new SQInterval value
+func NewSQInterval(months, days int, d time.Duration) Value {
+	return SQInterval{Months: months, Days: days, Dur: d}
+}
+
+// Negate returns minus the current value
+func (iv SQInterval) Negate() Value {
+	return NewSQInterval(-iv.Months, -iv.Days, -iv.Dur)
+}
+
+// Clone creates a deep copy of the Value
+func (iv SQInterval) Clone() Value {
+	return NewSQInterval(iv.Months, iv.Days, iv.Dur)
+}
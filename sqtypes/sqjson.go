@@ -0,0 +1,311 @@
+package sqtypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/wilphi/sqsrv/sqbin"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// SQJSON - JSON document type for SQ. Val is always valid JSON in a canonical form with no whitespace
+//   and the keys of objects sorted so that equal documents have the same text
+type SQJSON struct {
+	Val string
+}
+
+var jsonPathPart = regexp.MustCompile(`^(\.([A-Za-z_][A-Za-z0-9_]*)|\.\"([^"]*)\"|\[(\d+)\])`)
+
+// SQJSON Methods & Functions  =========================================
+
+// String - return string representation of type
+func (j SQJSON) String() string {
+	return j.Val
+}
+
+// Type - returns the type
+func (j SQJSON) Type() tokens.TokenID {
+	return tokens.JSON
+}
+
+// Len -
+func (j SQJSON) Len() int {
+	return -SQJSONWidth
+}
+
+// Equal - true if values are the same. type mismatch will return false
+func (j SQJSON) Equal(v Value) bool {
+	vj, ok := v.(SQJSON)
+	return ok && j.Val == vj.Val
+}
+
+// LessThan -
+func (j SQJSON) LessThan(v Value) bool {
+	if v.IsNull() {
+		return true
+	}
+	vj, ok := v.(SQJSON)
+	return ok && j.Val < vj.Val
+}
+
+// GreaterThan -
+func (j SQJSON) GreaterThan(v Value) bool {
+	if v.IsNull() {
+		return false
+	}
+	vj, ok := v.(SQJSON)
+	return ok && j.Val > vj.Val
+}
+
+// IsNull - Is the value Null or not
+func (j SQJSON) IsNull() bool {
+	return false
+}
+
+// Write returns a binary representation of the value
+func (j SQJSON) Write(c *sqbin.Codec) {
+	c.Writebyte(SQJSONType)
+	c.WriteString(j.Val)
+}
+
+// Operation transforms a SQJSON value based on given operator. -> returns the JSON of an object key or
+//   array element and ->> returns it as a value of the matching type
+func (j SQJSON) Operation(op tokens.TokenID, v Value) (retVal Value, err error) {
+
+	// if v is null then the result is null
+	if v.IsNull() {
+		retVal = v
+		return
+	}
+
+	switch op {
+	case tokens.Arrow, tokens.DoubleArrow:
+		var elem interface{}
+		var found bool
+		switch key := v.(type) {
+		case SQString:
+			elem, found = jsonStep(j.decode(), key.Val, -1)
+		case SQInt:
+			elem, found = jsonStep(j.decode(), "", key.Val)
+		default:
+			err = sqerr.Newf("The key of %s must be a STRING or INT not %s", tokens.IDName(op), tokens.IDName(v.Type()))
+			return
+		}
+		retVal = NewSQNull()
+		if found {
+			retVal = jsonResult(elem, op == tokens.DoubleArrow)
+		}
+		return
+	}
+
+	vj, ok := v.(SQJSON)
+	if !ok {
+		err = sqerr.Newf("Type Mismatch: %s is not a JSON", v.String())
+		return
+	}
+	switch op {
+	case tokens.Equal:
+		retVal = NewSQBool(j.Val == vj.Val)
+	case tokens.NotEqual:
+		retVal = NewSQBool(j.Val != vj.Val)
+	default:
+		err = sqerr.NewSyntax("Invalid JSON Operator " + tokens.IDName(op))
+	}
+	return
+}
+
+// Convert returns the value converted to the given type
+func (j SQJSON) Convert(newtype tokens.TokenID) (retVal Value, err error) {
+	switch newtype {
+	case tokens.JSON:
+		retVal = j
+	case tokens.String:
+		retVal = NewSQString(j.Val)
+	default:
+		err = sqerr.Newf("A value of type %s can not be converted to type %s", tokens.IDName(j.Type()), tokens.IDName(newtype))
+	}
+	return
+}
+
+// decode returns the document as Go values. Numbers are decoded as json.Number
+func (j SQJSON) decode() interface{} {
+	var doc interface{}
+	dec := json.NewDecoder(strings.NewReader(j.Val))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		// Val is always valid so this should never happen
+		panic("Invalid JSON value: " + err.Error())
+	}
+	return doc
+}
+
+// Clone creates a deep copy of the Value
+func (j SQJSON) Clone() Value {
+	return SQJSON{j.Val}
+}
+
+// ParseJSON validates the string as a JSON document and returns it as a SQJSON
+func ParseJSON(s string) (Value, error) {
+	var doc interface{}
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	err := dec.Decode(&doc)
+	if err != nil || dec.More() {
+		return nil, sqerr.Newf("Unable to Convert %q to JSON", s)
+	}
+	return newSQJSON(doc), nil
+}
+
+// newSQJSON creates a SQJSON from Go values
+func newSQJSON(doc interface{}) Value {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		// All documents are built from valid values so this should never happen
+		panic("Unable to encode JSON value: " + err.Error())
+	}
+	return SQJSON{strings.TrimSuffix(b.String(), "\n")}
+}
+
+// JSONArray creates a JSON array from the values
+func JSONArray(vals []Value) Value {
+	arr := make([]interface{}, len(vals))
+	for i, v := range vals {
+		arr[i] = jsonElem(v)
+	}
+	return newSQJSON(arr)
+}
+
+// JSONObject creates a JSON object from the keys and values. If a key is repeated the last value is used
+func JSONObject(keys []string, vals []Value) Value {
+	obj := make(map[string]interface{}, len(keys))
+	for i, key := range keys {
+		obj[key] = jsonElem(vals[i])
+	}
+	return newSQJSON(obj)
+}
+
+// jsonElem converts a value into the Go value used for it in a JSON document
+func jsonElem(v Value) interface{} {
+	if v.IsNull() {
+		return nil
+	}
+	switch val := v.(type) {
+	case SQInt:
+		return val.Val
+	case SQFloat:
+		return json.Number(strconv.FormatFloat(val.Val, 'g', -1, 64))
+	case SQDecimal:
+		return json.Number(val.String())
+	case SQBool:
+		return val.Val
+	case SQJSON:
+		return json.RawMessage(val.Val)
+	}
+	return v.String()
+}
+
+// jsonResult converts an element of a JSON document into a Value. If typed is true then strings, numbers
+//   and booleans are returned as a STRING, INT, FLOAT or BOOL and a JSON null is a null. Otherwise the
+//   element is returned as JSON
+func jsonResult(elem interface{}, typed bool) Value {
+	if !typed {
+		return newSQJSON(elem)
+	}
+	switch val := elem.(type) {
+	case nil:
+		return NewSQNull()
+	case string:
+		return NewSQString(val)
+	case bool:
+		return NewSQBool(val)
+	case json.Number:
+		if i, err := strconv.Atoi(val.String()); err == nil {
+			return NewSQInt(i)
+		}
+		f, _ := val.Float64()
+		return NewSQFloat(f)
+	}
+	return newSQJSON(elem)
+}
+
+// jsonStep returns the element of an object with the given key or if idx >= 0 the element of an array
+func jsonStep(elem interface{}, key string, idx int) (interface{}, bool) {
+	if idx >= 0 {
+		arr, ok := elem.([]interface{})
+		if !ok || idx >= len(arr) {
+			return nil, false
+		}
+		return arr[idx], true
+	}
+	obj, ok := elem.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	val, ok := obj[key]
+	return val, ok
+}
+
+// IsJSONPath returns true if the path is valid for JSON_EXTRACT. A path starts with $ followed by
+//   any number of .key, ."key" or [index]
+func IsJSONPath(path string) bool {
+	_, ok := parseJSONPath(path)
+	return ok
+}
+
+// jsonPathStep is a key or array index of a JSON path
+type jsonPathStep struct {
+	key string
+	idx int
+}
+
+// parseJSONPath splits a JSON path into its steps
+func parseJSONPath(path string) ([]jsonPathStep, bool) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, false
+	}
+	var steps []jsonPathStep
+	rest := path[1:]
+	for rest != "" {
+		m := jsonPathPart.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, false
+		}
+		step := jsonPathStep{key: m[2] + m[3], idx: -1}
+		if m[4] != "" {
+			step.idx, _ = strconv.Atoi(m[4])
+		}
+		steps = append(steps, step)
+		rest = rest[len(m[0]):]
+	}
+	return steps, true
+}
+
+// JSONExtract returns the element of a JSON document at the path as a value of the matching type. If
+//   the element does not exist the result is null
+func JSONExtract(v Value, path string) (Value, error) {
+	if v.IsNull() {
+		return v, nil
+	}
+	j, ok := v.(SQJSON)
+	if !ok {
+		return nil, sqerr.Newf("JSON_EXTRACT can only be used with JSON values not %s", tokens.IDName(v.Type()))
+	}
+	steps, ok := parseJSONPath(path)
+	if !ok {
+		return nil, sqerr.Newf("%q is not a valid JSON path", path)
+	}
+	elem := j.decode()
+	for _, step := range steps {
+		elem, ok = jsonStep(elem, step.key, step.idx)
+		if !ok {
+			return NewSQNull(), nil
+		}
+	}
+	return jsonResult(elem, true), nil
+}
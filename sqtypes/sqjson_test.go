@@ -0,0 +1,114 @@
+package sqtypes_test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+func TestSQJSON(t *testing.T) {
+	a := mustConvert(`{"b": [1, 2.5, "x"], "a": {"c": true, "d": null}}`, tokens.JSON)
+	equalA := mustConvert(`{"a":{"d":null,"c":true},"b":[1,2.5,"x"]}`, tokens.JSON)
+	b := mustConvert(`[10, {"k": "v"}]`, tokens.JSON)
+	t.Run("Type Test", testValueType(a, tokens.JSON))
+	t.Run("To String Test", testValueString(a, `{"a":{"c":true,"d":null},"b":[1,2.5,"x"]}`))
+	t.Run("To String Test no html escape", testValueString(mustConvert(`"<a&b>"`, tokens.JSON), `"<a&b>"`))
+	t.Run("GetLen Test", testGetLen(a, -sqtypes.SQJSONWidth))
+	t.Run("Equal Test:equal", testEqual(a, equalA, true))
+	t.Run("Equal Test:not equal", testEqual(a, b, false))
+	t.Run("Equal Test:string", testEqual(mustConvert(`"abc"`, tokens.JSON), sqtypes.NewSQString(`"abc"`), false))
+	t.Run("LessThan Test:true", testLessThan(b, a, true))
+	t.Run("LessThan Test:Null", testLessThan(a, sqtypes.NewSQNull(), true))
+	t.Run("GreaterThan Test:true", testGreaterThan(a, b, true))
+	t.Run("GreaterThan Test:Null", testGreaterThan(a, sqtypes.NewSQNull(), false))
+	t.Run("IsNull", testisNull(a, false))
+	t.Run("Write/Read", testWriteRead(a))
+	t.Run("Negate", testNegate(a, a, "sqtypes.SQJSON is not Negatable"))
+	t.Run("Clone Test", testClone(a))
+	data := []OperationData{
+		{name: "json->key", a: a, b: sqtypes.NewSQString("a"), op: tokens.Arrow, ExpVal: mustConvert(`{"c":true,"d":null}`, tokens.JSON)},
+		{name: "json->>key object", a: a, b: sqtypes.NewSQString("a"), op: tokens.DoubleArrow, ExpVal: mustConvert(`{"c":true,"d":null}`, tokens.JSON)},
+		{name: "json->key null", a: a, b: sqtypes.NewSQString("missing"), op: tokens.Arrow, ExpVal: sqtypes.NewSQNull()},
+		{name: "json->index", a: b, b: sqtypes.NewSQInt(0), op: tokens.Arrow, ExpVal: mustConvert("10", tokens.JSON)},
+		{name: "json->>index int", a: b, b: sqtypes.NewSQInt(0), op: tokens.DoubleArrow, ExpVal: sqtypes.NewSQInt(10)},
+		{name: "json->>index out of range", a: b, b: sqtypes.NewSQInt(2), op: tokens.DoubleArrow, ExpVal: sqtypes.NewSQNull()},
+		{name: "json->>key of array", a: b, b: sqtypes.NewSQString("k"), op: tokens.DoubleArrow, ExpVal: sqtypes.NewSQNull()},
+		{name: "json->>index of object", a: a, b: sqtypes.NewSQInt(0), op: tokens.DoubleArrow, ExpVal: sqtypes.NewSQNull()},
+		{name: "json->float", a: a, b: sqtypes.NewSQFloat(1), op: tokens.Arrow, ExpErr: "Error: The key of -> must be a STRING or INT not FLOAT"},
+		{name: "json=json : true", a: a, b: equalA, op: tokens.Equal, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "json!=json : true", a: a, b: b, op: tokens.NotEqual, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "json<json", a: a, b: b, op: tokens.LessThan, ExpErr: "Syntax Error: Invalid JSON Operator <"},
+		{name: "Null Value", a: a, b: sqtypes.NewSQNull(), op: tokens.Arrow, ExpVal: sqtypes.NewSQNull()},
+		{name: "Type Mismatch string", a: a, b: sqtypes.NewSQString("test"), op: tokens.Equal, ExpErr: "Error: Type Mismatch: test is not a JSON"},
+	}
+	for _, row := range data {
+		t.Run(row.name, testOperation(row))
+	}
+}
+
+func TestJSONConvert(t *testing.T) {
+	data := []ConvertData{
+		{TestName: "String to JSON", V: sqtypes.NewSQString(` [ 1, "a" ] `), NewType: tokens.JSON, ExpVal: mustConvert(`[1,"a"]`, tokens.JSON)},
+		{TestName: "Invalid String to JSON", V: sqtypes.NewSQString(`{"a":}`), NewType: tokens.JSON, ExpErr: "Error: Unable to Convert \"{\\\"a\\\":}\" to JSON"},
+		{TestName: "Extra text to JSON", V: sqtypes.NewSQString(`{} {}`), NewType: tokens.JSON, ExpErr: "Error: Unable to Convert \"{} {}\" to JSON"},
+		{TestName: "JSON to String", V: mustConvert(`{"a": 1}`, tokens.JSON), NewType: tokens.String, ExpVal: `{"a":1}`},
+		{TestName: "JSON to Int", V: mustConvert("1", tokens.JSON), NewType: tokens.Int, ExpErr: "Error: A value of type JSON can not be converted to type INT"},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testConvertFunc(row))
+
+	}
+}
+
+func TestJSONExtract(t *testing.T) {
+	doc := mustConvert(`{"a": {"b": [1, 2.5, "three", false, null]}, "odd key": 7}`, tokens.JSON)
+	data := []StrFuncData{
+		{TestName: "Whole document", V: doc, Args: []sqtypes.Value{sqtypes.NewSQString("$")}, ExpVal: doc},
+		{TestName: "Object", V: doc, Args: []sqtypes.Value{sqtypes.NewSQString("$.a")}, ExpVal: mustConvert(`{"b":[1,2.5,"three",false,null]}`, tokens.JSON)},
+		{TestName: "Int", V: doc, Args: []sqtypes.Value{sqtypes.NewSQString("$.a.b[0]")}, ExpVal: 1},
+		{TestName: "Float", V: doc, Args: []sqtypes.Value{sqtypes.NewSQString("$.a.b[1]")}, ExpVal: 2.5},
+		{TestName: "String", V: doc, Args: []sqtypes.Value{sqtypes.NewSQString("$.a.b[2]")}, ExpVal: "three"},
+		{TestName: "Bool", V: doc, Args: []sqtypes.Value{sqtypes.NewSQString("$.a.b[3]")}, ExpVal: false},
+		{TestName: "JSON null", V: doc, Args: []sqtypes.Value{sqtypes.NewSQString("$.a.b[4]")}, ExpVal: nil},
+		{TestName: "Quoted key", V: doc, Args: []sqtypes.Value{sqtypes.NewSQString(`$."odd key"`)}, ExpVal: 7},
+		{TestName: "Missing key", V: doc, Args: []sqtypes.Value{sqtypes.NewSQString("$.a.c")}, ExpVal: nil},
+		{TestName: "Index out of range", V: doc, Args: []sqtypes.Value{sqtypes.NewSQString("$.a.b[5]")}, ExpVal: nil},
+		{TestName: "Null value", V: sqtypes.NewSQNull(), Args: []sqtypes.Value{sqtypes.NewSQString("$.a")}, ExpVal: nil},
+		{TestName: "Invalid path", V: doc, Args: []sqtypes.Value{sqtypes.NewSQString("$.a[x]")}, ExpErr: "Error: \"$.a[x]\" is not a valid JSON path"},
+		{TestName: "String value", V: sqtypes.NewSQString("{}"), Args: []sqtypes.Value{sqtypes.NewSQString("$")}, ExpErr: "Error: JSON_EXTRACT can only be used with JSON values not STRING"},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testStrFunc(row, func(v sqtypes.Value, args ...sqtypes.Value) (sqtypes.Value, error) {
+				return sqtypes.JSONExtract(v, args[0].String())
+			}))
+	}
+}
+
+func TestJSONBuild(t *testing.T) {
+	vals := []sqtypes.Value{
+		sqtypes.NewSQInt(1),
+		sqtypes.NewSQFloat(2.5),
+		sqtypes.NewSQDecimal(big.NewInt(1050), 2),
+		sqtypes.NewSQString("a\"b"),
+		sqtypes.NewSQBool(true),
+		sqtypes.NewSQNull(),
+		mustConvert(`{"x": [1]}`, tokens.JSON),
+	}
+	arr := sqtypes.JSONArray(vals)
+	expArr := `[1,2.5,10.50,"a\"b",true,null,{"x":[1]}]`
+	if arr.String() != expArr {
+		t.Errorf("JSONArray actual (%s) does not match expected (%s)", arr.String(), expArr)
+	}
+	obj := sqtypes.JSONObject([]string{"z", "a", "z"}, vals[:3])
+	expObj := `{"a":2.5,"z":10.50}`
+	if obj.String() != expObj {
+		t.Errorf("JSONObject actual (%s) does not match expected (%s)", obj.String(), expObj)
+	}
+}
@@ -129,6 +129,47 @@ func (s SQString) Convert(newtype tokens.TokenID) (retVal Value, err error) {
 		}
 	case tokens.String:
 		retVal = s
+	case tokens.Blob:
+		retVal = NewSQBlob([]byte(s.Val))
+	case tokens.JSON:
+		retVal, err = ParseJSON(s.Val)
+	case tokens.UUID:
+		retVal, err = ParseUUID(s.Val)
+	case tokens.Decimal:
+		d, ok := parseDecimal(s.Val)
+		if ok {
+			retVal = d
+		} else {
+			err = sqerr.Newf("Unable to Convert %q to a DECIMAL", s.Val)
+		}
+	case tokens.Date:
+		t, ok := parseTimestamp(s.Val)
+		if ok {
+			retVal = NewSQDate(t)
+		} else {
+			err = sqerr.Newf("Unable to Convert %q to a DATE", s.Val)
+		}
+	case tokens.Time:
+		d, ok := parseTime(s.Val)
+		if ok {
+			retVal = NewSQTime(d)
+		} else {
+			err = sqerr.Newf("Unable to Convert %q to a TIME", s.Val)
+		}
+	case tokens.Timestamp:
+		t, ok := parseTimestamp(s.Val)
+		if ok {
+			retVal = NewSQTimestamp(t)
+		} else {
+			err = sqerr.Newf("Unable to Convert %q to a TIMESTAMP", s.Val)
+		}
+	case tokens.Interval:
+		iv, ok := parseInterval(s.Val)
+		if ok {
+			retVal = iv
+		} else {
+			err = sqerr.Newf("Unable to Convert %q to an INTERVAL", s.Val)
+		}
 	default:
 		err = sqerr.Newf("A value of type %s can not be converted to type %s", tokens.IDName(s.Type()), tokens.IDName(newtype))
 	}
@@ -0,0 +1,131 @@
+package sqtypes
+
+import (
+	"time"
+
+	"github.com/wilphi/sqsrv/sqbin"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// SQTime - Time of day type for SQ. The value is the time since midnight
+type SQTime struct {
+	Val time.Duration
+}
+
+// SQTime Methods & Functions  =========================================
+
+// String - return string representation of type
+func (tm SQTime) String() string {
+	return formatDuration(tm.Val)
+}
+
+// Type - returns the type
+func (tm SQTime) Type() tokens.TokenID {
+	return tokens.Time
+}
+
+// Len -
+func (tm SQTime) Len() int {
+	return SQTimeWidth
+}
+
+// Equal - true if values are the same. type mismatch will return false
+func (tm SQTime) Equal(v Value) bool {
+	vtm, ok := v.(SQTime)
+	return ok && tm.Val == vtm.Val
+}
+
+// LessThan -
+func (tm SQTime) LessThan(v Value) bool {
+	if v.IsNull() {
+		return true
+	}
+	vtm, ok := v.(SQTime)
+	return ok && tm.Val < vtm.Val
+}
+
+// GreaterThan -
+func (tm SQTime) GreaterThan(v Value) bool {
+	if v.IsNull() {
+		return false
+	}
+	vtm, ok := v.(SQTime)
+	return ok && tm.Val > vtm.Val
+}
+
+// IsNull - Is the value Null or not
+func (tm SQTime) IsNull() bool {
+	return false
+}
+
+// Write returns a binary representation of the value
+func (tm SQTime) Write(c *sqbin.Codec) {
+	c.Writebyte(SQTimeType)
+	c.WriteInt64(int64(tm.Val))
+}
+
+// Operation transforms a SQTime value based on given operator. The time part of an interval can be
+//   added or subtracted and the result wraps around midnight. Subtracting two times returns an interval
+func (tm SQTime) Operation(op tokens.TokenID, v Value) (retVal Value, err error) {
+
+	// if v is null then the result is null
+	if v.IsNull() {
+		retVal = v
+		return
+	}
+
+	switch val := v.(type) {
+	case SQInterval:
+		switch op {
+		case tokens.Plus:
+			retVal = NewSQTime(tm.Val + val.Dur)
+		case tokens.Minus:
+			retVal = NewSQTime(tm.Val - val.Dur)
+		default:
+			err = sqerr.NewSyntax("Invalid Time Operator " + tokens.IDName(op))
+		}
+	case SQTime:
+		if op == tokens.Minus {
+			retVal = NewSQInterval(0, 0, tm.Val-val.Val)
+			return
+		}
+		var ok bool
+		retVal, ok = compareOp(op, compareInt64(int64(tm.Val), int64(val.Val)))
+		if !ok {
+			err = sqerr.NewSyntax("Invalid Time Operator " + tokens.IDName(op))
+		}
+	default:
+		err = sqerr.New("Type Mismatch: " + v.String() + " is not a Time")
+	}
+	return
+}
+
+// Convert returns the value converted to the given type
+func (tm SQTime) Convert(newtype tokens.TokenID) (retVal Value, err error) {
+	switch newtype {
+	case tokens.Time:
+		retVal = tm
+	case tokens.Interval:
+		retVal = NewSQInterval(0, 0, tm.Val)
+	case tokens.String:
+		retVal = NewSQString(tm.String())
+	default:
+		err = sqerr.Newf("A value of type %s can not be converted to type %s", tokens.IDName(tm.Type()), tokens.IDName(newtype))
+	}
+	return
+}
+
+// NewSQTime - creates a new SQTime value. The time wraps around midnight
+func NewSQTime(d time.Duration) Value {
+	d %= day
+	if d < 0 {
+		d += day
+	}
+	return SQTime{d}
+}
+
+// Clone creates a deep copy of the Value
+func (tm SQTime) Clone() Value {
+	return NewSQTime(tm.Val)
+}
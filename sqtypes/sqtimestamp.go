@@ -0,0 +1,131 @@
+package sqtypes
+
+import (
+	"time"
+
+	"github.com/wilphi/sqsrv/sqbin"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// SQTimestamp - Date and time type for SQ. All timestamps are UTC
+type SQTimestamp struct {
+	Val time.Time
+}
+
+// SQTimestamp Methods & Functions  =========================================
+
+// String - return string representation of type
+func (ts SQTimestamp) String() string {
+	return ts.Val.Format(timestampFormat)
+}
+
+// Type - returns the type
+func (ts SQTimestamp) Type() tokens.TokenID {
+	return tokens.Timestamp
+}
+
+// Len -
+func (ts SQTimestamp) Len() int {
+	return SQTimestampWidth
+}
+
+// Equal - true if values are the same. type mismatch will return false
+func (ts SQTimestamp) Equal(v Value) bool {
+	vts, ok := v.(SQTimestamp)
+	return ok && ts.Val.Equal(vts.Val)
+}
+
+// LessThan -
+func (ts SQTimestamp) LessThan(v Value) bool {
+	if v.IsNull() {
+		return true
+	}
+	vts, ok := v.(SQTimestamp)
+	return ok && ts.Val.Before(vts.Val)
+}
+
+// GreaterThan -
+func (ts SQTimestamp) GreaterThan(v Value) bool {
+	if v.IsNull() {
+		return false
+	}
+	vts, ok := v.(SQTimestamp)
+	return ok && ts.Val.After(vts.Val)
+}
+
+// IsNull - Is the value Null or not
+func (ts SQTimestamp) IsNull() bool {
+	return false
+}
+
+// Write returns a binary representation of the value
+func (ts SQTimestamp) Write(c *sqbin.Codec) {
+	c.Writebyte(SQTimestampType)
+	c.WriteInt64(ts.Val.Unix())
+	c.WriteInt(ts.Val.Nanosecond())
+}
+
+// Operation transforms a SQTimestamp value based on given operator. Intervals can be added or subtracted.
+//   Subtracting two timestamps returns the interval between them
+func (ts SQTimestamp) Operation(op tokens.TokenID, v Value) (retVal Value, err error) {
+
+	// if v is null then the result is null
+	if v.IsNull() {
+		retVal = v
+		return
+	}
+
+	switch val := v.(type) {
+	case SQInterval:
+		switch op {
+		case tokens.Plus:
+			retVal = NewSQTimestamp(addInterval(ts.Val, val))
+		case tokens.Minus:
+			retVal = NewSQTimestamp(addInterval(ts.Val, val.Negate().(SQInterval)))
+		default:
+			err = sqerr.NewSyntax("Invalid Timestamp Operator " + tokens.IDName(op))
+		}
+	case SQTimestamp:
+		if op == tokens.Minus {
+			diff := ts.Val.Sub(val.Val)
+			retVal = NewSQInterval(0, int(diff/day), diff%day)
+			return
+		}
+		var ok bool
+		retVal, ok = compareOp(op, compareTimes(ts.Val, val.Val))
+		if !ok {
+			err = sqerr.NewSyntax("Invalid Timestamp Operator " + tokens.IDName(op))
+		}
+	default:
+		err = sqerr.New("Type Mismatch: " + v.String() + " is not a Timestamp")
+	}
+	return
+}
+
+// Convert returns the value converted to the given type
+func (ts SQTimestamp) Convert(newtype tokens.TokenID) (retVal Value, err error) {
+	switch newtype {
+	case tokens.Timestamp:
+		retVal = ts
+	case tokens.Date:
+		retVal = NewSQDate(ts.Val)
+	case tokens.Time:
+		retVal = NewSQTime(sinceMidnight(ts.Val))
+	case tokens.String:
+		retVal = NewSQString(ts.String())
+	default:
+		err = sqerr.Newf("A value of type %s can not be converted to type %s", tokens.IDName(ts.Type()), tokens.IDName(newtype))
+	}
+	return
+}
+
+// NewSQTimestamp - creates a new SQTimestamp value
+func NewSQTimestamp(t time.Time) Value {
+	return SQTimestamp{t.UTC()}
+}
+
+// Clone creates a deep copy of the Value
+func (ts SQTimestamp) Clone() Value {
+	return NewSQTimestamp(ts.Val)
+}
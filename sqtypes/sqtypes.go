@@ -2,9 +2,11 @@ package sqtypes
 
 import (
 	"fmt"
+	"math/big"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -19,6 +21,15 @@ const (
 	SQStringWidth = 30
 	SQBoolWidth   = 6
 	SQFloatWidth  = 24
+
+	SQDateWidth      = 10
+	SQTimeWidth      = 18
+	SQTimestampWidth = 29
+	SQIntervalWidth  = 30
+	SQDecimalWidth   = 24
+	SQBlobWidth      = 30
+	SQJSONWidth      = 40
+	SQUUIDWidth      = 36
 )
 
 // Value TypeIDs
@@ -29,6 +40,14 @@ const (
 	SQBoolTrueType
 	SQBoolFalseType
 	SQFloatType
+	SQDateType
+	SQTimeType
+	SQTimestampType
+	SQIntervalType
+	SQDecimalType
+	SQBlobType
+	SQJSONType
+	SQUUIDType
 )
 
 // Value interface - All Values must be Immutable
@@ -59,6 +78,14 @@ func init() {
 	sqbin.RegisterType("SQBoolTrue", SQBoolTrueType)
 	sqbin.RegisterType("SQBoolFalse", SQBoolFalseType)
 	sqbin.RegisterType("SQFloat", SQFloatType)
+	sqbin.RegisterType("SQDate", SQDateType)
+	sqbin.RegisterType("SQTime", SQTimeType)
+	sqbin.RegisterType("SQTimestamp", SQTimestampType)
+	sqbin.RegisterType("SQInterval", SQIntervalType)
+	sqbin.RegisterType("SQDecimal", SQDecimalType)
+	sqbin.RegisterType("SQBlob", SQBlobType)
+	sqbin.RegisterType("SQJSON", SQJSONType)
+	sqbin.RegisterType("SQUUID", SQUUIDType)
 
 }
 
@@ -88,6 +115,34 @@ func ReadValue(c *sqbin.Codec) Value {
 	case SQFloatType:
 		fp := c.ReadFloat()
 		ret = NewSQFloat(fp)
+	case SQDateType:
+		days := c.ReadInt64()
+		ret = NewSQDate(time.Unix(days*int64(day/time.Second), 0))
+	case SQTimeType:
+		d := c.ReadInt64()
+		ret = NewSQTime(time.Duration(d))
+	case SQTimestampType:
+		secs := c.ReadInt64()
+		nsec := c.ReadInt()
+		ret = NewSQTimestamp(time.Unix(secs, int64(nsec)))
+	case SQIntervalType:
+		months := c.ReadInt()
+		days := c.ReadInt()
+		d := c.ReadInt64()
+		ret = NewSQInterval(months, days, time.Duration(d))
+	case SQDecimalType:
+		scale := c.ReadInt()
+		val, ok := new(big.Int).SetString(c.ReadString(), 10)
+		if !ok {
+			log.Panic("Invalid Decimal value")
+		}
+		ret = NewSQDecimal(val, scale)
+	case SQBlobType:
+		ret = NewSQBlob(c.ReadBytes())
+	case SQJSONType:
+		ret = SQJSON{c.ReadString()}
+	case SQUUIDType:
+		ret = NewSQUUID(c.ReadUUID())
 	default:
 		log.Panicf("Unknown Value TypeID %d", b)
 	}
@@ -118,6 +173,8 @@ func CreateValueFromToken(tkn tokens.Token) (Value, error) {
 	case tokens.Quote:
 		val := tkn.(*tokens.ValueToken).Value()
 		retVal = NewSQString(val)
+	case tokens.Hex:
+		return ParseHex(tkn.(*tokens.ValueToken).Value())
 	case tokens.RWTrue:
 		retVal = NewSQBool(true)
 	case tokens.RWFalse:
@@ -5,6 +5,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -39,6 +40,12 @@ func TestInterfaces(t *testing.T) {
 		{"SQBool is a Value", sqtypes.SQBool{}},
 		{"SQNull is a Value", sqtypes.SQNull{}},
 		{"SQFloat is a Value", sqtypes.SQFloat{}},
+		{"SQDate is a Value", sqtypes.SQDate{}},
+		{"SQTime is a Value", sqtypes.SQTime{}},
+		{"SQTimestamp is a Value", sqtypes.SQTimestamp{}},
+		{"SQInterval is a Value", sqtypes.SQInterval{}},
+		{"SQDecimal is a Value", sqtypes.SQDecimal{}},
+		{"SQBlob is a Value", sqtypes.SQBlob{}},
 	}
 
 	for i, row := range data {
@@ -718,6 +725,9 @@ func TestCreateValueFromToken(t *testing.T) {
 		{"Bool TRUE Test", tokens.GetWordToken(tokens.RWTrue), "", tokens.Bool},
 		{"Bool FALSE Test", tokens.GetWordToken(tokens.RWFalse), "", tokens.Bool},
 		{"Null Test", tokens.GetWordToken(tokens.Null), "", tokens.Null},
+		{"Hex Test", tokens.NewValueToken(tokens.Hex, "DEADbeef"), "", tokens.Blob},
+		{"Hex Test invalid digit", tokens.NewValueToken(tokens.Hex, "DEADBEEG"), "Syntax Error: x'DEADBEEG' is not a valid hex value", tokens.Blob},
+		{"Hex Test odd length", tokens.NewValueToken(tokens.Hex, "ABC"), "Syntax Error: x'ABC' is not a valid hex value", tokens.Blob},
 		{"Not A Value Token Test", tokens.NewValueToken(tokens.Ident, "This Is a test"), "Internal Error: \"[IDENT=This Is a test]\" is not a valid Value", tokens.String},
 	}
 
@@ -763,6 +773,9 @@ func TestRawValue(t *testing.T) {
 		{Name: "Float", ExpPanic: "", Arg: 123.4, expVal: sqtypes.NewSQFloat(123.4)},
 		{Name: "Float32", ExpPanic: "", Arg: float32(123.0), expVal: sqtypes.NewSQFloat(123.0)},
 		{Name: "Float64", ExpPanic: "", Arg: float64(123.4), expVal: sqtypes.NewSQFloat(123.4)},
+		{Name: "Time", ExpPanic: "", Arg: time.Date(2020, 1, 31, 10, 0, 0, 0, time.UTC), expVal: sqtypes.NewSQTimestamp(time.Date(2020, 1, 31, 10, 0, 0, 0, time.UTC))},
+		{Name: "Bytes", ExpPanic: "", Arg: []byte{0xDE, 0xAD}, expVal: sqtypes.NewSQBlob([]byte{0xDE, 0xAD})},
+		{Name: "Value", ExpPanic: "", Arg: sqtypes.NewSQInterval(1, 2, 0), expVal: sqtypes.NewSQInterval(1, 2, 0)},
 		{Name: "Invalid", ExpPanic: "sqtypes_test.RawValueData is not a valid Raw SQ type", Arg: RawValueData{}, expVal: sqtypes.NewSQFloat(123.4)},
 	}
 
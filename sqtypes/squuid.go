@@ -0,0 +1,157 @@
+package sqtypes
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/wilphi/sqsrv/sqbin"
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// SQUUID - 16 byte universally unique identifier type for SQ. Values are ordered by their bytes
+type SQUUID struct {
+	Val [16]byte
+}
+
+// SQUUID Methods & Functions  =========================================
+
+// String - return string representation of type in the canonical form e.g. a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11
+func (u SQUUID) String() string {
+	var b [36]byte
+
+	hex.Encode(b[0:8], u.Val[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u.Val[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u.Val[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u.Val[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u.Val[10:])
+	return string(b[:])
+}
+
+// Type - returns the type
+func (u SQUUID) Type() tokens.TokenID {
+	return tokens.UUID
+}
+
+// Len -
+func (u SQUUID) Len() int {
+	return SQUUIDWidth
+}
+
+// Equal - true if values are the same. type mismatch will return false
+func (u SQUUID) Equal(v Value) bool {
+	vu, ok := v.(SQUUID)
+	return ok && u.Val == vu.Val
+}
+
+// LessThan -
+func (u SQUUID) LessThan(v Value) bool {
+	if v.IsNull() {
+		return true
+	}
+	vu, ok := v.(SQUUID)
+	return ok && bytes.Compare(u.Val[:], vu.Val[:]) < 0
+}
+
+// GreaterThan -
+func (u SQUUID) GreaterThan(v Value) bool {
+	if v.IsNull() {
+		return false
+	}
+	vu, ok := v.(SQUUID)
+	return ok && bytes.Compare(u.Val[:], vu.Val[:]) > 0
+}
+
+// IsNull - Is the value Null or not
+func (u SQUUID) IsNull() bool {
+	return false
+}
+
+// Write returns a binary representation of the value
+func (u SQUUID) Write(c *sqbin.Codec) {
+	c.Writebyte(SQUUIDType)
+	c.WriteUUID(u.Val)
+}
+
+// Operation transforms two SQUUID values based on given operator. Only comparisons are allowed
+func (u SQUUID) Operation(op tokens.TokenID, v Value) (retVal Value, err error) {
+	vu, ok := v.(SQUUID)
+	if !ok {
+		if v.IsNull() {
+			retVal = v
+			return
+		}
+		err = sqerr.Newf("Type Mismatch: %s is not a UUID", v.String())
+		return
+	}
+	retVal, ok = compareOp(op, bytes.Compare(u.Val[:], vu.Val[:]))
+	if !ok {
+		err = sqerr.NewSyntax("Invalid UUID Operator " + tokens.IDName(op))
+	}
+	return
+}
+
+// Convert returns the value converted to the given type. A BLOB is the 16 bytes of the value
+func (u SQUUID) Convert(newtype tokens.TokenID) (retVal Value, err error) {
+	switch newtype {
+	case tokens.UUID:
+		retVal = u
+	case tokens.String:
+		retVal = NewSQString(u.String())
+	case tokens.Blob:
+		val := make([]byte, len(u.Val))
+		copy(val, u.Val[:])
+		retVal = NewSQBlob(val)
+	default:
+		err = sqerr.Newf("A value of type %s can not be converted to type %s", tokens.IDName(u.Type()), tokens.IDName(newtype))
+	}
+	return
+}
+
+// NewSQUUID - creates a new SQUUID value
+func NewSQUUID(u [16]byte) Value {
+	return SQUUID{u}
+}
+
+// Clone creates a deep copy of the Value
+func (u SQUUID) Clone() Value {
+	return SQUUID{u.Val}
+}
+
+// ParseUUID converts a string in the canonical form into a SQUUID. Upper case hex digits and
+//   enclosing braces are also accepted
+func ParseUUID(s string) (Value, error) {
+	var u [16]byte
+
+	str := s
+	if strings.HasPrefix(str, "{") && strings.HasSuffix(str, "}") {
+		str = str[1 : len(str)-1]
+	}
+	if len(str) != 36 || str[8] != '-' || str[13] != '-' || str[18] != '-' || str[23] != '-' {
+		return nil, sqerr.Newf("Unable to Convert %q to a UUID", s)
+	}
+	digits := str[0:8] + str[9:13] + str[14:18] + str[19:23] + str[24:]
+	if _, err := hex.Decode(u[:], []byte(digits)); err != nil {
+		return nil, sqerr.Newf("Unable to Convert %q to a UUID", s)
+	}
+	return NewSQUUID(u), nil
+}
+
+// RandomUUID returns a new version 4 UUID made from random bytes
+func RandomUUID() (Value, error) {
+	var u [16]byte
+
+	if _, err := rand.Read(u[:]); err != nil {
+		return nil, sqerr.Newf("Unable to generate a UUID: %s", err)
+	}
+	// Set the version to 4 and the variant to RFC 4122
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return NewSQUUID(u), nil
+}
@@ -0,0 +1,85 @@
+package sqtypes_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wilphi/sqsrv/sqtypes"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+func TestSQUUID(t *testing.T) {
+	a := mustConvert("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", tokens.UUID)
+	equalA := mustConvert("{A0EEBC99-9C0B-4EF8-BB6D-6BB9BD380A11}", tokens.UUID)
+	b := mustConvert("b0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", tokens.UUID)
+	t.Run("Type Test", testValueType(a, tokens.UUID))
+	t.Run("To String Test", testValueString(equalA, "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"))
+	t.Run("GetLen Test", testGetLen(a, sqtypes.SQUUIDWidth))
+	t.Run("Equal Test:equal", testEqual(a, equalA, true))
+	t.Run("Equal Test:not equal", testEqual(a, b, false))
+	t.Run("Equal Test:string", testEqual(a, sqtypes.NewSQString(a.String()), false))
+	t.Run("LessThan Test:true", testLessThan(a, b, true))
+	t.Run("LessThan Test:false", testLessThan(b, a, false))
+	t.Run("LessThan Test:Null", testLessThan(a, sqtypes.NewSQNull(), true))
+	t.Run("GreaterThan Test:true", testGreaterThan(b, a, true))
+	t.Run("GreaterThan Test:false", testGreaterThan(a, equalA, false))
+	t.Run("GreaterThan Test:Null", testGreaterThan(a, sqtypes.NewSQNull(), false))
+	t.Run("IsNull", testisNull(a, false))
+	t.Run("Write/Read", testWriteRead(a))
+	t.Run("Negate", testNegate(a, a, "sqtypes.SQUUID is not Negatable"))
+	t.Run("Clone Test", testClone(a))
+	data := []OperationData{
+		{name: "uuid=uuid : true", a: a, b: equalA, op: tokens.Equal, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "uuid!=uuid : true", a: a, b: b, op: tokens.NotEqual, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "uuid<uuid : true", a: a, b: b, op: tokens.LessThan, ExpVal: sqtypes.NewSQBool(true)},
+		{name: "uuid>=uuid : false", a: a, b: b, op: tokens.GreaterThanEqual, ExpVal: sqtypes.NewSQBool(false)},
+		{name: "uuid+uuid", a: a, b: b, op: tokens.Plus, ExpErr: "Syntax Error: Invalid UUID Operator +"},
+		{name: "Null Value", a: a, b: sqtypes.NewSQNull(), op: tokens.Equal, ExpVal: sqtypes.NewSQNull()},
+		{name: "Type Mismatch string", a: a, b: sqtypes.NewSQString("test"), op: tokens.Equal, ExpErr: "Error: Type Mismatch: test is not a UUID"},
+	}
+	for _, row := range data {
+		t.Run(row.name, testOperation(row))
+	}
+}
+
+func TestUUIDConvert(t *testing.T) {
+	uuid := mustConvert("00112233-4455-6677-8899-aabbccddeeff", tokens.UUID)
+	bytes := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	data := []ConvertData{
+		{TestName: "String to UUID", V: sqtypes.NewSQString("00112233-4455-6677-8899-AABBCCDDEEFF"), NewType: tokens.UUID, ExpVal: uuid},
+		{TestName: "String missing hyphens to UUID", V: sqtypes.NewSQString("00112233445566778899aabbccddeeff"), NewType: tokens.UUID, ExpErr: "Error: Unable to Convert \"00112233445566778899aabbccddeeff\" to a UUID"},
+		{TestName: "String invalid digit to UUID", V: sqtypes.NewSQString("0011223g-4455-6677-8899-aabbccddeeff"), NewType: tokens.UUID, ExpErr: "Error: Unable to Convert \"0011223g-4455-6677-8899-aabbccddeeff\" to a UUID"},
+		{TestName: "UUID to String", V: uuid, NewType: tokens.String, ExpVal: "00112233-4455-6677-8899-aabbccddeeff"},
+		{TestName: "UUID to Blob", V: uuid, NewType: tokens.Blob, ExpVal: bytes},
+		{TestName: "Blob to UUID", V: sqtypes.NewSQBlob(bytes), NewType: tokens.UUID, ExpVal: uuid},
+		{TestName: "Short Blob to UUID", V: sqtypes.NewSQBlob(bytes[:4]), NewType: tokens.UUID, ExpErr: "Error: Unable to Convert x'00112233' to a UUID"},
+		{TestName: "UUID to Int", V: uuid, NewType: tokens.Int, ExpErr: "Error: A value of type UUID can not be converted to type INT"},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testConvertFunc(row))
+
+	}
+}
+
+func TestRandomUUID(t *testing.T) {
+	a, err := sqtypes.RandomUUID()
+	if err != nil {
+		t.Fatalf("Unexpected Error: %s", err)
+	}
+	b, err := sqtypes.RandomUUID()
+	if err != nil {
+		t.Fatalf("Unexpected Error: %s", err)
+	}
+	if a.Equal(b) {
+		t.Errorf("Random UUIDs %s and %s are the same", a.String(), b.String())
+	}
+	u := a.(sqtypes.SQUUID)
+	if u.Val[6]>>4 != 4 || u.Val[8]>>6 != 2 {
+		t.Errorf("Random UUID %s is not a version 4 UUID", a.String())
+	}
+	if _, err := sqtypes.ParseUUID(a.String()); err != nil {
+		t.Errorf("Random UUID %s can not be parsed: %s", a.String(), err)
+	}
+}
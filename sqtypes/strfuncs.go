@@ -0,0 +1,77 @@
+package sqtypes
+
+import (
+	"unicode/utf8"
+
+	"github.com/wilphi/sqsrv/sqerr"
+	"github.com/wilphi/sqsrv/tokens"
+)
+
+// Length returns the number of characters in a STRING or the number of bytes in a BLOB
+func Length(v Value) (Value, error) {
+	if v.IsNull() {
+		return v, nil
+	}
+	switch val := v.(type) {
+	case SQString:
+		return NewSQInt(utf8.RuneCountInString(val.Val)), nil
+	case SQBlob:
+		return NewSQInt(len(val.Val)), nil
+	}
+	return nil, sqerr.Newf("LENGTH can only be used with STRING or BLOB values not %s", tokens.IDName(v.Type()))
+}
+
+// Substr returns part of a STRING or BLOB starting at the 1 based position start. If count is given
+//   at most count characters or bytes are returned otherwise the rest of the value is returned
+func Substr(v, start Value, count ...Value) (Value, error) {
+	if v.IsNull() || start.IsNull() || (len(count) > 0 && count[0].IsNull()) {
+		return NewSQNull(), nil
+	}
+	startInt, ok := start.(SQInt)
+	if !ok {
+		return nil, sqerr.Newf("The start position of SUBSTR must be an INT not %s", tokens.IDName(start.Type()))
+	}
+	var size int
+	switch val := v.(type) {
+	case SQString:
+		size = utf8.RuneCountInString(val.Val)
+	case SQBlob:
+		size = len(val.Val)
+	default:
+		return nil, sqerr.Newf("SUBSTR can only be used with STRING or BLOB values not %s", tokens.IDName(v.Type()))
+	}
+
+	// Positions before the start of the value still count towards the number returned
+	from := startInt.Val - 1
+	to := size
+	if len(count) > 0 {
+		countInt, ok := count[0].(SQInt)
+		if !ok {
+			return nil, sqerr.Newf("The length of SUBSTR must be an INT not %s", tokens.IDName(count[0].Type()))
+		}
+		if countInt.Val < 0 {
+			return nil, sqerr.New("The length of SUBSTR can not be negative")
+		}
+		if from+countInt.Val < to {
+			to = from + countInt.Val
+		}
+	}
+	from = clamp(from, 0, size)
+	to = clamp(to, from, size)
+
+	if str, ok := v.(SQString); ok {
+		return NewSQString(string([]rune(str.Val)[from:to])), nil
+	}
+	return NewSQBlob(v.(SQBlob).Val[from:to]), nil
+}
+
+// clamp limits n to be between min and max
+func clamp(n, min, max int) int {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
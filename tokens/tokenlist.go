@@ -66,6 +66,34 @@ func (tl *TokenList) String() string {
 	return output
 }
 
+// SQL - returns the list as SQL text. Tokenizing the text will recreate the list
+func (tl *TokenList) SQL() string {
+	var b strings.Builder
+
+	for i, tkn := range tl.tkns {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		switch v := tkn.(type) {
+		case *ValueToken:
+			switch {
+			case v.ID() == Quote && strings.Contains(v.Value(), "\""):
+				// Use single quotes so that the double quotes are kept
+				b.WriteString("'" + strings.ReplaceAll(v.Value(), "'", "''") + "'")
+			case v.ID() == Quote:
+				b.WriteString("\"" + v.Value() + "\"")
+			case v.ID() == Hex:
+				b.WriteString("x'" + v.Value() + "'")
+			default:
+				b.WriteString(v.Value())
+			}
+		default:
+			b.WriteString(tkn.String())
+		}
+	}
+	return b.String()
+}
+
 // TestTkn - Test a token to see if it matches one of the tknNames.
 //  Returns the token if matched otherwise nil
 //  If there are no more tokens in list nil is returned as well
@@ -103,6 +131,46 @@ func (tl *TokenList) IsARemove(tkn TokenID) bool {
 	return false
 }
 
+// IsAKeyword - tests to see if the first token is an identifier that matches the non reserved keyword.
+//   Non reserved keywords are only special in some clauses and can still be used as names elsewhere.
+func (tl *TokenList) IsAKeyword(word string) bool {
+	if len(tl.tkns) > 0 {
+		if vtkn, ok := tl.tkns[0].(*ValueToken); ok && vtkn.ID() == Ident {
+			return strings.EqualFold(vtkn.Value(), word)
+		}
+	}
+	return false
+}
+
+// IsAKeywordRemove - tests to see if the first token matches the non reserved keyword. If so then removes it.
+func (tl *TokenList) IsAKeywordRemove(word string) bool {
+	if tl.IsAKeyword(word) {
+		tl.Remove()
+		return true
+	}
+	return false
+}
+
+// Keyword returns the token of the non reserved word that is the first token in the list. If the first
+//   token is not an identifier that matches a non reserved word then nil is returned
+func (tl *TokenList) Keyword() Token {
+	if vtkn, ok := tl.Peek().(*ValueToken); ok && vtkn.ID() == Ident {
+		return keywordMap[strings.ToUpper(vtkn.Value())]
+	}
+	return nil
+}
+
+// UseKeyword replaces the identifier at the head of the list with the token of the non reserved word that
+//   it matches if the token has all of the flags in mask. Returns true if the identifier was replaced
+func (tl *TokenList) UseKeyword(mask TokenFlags) bool {
+	kw := tl.Keyword()
+	if kw == nil || !kw.TestFlags(mask) {
+		return false
+	}
+	tl.tkns[0] = kw
+	return true
+}
+
 // IsReservedWord - checks to see if the first token in list is a reserved word token
 func (tl *TokenList) IsReservedWord() bool {
 	if len(tl.tkns) > 0 {
@@ -283,3 +283,181 @@ func testIsARemoveFunc(d IsARemoveData) func(t *testing.T) {
 		}
 	}
 }
+
+func TestIsAKeyword(t *testing.T) {
+
+	data := []IsAKeywordData{
+		{
+			TestName: "Empty List",
+			TestStr:  "",
+			Word:     "FIRST",
+			IsAret:   false,
+			ExpList:  "",
+		},
+		{
+			TestName: "Matching Keyword",
+			TestStr:  "first 10 rows",
+			Word:     "FIRST",
+			IsAret:   true,
+			ExpList:  "[NUM=10] [IDENT=rows]",
+		},
+		{
+			TestName: "Different Keyword",
+			TestStr:  "next 10 rows",
+			Word:     "FIRST",
+			IsAret:   false,
+			ExpList:  "[IDENT=next] [NUM=10] [IDENT=rows]",
+		},
+		{
+			TestName: "Quoted string",
+			TestStr:  "\"first\" 10 rows",
+			Word:     "FIRST",
+			IsAret:   false,
+			ExpList:  "[QUOTE=first] [NUM=10] [IDENT=rows]",
+		},
+		{
+			TestName: "Reserved Word",
+			TestStr:  "SELECT * from test",
+			Word:     "SELECT",
+			IsAret:   false,
+			ExpList:  "SELECT * FROM [IDENT=test]",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName),
+			testIsAKeywordFunc(row))
+
+	}
+
+}
+
+type IsAKeywordData struct {
+	TestName string
+	TestStr  string
+	Word     string
+	IsAret   bool
+	ExpList  string
+}
+
+func testIsAKeywordFunc(d IsAKeywordData) func(t *testing.T) {
+	return func(t *testing.T) {
+		defer sqtest.PanicTestRecovery(t, "")
+
+		tkns := tokens.Tokenize(d.TestStr)
+
+		if tkns.IsAKeyword(d.Word) != d.IsAret {
+			t.Errorf("IsAKeyword(%s) returned %t when it should not have", d.Word, !d.IsAret)
+			return
+		}
+		if tkns.IsAKeywordRemove(d.Word) != d.IsAret {
+			t.Errorf("IsAKeywordRemove(%s) returned %t when it should not have", d.Word, !d.IsAret)
+			return
+		}
+		if tkns.String() != d.ExpList {
+			t.Errorf("Token list %q does not match expected list %q", tkns.String(), d.ExpList)
+			return
+		}
+	}
+}
+
+func TestUseKeyword(t *testing.T) {
+
+	data := []struct {
+		TestName  string
+		TestStr   string
+		Mask      tokens.TokenFlags
+		IsKeyword bool
+		UseRet    bool
+		ExpList   string
+	}{
+		{
+			TestName: "Empty List",
+			TestStr:  "",
+			Mask:     tokens.IsType,
+			ExpList:  "",
+		},
+		{
+			TestName:  "Type Keyword",
+			TestStr:   "date \"2020-01-31\"",
+			Mask:      tokens.IsType,
+			IsKeyword: true,
+			UseRet:    true,
+			ExpList:   "DATE [QUOTE=2020-01-31]",
+		},
+		{
+			TestName:  "Keyword without flags",
+			TestStr:   "Time(x)",
+			Mask:      tokens.IsAggregate,
+			IsKeyword: true,
+			UseRet:    false,
+			ExpList:   "[IDENT=Time] ( [IDENT=x] )",
+		},
+		{
+			TestName: "Not a keyword",
+			TestStr:  "dates",
+			Mask:     tokens.IsType,
+			ExpList:  "[IDENT=dates]",
+		},
+		{
+			TestName: "Reserved Word",
+			TestStr:  "INT",
+			Mask:     tokens.IsType,
+			ExpList:  "INT",
+		},
+	}
+
+	for i, row := range data {
+		t.Run(fmt.Sprintf("%d: %s", i, row.TestName), func(t *testing.T) {
+			defer sqtest.PanicTestRecovery(t, "")
+
+			tkns := tokens.Tokenize(row.TestStr)
+			if (tkns.Keyword() != nil) != row.IsKeyword {
+				t.Errorf("Keyword() returned %v", tkns.Keyword())
+				return
+			}
+			if tkns.UseKeyword(row.Mask) != row.UseRet {
+				t.Errorf("UseKeyword returned %t when it should not have", !row.UseRet)
+				return
+			}
+			if tkns.String() != row.ExpList {
+				t.Errorf("Token list %q does not match expected list %q", tkns.String(), row.ExpList)
+			}
+		})
+	}
+}
+
+func TestSQL(t *testing.T) {
+	data := []struct {
+		TestName string
+		TestStr  string
+		ExpSQL   string
+	}{
+		{TestName: "Empty List", TestStr: "", ExpSQL: ""},
+		{TestName: "Select", TestStr: "select a.col1, count(*) from a where col2>=-5.5 and col3 = \"It's a test\"",
+			ExpSQL: "SELECT a . col1 , COUNT ( * ) FROM a WHERE col2 >= - 5.5 AND col3 = \"It's a test\""},
+		{TestName: "Types", TestStr: "create table x (col1 int not null, col2 string)",
+			ExpSQL: "CREATE TABLE x ( col1 INT NOT NULL , col2 STRING )"},
+		{TestName: "Single Quotes", TestStr: "select 'It''s \"quoted\"', 'abc' from a",
+			ExpSQL: "SELECT 'It''s \"quoted\"' , \"abc\" FROM a"},
+		{TestName: "Hex", TestStr: "select x'DEAD01' from a",
+			ExpSQL: "SELECT x'DEAD01' FROM a"},
+	}
+
+	for i, row := range data {
+		d := row
+		t.Run(fmt.Sprintf("%d: %s", i, d.TestName), func(t *testing.T) {
+			defer sqtest.PanicTestRecovery(t, "")
+
+			tkns := tokens.Tokenize(d.TestStr)
+			sql := tkns.SQL()
+			if sql != d.ExpSQL {
+				t.Errorf("Actual SQL %q does not match expected %q", sql, d.ExpSQL)
+				return
+			}
+			if tokens.Tokenize(sql).String() != tkns.String() {
+				t.Errorf("Tokenized SQL %q does not match the original list %q", tokens.Tokenize(sql).String(), tkns.String())
+			}
+		})
+	}
+}
@@ -9,7 +9,7 @@ import (
 )
 
 // TokenID type
-type TokenID uint8
+type TokenID uint16
 
 // TokenFlags type
 type TokenFlags uint8
@@ -31,6 +31,7 @@ const (
 	IsAggregate
 	IsNoArg
 	IsOneArg
+	IsWindow
 )
 
 //Type Lengths
@@ -107,25 +108,54 @@ func getIdentifier(r []rune) ([]rune, Token) {
 
 }
 func isQuote(ch rune) bool {
-	return (ch == '"')
+	return (ch == '"' || ch == '\'')
+}
+
+// isHexQuote checks for the start of a hex literal such as x'DEADBEEF'
+func isHexQuote(r []rune) bool {
+	return len(r) > 1 && (r[0] == 'x' || r[0] == 'X') && r[1] == '\''
+}
+
+// getHexQuote returns a HEX token with the digits between the single quotes of a hex literal
+func getHexQuote(r []rune) ([]rune, Token) {
+	//eat the x and first quote by starting at 2
+	// loop until next quote
+	for idx := 2; idx < len(r); idx++ {
+		if r[idx] == '\'' {
+			//found the end of quote
+			hexVal := string(runesToBytes(r[2:idx]))
+			r = r[idx+1:]
+			return r, NewValueToken(Hex, hexVal)
+		}
+	}
+	r = r[len(r):]
+	return r, NewValueToken(Err, "Missing End Quote")
 }
 
 func isUnderScore(ch rune) bool {
 	return (ch == '_')
 }
 
+// getQuote returns a QUOTE token with the string between double or single quotes. Two single quotes
+//   together are a single quote that is part of the string
 func getQuote(r []rune) ([]rune, Token) {
-	var quoteVal = ""
+	var quoteVal []rune
+	quote := r[0]
 
 	//eat first quote by starting at 1
-	// loop until next quote
+	// loop until next matching quote
 	for idx := 1; idx < len(r); idx++ {
-		if isQuote(r[idx]) {
+		if r[idx] == quote {
+			if quote == '\'' && idx+1 < len(r) && r[idx+1] == quote {
+				quoteVal = append(quoteVal, quote)
+				idx++
+				continue
+			}
 			//found the end of quote
-			quoteVal = string(runesToBytes(r[1:idx]))
 			r = r[idx+1:]
-			return r, NewValueToken(Quote, quoteVal)
+			return r, NewValueToken(Quote, string(runesToBytes(quoteVal)))
 		}
+		quoteVal = append(quoteVal, r[idx])
 	}
 	r = r[len(r):]
 	return r, NewValueToken(Err, "Missing End Quote")
@@ -196,6 +226,11 @@ func Tokenize(str string) *TokenList {
 			r = getWhiteSpace(r)
 			continue
 		}
+		if isHexQuote(r) {
+			r, tkn = getHexQuote(r)
+			tl.Add(tkn)
+			continue
+		}
 		if isLetter(r[0]) || isUnderScore(r[0]) {
 			r, tkn = getIdentifier(r)
 			tl.Add(tkn)
@@ -217,7 +252,15 @@ func Tokenize(str string) *TokenList {
 		}
 
 		//check to see if it is a symbol
-		// Check double char symbols first
+		// Check triple char symbols first
+		if len(r) > 2 {
+			if Symbl, isSymbl := WordMap[string(r[:3])]; isSymbl {
+				tl.Add(Symbl)
+				r = r[3:]
+				continue
+			}
+		}
+		// then double char symbols
 		if len(r) > 1 {
 			if Symbl, isSymbl := WordMap[string(r[0])+string(r[1])]; isSymbl {
 				tl.Add(Symbl)
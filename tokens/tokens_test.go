@@ -25,8 +25,8 @@ type TokenData struct {
 func allWords(mask TokenFlags) []Token {
 	var tkns []Token
 
-	for _, tkn := range wordTokens {
-		if tkn.TestFlags(mask) {
+	for id, tkn := range wordTokens {
+		if tkn.TestFlags(mask) && !nonReserved[id] {
 			tkns = append(tkns, tkn)
 		}
 	}
@@ -99,29 +99,71 @@ func TestTokenize(t *testing.T) {
 				GetWordToken(OpenBracket), NewValueToken(Ident, "col1"), GetWordToken(Comma), NewValueToken(Ident, "col2"), GetWordToken(Comma), NewValueToken(Ident, "col3"), GetWordToken(CloseBracket),
 				GetWordToken(Values), GetWordToken(OpenBracket), NewValueToken(Num, "123"), GetWordToken(Comma), NewValueToken(Err, "Missing End Quote")}),
 		},
+		{
+			TestName: "Hex literal",
+			testStr:  "Insert Into test1 (col1) values (x'DEADbeef', X'')",
+			Tokens: CreateList([]Token{GetWordToken(Insert), GetWordToken(Into), NewValueToken(Ident, "test1"),
+				GetWordToken(OpenBracket), NewValueToken(Ident, "col1"), GetWordToken(CloseBracket),
+				GetWordToken(Values), GetWordToken(OpenBracket), NewValueToken(Hex, "DEADbeef"), GetWordToken(Comma), NewValueToken(Hex, ""), GetWordToken(CloseBracket)}),
+		},
+		{
+			TestName: "Missing End Quote for hex literal",
+			testStr:  "Select x'0A0B from test1",
+			Tokens:   CreateList([]Token{GetWordToken(Select), NewValueToken(Err, "Missing End Quote")}),
+		},
+		{
+			TestName: "Single quoted strings",
+			testStr:  `Select '{"a": 1}', 'it''s', '' from test1`,
+			Tokens: CreateList([]Token{GetWordToken(Select), NewValueToken(Quote, `{"a": 1}`), GetWordToken(Comma), NewValueToken(Quote, "it's"),
+				GetWordToken(Comma), NewValueToken(Quote, ""), GetWordToken(From), NewValueToken(Ident, "test1")}),
+		},
+		{
+			TestName: "Missing End Quote for single quoted string",
+			testStr:  "Select 'abc\" from test1",
+			Tokens:   CreateList([]Token{GetWordToken(Select), NewValueToken(Err, "Missing End Quote")}),
+		},
+		{
+			TestName: "JSON operators",
+			testStr:  "Select doc->'a'->>0, a-b from test1",
+			Tokens: CreateList([]Token{GetWordToken(Select), NewValueToken(Ident, "doc"), GetWordToken(Arrow), NewValueToken(Quote, "a"),
+				GetWordToken(DoubleArrow), NewValueToken(Num, "0"), GetWordToken(Comma), NewValueToken(Ident, "a"), GetWordToken(Minus),
+				NewValueToken(Ident, "b"), GetWordToken(From), NewValueToken(Ident, "test1")}),
+		},
+		{
+			TestName: "Ident starting with x",
+			testStr:  "Select xcol from test1",
+			Tokens:   CreateList([]Token{GetWordToken(Select), NewValueToken(Ident, "xcol"), GetWordToken(From), NewValueToken(Ident, "test1")}),
+		},
 		{
 			TestName: "Multi char Symbol ",
 			testStr:  " SElect * from _Table_a whEre a<=b \n",
 			Tokens:   CreateList([]Token{GetWordToken(Select), GetWordToken(Asterix), GetWordToken(From), NewValueToken(Ident, "_Table_a"), GetWordToken(Where), NewValueToken(Ident, "a"), GetWordToken(LessThanEqual), NewValueToken(Ident, "b")}),
 		},
+		{
+			TestName: "Non reserved words",
+			testStr:  "date Time TIMESTAMP interval rank lag lead partition filter length",
+			Tokens: CreateList([]Token{NewValueToken(Ident, "date"), NewValueToken(Ident, "Time"), NewValueToken(Ident, "TIMESTAMP"), NewValueToken(Ident, "interval"),
+				NewValueToken(Ident, "rank"), NewValueToken(Ident, "lag"), NewValueToken(Ident, "lead"), NewValueToken(Ident, "partition"),
+				NewValueToken(Ident, "filter"), NewValueToken(Ident, "length")}),
+		},
 		{
 			TestName: "All WordTokens ",
-			testStr:  "AND ASC AVG BEGIN BOOL BY COMMIT COUNT CREATE CROSS DELETE DESC DISTINCT DROP FALSE FLOAT FOREIGN FROM FULL GROUP HAVING INDEX INNER INSERT INT INTO JOIN KEY LEFT MAX MIN NOT NULL ON OR ORDER OUTER PRIMARY RIGHT ROLLBACK SELECT SET STRING SUM TABLE TRUE UNIQUE UPDATE VALUES WHERE \n",
+			testStr:  "ALL ALTER AND AS ASC AVG BEGIN BIGINT BLOB BOOL BY CHAR CHECK COMMIT CONSTRAINT COUNT CREATE CROSS CURRENT_DATE CURRVAL DATE_TRUNC DECIMAL DEFAULT DELETE DENSE_RANK DESC DISTINCT DROP EXCEPT EXTRACT FALSE FETCH FIRST_VALUE FLOAT FOREIGN FROM FULL GEN_RANDOM_UUID GROUP GROUPING HAVING INDEX INNER INSERT INT INTEGER INTERSECT INTO JOIN JSON JSON_ARRAYAGG JSON_EXTRACT JSON_OBJECTAGG KEY LEFT LIMIT MAX MEDIAN MERGE MIN NEXTVAL NOT NOW NULL OFFSET ON OR ORDER OUTER OVER PERCENTILE_CONT PERCENTILE_DISC PRIMARY RECURSIVE RETURNING RIGHT ROLLBACK ROW_NUMBER SELECT SEQUENCE SET SETVAL SMALLINT STDDEV STDDEV_POP STDDEV_SAMP STRING STRING_AGG SUBSTR SUM TABLE TRUE TRUNCATE UNION UNIQUE UPDATE UUID VALUES VARCHAR VARIANCE VAR_POP VAR_SAMP VIEW WHERE WITH WITHIN \n",
 			Tokens:   CreateList(allWords(IsWord)),
 		},
 		{
 			TestName: "All Functions ",
-			testStr:  "AVG BOOL COUNT FLOAT INT MAX MIN STRING SUM\n",
+			testStr:  "AVG BLOB BOOL COUNT CURRVAL DATE_TRUNC DECIMAL DENSE_RANK EXTRACT FIRST_VALUE FLOAT GEN_RANDOM_UUID GROUPING INT JSON JSON_ARRAYAGG JSON_EXTRACT JSON_OBJECTAGG MAX MEDIAN MIN NEXTVAL NOW PERCENTILE_CONT PERCENTILE_DISC ROW_NUMBER SETVAL STDDEV STDDEV_POP STDDEV_SAMP STRING STRING_AGG SUBSTR SUM UUID VARIANCE VAR_POP VAR_SAMP\n",
 			Tokens:   CreateList(allWords(IsFunction)),
 		},
 		{
 			TestName: "All Symbols",
-			testStr:  "!= % ( ) * + , - . / : ; < <= = > >= _\n",
+			testStr:  "!= % ( ) * + , - -> ->> . / : ; < <= = > >= _\n",
 			Tokens:   CreateList(allWords(IsSymbol)),
 		},
 		{
 			TestName: "All Aggregate Functions",
-			testStr:  "AVG COUNT MAX MIN SUM \n",
+			testStr:  "AVG COUNT GROUPING JSON_ARRAYAGG JSON_OBJECTAGG MAX MEDIAN MIN PERCENTILE_CONT PERCENTILE_DISC STDDEV STDDEV_POP STDDEV_SAMP STRING_AGG SUM VARIANCE VAR_POP VAR_SAMP \n",
 			Tokens:   CreateList(allWords(IsAggregate)),
 		},
 	}
@@ -14,15 +14,16 @@ type ValueToken struct {
 
 // Value Token Constants
 const (
-	Ident = 1<<7 + iota
+	Ident = 1<<15 + iota
 	Quote
 	Num
 	Err
 	Unk
+	Hex
 )
 
 // ValueTokenNames contains the list of value token names
-var valueTokenNames = map[TokenID]string{Ident: "IDENT", Quote: "QUOTE", Num: "NUM", Err: "ERR", Unk: "UNK"}
+var valueTokenNames = map[TokenID]string{Ident: "IDENT", Quote: "QUOTE", Num: "NUM", Err: "ERR", Unk: "UNK", Hex: "HEX"}
 
 // ID returns the Id of the token
 func (tkn *ValueToken) ID() TokenID {
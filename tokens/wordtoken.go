@@ -77,6 +77,74 @@ const (
 	Begin
 	Commit
 	Rollback
+	Limit
+	Offset
+	Fetch
+	Union
+	Intersect
+	Except
+	All
+	With
+	Recursive
+	As
+	View
+	Over
+	Partition
+	RowNumber
+	Rank
+	DenseRank
+	Lag
+	Lead
+	FirstValue
+	Filter
+	StddevPop
+	StddevSamp
+	Stddev
+	VarPop
+	VarSamp
+	Variance
+	Median
+	PercentileCont
+	PercentileDisc
+	StringAgg
+	Within
+	Grouping
+	Date
+	Time
+	Timestamp
+	Interval
+	Now
+	CurrentDate
+	Extract
+	DateTrunc
+	Decimal
+	Blob
+	Length
+	Substr
+	JSON
+	Arrow
+	DoubleArrow
+	JSONExtract
+	JSONArrayAgg
+	JSONObjectAgg
+	Varchar
+	Char
+	SmallInt
+	Integer
+	BigInt
+	UUID
+	GenRandomUUID
+	Default
+	Sequence
+	NextVal
+	CurrVal
+	SetVal
+	Check
+	Constraint
+	Alter
+	Truncate
+	Merge
+	Returning
 )
 
 var wordNames = []string{"Invalid", "CREATE", "TABLE",
@@ -93,14 +161,37 @@ var wordNames = []string{"Invalid", "CREATE", "TABLE",
 	"FULL", "OUTER", "LEFT", "RIGHT", "CROSS",
 	"PRIMARY", "KEY", "UNIQUE", "FOREIGN", "INDEX",
 	"BEGIN", "COMMIT", "ROLLBACK",
+	"LIMIT", "OFFSET", "FETCH",
+	"UNION", "INTERSECT", "EXCEPT", "ALL",
+	"WITH", "RECURSIVE", "AS", "VIEW",
+	"OVER", "PARTITION", "ROW_NUMBER", "RANK", "DENSE_RANK",
+	"LAG", "LEAD", "FIRST_VALUE", "FILTER",
+	"STDDEV_POP", "STDDEV_SAMP", "STDDEV", "VAR_POP", "VAR_SAMP", "VARIANCE", "MEDIAN", "PERCENTILE_CONT",
+	"PERCENTILE_DISC", "STRING_AGG", "WITHIN", "GROUPING",
+	"DATE", "TIME", "TIMESTAMP", "INTERVAL", "NOW", "CURRENT_DATE", "EXTRACT", "DATE_TRUNC",
+	"DECIMAL",
+	"BLOB",
+	"LENGTH",
+	"SUBSTR",
+	"JSON", "->", "->>", "JSON_EXTRACT", "JSON_ARRAYAGG", "JSON_OBJECTAGG", "VARCHAR", "CHAR", "SMALLINT", "INTEGER", "BIGINT", "UUID", "GEN_RANDOM_UUID", "DEFAULT",
+	"SEQUENCE", "NEXTVAL", "CURRVAL", "SETVAL", "CHECK", "CONSTRAINT", "ALTER", "TRUNCATE", "MERGE", "RETURNING",
 }
 
-//wordTokens -
+// wordTokens -
 var wordTokens map[TokenID]Token
 
-//WordMap will map a string to a token
+// WordMap will map a string to a token
 var WordMap map[string]Token
 
+// nonReserved are the words that are only keywords in some places. They are tokenized as identifiers
+//   so that they can be used as the names of tables and columns. The parser checks for them with
+//   Keyword or UseKeyword where they are keywords
+var nonReserved = map[TokenID]bool{Date: true, Time: true, Timestamp: true, Interval: true,
+	Rank: true, Lag: true, Lead: true, Partition: true, Filter: true, Length: true}
+
+// keywordMap will map a string to the token of a non reserved word
+var keywordMap map[string]Token
+
 // AllTypes is an array of all Type tokens
 var AllTypes []TokenID
 
@@ -175,13 +266,86 @@ func init() {
 		Begin:            newWordToken(Begin, IsWord),
 		Commit:           newWordToken(Commit, IsWord),
 		Rollback:         newWordToken(Rollback, IsWord),
+		Limit:            newWordToken(Limit, IsWord),
+		Offset:           newWordToken(Offset, IsWord),
+		Fetch:            newWordToken(Fetch, IsWord),
+		Union:            newWordToken(Union, IsWord),
+		Intersect:        newWordToken(Intersect, IsWord),
+		Except:           newWordToken(Except, IsWord),
+		All:              newWordToken(All, IsWord),
+		With:             newWordToken(With, IsWord),
+		Recursive:        newWordToken(Recursive, IsWord),
+		As:               newWordToken(As, IsWord),
+		View:             newWordToken(View, IsWord),
+		Over:             newWordToken(Over, IsWord),
+		Partition:        newWordToken(Partition, IsWord),
+		RowNumber:        newWordToken(RowNumber, IsWord|IsFunction|IsNoArg|IsWindow),
+		Rank:             newWordToken(Rank, IsWord|IsFunction|IsNoArg|IsWindow),
+		DenseRank:        newWordToken(DenseRank, IsWord|IsFunction|IsNoArg|IsWindow),
+		Lag:              newWordToken(Lag, IsWord|IsFunction|IsWindow),
+		Lead:             newWordToken(Lead, IsWord|IsFunction|IsWindow),
+		FirstValue:       newWordToken(FirstValue, IsWord|IsFunction|IsOneArg|IsWindow),
+		Filter:           newWordToken(Filter, IsWord),
+		StddevPop:        newWordToken(StddevPop, IsWord|IsFunction|IsOneArg|IsAggregate),
+		StddevSamp:       newWordToken(StddevSamp, IsWord|IsFunction|IsOneArg|IsAggregate),
+		Stddev:           newWordToken(Stddev, IsWord|IsFunction|IsOneArg|IsAggregate),
+		VarPop:           newWordToken(VarPop, IsWord|IsFunction|IsOneArg|IsAggregate),
+		VarSamp:          newWordToken(VarSamp, IsWord|IsFunction|IsOneArg|IsAggregate),
+		Variance:         newWordToken(Variance, IsWord|IsFunction|IsOneArg|IsAggregate),
+		Median:           newWordToken(Median, IsWord|IsFunction|IsOneArg|IsAggregate),
+		PercentileCont:   newWordToken(PercentileCont, IsWord|IsFunction|IsAggregate),
+		PercentileDisc:   newWordToken(PercentileDisc, IsWord|IsFunction|IsAggregate),
+		StringAgg:        newWordToken(StringAgg, IsWord|IsFunction|IsAggregate),
+		Within:           newWordToken(Within, IsWord),
+		Grouping:         newWordToken(Grouping, IsWord|IsFunction|IsOneArg|IsAggregate),
+		Date:             newWordToken(Date, IsWord|IsType|IsFunction|IsOneArg),
+		Time:             newWordToken(Time, IsWord|IsType|IsFunction|IsOneArg),
+		Timestamp:        newWordToken(Timestamp, IsWord|IsType|IsFunction|IsOneArg),
+		Interval:         newWordToken(Interval, IsWord|IsType|IsFunction|IsOneArg),
+		Now:              newWordToken(Now, IsWord|IsFunction|IsNoArg),
+		CurrentDate:      newWordToken(CurrentDate, IsWord),
+		Extract:          newWordToken(Extract, IsWord|IsFunction),
+		DateTrunc:        newWordToken(DateTrunc, IsWord|IsFunction),
+		Decimal:          newWordToken(Decimal, IsWord|IsType|IsFunction|IsOneArg),
+		Blob:             newWordToken(Blob, IsWord|IsType|IsFunction|IsOneArg),
+		Length:           newWordToken(Length, IsWord|IsFunction|IsOneArg),
+		Substr:           newWordToken(Substr, IsWord|IsFunction),
+		JSON:             newWordToken(JSON, IsWord|IsType|IsFunction|IsOneArg),
+		Arrow:            newWordToken(Arrow, IsSymbol),
+		DoubleArrow:      newWordToken(DoubleArrow, IsSymbol),
+		JSONExtract:      newWordToken(JSONExtract, IsWord|IsFunction),
+		JSONArrayAgg:     newWordToken(JSONArrayAgg, IsWord|IsFunction|IsOneArg|IsAggregate),
+		JSONObjectAgg:    newWordToken(JSONObjectAgg, IsWord|IsFunction|IsAggregate),
+		Varchar:          newWordToken(Varchar, IsWord),
+		Char:             newWordToken(Char, IsWord),
+		SmallInt:         newWordToken(SmallInt, IsWord),
+		Integer:          newWordToken(Integer, IsWord),
+		BigInt:           newWordToken(BigInt, IsWord),
+		UUID:             newWordToken(UUID, IsWord|IsType|IsFunction|IsOneArg),
+		GenRandomUUID:    newWordToken(GenRandomUUID, IsWord|IsFunction|IsNoArg),
+		Default:          newWordToken(Default, IsWord),
+		Sequence:         newWordToken(Sequence, IsWord),
+		NextVal:          newWordToken(NextVal, IsWord|IsFunction),
+		CurrVal:          newWordToken(CurrVal, IsWord|IsFunction),
+		SetVal:           newWordToken(SetVal, IsWord|IsFunction),
+		Check:            newWordToken(Check, IsWord),
+		Constraint:       newWordToken(Constraint, IsWord),
+		Alter:            newWordToken(Alter, IsWord),
+		Truncate:         newWordToken(Truncate, IsWord),
+		Merge:            newWordToken(Merge, IsWord),
+		Returning:        newWordToken(Returning, IsWord),
 	}
 	// create the word map of reserved words and symbols
 	// making sure that all words are uppercase
 	WordMap = make(map[string]Token)
+	keywordMap = make(map[string]Token)
 	for i, word := range wordTokens {
 		if i != 0 {
-			WordMap[strings.ToUpper(wordNames[i])] = word
+			if nonReserved[i] {
+				keywordMap[strings.ToUpper(wordNames[i])] = word
+			} else {
+				WordMap[strings.ToUpper(wordNames[i])] = word
+			}
 
 			// Add any type tokens to AllTypes
 			if word.TestFlags(IsType) {
@@ -189,6 +353,9 @@ func init() {
 			}
 		}
 	}
+	// Words that are the same as another word
+	WordMap["NUMERIC"] = wordTokens[Decimal]
+	WordMap["BYTES"] = wordTokens[Blob]
 
 }
 
@@ -214,7 +381,8 @@ func (tkn *WordToken) Name() string {
 }
 
 // String returns a string representation of the token
-//   This may or may not be the same as the token Name
+//
+//	This may or may not be the same as the token Name
 func (tkn *WordToken) String() string {
 	return wordNames[tkn.tokenID]
 }